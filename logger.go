@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 )
 
 const (
@@ -32,7 +33,8 @@ type Level uint32
 // LeveledLogger is a leveled logger implementation.
 //
 // It prints warnings and errors to `os.Stderr` and other messages to
-// `os.Stdout`.
+// `os.Stdout`. If LogFile is set, every message is also appended to it once
+// the logger has been opened with Open.
 type LeveledLogger struct {
 	// Level is the minimum logging level that will be emitted by this logger.
 	//
@@ -43,6 +45,18 @@ type LeveledLogger struct {
 	// values are not guaranteed to be stable.
 	Level Level
 
+	// LogFile is the path of a file to which messages are written in
+	// addition to stdout and stderr. It's only used after calling Open.
+	LogFile string
+
+	// LogFileMaxSize is the size in bytes past which LogFile is rotated. The
+	// current file is renamed with a ".1" suffix (replacing any previous
+	// one), and a new one is started. Zero disables rotation.
+	LogFileMaxSize int64
+
+	// file is LogFile once it's been opened. Nil if LogFile is empty.
+	file *rotatingFile
+
 	// Internal testing use only.
 	stderrOverride io.Writer
 	stdoutOverride io.Writer
@@ -77,20 +91,57 @@ func (l *LeveledLogger) Warnf(format string, v ...interface{}) {
 	}
 }
 
+// Open opens LogFile for appending if it's set. It should be called before
+// any messages are logged, and paired with a call to Close.
+func (l *LeveledLogger) Open() error {
+	if l.LogFile == "" {
+		return nil
+	}
+
+	file, err := openRotatingFile(l.LogFile, l.LogFileMaxSize)
+	if err != nil {
+		return err
+	}
+
+	l.file = file
+	return nil
+}
+
+// Close closes LogFile if it was opened.
+func (l *LeveledLogger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
 func (l *LeveledLogger) stderr() io.Writer {
+	var w io.Writer = os.Stderr
 	if l.stderrOverride != nil {
-		return l.stderrOverride
+		w = l.stderrOverride
 	}
 
-	return os.Stderr
+	return l.withFile(w)
 }
 
 func (l *LeveledLogger) stdout() io.Writer {
+	var w io.Writer = os.Stdout
 	if l.stdoutOverride != nil {
-		return l.stdoutOverride
+		w = l.stdoutOverride
+	}
+
+	return l.withFile(w)
+}
+
+func (l *LeveledLogger) withFile(w io.Writer) io.Writer {
+	if l.file == nil {
+		return w
 	}
 
-	return os.Stdout
+	return io.MultiWriter(w, l.file)
 }
 
 // LeveledLoggerInterface provides a basic leveled logging interface for
@@ -112,3 +163,77 @@ type LeveledLoggerInterface interface {
 	// Warnf logs a warning message using Printf conventions.
 	Warnf(format string, v ...interface{})
 }
+
+// rotatingFile is an io.Writer that appends to a file, and rotates it by
+// renaming it and opening a new one once it's grown past a maximum size. It's
+// safe for concurrent use.
+type rotatingFile struct {
+	file    *os.File
+	maxSize int64
+	mu      sync.Mutex
+	path    string
+	size    int64
+}
+
+func openRotatingFile(path string, maxSize int64) (*rotatingFile, error) {
+	f := &rotatingFile{maxSize: maxSize, path: path}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Close closes the underlying file.
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+// Write appends p to the file, rotating it first if p would take it past its
+// maximum size. A file is never rotated while empty, so a single write larger
+// than the maximum size still goes through.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error checking log file size: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("error closing log file: %w", err)
+	}
+
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		return fmt.Errorf("error rotating log file: %w", err)
+	}
+
+	return f.open()
+}
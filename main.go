@@ -1,20 +1,46 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"embed"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"html"
+	"html/template"
+	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	texttemplate "text/template"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/brandur/wanikaniapi"
 	"github.com/dghubble/go-twitter/twitter"
@@ -22,6 +48,8 @@ import (
 	"github.com/joeshaw/envdecode"
 	"github.com/pelletier/go-toml"
 	"github.com/spf13/cobra"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
 )
 
 //////////////////////////////////////////////////////////////////////////////
@@ -34,11 +62,380 @@ import (
 //
 //////////////////////////////////////////////////////////////////////////////
 
+// AnnotateOptions are options that get passed into the `annotate` command.
+type AnnotateOptions struct {
+	// Notes are private notes stored in Reading.Notes. They're only stored
+	// if NotesSet is true so that other fields can be annotated without
+	// clearing them.
+	Notes    string
+	NotesSet bool
+
+	ReadingsPath string
+
+	// RecommendedBy is stored in Reading.RecommendedBy if RecommendedBySet
+	// is true.
+	RecommendedBy    string
+	RecommendedBySet bool
+
+	ReviewID int
+}
+
+// ExportHTMLOptions are options that get passed into the `export-html`
+// command.
+type ExportHTMLOptions struct {
+	GoodreadsPath string
+	TwitterPath   string
+}
+
+// ExportOPMLOptions are options that get passed into the `export-opml`
+// command.
+type ExportOPMLOptions struct {
+	GoodreadsPath string
+}
+
+// RootOptions are options that apply to every command.
+type RootOptions struct {
+	// CompactTOML causes keys with zero values (empty strings, zero numbers,
+	// empty arrays and tables) to be pruned from written TOML files.
+	CompactTOML bool
+
+	// HTTPProxy is the URL of a proxy through which API requests are routed.
+	// Requests are made directly if it's empty.
+	HTTPProxy string
+
+	// HTTPProxyURL is HTTPProxy parsed and validated before any command runs.
+	HTTPProxyURL *url.URL
+
+	// LogFile is the path of a file to which log output is appended in
+	// addition to stdout and stderr. Nothing is written to a file if it's
+	// empty.
+	LogFile string
+
+	// LogFileMaxSizeMB is the size in megabytes past which LogFile is
+	// rotated.
+	LogFileMaxSizeMB int
+
+	// OutputEncoding is the encoding of written TOML files. One of
+	// outputEncodingUTF8 (the default), outputEncodingUTF8BOM, or
+	// outputEncodingLatin1.
+	OutputEncoding string
+}
+
+// ScheduleOptions are options that get passed into the `schedule` command.
+type ScheduleOptions struct {
+	// CronUser is the user whose crontab or Scheduled Task runs the sync.
+	// If empty, it's the current user. It's ignored on macOS, where
+	// LaunchAgents always run as the user that installed them.
+	CronUser string
+
+	// IntervalHours is how often the sync runs. Cron can't express
+	// intervals of more than a day, so it's at most 24 on Linux.
+	IntervalHours int
+
+	// LogPath is the path of a file to which the output of scheduled syncs
+	// is appended. If empty, output is left to the scheduler (which for cron
+	// usually means it's mailed to the user).
+	LogPath string
+
+	// SyncAllArgs are arguments passed through to `sync-all`, like the
+	// paths of target files.
+	SyncAllArgs []string
+}
+
+// SearchOptions are options that get passed into the `search` command.
+type SearchOptions struct {
+	TwitterPath string
+}
+
+// StatsOptions are options that get passed into the `stats` command.
+type StatsOptions struct {
+	GoodreadsPath string
+
+	// HomeCountryCode is the ISO country code of the user's home country.
+	// NomadList stays there aren't counted towards days abroad. If empty,
+	// every stay is counted.
+	HomeCountryCode string
+
+	NomadListPath string
+
+	// NoReplies and NoRetweets leave replies and retweets out of tweet
+	// statistics. Retweets especially skew them, because their content
+	// belongs to another author. The data file isn't changed.
+	NoReplies  bool
+	NoRetweets bool
+
+	OvercastPath string
+
+	TwitterPath string
+}
+
 // SyncAllOptions are options that get passed into the `sync-all` command.
 type SyncAllOptions struct {
+	BeeminderPath           string
+	CalPath                 string
+	ChessPath               string
+	ClockifyPath            string
+	CloudflarePath          string
+	ExistPath               string
+	FailFast                bool
+	GoodreadsAbandonedShelf string
+	GoodreadsDateFormat     string
+	GoodreadsPath           string
+	GooglePhotosPath        string
+	LinkedInArticlesPath    string
+	LinkedInPath            string
+	MediumPath              string
+	MonzoPath               string
+	NoHTMLDecode            bool
+	NomadListPath           string
+	OuraReadinessPath       string
+	OuraSleepPath           string
+	RunkeeperPath           string
+	SteamPath               string
+	Strict                  bool
+	StripeChargesPath       string
+	StripePayoutsPath       string
+	SubstackPath            string
+	TelegramPath            string
+	TogglPath               string
+	TweetFilterRegexp       string
+	TwitterAPIV2            bool
+	TwitterLikesPath        string
+	TwitterPath             string
+	WakaTimePath            string
+	WaniKaniPath            string
+	WeatherOpenWeatherPath  string
+	WeatherPWSPath          string
+	WithingsPath            string
+}
+
+// SyncAllError is returned by `sync-all` when one or more of its syncs
+// failed, and carries the error of each one. It's not used with
+// SyncAllOptions.FailFast, where the rest of the syncs are canceled as soon as
+// one fails, and only that one's error is returned.
+type SyncAllError struct {
+	Errs []error
+}
+
+// Error joins the errors of failed syncs.
+func (e *SyncAllError) Error() string {
+	messages := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors of failed syncs so that they can be inspected
+// with errors.Is and errors.As.
+func (e *SyncAllError) Unwrap() []error {
+	return e.Errs
+}
+
+// SyncExistOptions are options that get passed into the `sync-exist` command.
+type SyncExistOptions struct {
+	// Attributes are the names of the Exist attributes to sync, like "steps"
+	// or "mood". If empty, every attribute that the user has is synced.
+	Attributes []string
+}
+
+// SyncGoodreadsChallengesOptions are options that get passed into the
+// `sync-goodreads-challenges` command.
+type SyncGoodreadsChallengesOptions struct {
+	// Key is a Goodreads API key that overrides GOODREADS_KEY.
+	Key string
+
+	// UserID is the ID of the Goodreads user whose challenges are synced, and
+	// overrides GOODREADS_ID.
+	UserID string
+}
+
+// SyncGoodreadsFriendsOptions are options that get passed into the
+// `sync-goodreads-friends` command.
+type SyncGoodreadsFriendsOptions struct {
+	// Key is a Goodreads API key that overrides GOODREADS_KEY.
+	Key string
+
+	// MaxFriends is the maximum number of friends whose readings are synced.
+	// Each friend costs an API request, and an account may have thousands.
+	MaxFriends int
+
+	// UserID is the ID of the Goodreads user whose friends are synced, and
+	// overrides GOODREADS_ID.
+	UserID string
+}
+
+// SyncGoodreadsOptions are options that get passed into the `sync-goodreads`
+// command.
+type SyncGoodreadsOptions struct {
+	// AbandonedShelf is the name of a Goodreads shelf holding books that
+	// were started but not finished. If set, its books are synced alongside
+	// those on the "read" shelf and marked as abandoned.
+	AbandonedShelf string
+
+	// APISort is the order in which the Goodreads API is asked to return
+	// reviews, which determines which of them land on each page. It's one of
+	// goodreadsSorts. If empty, goodreadsSortDateRead is used.
+	APISort string
+
+	// CacheDir is a directory in which raw responses from the Goodreads API
+	// are cached, and from which they're served on subsequent runs instead of
+	// making a request. Meant to speed up development. If empty, responses
+	// aren't cached.
+	CacheDir string
+
+	// CacheTTL is the age after which entries in CacheDir are considered
+	// stale and refetched. If zero, entries never expire.
+	CacheTTL time.Duration
+
+	// ComputeSentiment computes Reading.MeanSentiment from the text of each
+	// review.
+	ComputeSentiment bool
+
+	// CoverDownloadConcurrency is the maximum number of covers downloaded at
+	// once with CoverDownloadDir.
+	CoverDownloadConcurrency int
+
+	// CoverDownloadDir is a directory to which each book's cover image is
+	// downloaded after syncing, with its path stored in
+	// Reading.CoverLocalPath. Covers already in the directory aren't
+	// downloaded again. If empty, covers aren't downloaded.
+	CoverDownloadDir string
+
+	// DateFormat is the Go time layout with which dates from Goodreads are
+	// parsed. If empty, goodreadsTimeFormat is used.
+	DateFormat string
+
+	// Key is a Goodreads API key that overrides GOODREADS_KEY.
+	Key string
+
+	// NoHTMLDecode skips unescaping HTML entities in reviews, which can
+	// mangle code snippets that contain them.
+	NoHTMLDecode bool
+
+	// Sort is the order in which readings are written, either sortOrderAsc or
+	// sortOrderDesc (by review ID). If empty, sortOrderDesc is used.
+	Sort string
+
+	// Strict causes the sync to fail if any reviews had to be skipped because
+	// they couldn't be processed. The data file is still written.
+	Strict bool
+
+	// UserID is the ID of the Goodreads user whose reviews are synced, and
+	// overrides GOODREADS_ID.
+	UserID string
+
+	// WordsPerPage is the multiplier used to estimate Reading.WordCount from
+	// a book's number of pages. If zero, defaultWordsPerPage is used.
+	WordsPerPage int
+}
+
+// SyncTwitterOptions are options that get passed into the `sync-twitter`
+// command.
+type SyncTwitterOptions struct {
+	// APIV2 causes tweets to be enriched with metrics only available from
+	// Twitter's v2 API, like reply counts.
+	APIV2 bool
+
+	// CheckURLs causes a HEAD request to be made to every URL referenced by a
+	// tweet after syncing, and its response's status code to be stored in
+	// TweetEntitiesURL.Status.
+	CheckURLs bool
+
+	// ComputeEngagement causes Tweet.LikesByFollowers to be computed using
+	// the user's follower count at the time of the sync.
+	ComputeEngagement bool
+
+	// EngagementThreshold is the largest change in Tweet.LikesByFollowers
+	// that's considered trivial when deciding whether to keep an existing
+	// tweet over a newly fetched one.
+	EngagementThreshold float64
+
+	// EngagementWeights are the multipliers applied to a tweet's likes,
+	// retweets, replies, and bookmarks (in that order) when computing
+	// Tweet.EngagementScore. If nil, defaultEngagementWeights is used.
+	EngagementWeights []float64
+
+	// ExpandURLs causes t.co links in Tweet.Text to be replaced with the
+	// expanded URLs they point to, as found in Tweet.Entities. The entities
+	// themselves are stored unchanged so that the original links are kept.
+	ExpandURLs bool
+
+	// FetchCards causes Twitter Cards attached to tweets to be fetched and
+	// stored in Tweet.Card. Cards aren't included in timelines, so this
+	// takes an extra request for every 100 tweets.
+	FetchCards bool
+
+	// FilterRegexp is a regular expression matched against the text of
+	// tweets. Tweets that match it are left out of the data file, including
+	// ones that were stored by a previous sync.
+	FilterRegexp string
+
+	// IncludeAds causes promoted tweets (see Tweet.IsAd) to be kept. By
+	// default they're skipped.
+	IncludeAds bool
+
+	// IncludeLikes causes tweets that the user has liked to be synced as
+	// well, and written to LikesPath.
+	IncludeLikes bool
+
+	// LikesPath is the path of the data file to which liked tweets are
+	// written with IncludeLikes.
+	LikesPath string
+
+	// MergeStrategy decides which version of a tweet is kept when it's both
+	// fetched from the API and already stored: mergeStrategyPreferNew,
+	// mergeStrategyPreferExisting, or mergeStrategySmart. If empty,
+	// mergeStrategySmart is used.
+	MergeStrategy string
+
+	// MinFavorites and MinRetweets leave tweets with fewer favorites or
+	// retweets out of the data file. They're applied after merging so that a
+	// tweet that crosses a threshold on a later sync is still added. Zero
+	// disables each of them.
+	MinFavorites int
+	MinRetweets  int
+
+	// NoHTMLDecode skips unescaping HTML entities in tweet text.
+	NoHTMLDecode bool
+
+	// NoNormalizeText leaves Tweet.NormalizedText empty for users who only
+	// want tweet text exactly as the API returned it.
+	NoNormalizeText bool
+
+	// RangeEnd and RangeStart are RFC 3339 times outside of which tweets are
+	// left out of the data file, including ones stored by a previous sync.
+	// Either may be empty to leave that side of the range open.
+	RangeEnd   string
+	RangeStart string
+
+	// RangeSourcePath is an optional data file holding a fuller history of
+	// tweets than the ranged one being synced. Paging through the timeline
+	// starts and stops at its tweets just outside the range, and its tweets
+	// within the range are merged like stored ones.
+	RangeSourcePath string
+
+	// Sort is the order in which tweets are written, either sortOrderAsc or
+	// sortOrderDesc (by tweet ID). If empty, sortOrderDesc is used.
+	Sort string
+
+	// Strict causes the sync to fail if any tweets had to be skipped because
+	// they couldn't be processed. The data file is still written.
+	Strict bool
+
+	// TrivialViewThreshold is the largest change in Tweet.ViewCount that's
+	// considered trivial when deciding whether to keep an existing tweet over
+	// a newly fetched one.
+	TrivialViewThreshold int64
+
+	// URLCheckConcurrency is the maximum number of URLs checked at once with
+	// CheckURLs.
+	URLCheckConcurrency int
+}
+
+// ValidateOptions are options that get passed into the `validate` command.
+type ValidateOptions struct {
 	GoodreadsPath string
-	TwitterPath   string
-	WaniKaniPath  string
 }
 
 func main() {
@@ -49,6 +446,197 @@ func main() {
 Qself is a small tool to sync personal data from APIs down to
 local TOML files for easier portability and storage.`),
 	}
+	rootCmd.PersistentFlags().BoolVar(&rootOptions.CompactTOML,
+		"compact-toml", false, "Omit keys with zero values from written TOML files")
+	rootCmd.PersistentFlags().StringVar(&rootOptions.HTTPProxy,
+		"http-proxy", "", "URL of a proxy to route API requests through")
+	rootCmd.PersistentFlags().StringVar(&rootOptions.LogFile,
+		"log-file", "", "File to append log output to in addition to stdout and stderr")
+	rootCmd.PersistentFlags().IntVar(&rootOptions.LogFileMaxSizeMB,
+		"log-file-max-size-mb", defaultLogFileMaxSizeMB, "Size in MB past which the log file is rotated")
+	rootCmd.PersistentFlags().StringVar(&rootOptions.OutputEncoding,
+		"output-encoding", outputEncodingUTF8, "Encoding of written TOML files ('utf-8', 'utf-8-bom', or 'latin-1')")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputEncoding(rootOptions.OutputEncoding); err != nil {
+			return err
+		}
+
+		if rootOptions.HTTPProxy != "" {
+			proxyURL, err := parseHTTPProxy(rootOptions.HTTPProxy)
+			if err != nil {
+				return err
+			}
+			rootOptions.HTTPProxyURL = proxyURL
+		}
+
+		logger.LogFile = rootOptions.LogFile
+		logger.LogFileMaxSize = int64(rootOptions.LogFileMaxSizeMB) * 1024 * 1024
+		return logger.Open()
+	}
+
+	var annotateOptions AnnotateOptions
+	annotateCommand := &cobra.Command{
+		Use:   "annotate",
+		Short: "Add private notes to a reading",
+		Long: strings.TrimSpace(`
+Add private notes, or who recommended the book, to a reading in a previously
+synced Goodreads data file. Annotations are stored locally only, and are kept
+by later syncs. Only fields whose flags are given are changed.`),
+		Run: func(cmd *cobra.Command, args []string) {
+			annotateOptions.NotesSet = cmd.Flags().Changed("notes")
+			annotateOptions.RecommendedBySet = cmd.Flags().Changed("recommended-by")
+
+			if err := annotate(&annotateOptions); err != nil {
+				die(fmt.Sprintf("error annotating: %v", err))
+			}
+		},
+	}
+	annotateCommand.Flags().StringVar(&annotateOptions.Notes,
+		"notes", "", "Notes to store on the reading (replacing any existing ones)")
+	annotateCommand.Flags().StringVar(&annotateOptions.ReadingsPath,
+		"readings-path", "PATH", "Goodreads source path")
+	annotateCommand.Flags().StringVar(&annotateOptions.RecommendedBy,
+		"recommended-by", "", "Who recommended the book (replacing anyone already stored)")
+	annotateCommand.Flags().IntVar(&annotateOptions.ReviewID,
+		"review-id", 0, "Review ID of the reading to annotate")
+	rootCmd.AddCommand(annotateCommand)
+
+	var exportHTMLOptions ExportHTMLOptions
+	exportHTMLCommand := &cobra.Command{
+		Use:   "export-html [target HTML file]",
+		Short: "Export synced data to a standalone HTML file",
+		Long: strings.TrimSpace(`
+Export previously synced readings and tweets to a single HTML file with inline
+styles that can be opened in a browser without qself. Individual source files
+should be set as options.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := exportHTMLFile(args[0], &exportHTMLOptions); err != nil {
+				die(fmt.Sprintf("error exporting HTML: %v", err))
+			}
+		},
+	}
+	exportHTMLCommand.Flags().StringVar(&exportHTMLOptions.GoodreadsPath,
+		"goodreads-path", "PATH", "Goodreads source path")
+	exportHTMLCommand.Flags().StringVar(&exportHTMLOptions.TwitterPath,
+		"twitter-path", "PATH", "Twitter source path")
+	rootCmd.AddCommand(exportHTMLCommand)
+
+	var exportOPMLOptions ExportOPMLOptions
+	exportOPMLCommand := &cobra.Command{
+		Use:   "export-opml [target OPML file]",
+		Short: "Export synced readings to an OPML file",
+		Long: strings.TrimSpace(`
+Export previously synced readings to an OPML file with an outline for each book,
+which can be imported into RSS readers and other tools that take OPML reading
+lists.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := exportOPMLFile(args[0], &exportOPMLOptions); err != nil {
+				die(fmt.Sprintf("error exporting OPML: %v", err))
+			}
+		},
+	}
+	exportOPMLCommand.Flags().StringVar(&exportOPMLOptions.GoodreadsPath,
+		"goodreads-path", "PATH", "Goodreads source path")
+	rootCmd.AddCommand(exportOPMLCommand)
+
+	var scheduleOptions ScheduleOptions
+	scheduleCommand := &cobra.Command{
+		Use:   "schedule [-- sync-all flags]",
+		Short: "Schedule sync-all to run periodically",
+		Long: strings.TrimSpace(`
+Install a job that runs sync-all periodically. This is a crontab entry on
+Linux, a LaunchAgent on macOS, and a Scheduled Task on Windows. Arguments after
+'--' are passed through to sync-all. Running schedule again replaces any job
+that was previously installed.`),
+		Run: func(cmd *cobra.Command, args []string) {
+			env, err := newScheduleEnv()
+			if err != nil {
+				die(fmt.Sprintf("error installing schedule: %v", err))
+			}
+
+			scheduleOptions.SyncAllArgs = args
+			if err := scheduleInstall(cmd.Context(), env, &scheduleOptions); err != nil {
+				die(fmt.Sprintf("error installing schedule: %v", err))
+			}
+		},
+	}
+	scheduleCommand.PersistentFlags().StringVar(&scheduleOptions.CronUser,
+		"cron-user", "", "User whose crontab or Scheduled Task runs the sync (not used on macOS)")
+	scheduleCommand.Flags().IntVar(&scheduleOptions.IntervalHours,
+		"interval-hours", defaultScheduleIntervalHours, "Hours between syncs")
+	scheduleCommand.Flags().StringVar(&scheduleOptions.LogPath,
+		"log-path", "", "File to which the output of syncs is appended")
+
+	scheduleRemoveCommand := &cobra.Command{
+		Use:   "remove",
+		Short: "Remove a schedule installed by schedule",
+		Long: strings.TrimSpace(`
+Remove the job installed by schedule so that sync-all no longer runs
+periodically.`),
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			env, err := newScheduleEnv()
+			if err != nil {
+				die(fmt.Sprintf("error removing schedule: %v", err))
+			}
+
+			if err := scheduleRemove(cmd.Context(), env, &scheduleOptions); err != nil {
+				die(fmt.Sprintf("error removing schedule: %v", err))
+			}
+		},
+	}
+	scheduleCommand.AddCommand(scheduleRemoveCommand)
+	rootCmd.AddCommand(scheduleCommand)
+
+	var searchOptions SearchOptions
+	searchCommand := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search synced tweets",
+		Long: strings.TrimSpace(`
+Search previously synced tweets for text, printing every tweet that contains
+it. Case is ignored, and lookalike characters like fullwidth letters match
+their plain equivalents.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := search(os.Stdout, args[0], &searchOptions); err != nil {
+				die(fmt.Sprintf("error searching: %v", err))
+			}
+		},
+	}
+	searchCommand.Flags().StringVar(&searchOptions.TwitterPath,
+		"twitter-path", "PATH", "Twitter source path")
+	rootCmd.AddCommand(searchCommand)
+
+	var statsOptions StatsOptions
+	statsCommand := &cobra.Command{
+		Use:   "stats",
+		Short: "Show statistics on synced data",
+		Long: strings.TrimSpace(`
+Show statistics computed over previously synced data. Individual source files
+should be set as options.`),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := stats(os.Stdout, &statsOptions); err != nil {
+				die(fmt.Sprintf("error computing stats: %v", err))
+			}
+		},
+	}
+	statsCommand.Flags().StringVar(&statsOptions.GoodreadsPath,
+		"goodreads-path", "PATH", "Goodreads source path")
+	statsCommand.Flags().StringVar(&statsOptions.HomeCountryCode,
+		"home-country-code", "", "ISO code of home country, which isn't counted as abroad")
+	statsCommand.Flags().StringVar(&statsOptions.NomadListPath,
+		"nomadlist-path", "PATH", "NomadList source path")
+	statsCommand.Flags().BoolVar(&statsOptions.NoReplies,
+		"no-replies", false, "Leave replies out of tweet statistics")
+	statsCommand.Flags().BoolVar(&statsOptions.NoRetweets,
+		"no-retweets", false, "Leave retweets out of tweet statistics")
+	statsCommand.Flags().StringVar(&statsOptions.OvercastPath,
+		"overcast-path", "PATH", "Overcast source path")
+	statsCommand.Flags().StringVar(&statsOptions.TwitterPath,
+		"twitter-path", "PATH", "Twitter source path")
+	rootCmd.AddCommand(statsCommand)
 
 	var syncAllOptions SyncAllOptions
 	syncAllCommand := &cobra.Command{
@@ -57,1038 +645,14017 @@ local TOML files for easier portability and storage.`),
 		Long: strings.TrimSpace(`
 Sync all qself data. Individual target files should be set as options.`),
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := syncAll(&syncAllOptions); err != nil {
+			if err := syncAll(cmd.Context(), &syncAllOptions); err != nil {
 				die(fmt.Sprintf("error syncing all: %v", err))
 			}
 		},
 	}
+	syncAllCommand.Flags().StringVar(&syncAllOptions.BeeminderPath,
+		"beeminder-path", "PATH", "Beeminder target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.CalPath,
+		"cal-path", "PATH", "Google Calendar target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.ChessPath,
+		"chess-path", "PATH", "Chess target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.ClockifyPath,
+		"clockify-path", "PATH", "Clockify target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.CloudflarePath,
+		"cloudflare-path", "PATH", "Cloudflare target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.ExistPath,
+		"exist-path", "PATH", "Exist target path")
+	syncAllCommand.Flags().BoolVar(&syncAllOptions.FailFast,
+		"fail-fast", false, "Stop all syncs as soon as one of them fails")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.GoodreadsAbandonedShelf,
+		"goodreads-abandoned-shelf", "", "Goodreads shelf of books that were started but not finished")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.GoodreadsDateFormat,
+		"goodreads-date-format", goodreadsTimeFormat, "Go time layout for Goodreads dates")
 	syncAllCommand.Flags().StringVar(&syncAllOptions.GoodreadsPath,
 		"goodreads-path", "PATH", "Goodreads target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.GooglePhotosPath,
+		"google-photos-path", "PATH", "Google Photos target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.LinkedInArticlesPath,
+		"linkedin-articles-path", "PATH", "LinkedIn articles target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.LinkedInPath,
+		"linkedin-path", "PATH", "LinkedIn target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.MediumPath,
+		"medium-path", "PATH", "Medium target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.MonzoPath,
+		"monzo-path", "PATH", "Monzo target path")
+	syncAllCommand.Flags().BoolVar(&syncAllOptions.NoHTMLDecode,
+		"no-html-decode", false, "Don't unescape HTML entities in reviews and tweets")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.NomadListPath,
+		"nomadlist-path", "PATH", "NomadList target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.OuraReadinessPath,
+		"oura-readiness-path", "PATH", "Oura readiness target path (requires --oura-sleep-path)")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.OuraSleepPath,
+		"oura-sleep-path", "PATH", "Oura sleep target path (requires --oura-readiness-path)")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.RunkeeperPath,
+		"runkeeper-path", "PATH", "Runkeeper target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.SteamPath,
+		"steam-path", "PATH", "Steam target path")
+	syncAllCommand.Flags().BoolVar(&syncAllOptions.Strict,
+		"strict", false, "Fail if any records were skipped")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.StripeChargesPath,
+		"stripe-charges-path", "PATH", "Stripe charges target path (requires --stripe-payouts-path)")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.StripePayoutsPath,
+		"stripe-payouts-path", "PATH", "Stripe payouts target path (requires --stripe-charges-path)")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.SubstackPath,
+		"substack-path", "PATH", "Substack target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.TelegramPath,
+		"telegram-path", "PATH", "Telegram target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.TogglPath,
+		"toggl-path", "PATH", "Toggl target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.TweetFilterRegexp,
+		"tweet-filter-regexp", "", "Leave out tweets with text matching this regexp")
+	syncAllCommand.Flags().BoolVar(&syncAllOptions.TwitterAPIV2,
+		"twitter-api-v2", false, "Fetch additional Twitter metrics from API v2")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.TwitterLikesPath,
+		"twitter-likes-path", "PATH", "Twitter liked tweets target path (requires --twitter-path)")
 	syncAllCommand.Flags().StringVar(&syncAllOptions.TwitterPath,
 		"twitter-path", "PATH", "Twitter target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.WakaTimePath,
+		"wakatime-path", "PATH", "WakaTime target path")
 	syncAllCommand.Flags().StringVar(&syncAllOptions.WaniKaniPath,
 		"wanikani-path", "PATH", "Twitter target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.WeatherOpenWeatherPath,
+		"weather-openweather-path", "PATH", "OpenWeather target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.WeatherPWSPath,
+		"weather-pws-path", "PATH", "Weather Underground PWS target path")
+	syncAllCommand.Flags().StringVar(&syncAllOptions.WithingsPath,
+		"withings-path", "PATH", "Withings target path")
 	rootCmd.AddCommand(syncAllCommand)
 
+	syncBeeminderCommand := &cobra.Command{
+		Use:   "sync-beeminder [target TOML file]",
+		Short: "Sync Beeminder data",
+		Long: strings.TrimSpace(`
+Sync goals and their data points down from the Beeminder API. Data points are
+fetched for several goals at once.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncBeeminder(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(beeminder) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncBeeminderCommand)
+
+	syncCalCommand := &cobra.Command{
+		Use:   "sync-cal [target TOML file]",
+		Short: "Sync Google Calendar data",
+		Long: strings.TrimSpace(`
+Sync past events down from the Google Calendar API.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncCal(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(cal) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncCalCommand)
+
+	syncChessCommand := &cobra.Command{
+		Use:   "sync-chess [target TOML file]",
+		Short: "Sync chess data",
+		Long: strings.TrimSpace(`
+Sync games played on Chess.com, and optionally Lichess, down from their APIs.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncChess(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(chess) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncChessCommand)
+
+	syncClockifyCommand := &cobra.Command{
+		Use:   "sync-clockify [target TOML file]",
+		Short: "Sync Clockify data",
+		Long: strings.TrimSpace(`
+Sync time entries down from the Clockify API for every workspace that the user
+belongs to. Entries that are still running are skipped until they're stopped.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncClockify(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(clockify) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncClockifyCommand)
+
+	syncCloudflareCommand := &cobra.Command{
+		Use:   "sync-cloudflare [target TOML file]",
+		Short: "Sync Cloudflare data",
+		Long: strings.TrimSpace(`
+Sync daily traffic totals for a zone down from Cloudflare's GraphQL Analytics
+API. Cloudflare only retains this data for a limited time, so sync regularly to
+keep a full history.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncCloudflare(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(cloudflare) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncCloudflareCommand)
+
+	var syncExistOptions SyncExistOptions
+	syncExistCommand := &cobra.Command{
+		Use:   "sync-exist [target TOML file]",
+		Short: "Sync Exist data",
+		Long: strings.TrimSpace(`
+Sync daily attribute values down from the Exist API.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncExist(cmd.Context(), args[0], &syncExistOptions); err != nil {
+				die(fmt.Sprintf("(exist) error syncing: %v", err))
+			}
+		},
+	}
+	syncExistCommand.Flags().StringSliceVar(&syncExistOptions.Attributes,
+		"exist-attributes", nil, "Comma-separated names of attributes to sync (all if not set)")
+	rootCmd.AddCommand(syncExistCommand)
+
+	var syncGoodreadsOptions SyncGoodreadsOptions
 	syncGoodreadsCommand := &cobra.Command{
 		Use:   "sync-goodreads [target TOML file]",
 		Short: "Sync Goodreads data",
 		Long: strings.TrimSpace(`
-Sync personal tweets down from the Goodreads API.`),
+Sync personal tweets down from the Goodreads API.
+
+Dates that can't be parsed with --goodreads-date-format are retried after
+translating day and month names from a number of common locales to English.
+
+Reviews are fetched in pages, several at a time. --goodreads-sort changes the
+order in which Goodreads returns them, and therefore which reviews land on each
+page. Pages cached with --goodreads-cache-dir are kept separately for each
+order.`),
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := syncGoodreads(args[0]); err != nil {
+			if err := syncGoodreads(cmd.Context(), args[0], &syncGoodreadsOptions); err != nil {
 				die(fmt.Sprintf("(goodreads) error syncing: %v", err))
 			}
 		},
 	}
+	syncGoodreadsCommand.Flags().StringVar(&syncGoodreadsOptions.AbandonedShelf,
+		"abandoned-shelf", "", "Shelf of books that were started but not finished")
+	syncGoodreadsCommand.Flags().StringVar(&syncGoodreadsOptions.CacheDir,
+		"goodreads-cache-dir", "", "Directory in which to cache Goodreads API responses")
+	syncGoodreadsCommand.Flags().DurationVar(&syncGoodreadsOptions.CacheTTL,
+		"goodreads-cache-ttl", time.Hour, "Age after which cached Goodreads API responses are refetched")
+	syncGoodreadsCommand.Flags().BoolVar(&syncGoodreadsOptions.ComputeSentiment,
+		"compute-sentiment", false, "Compute the sentiment of review text")
+	syncGoodreadsCommand.Flags().IntVar(&syncGoodreadsOptions.CoverDownloadConcurrency,
+		"cover-download-concurrency", defaultCoverDownloadConcurrency, "Maximum number of covers downloaded at once")
+	syncGoodreadsCommand.Flags().StringVar(&syncGoodreadsOptions.CoverDownloadDir,
+		"goodreads-cover-download-dir", "", "Directory to download book cover images to")
+	syncGoodreadsCommand.Flags().StringVar(&syncGoodreadsOptions.DateFormat,
+		"goodreads-date-format", goodreadsTimeFormat, "Go time layout for Goodreads dates")
+	syncGoodreadsCommand.Flags().StringVar(&syncGoodreadsOptions.Key,
+		"goodreads-key", "", "Goodreads API key (overrides GOODREADS_KEY)")
+	syncGoodreadsCommand.Flags().StringVar(&syncGoodreadsOptions.APISort,
+		"goodreads-sort", goodreadsSortDateRead, "Order in which Goodreads returns reviews ("+strings.Join(goodreadsSorts, ", ")+")")
+	syncGoodreadsCommand.Flags().StringVar(&syncGoodreadsOptions.UserID,
+		"goodreads-user-id", "", "ID of user whose reviews to sync (overrides GOODREADS_ID)")
+	syncGoodreadsCommand.Flags().BoolVar(&syncGoodreadsOptions.NoHTMLDecode,
+		"no-html-decode", false, "Don't unescape HTML entities in reviews")
+	syncGoodreadsCommand.Flags().StringVar(&syncGoodreadsOptions.Sort,
+		"sort", sortOrderDesc, "Order of readings by review ID ('asc' or 'desc')")
+	syncGoodreadsCommand.Flags().BoolVar(&syncGoodreadsOptions.Strict,
+		"strict", false, "Fail if any reviews were skipped")
+	syncGoodreadsCommand.Flags().IntVar(&syncGoodreadsOptions.WordsPerPage,
+		"words-per-page", defaultWordsPerPage, "Words per page used to estimate word counts")
 	rootCmd.AddCommand(syncGoodreadsCommand)
 
-	syncTwitterCommand := &cobra.Command{
-		Use:   "sync-twitter [target TOML file]",
-		Short: "Sync Twitter data",
+	var syncGoodreadsChallengesOptions SyncGoodreadsChallengesOptions
+	syncGoodreadsChallengesCommand := &cobra.Command{
+		Use:   "sync-goodreads-challenges [readings TOML file]",
+		Short: "Sync Goodreads reading challenges onto readings",
 		Long: strings.TrimSpace(`
-Sync personal tweets down from the Twitter API.`),
+Sync the Goodreads reading challenges that a user has joined, and store the IDs
+of the challenges that each book counted towards on its readings in a
+previously synced Goodreads data file.`),
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := syncTwitter(args[0]); err != nil {
-				die(fmt.Sprintf("(twitter) error syncing: %v", err))
+			if err := syncGoodreadsChallenges(cmd.Context(), args[0], &syncGoodreadsChallengesOptions); err != nil {
+				die(fmt.Sprintf("(goodreads) error syncing challenges: %v", err))
 			}
 		},
 	}
-	rootCmd.AddCommand(syncTwitterCommand)
-
-	syncWaniKaniCommand := &cobra.Command{
-		Use:   "sync-wanikani [target TOML file]",
-		Short: "Sync WaniKani data",
+	syncGoodreadsChallengesCommand.Flags().StringVar(&syncGoodreadsChallengesOptions.Key,
+		"goodreads-key", "", "Goodreads API key (overrides GOODREADS_KEY)")
+	syncGoodreadsChallengesCommand.Flags().StringVar(&syncGoodreadsChallengesOptions.UserID,
+		"goodreads-user-id", "", "ID of user whose challenges to sync (overrides GOODREADS_ID)")
+	rootCmd.AddCommand(syncGoodreadsChallengesCommand)
+
+	var syncGoodreadsFriendsOptions SyncGoodreadsFriendsOptions
+	syncGoodreadsFriendsCommand := &cobra.Command{
+		Use:   "sync-goodreads-friends [target TOML file]",
+		Short: "Sync Goodreads friends' recent readings",
 		Long: strings.TrimSpace(`
-Sync personal data down from the WaniKani API.`),
+Sync the most recent books read by each of a user's Goodreads friends down from
+the Goodreads API, for use as recommendations.`),
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := syncWaniKani(args[0]); err != nil {
-				die(fmt.Sprintf("(wanikani) error syncing: %v", err))
+			if err := syncGoodreadsFriends(cmd.Context(), args[0], &syncGoodreadsFriendsOptions); err != nil {
+				die(fmt.Sprintf("(goodreads) error syncing friends: %v", err))
 			}
 		},
 	}
-	rootCmd.AddCommand(syncWaniKaniCommand)
+	syncGoodreadsFriendsCommand.Flags().StringVar(&syncGoodreadsFriendsOptions.Key,
+		"goodreads-key", "", "Goodreads API key (overrides GOODREADS_KEY)")
+	syncGoodreadsFriendsCommand.Flags().StringVar(&syncGoodreadsFriendsOptions.UserID,
+		"goodreads-user-id", "", "ID of user whose friends to sync (overrides GOODREADS_ID)")
+	syncGoodreadsFriendsCommand.Flags().IntVar(&syncGoodreadsFriendsOptions.MaxFriends,
+		"max-friends", defaultMaxFriends, "Maximum number of friends whose readings are synced")
+	rootCmd.AddCommand(syncGoodreadsFriendsCommand)
+
+	syncGooglePhotosCommand := &cobra.Command{
+		Use:   "sync-google-photos [target TOML file]",
+		Short: "Sync Google Photos data",
+		Long: strings.TrimSpace(`
+Sync metadata of photos and videos in a Google Photos library down from the
+Google Photos Library API, including the albums that each one is in. The
+photos themselves aren't downloaded.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncGooglePhotos(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(googlephotos) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncGooglePhotosCommand)
 
-	if err := rootCmd.Execute(); err != nil {
-		die(fmt.Sprintf("Error executing command: %v", err))
+	syncKoboCommand := &cobra.Command{
+		Use:   "sync-kobo [KoboReader.sqlite file] [annotations target TOML file] [sessions target TOML file]",
+		Short: "Sync Kobo data",
+		Long: strings.TrimSpace(`
+Import highlights, notes, and reading sessions from the KoboReader.sqlite
+database of a Kobo e-reader. Kobo has no API, so the database has to be
+copied from the device while it's connected over USB first.`),
+		Args: cobra.ExactArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncKobo(args[0], args[1], args[2]); err != nil {
+				die(fmt.Sprintf("(kobo) error syncing: %v", err))
+			}
+		},
 	}
-}
+	rootCmd.AddCommand(syncKoboCommand)
 
-//////////////////////////////////////////////////////////////////////////////
-//
-//
-//
-// Types
-//
-//
-//
-//////////////////////////////////////////////////////////////////////////////
+	syncLinkedInCommand := &cobra.Command{
+		Use:   "sync-linkedin [target TOML file]",
+		Short: "Sync LinkedIn data",
+		Long: strings.TrimSpace(`
+Sync posts down from the LinkedIn API.
 
-//
-// Confs
-//
+LinkedIn's API allows only 500 requests a day. One request is made for each
+page of posts and one for each post's like and comment counts, and a warning is
+logged as a sync approaches the limit.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncLinkedIn(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(linkedin) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncLinkedInCommand)
 
-// GoodreadsConf contains configuration information for syncing Goodreads. It's
-// extracted from environment variables.
-type GoodreadsConf struct {
-	GoodreadsID  string `env:"GOODREADS_ID,required"`
-	GoodreadsKey string `env:"GOODREADS_KEY,required"`
-}
+	syncLinkedInArticlesCommand := &cobra.Command{
+		Use:   "sync-linkedin-articles [target TOML file]",
+		Short: "Sync LinkedIn articles",
+		Long: strings.TrimSpace(`
+Sync long-form articles down from the LinkedIn API, with their bodies converted
+from HTML to plain text. Articles are stored separately from the posts synced
+by sync-linkedin.
 
-// TwitterConf contains configuration information for syncing Twitter. It's
-// extracted from environment variables.
-type TwitterConf struct {
-	TwitterConsumerKey    string `env:"TWITTER_CONSUMER_KEY,required"`
-	TwitterConsumerSecret string `env:"TWITTER_CONSUMER_SECRET,required"`
+Requests count towards the same limit of 500 a day as sync-linkedin. One
+request is made for each page of articles and one for each article's like and
+comment counts.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncLinkedInArticles(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(linkedin) error syncing articles: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncLinkedInArticlesCommand)
 
-	TwitterAccessToken  string `env:"TWITTER_ACCESS_TOKEN,required"`
-	TwitterAccessSecret string `env:"TWITTER_ACCESS_SECRET,required"`
+	syncMediumCommand := &cobra.Command{
+		Use:   "sync-medium [target TOML file]",
+		Short: "Sync Medium data",
+		Long: strings.TrimSpace(`
+Sync metadata for published posts down from Medium.
 
-	TwitterUser string `env:"TWITTER_USER,required"`
-}
+Medium's official API has been deprecated, so posts are read from the user's
+RSS feed, which only includes the ten most recent ones. Subtitles and clap
+counts aren't in the feed, and are fetched from an undocumented endpoint.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncMedium(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(medium) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncMediumCommand)
 
-// WaniKaniConf contains configuration information for syncing WaniKani. It's
-// extracted from environment variables.
-type WaniKaniConf struct {
-	WaniKaniAPIToken string `env:"WANI_KANI_API_TOKEN,required"`
-}
+	syncMonzoCommand := &cobra.Command{
+		Use:   "sync-monzo [target TOML file]",
+		Short: "Sync Monzo data",
+		Long: strings.TrimSpace(`
+Sync transactions for all open accounts down from the Monzo API.
 
-//
-// Goodreads
-//
+Monzo access tokens expire after a few hours. For unattended syncs, register a
+confidential OAuth client in the Monzo developer portal so that a refresh token
+is issued alongside the access token, then exchange the refresh token for a
+new access token before each sync:
 
-// APIBook is the book nested within a Goodreads review from the API.
-type APIBook struct {
-	XMLName struct{} `xml:"book"`
+    curl -X POST https://api.monzo.com/oauth2/token \
+        -d grant_type=refresh_token \
+        -d client_id=$MONZO_CLIENT_ID \
+        -d client_secret=$MONZO_CLIENT_SECRET \
+        -d refresh_token=$MONZO_REFRESH_TOKEN
 
-	Authors       []*APIBookAuthor `xml:"authors>author"`
-	ID            int              `xml:"id"`
-	ISBN          string           `xml:"isbn"`
-	ISBN13        string           `xml:"isbn13"`
-	NumPages      int              `xml:"num_pages"`
-	PublishedYear int              `xml:"published"`
-	Title         string           `xml:"title"`
-}
+Set MONZO_ACCESS_TOKEN to the "access_token" in the response, and store the new
+"refresh_token" because each one can only be used once.
 
-// APIBookAuthor is an author nested within a Goodreads book from the API.
-type APIBookAuthor struct {
-	XMLName struct{} `xml:"author"`
+Monzo only allows a token's full transaction history to be fetched within five
+minutes of it being authorized. After that, only the last 90 days are
+available, so run a first sync right after authorizing.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncMonzo(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(monzo) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncMonzoCommand)
 
-	ID   int    `xml:"id"`
-	Name string `xml:"name"`
-}
+	syncNomadListCommand := &cobra.Command{
+		Use:   "sync-nomadlist [target TOML file]",
+		Short: "Sync NomadList data",
+		Long: strings.TrimSpace(`
+Sync travel history down from a public NomadList profile.
 
-// APIReview is a single review within a Goodreads reviews API request.
-type APIReview struct {
-	XMLName struct{} `xml:"review"`
+The profile includes every stay at once, so stays that were added or removed
+since the last sync are logged. Removed stays are kept in the target file.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncNomadList(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(nomadlist) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncNomadListCommand)
 
-	Body   string   `xml:"body"`
-	Book   *APIBook `xml:"book"`
-	ID     int      `xml:"id"`
-	Rating int      `xml:"rating"`
-	ReadAt string   `xml:"read_at"`
-}
+	syncOuraCommand := &cobra.Command{
+		Use:   "sync-oura [sleep target TOML file] [readiness target TOML file]",
+		Short: "Sync Oura data",
+		Long: strings.TrimSpace(`
+Sync personal sleep and readiness data down from the Oura API.`),
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncOura(cmd.Context(), args[0], args[1]); err != nil {
+				die(fmt.Sprintf("(oura) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncOuraCommand)
+
+	syncOvercastCommand := &cobra.Command{
+		Use:   "sync-overcast [OPML export file] [target TOML file]",
+		Short: "Sync Overcast data",
+		Long: strings.TrimSpace(`
+Import podcast listening history from an Overcast extended OPML export.
+Overcast has no API, so the export has to be downloaded from its website
+first.`),
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncOvercast(args[0], args[1]); err != nil {
+				die(fmt.Sprintf("(overcast) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncOvercastCommand)
+
+	syncRunkeeperCommand := &cobra.Command{
+		Use:   "sync-runkeeper [target TOML file]",
+		Short: "Sync Runkeeper data",
+		Long: strings.TrimSpace(`
+Sync fitness activities like runs down from the Runkeeper Health Graph API.
+
+Notes and heart rate aren't included in Runkeeper's activity feed, so each
+activity is also fetched individually. After a first sync, only activities
+from the last week before the most recent one stored are fetched again.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncRunkeeper(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(runkeeper) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncRunkeeperCommand)
+
+	syncRunningAheadCommand := &cobra.Command{
+		Use:   "sync-runningahead [CSV export file] [target TOML file]",
+		Short: "Sync RunningAHEAD data",
+		Long: strings.TrimSpace(`
+Import workouts from a RunningAHEAD CSV export. RunningAHEAD's API isn't
+available, so the export has to be downloaded from its website first.`),
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncRunningAhead(args[0], args[1]); err != nil {
+				die(fmt.Sprintf("(runningahead) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncRunningAheadCommand)
+
+	syncSleepCycleCommand := &cobra.Command{
+		Use:   "sync-sleep-cycle [CSV export file] [target TOML file]",
+		Short: "Sync SleepCycle data",
+		Long: strings.TrimSpace(`
+Import nights of sleep from a SleepCycle CSV export. SleepCycle has no API,
+so the export has to be saved from the app first.`),
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncSleepCycle(args[0], args[1]); err != nil {
+				die(fmt.Sprintf("(sleepcycle) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncSleepCycleCommand)
+
+	syncSteamCommand := &cobra.Command{
+		Use:   "sync-steam [target TOML file]",
+		Short: "Sync Steam data",
+		Long: strings.TrimSpace(`
+Sync owned games and time played down from the Steam Web API.
+
+The API returns every owned game at once. Games that are no longer returned
+are kept in the target file.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncSteam(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(steam) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncSteamCommand)
+
+	syncStripeCommand := &cobra.Command{
+		Use:   "sync-stripe [charges target TOML file] [payouts target TOML file]",
+		Short: "Sync Stripe data",
+		Long: strings.TrimSpace(`
+Sync charges and payouts down from the Stripe API.`),
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncStripe(cmd.Context(), args[0], args[1]); err != nil {
+				die(fmt.Sprintf("(stripe) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncStripeCommand)
+
+	syncSubstackCommand := &cobra.Command{
+		Use:   "sync-substack [target TOML file]",
+		Short: "Sync Substack data",
+		Long: strings.TrimSpace(`
+Sync metadata of a Substack publication's posts down from Substack's
+undocumented API, which may change without notice. Open rates, click rates,
+and views are only returned to a session of one of the publication's authors.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncSubstack(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(substack) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncSubstackCommand)
+
+	syncTelegramCommand := &cobra.Command{
+		Use:   "sync-telegram [target TOML file]",
+		Short: "Sync Telegram data",
+		Long: strings.TrimSpace(`
+Sync messages from a Telegram channel using the Bot API.
+
+The bot receives posts to the channel if it's been added as an administrator,
+or messages from the channel that are forwarded to it. Telegram only holds on
+to updates for 24 hours and discards them once they've been fetched, so sync
+regularly. Updates can't be fetched while the bot has a webhook set.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncTelegram(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(telegram) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncTelegramCommand)
+
+	syncTogglCommand := &cobra.Command{
+		Use:   "sync-toggl [target TOML file]",
+		Short: "Sync Toggl data",
+		Long: strings.TrimSpace(`
+Sync time entries down from the Toggl Track API.
+
+Toggl only serves time entries from roughly the last three months through its
+API, so a first sync starts from there, and syncs should be run at least that
+often to keep a complete archive. Entries that are still running are skipped
+until they're stopped.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncToggl(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(toggl) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncTogglCommand)
+
+	var syncTwitterOptions SyncTwitterOptions
+	syncTwitterCommand := &cobra.Command{
+		Use:   "sync-twitter [target TOML file]",
+		Short: "Sync Twitter data",
+		Long: strings.TrimSpace(`
+Sync personal tweets down from the Twitter API.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncTwitter(cmd.Context(), args[0], &syncTwitterOptions); err != nil {
+				die(fmt.Sprintf("(twitter) error syncing: %v", err))
+			}
+		},
+	}
+	syncTwitterCommand.Flags().BoolVar(&syncTwitterOptions.APIV2,
+		"twitter-api-v2", false, "Fetch additional metrics from API v2")
+	syncTwitterCommand.Flags().BoolVar(&syncTwitterOptions.CheckURLs,
+		"check-urls", false, "Check whether URLs in tweets still resolve")
+	syncTwitterCommand.Flags().BoolVar(&syncTwitterOptions.ComputeEngagement,
+		"compute-engagement", false, "Store likes relative to the user's follower count")
+	syncTwitterCommand.Flags().Float64Var(&syncTwitterOptions.EngagementThreshold,
+		"engagement-threshold", defaultEngagementThreshold, "Largest change in likes by followers considered trivial")
+	syncTwitterCommand.Flags().Float64SliceVar(&syncTwitterOptions.EngagementWeights,
+		"engagement-weights", defaultEngagementWeights, "Engagement score multipliers for likes,retweets,replies,bookmarks")
+	syncTwitterCommand.Flags().BoolVar(&syncTwitterOptions.ExpandURLs,
+		"expand-urls", false, "Replace t.co links in tweet text with their expanded URLs")
+	syncTwitterCommand.Flags().StringVar(&syncTwitterOptions.FilterRegexp,
+		"tweet-filter-regexp", "", "Leave out tweets with text matching this regexp")
+	syncTwitterCommand.Flags().BoolVar(&syncTwitterOptions.FetchCards,
+		"twitter-fetch-cards", false, "Fetch Twitter Cards attached to tweets")
+	syncTwitterCommand.Flags().BoolVar(&syncTwitterOptions.IncludeAds,
+		"include-ads", false, "Keep promoted tweets instead of skipping them")
+	syncTwitterCommand.Flags().BoolVar(&syncTwitterOptions.IncludeLikes,
+		"twitter-include-likes", false, "Also sync liked tweets (requires --twitter-likes-path)")
+	syncTwitterCommand.Flags().StringVar(&syncTwitterOptions.LikesPath,
+		"twitter-likes-path", "", "Target path for liked tweets")
+	syncTwitterCommand.Flags().StringVar(&syncTwitterOptions.MergeStrategy,
+		"merge-strategy", mergeStrategySmart, "Version kept for stored tweets ('prefer-new', 'prefer-existing', or 'smart')")
+	syncTwitterCommand.Flags().IntVar(&syncTwitterOptions.MinFavorites,
+		"tweet-min-favorites", 0, "Leave out tweets with fewer favorites than this")
+	syncTwitterCommand.Flags().IntVar(&syncTwitterOptions.MinRetweets,
+		"tweet-min-retweets", 0, "Leave out tweets with fewer retweets than this")
+	syncTwitterCommand.Flags().BoolVar(&syncTwitterOptions.NoHTMLDecode,
+		"no-html-decode", false, "Don't unescape HTML entities in tweets")
+	syncTwitterCommand.Flags().BoolVar(&syncTwitterOptions.NoNormalizeText,
+		"no-normalize-text", false, "Don't store Unicode normalized tweet text")
+	syncTwitterCommand.Flags().StringVar(&syncTwitterOptions.RangeEnd,
+		"tweet-end", "", "Leave out tweets created after this time (RFC 3339)")
+	syncTwitterCommand.Flags().StringVar(&syncTwitterOptions.RangeSourcePath,
+		"tweet-range-source", "", "Data file with tweets outside of --tweet-start and --tweet-end to page from")
+	syncTwitterCommand.Flags().StringVar(&syncTwitterOptions.RangeStart,
+		"tweet-start", "", "Leave out tweets created before this time (RFC 3339)")
+	syncTwitterCommand.Flags().StringVar(&syncTwitterOptions.Sort,
+		"sort", sortOrderDesc, "Order of tweets by ID ('asc' or 'desc')")
+	syncTwitterCommand.Flags().BoolVar(&syncTwitterOptions.Strict,
+		"strict", false, "Fail if any tweets were skipped")
+	syncTwitterCommand.Flags().Int64Var(&syncTwitterOptions.TrivialViewThreshold,
+		"trivial-view-threshold", defaultTrivialViewThreshold, "Largest change in views considered trivial")
+	syncTwitterCommand.Flags().IntVar(&syncTwitterOptions.URLCheckConcurrency,
+		"url-check-concurrency", defaultURLCheckConcurrency, "Maximum number of URLs checked at once")
+	rootCmd.AddCommand(syncTwitterCommand)
+
+	syncWakaTimeCommand := &cobra.Command{
+		Use:   "sync-wakatime [target TOML file]",
+		Short: "Sync WakaTime data",
+		Long: strings.TrimSpace(`
+Sync daily coding activity down from the WakaTime API.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncWakaTime(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(wakatime) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncWakaTimeCommand)
+
+	syncWaniKaniCommand := &cobra.Command{
+		Use:   "sync-wanikani [target TOML file]",
+		Short: "Sync WaniKani data",
+		Long: strings.TrimSpace(`
+Sync personal data down from the WaniKani API.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncWaniKani(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(wanikani) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncWaniKaniCommand)
+
+	syncWeatherOpenWeatherCommand := &cobra.Command{
+		Use:   "sync-weather-openweather [target TOML file]",
+		Short: "Sync OpenWeather data",
+		Long: strings.TrimSpace(`
+Sync daily weather history for a location down from the OpenWeather One Call
+API.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncWeatherOpenWeather(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(openweather) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncWeatherOpenWeatherCommand)
+
+	syncWeatherPWSCommand := &cobra.Command{
+		Use:   "sync-weather-pws [target TOML file]",
+		Short: "Sync personal weather station data",
+		Long: strings.TrimSpace(`
+Sync daily summaries from a personal weather station down from the Weather
+Underground API.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncWeatherPWS(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(wu) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncWeatherPWSCommand)
+
+	syncWithingsCommand := &cobra.Command{
+		Use:   "sync-withings [target TOML file]",
+		Short: "Sync Withings data",
+		Long: strings.TrimSpace(`
+Sync body measurements like weight and body composition down from the Withings
+API.
+
+Withings access tokens expire after three hours. For unattended syncs, also set
+WITHINGS_CLIENT_ID, WITHINGS_CLIENT_SECRET, and WITHINGS_REFRESH_TOKEN so that
+an expired token can be refreshed automatically. Withings issues a new refresh
+token with every refresh, so tokens are saved to a file next to the target
+named like withings_token.toml, which takes precedence over the environment on
+later syncs. Keep it out of version control.`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncWithings(cmd.Context(), args[0]); err != nil {
+				die(fmt.Sprintf("(withings) error syncing: %v", err))
+			}
+		},
+	}
+	rootCmd.AddCommand(syncWithingsCommand)
+
+	var validateOptions ValidateOptions
+	validateCommand := &cobra.Command{
+		Use:   "validate",
+		Short: "Check synced data for problems",
+		Long: strings.TrimSpace(`
+Check previously synced data for likely problems, printing a warning for each
+one that's found. Individual source files should be set as options.`),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := validate(os.Stdout, &validateOptions); err != nil {
+				die(fmt.Sprintf("error validating: %v", err))
+			}
+		},
+	}
+	validateCommand.Flags().StringVar(&validateOptions.GoodreadsPath,
+		"goodreads-path", "PATH", "Goodreads source path")
+	rootCmd.AddCommand(validateCommand)
+
+	// Cancel any sync that's in progress on an interrupt so that it stops
+	// before writing a partial data file.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	defer logger.Close()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		die(fmt.Sprintf("Error executing command: %v", err))
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+//
+// Confs
+//
+
+// BeeminderConf contains configuration information for syncing Beeminder.
+// It's extracted from environment variables.
+type BeeminderConf struct {
+	BeeminderAuthToken string `env:"BEEMINDER_AUTH_TOKEN,required"`
+	BeeminderUsername  string `env:"BEEMINDER_USERNAME,required"`
+}
+
+// CalConf contains configuration information for syncing Google Calendar.
+// It's extracted from environment variables.
+type CalConf struct {
+	CalCalendarID string `env:"CAL_CALENDAR_ID,required"`
+
+	// CalCredentialsJSON is the path to the JSON key file of a Google Cloud
+	// service account that the calendar has been shared with.
+	CalCredentialsJSON string `env:"CAL_CREDENTIALS_JSON,required"`
+}
+
+// ChessConf contains configuration information for syncing chess games. It's
+// extracted from environment variables.
+type ChessConf struct {
+	ChessComUsername string `env:"CHESS_COM_USERNAME,required"`
+
+	// LichessUsername is optional. Lichess games are only synced if it's set.
+	LichessUsername string `env:"LICHESS_USERNAME"`
+}
+
+// ClockifyConf contains configuration information for syncing Clockify. It's
+// extracted from environment variables.
+type ClockifyConf struct {
+	ClockifyAPIKey string `env:"CLOCKIFY_API_KEY,required"`
+}
+
+// CloudflareConf contains configuration information for syncing Cloudflare.
+// It's extracted from environment variables.
+type CloudflareConf struct {
+	// CFAPIToken is an API token with the "Analytics: Read" permission for
+	// the zone.
+	CFAPIToken string `env:"CF_API_TOKEN,required"`
+
+	CFZoneID string `env:"CF_ZONE_ID,required"`
+}
+
+// ExistConf contains configuration information for syncing Exist. It's
+// extracted from environment variables.
+type ExistConf struct {
+	ExistAccessToken string `env:"EXIST_ACCESS_TOKEN,required"`
+}
+
+// GoodreadsConf contains configuration information for syncing Goodreads. It's
+// extracted from environment variables, which can be overridden with flags to
+// `sync-goodreads`, so its fields aren't marked as required. See
+// goodreadsConfFromOptions.
+type GoodreadsConf struct {
+	GoodreadsID  string `env:"GOODREADS_ID"`
+	GoodreadsKey string `env:"GOODREADS_KEY"`
+}
+
+// GooglePhotosConf contains configuration information for syncing Google
+// Photos. It's extracted from environment variables.
+type GooglePhotosConf struct {
+	// GooglePhotosCredentialsJSON is the path to a JSON file holding the
+	// OAuth client ID, client secret, and refresh token of a user who has
+	// granted read-only access to their library. See
+	// GooglePhotosAPICredentials.
+	GooglePhotosCredentialsJSON string `env:"GOOGLE_PHOTOS_CREDENTIALS_JSON,required"`
+}
+
+// LinkedInArticleConf contains configuration information for syncing LinkedIn
+// articles. It's extracted from environment variables. Articles are read with
+// the same access token as posts.
+type LinkedInArticleConf struct {
+	LinkedInAccessToken string `env:"LINKEDIN_ACCESS_TOKEN,required"`
+}
+
+// LinkedInConf contains configuration information for syncing LinkedIn. It's
+// extracted from environment variables.
+type LinkedInConf struct {
+	LinkedInAccessToken string `env:"LINKEDIN_ACCESS_TOKEN,required"`
+}
+
+// MediumConf contains configuration information for syncing Medium. It's
+// extracted from environment variables.
+type MediumConf struct {
+	MediumUsername string `env:"MEDIUM_USERNAME,required"`
+}
+
+// MonzoConf contains configuration information for syncing Monzo. It's
+// extracted from environment variables.
+type MonzoConf struct {
+	MonzoAccessToken string `env:"MONZO_ACCESS_TOKEN,required"`
+}
+
+// NomadListConf contains configuration information for syncing NomadList.
+// It's extracted from environment variables.
+type NomadListConf struct {
+	NomadListUsername string `env:"NOMADLIST_USERNAME,required"`
+}
+
+// OpenWeatherConf contains configuration information for syncing OpenWeather.
+// It's extracted from environment variables.
+type OpenWeatherConf struct {
+	OpenWeatherAPIKey string  `env:"OPENWEATHER_API_KEY,required"`
+	OpenWeatherLat    float64 `env:"OPENWEATHER_LAT,required"`
+	OpenWeatherLon    float64 `env:"OPENWEATHER_LON,required"`
+}
+
+// OuraConf contains configuration information for syncing Oura. It's extracted
+// from environment variables.
+type OuraConf struct {
+	OuraAccessToken string `env:"OURA_ACCESS_TOKEN,required"`
+}
+
+// RunkeeperConf contains configuration information for syncing Runkeeper.
+// It's extracted from environment variables.
+type RunkeeperConf struct {
+	RunkeeperAccessToken string `env:"RUNKEEPER_ACCESS_TOKEN,required"`
+}
+
+// SteamConf contains configuration information for syncing Steam. It's
+// extracted from environment variables.
+type SteamConf struct {
+	SteamAPIKey string `env:"STEAM_API_KEY,required"`
+
+	// SteamUserID is the 64-bit Steam ID of the user, not their vanity
+	// username.
+	SteamUserID string `env:"STEAM_USER_ID,required"`
+}
+
+// StripeConf contains configuration information for syncing Stripe. It's
+// extracted from environment variables.
+type StripeConf struct {
+	// StripeSecretKey is a secret or restricted API key. A restricted key
+	// only needs read access to charges and payouts.
+	StripeSecretKey string `env:"STRIPE_SECRET_KEY,required"`
+}
+
+// SubstackConf contains configuration information for syncing Substack. It's
+// extracted from environment variables.
+type SubstackConf struct {
+	// SubstackCookie is the value of the `substack.sid` cookie of a logged in
+	// session of one of the publication's authors. Substack has no API keys.
+	SubstackCookie string `env:"SUBSTACK_COOKIE,required"`
+
+	// SubstackSubdomain is the publication's subdomain, like "example" for
+	// example.substack.com.
+	SubstackSubdomain string `env:"SUBSTACK_SUBDOMAIN,required"`
+}
+
+// TelegramConf contains configuration information for syncing Telegram. It's
+// extracted from environment variables.
+type TelegramConf struct {
+	TelegramBotToken  string `env:"TELEGRAM_BOT_TOKEN,required"`
+	TelegramChannelID int64  `env:"TELEGRAM_CHANNEL_ID,required"`
+}
+
+// TogglConf contains configuration information for syncing Toggl. It's
+// extracted from environment variables.
+type TogglConf struct {
+	TogglAPIToken string `env:"TOGGL_API_TOKEN,required"`
+}
+
+// TwitterConf contains configuration information for syncing Twitter. It's
+// extracted from environment variables.
+type TwitterConf struct {
+	TwitterConsumerKey    string `env:"TWITTER_CONSUMER_KEY,required"`
+	TwitterConsumerSecret string `env:"TWITTER_CONSUMER_SECRET,required"`
+
+	TwitterAccessToken  string `env:"TWITTER_ACCESS_TOKEN,required"`
+	TwitterAccessSecret string `env:"TWITTER_ACCESS_SECRET,required"`
+
+	TwitterUser string `env:"TWITTER_USER,required"`
+}
+
+// WakaTimeConf contains configuration information for syncing WakaTime. It's
+// extracted from environment variables.
+type WakaTimeConf struct {
+	WakaTimeAPIKey string `env:"WAKATIME_API_KEY,required"`
+}
+
+// WaniKaniConf contains configuration information for syncing WaniKani. It's
+// extracted from environment variables.
+type WaniKaniConf struct {
+	WaniKaniAPIToken string `env:"WANI_KANI_API_TOKEN,required"`
+}
+
+// WUConf contains configuration information for syncing a personal weather
+// station from Weather Underground. It's extracted from environment variables.
+type WUConf struct {
+	WUAPIKey    string `env:"WU_API_KEY,required"`
+	WUStationID string `env:"WU_STATION_ID,required"`
+}
+
+// WithingsConf contains configuration information for syncing Withings. It's
+// extracted from environment variables.
+type WithingsConf struct {
+	WithingsAccessToken string `env:"WITHINGS_ACCESS_TOKEN,required"`
+
+	// WithingsClientID, WithingsClientSecret, and WithingsRefreshToken are
+	// optional. If they're all set, an access token that's expired is
+	// refreshed automatically.
+	WithingsClientID     string `env:"WITHINGS_CLIENT_ID"`
+	WithingsClientSecret string `env:"WITHINGS_CLIENT_SECRET"`
+	WithingsRefreshToken string `env:"WITHINGS_REFRESH_TOKEN"`
+}
+
+//
+// Beeminder
+//
+
+// BeeminderAPIDataPoint is a data point of a goal from the Beeminder API.
+type BeeminderAPIDataPoint struct {
+	Comment string `json:"comment"`
+	ID      string `json:"id"`
+
+	// Timestamp is in seconds since the epoch.
+	Timestamp int64 `json:"timestamp"`
+
+	Value float64 `json:"value"`
+}
+
+// BeeminderAPIGoal is a goal from the Beeminder API.
+type BeeminderAPIGoal struct {
+	// GoalDate is in seconds since the epoch. It's nil for goals without an
+	// end date.
+	GoalDate *int64 `json:"goaldate"`
+
+	// GUnits are the units that the goal is measured in, like "pages".
+	GUnits string `json:"gunits"`
+
+	Pledge  float64 `json:"pledge"`
+	Rate    float64 `json:"rate"`
+	Safebuf int     `json:"safebuf"`
+	Slug    string  `json:"slug"`
+	Title   string  `json:"title"`
+}
+
+// BeeminderDataPoint is a single data point of a Beeminder goal stored to a
+// TOML file.
+type BeeminderDataPoint struct {
+	Comment   string    `toml:"comment"`
+	ID        string    `toml:"id"`
+	Timestamp time.Time `toml:"timestamp"`
+	Value     float64   `toml:"value"`
+}
+
+// BeeminderDB is a database of Beeminder goals stored to a TOML file.
+type BeeminderDB struct {
+	Goals []*BeeminderGoal `toml:"goals"`
+}
+
+// BeeminderGoal is a single Beeminder goal stored to a TOML file along with
+// its data points.
+type BeeminderGoal struct {
+	CurrentRate float64 `toml:"current_rate"`
+
+	// GoalDate is zero for goals without an end date.
+	GoalDate time.Time `toml:"goal_date"`
+
+	// Metric is the units that the goal is measured in, like "pages".
+	Metric string `toml:"metric"`
+
+	Pledge float64 `toml:"pledge"`
+
+	// Safebuf is the number of days before the goal would derail if no more
+	// data were entered.
+	Safebuf int `toml:"safebuf"`
+
+	Slug  string `toml:"slug"`
+	Title string `toml:"title"`
+
+	DataPoints []*BeeminderDataPoint `toml:"data_points"`
+}
+
+//
+// Calendar
+//
+
+// CalAPIAttendee is an attendee of an event from the Google Calendar API.
+type CalAPIAttendee struct {
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+}
+
+// CalAPIEvent is an event from the Google Calendar API.
+type CalAPIEvent struct {
+	Attendees   []*CalAPIAttendee `json:"attendees"`
+	Description string            `json:"description"`
+	End         *CalAPITime       `json:"end"`
+	ID          string            `json:"id"`
+	Location    string            `json:"location"`
+	Organizer   *CalAPIAttendee   `json:"organizer"`
+	Start       *CalAPITime       `json:"start"`
+	Status      string            `json:"status"`
+	Summary     string            `json:"summary"`
+}
+
+// CalAPIEvents is a page of events from the Google Calendar API.
+type CalAPIEvents struct {
+	Items         []*CalAPIEvent `json:"items"`
+	NextPageToken string         `json:"nextPageToken"`
+}
+
+// CalAPIServiceAccount is the JSON key file of a Google Cloud service account.
+type CalAPIServiceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// CalAPITime is the start or end of an event from the Google Calendar API.
+// All-day events have a Date like "2021-01-02" and other events a DateTime.
+type CalAPITime struct {
+	Date     string    `json:"date"`
+	DateTime time.Time `json:"dateTime"`
+}
+
+// CalAPIToken is an access token response from Google's OAuth API.
+type CalAPIToken struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// CalEvent is a single Google Calendar event stored to a TOML file.
+type CalEvent struct {
+	// Attendees are the email addresses of the event's attendees.
+	Attendees []string `toml:"attendees"`
+
+	Description string `toml:"description"`
+
+	// EndAt is exclusive, so an all-day event ends at midnight UTC of the
+	// day after its last day.
+	EndAt time.Time `toml:"end_at"`
+
+	ID string `toml:"id"`
+
+	// IsAllDay is true for events without a time of day. Their StartAt and
+	// EndAt are at midnight UTC.
+	IsAllDay bool `toml:"is_all_day"`
+
+	Location string `toml:"location"`
+
+	// Organizer is the email address of the event's organizer.
+	Organizer string `toml:"organizer"`
+
+	StartAt time.Time `toml:"start_at"`
+
+	// Status is one of "confirmed", "tentative", or "cancelled".
+	Status string `toml:"status"`
+
+	Title string `toml:"title"`
+}
+
+// CalEventDB is a database of Google Calendar events stored to a TOML file.
+type CalEventDB struct {
+	Events []*CalEvent `toml:"events"`
+}
+
+//
+// Chess
+//
+
+// ChessComAPIGame is a single game in a Chess.com monthly archive from the API.
+type ChessComAPIGame struct {
+	Black       *ChessComAPIPlayer `json:"black"`
+	ECOURL      string             `json:"eco"`
+	EndTime     int64              `json:"end_time"`
+	PGN         string             `json:"pgn"`
+	TimeControl string             `json:"time_control"`
+	URL         string             `json:"url"`
+	UUID        string             `json:"uuid"`
+	White       *ChessComAPIPlayer `json:"white"`
+}
+
+// ChessComAPIGamesRoot is the root document for a Chess.com monthly archive
+// API request.
+type ChessComAPIGamesRoot struct {
+	Games []*ChessComAPIGame `json:"games"`
+}
+
+// ChessComAPIPlayer is one side of a Chess.com game from the API.
+type ChessComAPIPlayer struct {
+	Rating   int    `json:"rating"`
+	Result   string `json:"result"`
+	Username string `json:"username"`
+}
+
+// ChessComAPIProfile is a player's profile from the Chess.com API.
+type ChessComAPIProfile struct {
+	Joined int64 `json:"joined"`
+}
+
+// ChessDB is a database of chess games stored to a TOML file.
+type ChessDB struct {
+	Games []*ChessGame `toml:"games"`
+}
+
+// ChessGame is a single game played on either Chess.com or Lichess stored to
+// a TOML file. Results, colors, and ratings are from the perspective of the
+// syncing user.
+type ChessGame struct {
+	ID               string    `toml:"id"`
+	MyColor          string    `toml:"my_color"`
+	MyRating         int       `toml:"my_rating"`
+	Opening          string    `toml:"opening"`
+	OpponentRating   int       `toml:"opponent_rating"`
+	OpponentUsername string    `toml:"opponent_username"`
+	PGN              string    `toml:"pgn"`
+	Platform         string    `toml:"platform"`
+	PlayedAt         time.Time `toml:"played_at"`
+	Result           string    `toml:"result"`
+	TimeControl      string    `toml:"time_control"`
+}
+
+// LichessAPIClock is the clock of a real time Lichess game from the API.
+type LichessAPIClock struct {
+	Increment int `json:"increment"`
+	Initial   int `json:"initial"`
+}
+
+// LichessAPIGame is a single game from the Lichess API.
+type LichessAPIGame struct {
+	Clock       *LichessAPIClock   `json:"clock"`
+	DaysPerTurn int                `json:"daysPerTurn"`
+	ID          string             `json:"id"`
+	LastMoveAt  int64              `json:"lastMoveAt"`
+	Opening     *LichessAPIOpening `json:"opening"`
+	PGN         string             `json:"pgn"`
+	Players     *LichessAPIPlayers `json:"players"`
+	Status      string             `json:"status"`
+	Winner      string             `json:"winner"`
+}
+
+// LichessAPIOpening is the opening of a Lichess game from the API.
+type LichessAPIOpening struct {
+	ECO  string `json:"eco"`
+	Name string `json:"name"`
+}
+
+// LichessAPIPlayer is one side of a Lichess game from the API. User is nil
+// when the side was played by the computer.
+type LichessAPIPlayer struct {
+	AILevel int             `json:"aiLevel"`
+	Rating  int             `json:"rating"`
+	User    *LichessAPIUser `json:"user"`
+}
+
+// LichessAPIPlayers are both sides of a Lichess game from the API.
+type LichessAPIPlayers struct {
+	Black *LichessAPIPlayer `json:"black"`
+	White *LichessAPIPlayer `json:"white"`
+}
+
+// LichessAPIUser is a user playing one side of a Lichess game from the API.
+type LichessAPIUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+//
+// Clockify
+//
+
+// ClockifyAPIProject is a project from the Clockify API.
+type ClockifyAPIProject struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	WorkspaceID string `json:"workspaceId"`
+}
+
+// ClockifyAPITag is a tag from the Clockify API.
+type ClockifyAPITag struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	WorkspaceID string `json:"workspaceId"`
+}
+
+// ClockifyAPITimeEntry is a time entry from the Clockify API.
+type ClockifyAPITimeEntry struct {
+	Billable     bool                     `json:"billable"`
+	Description  string                   `json:"description"`
+	ID           string                   `json:"id"`
+	ProjectID    string                   `json:"projectId"`
+	TagIDs       []string                 `json:"tagIds"`
+	TimeInterval *ClockifyAPITimeInterval `json:"timeInterval"`
+	WorkspaceID  string                   `json:"workspaceId"`
+}
+
+// ClockifyAPITimeInterval is the span of time covered by a time entry from the
+// Clockify API.
+type ClockifyAPITimeInterval struct {
+	// End is nil for an entry that's still running.
+	End *time.Time `json:"end"`
+
+	Start time.Time `json:"start"`
+}
+
+// ClockifyAPIUser is the authenticated user from the Clockify API.
+type ClockifyAPIUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ClockifyAPIWorkspace is a workspace from the Clockify API.
+type ClockifyAPIWorkspace struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ClockifyDB is a database of Clockify time entries stored to a TOML file.
+type ClockifyDB struct {
+	Entries []*ClockifyEntry `toml:"entries"`
+}
+
+// ClockifyEntry is a single Clockify time entry stored to a TOML file.
+type ClockifyEntry struct {
+	Billable        bool      `toml:"billable"`
+	Description     string    `toml:"description"`
+	DurationSeconds int       `toml:"duration_seconds"`
+	EndedAt         time.Time `toml:"ended_at"`
+	ID              string    `toml:"id"`
+	ProjectID       string    `toml:"project_id"`
+	ProjectName     string    `toml:"project_name"`
+	StartedAt       time.Time `toml:"started_at"`
+	Tags            []string  `toml:"tags"`
+	WorkspaceID     string    `toml:"workspace_id"`
+}
+
+//
+// Cloudflare
+//
+
+// CloudflareAPIGraphQLRequest is a request to Cloudflare's GraphQL Analytics
+// API.
+type CloudflareAPIGraphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// CloudflareAPIGraphQLResponse is the response to a request for a zone's HTTP
+// request groups from Cloudflare's GraphQL Analytics API. GraphQL responds
+// with errors in its body, often alongside a 200 status code.
+type CloudflareAPIGraphQLResponse struct {
+	Data *struct {
+		Viewer struct {
+			Zones []*struct {
+				HTTPRequestsAdaptiveGroups []*CloudflareAPIHTTPRequestsGroup `json:"httpRequestsAdaptiveGroups"`
+			} `json:"zones"`
+		} `json:"viewer"`
+	} `json:"data"`
+
+	Errors []*struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// CloudflareAPIHTTPRequestsGroup is a group of HTTP requests sharing the same
+// dimensions from Cloudflare's GraphQL Analytics API.
+type CloudflareAPIHTTPRequestsGroup struct {
+	Count int64 `json:"count"`
+
+	Dimensions struct {
+		CacheStatus       string `json:"cacheStatus"`
+		ClientCountryName string `json:"clientCountryName"`
+		Date              string `json:"date"`
+		SecurityAction    string `json:"securityAction"`
+	} `json:"dimensions"`
+
+	Sum struct {
+		EdgeResponseBytes int64 `json:"edgeResponseBytes"`
+		Visits            int64 `json:"visits"`
+	} `json:"sum"`
+}
+
+// CloudflareDB is a database of Cloudflare traffic days stored to a TOML file.
+type CloudflareDB struct {
+	Days []*CloudflareDay `toml:"days"`
+}
+
+// CloudflareDay is a day of traffic to a Cloudflare zone stored to a TOML
+// file.
+type CloudflareDay struct {
+	// Bandwidth is the number of bytes served to clients.
+	Bandwidth int64 `toml:"bandwidth"`
+
+	// CachedRequests is the number of requests served from Cloudflare's
+	// cache instead of the origin.
+	CachedRequests int64 `toml:"cached_requests"`
+
+	Date     time.Time `toml:"date"`
+	Requests int64     `toml:"requests"`
+
+	// Threats is the number of requests that were blocked or challenged.
+	Threats int64 `toml:"threats"`
+
+	// TopCountries maps the two-letter codes of the countries that sent the
+	// most requests to their number of requests.
+	TopCountries map[string]int64 `toml:"top_countries"`
+
+	// UniqueVisitors is the number of visits, which Cloudflare counts as page
+	// views coming from another site or from no referer. Cloudflare's
+	// adaptive datasets don't count unique IPs.
+	UniqueVisitors int64 `toml:"unique_visitors"`
+}
+
+//
+// Exist
+//
+
+// ExistAPIAttribute is an attribute that a user has from the Exist API.
+type ExistAPIAttribute struct {
+	// Name is an identifier like "steps" or "mood".
+	Name string `json:"name"`
+}
+
+// ExistAPIPage is a page of results from the Exist API.
+type ExistAPIPage[T any] struct {
+	// Next is the URL of the next page, or nil if this is the last one.
+	Next *string `json:"next"`
+
+	Results []T `json:"results"`
+}
+
+// ExistAPIValue is an attribute's value on a single day from the Exist API.
+type ExistAPIValue struct {
+	Date string `json:"date"`
+
+	// Value's type depends on the attribute, and may be a number or a
+	// string. It's nil for days without data.
+	Value interface{} `json:"value"`
+}
+
+// ExistDay is a single day of Exist attribute values stored to a TOML file.
+type ExistDay struct {
+	// Attributes are the day's values keyed by attribute name. Which
+	// attributes are present depends on the services that the user has
+	// connected to Exist. Attributes without data for the day are left out.
+	Attributes map[string]interface{} `toml:"attributes"`
+
+	Date time.Time `toml:"date"`
+}
+
+// ExistDB is a database of Exist days stored to a TOML file.
+type ExistDB struct {
+	Days []*ExistDay `toml:"days"`
+}
+
+//
+// Export
+//
+
+// ExportHTMLData is the data rendered by the `export-html` command's template.
+type ExportHTMLData struct {
+	Readings []*Reading
+	Tweets   []*Tweet
+}
+
+// ExportOPMLData is the data rendered by the `export-opml` command's template.
+type ExportOPMLData struct {
+	Readings []*Reading
+}
+
+//
+// Goodreads
+//
+
+// APIBook is the book nested within a Goodreads review from the API.
+type APIBook struct {
+	XMLName struct{} `xml:"book"`
+
+	Authors         []*APIBookAuthor `xml:"authors>author"`
+	CommunityRating float64          `xml:"average_rating"`
+	Format          string           `xml:"format"`
+	ID              int              `xml:"id"`
+	ImageURL        string           `xml:"image_url"`
+	ISBN            string           `xml:"isbn"`
+	ISBN13          string           `xml:"isbn13"`
+	LanguageCode    string           `xml:"language_code"`
+	NumPages        int              `xml:"num_pages"`
+	PublishedYear   int              `xml:"published"`
+	Title           string           `xml:"title"`
+}
+
+// APIBookAuthor is an author nested within a Goodreads book from the API.
+type APIBookAuthor struct {
+	XMLName struct{} `xml:"author"`
+
+	ID   int    `xml:"id"`
+	Name string `xml:"name"`
+	Role string `xml:"role"`
+}
+
+// APIChallenge is a single reading challenge within a Goodreads challenges
+// API request.
+type APIChallenge struct {
+	XMLName struct{} `xml:"challenge"`
+
+	// BookIDs are the IDs of the books that have counted towards the
+	// challenge.
+	BookIDs []int `xml:"books>book>id"`
+
+	ID   int    `xml:"id"`
+	Name string `xml:"name"`
+}
+
+// APIChallengesRoot is the root document for a Goodreads challenges API
+// request.
+type APIChallengesRoot struct {
+	XMLName struct{} `xml:"GoodreadsResponse"`
+
+	Challenges []*APIChallenge `xml:"challenges>challenge"`
+}
+
+// APIFriend is a single user within a Goodreads friends API request.
+type APIFriend struct {
+	XMLName struct{} `xml:"user"`
+
+	ID   int    `xml:"id"`
+	Name string `xml:"name"`
+}
+
+// APIFriends is a page of a user's friends within a Goodreads friends API
+// request.
+type APIFriends struct {
+	XMLName struct{} `xml:"friends"`
+
+	// Total is the number of friends that the user has across all pages.
+	Total int `xml:"total,attr"`
+
+	Users []*APIFriend `xml:"user"`
+}
+
+// APIFriendsRoot is the root document for a Goodreads friends API request.
+type APIFriendsRoot struct {
+	XMLName struct{} `xml:"GoodreadsResponse"`
+
+	Friends *APIFriends `xml:"friends"`
+}
+
+// APIReview is a single review within a Goodreads reviews API request.
+type APIReview struct {
+	XMLName struct{} `xml:"review"`
+
+	Body      string   `xml:"body"`
+	Book      *APIBook `xml:"book"`
+	DateAdded string   `xml:"date_added"`
+	ID        int      `xml:"id"`
+	Rating    int      `xml:"rating"`
+	ReadAt    string   `xml:"read_at"`
+
+	// Shelves are the shelves that the review's book is on.
+	Shelves []*APIReviewShelf `xml:"shelves>shelf"`
+
+	// StartedAt is only set if the user marked the date that they started
+	// the book.
+	StartedAt string `xml:"started_at"`
+
+	UpdatedAt string `xml:"updated_at"`
+}
+
+// APIReviewShelf is a shelf nested within a Goodreads review from the API.
+type APIReviewShelf struct {
+	XMLName struct{} `xml:"shelf"`
+
+	Name string `xml:"name,attr"`
+}
 
 // APIReviewsRoot is the root document for a Goodreads reviews API request.
 type APIReviewsRoot struct {
 	XMLName struct{} `xml:"GoodreadsResponse"`
 
-	Reviews []*APIReview `xml:"reviews>review"`
+	Reviews []*APIReview `xml:"reviews>review"`
+}
+
+// FriendReading is a single Goodreads book read by one of the user's friends
+// stored to a TOML file. Its fields from Reading are flattened into it.
+type FriendReading struct {
+	Reading
+
+	FriendID   int    `toml:"friend_id"`
+	FriendName string `toml:"friend_name"`
+}
+
+// FriendReadingDB is a database of Goodreads friends' readings stored to a
+// TOML file.
+type FriendReadingDB struct {
+	Readings []*FriendReading `toml:"readings"`
+}
+
+// RatingPoint is a rating that a reading had as of a point in time. See
+// Reading.RatingHistory.
+type RatingPoint struct {
+	Rating     int       `toml:"rating"`
+	RecordedAt time.Time `toml:"recorded_at"`
+}
+
+// Reading is a single Goodreads book stored to a TOML file.
+type Reading struct {
+	// Abandoned is true for books that were started but not finished, which
+	// come from SyncGoodreadsOptions.AbandonedShelf. They have an AbandonedAt
+	// time instead of a ReadAt time.
+	Abandoned   bool      `toml:"abandoned"`
+	AbandonedAt time.Time `toml:"abandoned_at"`
+
+	Authors []*ReadingAuthor `toml:"authors"`
+
+	// ChallengeIDs are the IDs of the Goodreads reading challenges that the
+	// book counted towards, stored by `sync-goodreads-challenges`. The
+	// reviews API doesn't include them, so they're kept from the existing
+	// data file when merging.
+	ChallengeIDs []int `toml:"challenge_ids,omitempty"`
+
+	CommunityRating float64   `toml:"community_rating"`
+	CoverURL        string    `toml:"cover_url"`
+	DateAdded       time.Time `toml:"date_added"`
+
+	// Format is the format of the edition that was read like "Hardcover",
+	// "ebook", or "Audiobook". See goodreadsKnownFormats.
+	Format string `toml:"format"`
+
+	ID     int    `toml:"id"`
+	ISBN   string `toml:"isbn"`
+	ISBN13 string `toml:"isbn13"`
+
+	// Language is the ISO 639-1 code of the language of the edition that was
+	// read like "en" or "fr". It's empty if Goodreads doesn't know it.
+	Language string `toml:"language"`
+
+	// MeanSentiment is the sentiment of the review's text from -1 (very
+	// negative) to 1 (very positive), the mean score of the words in it
+	// that are found in sentimentLexicon. It's zero if the review has no
+	// words with a sentiment, so MeanSentimentComputed tells a neutral
+	// review apart from one whose sentiment wasn't computed.
+	MeanSentiment float64 `toml:"mean_sentiment,omitempty"`
+
+	// MeanSentimentComputed is whether MeanSentiment was computed, which
+	// is only done with SyncGoodreadsOptions.ComputeSentiment and for
+	// readings with a review. It's stored separately because --compact-toml
+	// drops zero values, including a MeanSentiment of zero.
+	MeanSentimentComputed bool `toml:"mean_sentiment_computed,omitempty"`
+
+	NumPages      int       `toml:"num_pages"`
+	PublishedYear int       `toml:"published_year"`
+	ReadAt        time.Time `toml:"read_at"`
+	Rating        int       `toml:"rating"`
+	Review        string    `toml:"review"`
+	ReviewID      int       `toml:"review_id"`
+
+	// StartedAt is when the user marked that they started the book. It's
+	// zero if they never did.
+	StartedAt time.Time `toml:"started_at"`
+
+	Title string `toml:"title"`
+
+	// RatingHistory are the ratings that the book has had over time, oldest
+	// first. Goodreads only knows the current rating, so a point is added
+	// whenever a sync finds that it's changed. It's empty for books that
+	// have never been re-rated. See reconcileRatingHistory.
+	RatingHistory []*RatingPoint `toml:"rating_history,omitempty"`
+
+	// UpdatedAt is when the review was last edited on Goodreads. It's used
+	// to decide which version of a review's text to keep when merging.
+	UpdatedAt time.Time `toml:"updated_at,omitempty"`
+
+	// ReadingSpeedPPD is the number of pages read per day between the book
+	// being started (or added, if it has no start date) and being read. It's
+	// zero if either date or the number of pages is unknown, or if the book
+	// was started after it was read.
+	ReadingSpeedPPD float64 `toml:"reading_speed_ppd"`
+
+	// RereadCount is the 1-based index of this reading among all readings
+	// of the same book, ordered by ReadAt, so a book's first reading is 1
+	// and its second is 2. It's zero for abandoned readings.
+	RereadCount int `toml:"reread_count"`
+
+	// Notes are private notes added with the `annotate` command. Goodreads
+	// has no equivalent, so they're kept from the existing data file when
+	// merging.
+	Notes string `toml:"notes,omitempty"`
+
+	// RecommendedBy is who recommended the book, added with the `annotate`
+	// command. Like Notes, it's only stored locally, and the existing data
+	// file's value is always kept when merging.
+	RecommendedBy string `toml:"recommended_by,omitempty"`
+
+	// CoverLocalPath is the path of the book's cover image downloaded with
+	// SyncGoodreadsOptions.CoverDownloadDir. It's kept from the existing data
+	// file when merging so that syncs without a download directory don't
+	// drop it.
+	CoverLocalPath string `toml:"cover_local_path,omitempty"`
+
+	// WordCount is an estimate of the number of words in the book, from
+	// NumPages multiplied by SyncGoodreadsOptions.WordsPerPage. Goodreads
+	// doesn't know real word counts. It's zero if the number of pages is
+	// unknown.
+	WordCount int `toml:"word_count,omitempty" comment:"Estimated from num_pages"`
+}
+
+// ReadingMergeConflict describes a field that differed between the API's and
+// the existing version of a reading when they were merged, and which version
+// was kept. It's written as JSON to mergeReadings' conflict log.
+type ReadingMergeConflict struct {
+	APIValue string `json:"api_value"`
+
+	// Chosen is the version that was kept, either readingMergeChosenAPI or
+	// readingMergeChosenExisting.
+	Chosen string `json:"chosen"`
+
+	ExistingValue string `json:"existing_value"`
+	Field         string `json:"field"`
+	ReviewID      int    `json:"review_id"`
+}
+
+// ReadingAuthor is a single Goodreads author stored to a TOML file.
+type ReadingAuthor struct {
+	ID   int    `toml:"id"`
+	Name string `toml:"name"`
+
+	// Role is the contributor's role on the book like "Translator" or
+	// "Editor". It's empty for a book's primary authors.
+	Role string `toml:"role,omitempty"`
+}
+
+// ReadingDB is a database of Goodreads readings stored to a TOML file.
+type ReadingDB struct {
+	Readings []*Reading `toml:"readings"`
+
+	// Version is the schema version that the database was written with. See
+	// SchemaVersion.
+	Version int `toml:"version"`
+}
+
+//
+// Google Photos
+//
+
+// GooglePhoto is a single photo or video from Google Photos stored to a TOML
+// file. Camera information is only available for photos.
+type GooglePhoto struct {
+	// AlbumIDs are the IDs of the albums that the photo is in.
+	AlbumIDs []string `toml:"album_ids"`
+
+	ApertureFNumber float64 `toml:"aperture_f_number"`
+	CameraMake      string  `toml:"camera_make"`
+	CameraModel     string  `toml:"camera_model"`
+
+	// CreatedAt is when the photo was taken, or when it was uploaded if
+	// Google Photos doesn't know that.
+	CreatedAt time.Time `toml:"created_at"`
+
+	// ExposureTime is a duration in seconds like "0.008s".
+	ExposureTime string `toml:"exposure_time"`
+
+	Filename string `toml:"filename"`
+
+	// FocalLength is in millimeters.
+	FocalLength float64 `toml:"focal_length"`
+
+	Height        int    `toml:"height"`
+	ID            string `toml:"id"`
+	ISOEquivalent int    `toml:"iso_equivalent"`
+	MimeType      string `toml:"mime_type"`
+	Width         int    `toml:"width"`
+}
+
+// GooglePhotosAPIAlbum is an album from the Google Photos Library API.
+type GooglePhotosAPIAlbum struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// GooglePhotosAPIAlbums is a page of albums from the Google Photos Library
+// API.
+type GooglePhotosAPIAlbums struct {
+	Albums        []*GooglePhotosAPIAlbum `json:"albums"`
+	NextPageToken string                  `json:"nextPageToken"`
+}
+
+// GooglePhotosAPICredentials is a JSON file of OAuth credentials for a Google
+// user, in the "authorized_user" format written by tools like `gcloud auth
+// application-default login`.
+type GooglePhotosAPICredentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// GooglePhotosAPIMediaItem is a photo or video from the Google Photos Library
+// API.
+type GooglePhotosAPIMediaItem struct {
+	Filename      string                        `json:"filename"`
+	ID            string                        `json:"id"`
+	MediaMetadata *GooglePhotosAPIMediaMetadata `json:"mediaMetadata"`
+	MimeType      string                        `json:"mimeType"`
+}
+
+// GooglePhotosAPIMediaItems is a page of photos and videos from the Google
+// Photos Library API.
+type GooglePhotosAPIMediaItems struct {
+	MediaItems    []*GooglePhotosAPIMediaItem `json:"mediaItems"`
+	NextPageToken string                      `json:"nextPageToken"`
+}
+
+// GooglePhotosAPIMediaMetadata is metadata of a photo or video from the
+// Google Photos Library API. Width and Height are integers encoded as
+// strings.
+type GooglePhotosAPIMediaMetadata struct {
+	CreationTime time.Time                     `json:"creationTime"`
+	Height       string                        `json:"height"`
+	Photo        *GooglePhotosAPIPhotoMetadata `json:"photo"`
+	Width        string                        `json:"width"`
+}
+
+// GooglePhotosAPIPhotoMetadata is metadata specific to a photo from the
+// Google Photos Library API.
+type GooglePhotosAPIPhotoMetadata struct {
+	ApertureFNumber float64 `json:"apertureFNumber"`
+	CameraMake      string  `json:"cameraMake"`
+	CameraModel     string  `json:"cameraModel"`
+	ExposureTime    string  `json:"exposureTime"`
+	FocalLength     float64 `json:"focalLength"`
+	ISOEquivalent   int     `json:"isoEquivalent"`
+}
+
+// GooglePhotosDB is a database of Google Photos photos stored to a TOML file.
+type GooglePhotosDB struct {
+	Photos []*GooglePhoto `toml:"photos"`
+}
+
+//
+// HTTP
+//
+
+// contextTransport is an http.RoundTripper that attaches a context to every
+// request that it makes. It's for use with clients like go-twitter that don't
+// take a context of their own.
+type contextTransport struct {
+	ctx context.Context
+
+	// base is the transport used to make requests. If nil,
+	// http.DefaultTransport is used.
+	base http.RoundTripper
+}
+
+// RoundTrip makes a request with the transport's context.
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req.WithContext(t.ctx))
+}
+
+//
+// Kobo
+//
+
+// KoboAnnotation is a single highlight or note from a Kobo e-reader stored to
+// a TOML file.
+type KoboAnnotation struct {
+	// Annotation is the note attached to the highlight, if any.
+	Annotation string `toml:"annotation"`
+
+	BookTitle    string    `toml:"book_title"`
+	ChapterTitle string    `toml:"chapter_title"`
+	CreatedAt    time.Time `toml:"created_at"`
+	ID           string    `toml:"id"`
+
+	// Text is the highlighted text.
+	Text string `toml:"text"`
+}
+
+// KoboAnnotationDB is a database of Kobo highlights and notes stored to a
+// TOML file.
+type KoboAnnotationDB struct {
+	Annotations []*KoboAnnotation `toml:"annotations"`
+}
+
+// KoboSession is a single reading session from a Kobo e-reader stored to a
+// TOML file.
+type KoboSession struct {
+	BookTitle   string    `toml:"book_title"`
+	DurationSec int       `toml:"duration_sec"`
+	EndedAt     time.Time `toml:"ended_at"`
+	ID          string    `toml:"id"`
+	StartedAt   time.Time `toml:"started_at"`
+}
+
+// KoboSessionDB is a database of Kobo reading sessions stored to a TOML file.
+type KoboSessionDB struct {
+	Sessions []*KoboSession `toml:"sessions"`
+}
+
+//
+// LinkedIn
+//
+
+// LinkedInAPIArticle is a long-form article from LinkedIn's Articles API.
+type LinkedInAPIArticle struct {
+	Content struct {
+		HTMLContent struct {
+			HTMLText string `json:"htmlText"`
+		} `json:"com.linkedin.publishing.HtmlContent"`
+	} `json:"content"`
+
+	CoverImageURL string `json:"coverImageUrl"`
+	ID            string `json:"id"`
+
+	// PublishedAt is in milliseconds since the epoch.
+	PublishedAt int64 `json:"publishedAt"`
+
+	Statistics struct {
+		Views int `json:"views"`
+	} `json:"statistics"`
+
+	Title string `json:"title"`
+}
+
+// LinkedInAPIArticlesRoot is the root document for a LinkedIn Articles API
+// request.
+type LinkedInAPIArticlesRoot struct {
+	Elements []*LinkedInAPIArticle `json:"elements"`
+	Paging   *LinkedInAPIPaging    `json:"paging"`
+}
+
+// LinkedInAPIMe is the authenticated member from the LinkedIn API.
+type LinkedInAPIMe struct {
+	ID string `json:"id"`
+}
+
+// LinkedInAPIPaging is the paging information of a LinkedIn API collection.
+type LinkedInAPIPaging struct {
+	Count int `json:"count"`
+	Start int `json:"start"`
+	Total int `json:"total"`
+}
+
+// LinkedInAPIPost is a post from LinkedIn's UGC Posts API.
+type LinkedInAPIPost struct {
+	Created struct {
+		Time int64 `json:"time"`
+	} `json:"created"`
+
+	ID string `json:"id"`
+
+	SpecificContent struct {
+		ShareContent struct {
+			ShareCommentary struct {
+				Text string `json:"text"`
+			} `json:"shareCommentary"`
+		} `json:"com.linkedin.ugc.ShareContent"`
+	} `json:"specificContent"`
+
+	Visibility struct {
+		MemberNetworkVisibility string `json:"com.linkedin.ugc.MemberNetworkVisibility"`
+	} `json:"visibility"`
+}
+
+// LinkedInAPIPostsRoot is the root document for a LinkedIn UGC Posts API
+// request.
+type LinkedInAPIPostsRoot struct {
+	Elements []*LinkedInAPIPost `json:"elements"`
+	Paging   *LinkedInAPIPaging `json:"paging"`
+}
+
+// LinkedInAPISocialActions are the likes and comments on a post from
+// LinkedIn's Social Actions API.
+type LinkedInAPISocialActions struct {
+	CommentsSummary struct {
+		AggregatedTotalComments int `json:"aggregatedTotalComments"`
+	} `json:"commentsSummary"`
+
+	LikesSummary struct {
+		TotalLikes int `json:"totalLikes"`
+	} `json:"likesSummary"`
+}
+
+// LinkedInArticle is a single LinkedIn article stored to a TOML file.
+type LinkedInArticle struct {
+	CommentCount int `toml:"comment_count"`
+
+	// Content is the article's body converted from HTML to plain text. See
+	// sanitizeLinkedInArticle.
+	Content string `toml:"content"`
+
+	CoverImageURL string    `toml:"cover_image_url"`
+	ID            string    `toml:"id"`
+	LikeCount     int       `toml:"like_count"`
+	PublishedAt   time.Time `toml:"published_at"`
+	Title         string    `toml:"title"`
+	ViewCount     int       `toml:"view_count"`
+}
+
+// LinkedInArticleDB is a database of LinkedIn articles stored to a TOML file.
+type LinkedInArticleDB struct {
+	Articles []*LinkedInArticle `toml:"articles"`
+}
+
+// LinkedInDB is a database of LinkedIn posts stored to a TOML file.
+type LinkedInDB struct {
+	Posts []*LinkedInPost `toml:"posts"`
+}
+
+// LinkedInPost is a single LinkedIn post stored to a TOML file.
+type LinkedInPost struct {
+	CommentCount int       `toml:"comment_count"`
+	CreatedAt    time.Time `toml:"created_at"`
+	ID           string    `toml:"id"`
+	LikeCount    int       `toml:"like_count"`
+
+	// ShareCount is the number of times the post was reshared. LinkedIn only
+	// exposes it for posts by organizations, so it's zero for a member's
+	// posts.
+	ShareCount int `toml:"share_count"`
+
+	Text string `toml:"text"`
+
+	// Visibility is who the post is visible to, like "PUBLIC" or
+	// "CONNECTIONS".
+	Visibility string `toml:"visibility"`
+}
+
+//
+// Medium
+//
+
+// MediumAPIPost is a post from Medium's undocumented post API. Only the fields
+// that aren't available from its RSS feed are included.
+type MediumAPIPost struct {
+	Content  *MediumAPIPostContent  `json:"content"`
+	ID       string                 `json:"id"`
+	Virtuals *MediumAPIPostVirtuals `json:"virtuals"`
+}
+
+// MediumAPIPostContent is the content of a post from Medium's post API.
+type MediumAPIPostContent struct {
+	Subtitle string `json:"subtitle"`
+}
+
+// MediumAPIPostRoot is the root document for a request to Medium's post API.
+type MediumAPIPostRoot struct {
+	Payload struct {
+		Value *MediumAPIPost `json:"value"`
+	} `json:"payload"`
+}
+
+// MediumAPIPostVirtuals are computed fields of a post from Medium's post
+// API.
+type MediumAPIPostVirtuals struct {
+	TotalClapCount int `json:"totalClapCount"`
+}
+
+// MediumDB is a database of Medium posts stored to a TOML file.
+type MediumDB struct {
+	Posts []*MediumPost `toml:"posts"`
+}
+
+// MediumPost is a single Medium post stored to a TOML file.
+type MediumPost struct {
+	// ClapCount isn't available from the RSS feed, and comes from Medium's
+	// undocumented post API instead. If that couldn't be reached, it's kept
+	// from the previous sync, or zero for a new post.
+	ClapCount int `toml:"clap_count"`
+
+	ID          string    `toml:"id"`
+	PublishedAt time.Time `toml:"published_at"`
+
+	// Subtitle comes from Medium's undocumented post API, like ClapCount.
+	Subtitle string `toml:"subtitle"`
+
+	Tags      []string  `toml:"tags"`
+	Title     string    `toml:"title"`
+	UpdatedAt time.Time `toml:"updated_at"`
+	URL       string    `toml:"url"`
+}
+
+// MediumRSSItem is a single post in a Medium RSS feed.
+type MediumRSSItem struct {
+	Categories []string `xml:"category"`
+	GUID       string   `xml:"guid"`
+	Link       string   `xml:"link"`
+	PubDate    string   `xml:"pubDate"`
+	Title      string   `xml:"title"`
+	Updated    string   `xml:"http://www.w3.org/2005/Atom updated"`
+}
+
+// MediumRSSRoot is the root document of a Medium RSS feed.
+type MediumRSSRoot struct {
+	XMLName struct{} `xml:"rss"`
+
+	Items []*MediumRSSItem `xml:"channel>item"`
+}
+
+//
+// Monzo
+//
+
+// MonzoAPIAccount is a bank account from the Monzo API.
+type MonzoAPIAccount struct {
+	Closed bool   `json:"closed"`
+	ID     string `json:"id"`
+}
+
+// MonzoAPIAccountsRoot is the root document for a Monzo accounts API
+// request.
+type MonzoAPIAccountsRoot struct {
+	Accounts []*MonzoAPIAccount `json:"accounts"`
+}
+
+// MonzoAPIMerchant is the merchant of a Monzo transaction from the API. It's
+// only included when requested with `expand[]=merchant`.
+type MonzoAPIMerchant struct {
+	Address *MonzoAPIMerchantAddress `json:"address"`
+	Name    string                   `json:"name"`
+}
+
+// MonzoAPIMerchantAddress is the address of a Monzo merchant from the API.
+type MonzoAPIMerchantAddress struct {
+	City string `json:"city"`
+}
+
+// MonzoAPITransaction is a transaction from the Monzo API.
+type MonzoAPITransaction struct {
+	Amount      int               `json:"amount"`
+	Category    string            `json:"category"`
+	Created     time.Time         `json:"created"`
+	Currency    string            `json:"currency"`
+	Description string            `json:"description"`
+	ID          string            `json:"id"`
+	IsLoad      bool              `json:"is_load"`
+	Merchant    *MonzoAPIMerchant `json:"merchant"`
+	Notes       string            `json:"notes"`
+}
+
+// MonzoAPITransactionsRoot is the root document for a Monzo transactions API
+// request.
+type MonzoAPITransactionsRoot struct {
+	Transactions []*MonzoAPITransaction `json:"transactions"`
+}
+
+// MonzoDB is a database of Monzo transactions stored to a TOML file.
+type MonzoDB struct {
+	Transactions []*MonzoTransaction `toml:"transactions"`
+}
+
+// MonzoTransaction is a single Monzo transaction stored to a TOML file.
+type MonzoTransaction struct {
+	// Amount is in the minor units of Currency (e.g. pence), and is negative
+	// for debits.
+	Amount int `toml:"amount"`
+
+	Category     string    `toml:"category"`
+	CreatedAt    time.Time `toml:"created_at"`
+	Currency     string    `toml:"currency"`
+	Description  string    `toml:"description"`
+	ID           string    `toml:"id"`
+	IsLoad       bool      `toml:"is_load"`
+	MerchantCity string    `toml:"merchant_city"`
+	MerchantName string    `toml:"merchant_name"`
+	Notes        string    `toml:"notes"`
+}
+
+//
+// NomadList
+//
+
+// NomadListAPIProfile is a user's public profile from NomadList. Only travel
+// history is included.
+type NomadListAPIProfile struct {
+	Trips []*NomadListAPITrip `json:"trips"`
+}
+
+// NomadListAPITrip is a single stay from a NomadList profile.
+type NomadListAPITrip struct {
+	Country     string  `json:"country"`
+	CountryCode string  `json:"country_code"`
+	DateEnd     string  `json:"date_end"`
+	DateStart   string  `json:"date_start"`
+	ID          string  `json:"trip_id"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Place       string  `json:"place"`
+}
+
+// NomadListDB is a database of NomadList stays stored to a TOML file.
+type NomadListDB struct {
+	Stays []*NomadStay `toml:"stays"`
+}
+
+// NomadStay is a single NomadList stay stored to a TOML file.
+type NomadStay struct {
+	City        string    `toml:"city"`
+	Country     string    `toml:"country"`
+	CountryCode string    `toml:"country_code"`
+	DateEnd     time.Time `toml:"date_end"`
+	DateStart   time.Time `toml:"date_start"`
+
+	// DurationDays is the number of days between DateStart and DateEnd.
+	DurationDays int `toml:"duration_days"`
+
+	ID        string  `toml:"id"`
+	Latitude  float64 `toml:"latitude"`
+	Longitude float64 `toml:"longitude"`
+}
+
+//
+// OpenWeather
+//
+
+// OpenWeatherAPIHour is the weather at a single point in time from the
+// OpenWeather One Call API, requested in metric units.
+type OpenWeatherAPIHour struct {
+	Dt        int64                        `json:"dt"`
+	FeelsLike float64                      `json:"feels_like"`
+	Humidity  int                          `json:"humidity"`
+	Rain      *OpenWeatherAPIPrecipitation `json:"rain"`
+	Snow      *OpenWeatherAPIPrecipitation `json:"snow"`
+	Sunrise   int64                        `json:"sunrise"`
+	Sunset    int64                        `json:"sunset"`
+	Temp      float64                      `json:"temp"`
+	UVI       float64                      `json:"uvi"`
+
+	Weather []*OpenWeatherAPIWeather `json:"weather"`
+}
+
+// OpenWeatherAPIPrecipitation is the rain or snow that fell over the hour
+// leading up to an OpenWeather data point.
+type OpenWeatherAPIPrecipitation struct {
+	OneHourMM float64 `json:"1h"`
+}
+
+// OpenWeatherAPITimeMachineRoot is the root document for an OpenWeather One
+// Call historical weather API request.
+type OpenWeatherAPITimeMachineRoot struct {
+	Data []*OpenWeatherAPIHour `json:"data"`
+
+	// TimezoneOffset is the location's offset from UTC in seconds.
+	TimezoneOffset int `json:"timezone_offset"`
+}
+
+// OpenWeatherAPIWeather is a weather condition like rain or clouds from the
+// OpenWeather API.
+type OpenWeatherAPIWeather struct {
+	Description string `json:"description"`
+	Main        string `json:"main"`
+}
+
+// OpenWeatherDay is a single day of weather stored to a TOML file. It's
+// summarized from hourly data points in the location's time zone.
+type OpenWeatherDay struct {
+	Date          time.Time `toml:"date"`
+	FeelsLikeMaxC float64   `toml:"feels_like_max_c"`
+
+	// Humidity is the day's mean relative humidity as a percentage.
+	Humidity int `toml:"humidity"`
+
+	PrecipitationMM float64   `toml:"precipitation_mm"`
+	SunriseAt       time.Time `toml:"sunrise_at"`
+	SunsetAt        time.Time `toml:"sunset_at"`
+	TempMaxC        float64   `toml:"temp_max_c"`
+	TempMinC        float64   `toml:"temp_min_c"`
+
+	// UVI is the day's highest UV index.
+	UVI float64 `toml:"uvi"`
+
+	// WeatherMain is the day's most common weather condition like "Rain" or
+	// "Clouds", and WeatherDescription a more detailed description of it
+	// like "light rain".
+	WeatherDescription string `toml:"weather_description"`
+	WeatherMain        string `toml:"weather_main"`
+}
+
+// OpenWeatherDB is a database of OpenWeather days stored to a TOML file.
+type OpenWeatherDB struct {
+	Days []*OpenWeatherDay `toml:"days"`
+}
+
+//
+// Oura
+//
+
+// OuraAPIDailySleep is a daily sleep summary from the Oura API.
+type OuraAPIDailySleep struct {
+	Contributors *OuraAPIDailySleepContributors `json:"contributors"`
+	Day          string                         `json:"day"`
+	Score        int                            `json:"score"`
+}
+
+// OuraAPIDailySleepContributors are the scores contributing to the overall
+// score of a daily sleep summary from the Oura API.
+type OuraAPIDailySleepContributors struct {
+	Timing int `json:"timing"`
+}
+
+// OuraAPIPage is a single page of any collection from the Oura API. Data is
+// left raw so that it can be decoded to a type specific to the collection.
+type OuraAPIPage struct {
+	Data      json.RawMessage `json:"data"`
+	NextToken *string         `json:"next_token"`
+}
+
+// OuraAPIReadiness is a daily readiness summary from the Oura API.
+type OuraAPIReadiness struct {
+	Contributors         *OuraAPIReadinessContributors `json:"contributors"`
+	Day                  string                        `json:"day"`
+	Score                int                           `json:"score"`
+	TemperatureDeviation float64                       `json:"temperature_deviation"`
+}
+
+// OuraAPIReadinessContributors are the scores contributing to the overall
+// score of a daily readiness summary from the Oura API.
+type OuraAPIReadinessContributors struct {
+	ActivityBalance     int `json:"activity_balance"`
+	BodyTemperature     int `json:"body_temperature"`
+	HRVBalance          int `json:"hrv_balance"`
+	PreviousDayActivity int `json:"previous_day_activity"`
+	PreviousNight       int `json:"previous_night"`
+	RecoveryIndex       int `json:"recovery_index"`
+	RestingHeartRate    int `json:"resting_heart_rate"`
+	SleepBalance        int `json:"sleep_balance"`
+}
+
+// OuraAPISleepPeriod is a single sleep period from the Oura API. There may be
+// more than one for any given day (e.g. naps).
+type OuraAPISleepPeriod struct {
+	Day                string                       `json:"day"`
+	DeepSleepDuration  int                          `json:"deep_sleep_duration"`
+	Efficiency         int                          `json:"efficiency"`
+	Latency            int                          `json:"latency"`
+	LightSleepDuration int                          `json:"light_sleep_duration"`
+	Readiness          *OuraAPISleepPeriodReadiness `json:"readiness"`
+	REMSleepDuration   int                          `json:"rem_sleep_duration"`
+	TotalSleepDuration int                          `json:"total_sleep_duration"`
+}
+
+// OuraAPISleepPeriodReadiness is readiness information nested within a sleep
+// period from the Oura API.
+type OuraAPISleepPeriodReadiness struct {
+	TemperatureDeviation float64 `json:"temperature_deviation"`
+}
+
+// OuraReadinessDay is a single day of Oura readiness stored to a TOML file.
+type OuraReadinessDay struct {
+	ActivityBalance      int       `toml:"activity_balance"`
+	BodyTemperature      int       `toml:"body_temperature"`
+	Date                 time.Time `toml:"date"`
+	HRVBalance           int       `toml:"hrv_balance"`
+	PreviousDayActivity  int       `toml:"previous_day_activity"`
+	PreviousNight        int       `toml:"previous_night"`
+	RecoveryIndex        int       `toml:"recovery_index"`
+	RestingHeartRate     int       `toml:"resting_heart_rate"`
+	Score                int       `toml:"score"`
+	SleepBalance         int       `toml:"sleep_balance"`
+	TemperatureDeviation float64   `toml:"temperature_deviation"`
+}
+
+// OuraReadinessDB is a database of Oura readiness days stored to a TOML file.
+type OuraReadinessDB struct {
+	ReadinessDays []*OuraReadinessDay `toml:"readiness_days"`
+}
+
+// OuraSleepDay is a single day of Oura sleep stored to a TOML file.
+//
+// Scores come from Oura's daily sleep summary while durations (which are in
+// seconds) come from the day's longest sleep period.
+type OuraSleepDay struct {
+	Date             time.Time `toml:"date"`
+	DeepSleep        int       `toml:"deep_sleep"`
+	Efficiency       int       `toml:"efficiency"`
+	LatencyMin       int       `toml:"latency_min"`
+	LightSleep       int       `toml:"light_sleep"`
+	REMSleep         int       `toml:"rem_sleep"`
+	Score            int       `toml:"score"`
+	TemperatureDelta float64   `toml:"temperature_delta"`
+	TimingScore      int       `toml:"timing_score"`
+	TotalSleep       int       `toml:"total_sleep"`
+}
+
+// OuraSleepDB is a database of Oura sleep days stored to a TOML file.
+type OuraSleepDB struct {
+	SleepDays []*OuraSleepDay `toml:"sleep_days"`
+}
+
+//
+// Overcast
+//
+
+// OvercastDB is a database of podcast episodes from Overcast stored to a TOML
+// file.
+type OvercastDB struct {
+	Episodes []*PodcastEpisode `toml:"episodes"`
+}
+
+// OvercastOPML is the root of an Overcast extended OPML export.
+type OvercastOPML struct {
+	XMLName struct{} `xml:"opml"`
+
+	Outlines []*OvercastOPMLOutline `xml:"body>outline"`
+}
+
+// OvercastOPMLOutline is an outline from an Overcast extended OPML export.
+// Outlines are nested, with podcast feeds (of type "rss") grouped under a
+// "feeds" outline, and their episodes (of type "podcast-episode") under each
+// feed.
+type OvercastOPMLOutline struct {
+	EnclosureURL    string `xml:"enclosureUrl,attr"`
+	Played          string `xml:"played,attr"`
+	Progress        string `xml:"progress,attr"`
+	PubDate         string `xml:"pubDate,attr"`
+	Text            string `xml:"text,attr"`
+	Title           string `xml:"title,attr"`
+	Type            string `xml:"type,attr"`
+	URL             string `xml:"url,attr"`
+	UserUpdatedDate string `xml:"userUpdatedDate,attr"`
+	XMLURL          string `xml:"xmlUrl,attr"`
+
+	Outlines []*OvercastOPMLOutline `xml:"outline"`
+}
+
+// PodcastEpisode is a single podcast episode from an Overcast export stored
+// to a TOML file.
+type PodcastEpisode struct {
+	// AddedAt is when the episode was published to its feed.
+	AddedAt time.Time `toml:"added_at"`
+
+	// EpisodeURL is the URL of the episode's audio, or of its web page if
+	// the export didn't include one. Episodes are identified by it.
+	EpisodeURL string `toml:"episode_url"`
+
+	FeedURL string `toml:"feed_url"`
+
+	// PlayedAt is when the episode was last updated by its listener if it's
+	// been played through, and zero otherwise. Overcast doesn't export the
+	// time that an episode finished playing, so this is the closest
+	// approximation of it.
+	PlayedAt time.Time `toml:"played_at"`
+
+	// Progress is the position in seconds that playback of the episode
+	// reached. It's zero for episodes that haven't been started.
+	Progress int `toml:"progress"`
+
+	ShowTitle string `toml:"show_title"`
+	Title     string `toml:"title"`
+}
+
+//
+// Runkeeper
+//
+
+// RunkeeperAPIActivity is a fitness activity from Runkeeper's Health Graph
+// API. Items in the activity feed have the same shape, but are missing
+// AverageHeartRate and Notes.
+type RunkeeperAPIActivity struct {
+	AverageHeartRate float64 `json:"average_heart_rate"`
+	Duration         float64 `json:"duration"`
+	Notes            string  `json:"notes"`
+
+	// StartTime is local to where the activity took place, and in a format
+	// like RFC 2822's without a time zone. See UTCOffset.
+	StartTime string `json:"start_time"`
+
+	TotalCalories float64 `json:"total_calories"`
+	TotalDistance float64 `json:"total_distance"`
+	Type          string  `json:"type"`
+	URI           string  `json:"uri"`
+
+	// UTCOffset is the offset in hours of StartTime from UTC.
+	UTCOffset float64 `json:"utc_offset"`
+}
+
+// RunkeeperAPIFeedRoot is the root document for a page of Runkeeper's
+// fitness activity feed. Next is the path of the next page, and is empty on
+// the last page.
+type RunkeeperAPIFeedRoot struct {
+	Items []*RunkeeperAPIActivity `json:"items"`
+	Next  string                  `json:"next"`
+	Size  int                     `json:"size"`
+}
+
+// RunkeeperActivity is a single Runkeeper fitness activity stored to a TOML
+// file.
+type RunkeeperActivity struct {
+	// AverageHeartrate is in beats per minute. It's zero if the activity was
+	// tracked without a heart rate monitor.
+	AverageHeartrate float64 `toml:"average_heartrate"`
+
+	// AveragePaceMinPerKm is computed from Duration and TotalDistanceM, and
+	// is zero for activities without a distance.
+	AveragePaceMinPerKm float64 `toml:"average_pace_min_per_km"`
+
+	// Duration is in seconds.
+	Duration float64 `toml:"duration"`
+
+	Notes          string    `toml:"notes"`
+	StartTime      time.Time `toml:"start_time"`
+	TotalCalories  float64   `toml:"total_calories"`
+	TotalDistanceM float64   `toml:"total_distance_m"`
+
+	// Type is the kind of activity like "Running", "Cycling", or "Walking".
+	Type string `toml:"type"`
+
+	URI string `toml:"uri"`
+}
+
+// RunkeeperDB is a database of Runkeeper activities stored to a TOML file.
+type RunkeeperDB struct {
+	Activities []*RunkeeperActivity `toml:"activities"`
+}
+
+//
+// RunningAHEAD
+//
+
+// RunningAheadDB is a database of RunningAHEAD workouts stored to a TOML
+// file.
+type RunningAheadDB struct {
+	Workouts []*RunningWorkout `toml:"workouts"`
+}
+
+// RunningWorkout is a single workout from a RunningAHEAD CSV export stored to
+// a TOML file.
+type RunningWorkout struct {
+	ActivityType string  `toml:"activity_type"`
+	AvgHR        int     `toml:"avg_hr"`
+	CadenceRPM   int     `toml:"cadence_rpm"`
+	CourseKm     float64 `toml:"course_km"`
+
+	// Date is when the workout happened. Exports usually only include a
+	// date, in which case it's midnight UTC.
+	Date time.Time `toml:"date"`
+
+	DurationSec int `toml:"duration_sec"`
+
+	// ID is the SHA-1 of Date and the course's name. RunningAHEAD exports
+	// don't include an identifier.
+	ID string `toml:"id"`
+
+	Notes  string `toml:"notes"`
+	ShoeID string `toml:"shoe_id"`
+}
+
+//
+// Schedule
+//
+
+// ScheduleEnv is the environment in which a schedule is installed or
+// removed. It's broken out from ScheduleOptions so that tests can stand in
+// for the OS and the commands that get run.
+type ScheduleEnv struct {
+	// Executable is the path of the qself binary that's scheduled.
+	Executable string
+
+	// GOOS is the operating system, with values like runtime.GOOS.
+	GOOS string
+
+	// HomeDir is the user's home directory, under which LaunchAgents are
+	// installed on macOS.
+	HomeDir string
+
+	// RunCommand runs an OS command with stdin as its input, and returns its
+	// combined output.
+	RunCommand func(ctx context.Context, stdin string, name string, args ...string) (string, error)
+}
+
+//
+// SleepCycle
+//
+
+// SleepCycleDB is a database of SleepCycle nights stored to a TOML file.
+type SleepCycleDB struct {
+	Nights []*SleepCycleNight `toml:"nights"`
+}
+
+// SleepCycleNight is a single night of sleep from a SleepCycle CSV export
+// stored to a TOML file.
+type SleepCycleNight struct {
+	DurationMin  int       `toml:"duration_min"`
+	EndAt        time.Time `toml:"end_at"`
+	HeartRateBPM int       `toml:"heart_rate_bpm"`
+
+	// ID is StartAt formatted as RFC 3339. SleepCycle exports don't include
+	// an identifier, and only one night can start at a given time.
+	ID string `toml:"id"`
+
+	Notes string `toml:"notes"`
+
+	// QualityPct is SleepCycle's sleep quality score from 0 to 100.
+	QualityPct float64 `toml:"quality_pct"`
+
+	StartAt time.Time `toml:"start_at"`
+	Steps   int       `toml:"steps"`
+}
+
+//
+// Steam
+//
+
+// SteamAPIGame is an owned game from Steam's GetOwnedGames API.
+type SteamAPIGame struct {
+	AppID int `json:"appid"`
+
+	// ImgIconURL is the hash of the game's icon rather than a URL. See
+	// steamIconURL.
+	ImgIconURL string `json:"img_icon_url"`
+
+	Name             string `json:"name"`
+	PlaytimeForever  int    `json:"playtime_forever"`
+	PlaytimeTwoWeeks int    `json:"playtime_2weeks"`
+	RTimeLastPlayed  int64  `json:"rtime_last_played"`
+}
+
+// SteamAPIOwnedGamesRoot is the root document for a Steam GetOwnedGames API
+// request.
+type SteamAPIOwnedGamesRoot struct {
+	Response struct {
+		GameCount int             `json:"game_count"`
+		Games     []*SteamAPIGame `json:"games"`
+	} `json:"response"`
+}
+
+// SteamDB is a database of Steam games stored to a TOML file.
+type SteamDB struct {
+	Games []*SteamGame `toml:"games"`
+}
+
+// SteamGame is a single owned Steam game stored to a TOML file.
+type SteamGame struct {
+	AppID      int    `toml:"app_id"`
+	ImgIconURL string `toml:"img_icon_url"`
+
+	// LastPlayedAt is zero for games that were never played, or were last
+	// played before Steam started tracking it.
+	LastPlayedAt time.Time `toml:"last_played_at"`
+
+	Name string `toml:"name"`
+
+	// PlaytimeForeverMin is the total number of minutes the game has been
+	// played. It only ever goes up, so the larger of the fetched and stored
+	// values is kept when merging.
+	PlaytimeForeverMin int `toml:"playtime_forever_min"`
+
+	// PlaytimeTwoWeeksMin is the number of minutes the game was played in
+	// the two weeks before the sync.
+	PlaytimeTwoWeeksMin int `toml:"playtime_two_weeks_min"`
+}
+
+//
+// Stats
+//
+
+// AuthorCount is the number of books read by a single author or contributor.
+type AuthorCount struct {
+	Count int
+	Name  string
+	Role  string
+}
+
+// NomadStats are statistics computed over a set of NomadList stays.
+type NomadStats struct {
+	NumStays int
+
+	// TotalDaysAbroad is the sum of the durations of all stays outside of
+	// StatsOptions.HomeCountryCode.
+	TotalDaysAbroad int
+}
+
+// PodcastStats are statistics computed over a set of podcast episodes.
+type PodcastStats struct {
+	// HoursListened is an estimate of time spent listening, summed from the
+	// playback progress of every episode.
+	HoursListened float64
+
+	NumEpisodes int
+	NumPlayed   int
+}
+
+// PeriodCount is the number of records falling in a period of time like a
+// year ("2006") or month ("2006-01").
+type PeriodCount struct {
+	Count  int
+	Period string
+}
+
+// FormatCount is the number of books read in a single format.
+type FormatCount struct {
+	Count  int
+	Format string
+}
+
+// LanguageCount is the number of books read in a single language.
+type LanguageCount struct {
+	Count    int
+	Language string
+}
+
+// RecommenderCount is the number of books read on the recommendation of a
+// single person.
+type RecommenderCount struct {
+	Count int
+	Name  string
+}
+
+// BookCount is the number of times a single book was read.
+type BookCount struct {
+	Count int
+	Title string
+}
+
+// ReadingSpeedBucket is the number of readings with a reading speed in a
+// range of pages per day. Min is inclusive and Max exclusive. Max is zero for
+// the last, unbounded bucket.
+type ReadingSpeedBucket struct {
+	Count int
+	Max   float64
+	Min   float64
+}
+
+// SentimentDistribution is the distribution of the sentiment of reviews of
+// readings read in a single period. Counts holds the number of readings in
+// each of the buckets bounded by statsSentimentBounds.
+type SentimentDistribution struct {
+	Counts []int
+	Mean   float64
+	Period string
+}
+
+// ReadingStats are statistics computed over a set of readings.
+type ReadingStats struct {
+	// AbandonRate is the fraction of started books that were abandoned
+	// rather than finished, ranging from 0 to 1.
+	AbandonRate float64
+
+	// NumAbandonedReadings is the number of books that were abandoned.
+	// They're not included in any other statistic.
+	NumAbandonedReadings int
+
+	NumReadings int
+
+	// NumReadingSpeeds is the number of readings with a known reading speed,
+	// and which were used to compute ReadingSpeedAvgPPD and
+	// ReadingSpeedBuckets.
+	NumReadingSpeeds int
+
+	// NumRatedReadings is the number of readings that have both a personal
+	// and community rating, and which were used to compute
+	// RatingCorrelation.
+	NumRatedReadings int
+
+	// OtherContributors are contributors who weren't a book's primary author
+	// like translators, editors, or illustrators.
+	OtherContributors []*AuthorCount
+
+	// PrimaryAuthors are the authors credited as writing each book.
+	PrimaryAuthors []*AuthorCount
+
+	// RatingCorrelation is the correlation coefficient between personal
+	// ratings and Goodreads community ratings, ranging from -1 to 1. It's
+	// only valid if RatingCorrelationOK is true.
+	RatingCorrelation   float64
+	RatingCorrelationOK bool
+
+	// ReadingSpeedAvgPPD is the mean reading speed in pages per day.
+	ReadingSpeedAvgPPD float64
+
+	// ReadingSpeedBuckets is the distribution of reading speeds.
+	ReadingSpeedBuckets []*ReadingSpeedBucket
+
+	// ReadingsByFormat is the number of readings in each format, most common
+	// first. Readings without a format aren't included.
+	ReadingsByFormat []*FormatCount
+
+	// ReadingsByLanguage is the number of readings in each language, most
+	// common first. Readings without a language aren't included.
+	ReadingsByLanguage []*LanguageCount
+
+	// ReadingsByRecommender is the number of readings recommended by each
+	// person, most common first. Readings without a recommender aren't
+	// included.
+	ReadingsByRecommender []*RecommenderCount
+
+	// ReadingsByYear is the number of readings read in each year, oldest
+	// first. Readings without a read at time aren't included.
+	ReadingsByYear []*PeriodCount
+
+	// RereadBooks are books that were read more than once, most re-read
+	// first.
+	RereadBooks []*BookCount
+
+	// SentimentByYear is the distribution of review sentiment of readings
+	// read in each year, oldest first. Readings without a sentiment aren't
+	// included.
+	SentimentByYear []*SentimentDistribution
+
+	// WordsRead is the sum of the estimated word counts of readings. See
+	// Reading.WordCount.
+	WordsRead int
+}
+
+// TweetStats are statistics computed over a set of tweets.
+type TweetStats struct {
+	// NumExcludedReplies and NumExcludedRetweets are the number of tweets
+	// left out of every other statistic by StatsOptions.NoReplies and
+	// StatsOptions.NoRetweets. A retweet that's also a reply is counted as a
+	// retweet.
+	NumExcludedReplies  int
+	NumExcludedRetweets int
+
+	NumTweets int
+
+	// TweetsByMonth is the number of tweets in each month, oldest first.
+	TweetsByMonth []*PeriodCount
+}
+
+//
+// Stripe
+//
+
+// StripeAPIBillingDetails are the billing details of a charge from the Stripe
+// API.
+type StripeAPIBillingDetails struct {
+	Email string `json:"email"`
+}
+
+// StripeAPICharge is a charge from the Stripe API.
+type StripeAPICharge struct {
+	// Amount and AmountRefunded are in the currency's smallest unit, like
+	// cents.
+	Amount         int `json:"amount"`
+	AmountRefunded int `json:"amount_refunded"`
+
+	BalanceTransaction string                   `json:"balance_transaction"`
+	BillingDetails     *StripeAPIBillingDetails `json:"billing_details"`
+	Created            int64                    `json:"created"`
+	Currency           string                   `json:"currency"`
+	Description        string                   `json:"description"`
+	ID                 string                   `json:"id"`
+	ReceiptEmail       string                   `json:"receipt_email"`
+	Refunded           bool                     `json:"refunded"`
+}
+
+// StripeAPIList is a page of a list of objects from the Stripe API.
+type StripeAPIList[T any] struct {
+	Data    []T  `json:"data"`
+	HasMore bool `json:"has_more"`
+}
+
+// StripeAPIPayout is a payout from the Stripe API.
+type StripeAPIPayout struct {
+	Amount             int    `json:"amount"`
+	ArrivalDate        int64  `json:"arrival_date"`
+	BalanceTransaction string `json:"balance_transaction"`
+	Created            int64  `json:"created"`
+	Currency           string `json:"currency"`
+	Description        string `json:"description"`
+	ID                 string `json:"id"`
+	Method             string `json:"method"`
+	Status             string `json:"status"`
+}
+
+// StripeBalance is a single Stripe payout, a transfer from the Stripe balance
+// to a bank account, stored to a TOML file.
+type StripeBalance struct {
+	AmountCents          int       `toml:"amount_cents"`
+	ArrivalAt            time.Time `toml:"arrival_at"`
+	BalanceTransactionID string    `toml:"balance_transaction_id"`
+	CreatedAt            time.Time `toml:"created_at"`
+	Currency             string    `toml:"currency"`
+	Description          string    `toml:"description"`
+	ID                   string    `toml:"id"`
+
+	// Method is either "standard" or "instant".
+	Method string `toml:"method"`
+
+	// Status is one of "paid", "pending", "in_transit", "canceled", or
+	// "failed".
+	Status string `toml:"status"`
+}
+
+// StripeBalanceDB is a database of Stripe payouts stored to a TOML file.
+type StripeBalanceDB struct {
+	Payouts []*StripeBalance `toml:"payouts"`
+}
+
+// StripeCharge is a single Stripe charge stored to a TOML file.
+type StripeCharge struct {
+	// AmountCents and RefundedAmountCents are in the currency's smallest
+	// unit, which is cents for most currencies.
+	AmountCents int `toml:"amount_cents"`
+
+	BalanceTransactionID string    `toml:"balance_transaction_id"`
+	CreatedAt            time.Time `toml:"created_at"`
+	Currency             string    `toml:"currency"`
+
+	// CustomerEmail is the email from the charge's billing details, or its
+	// receipt email if there isn't one.
+	CustomerEmail string `toml:"customer_email"`
+
+	Description         string `toml:"description"`
+	ID                  string `toml:"id"`
+	Refunded            bool   `toml:"refunded"`
+	RefundedAmountCents int    `toml:"refunded_amount_cents"`
+}
+
+// StripeChargeDB is a database of Stripe charges stored to a TOML file.
+type StripeChargeDB struct {
+	Charges []*StripeCharge `toml:"charges"`
+}
+
+//
+// Substack
+//
+
+// SubstackAPIPost is a post from Substack's undocumented posts API. Stats are
+// only included for requests made with an author's session.
+type SubstackAPIPost struct {
+	AudioURL          string    `json:"audio_url"`
+	ClickRate         float64   `json:"click_rate"`
+	ID                int       `json:"id"`
+	OpenRate          float64   `json:"open_rate"`
+	PodcastURL        string    `json:"podcast_url"`
+	PostDate          time.Time `json:"post_date"`
+	ReactionCount     int       `json:"reaction_count"`
+	Slug              string    `json:"slug"`
+	Title             string    `json:"title"`
+	TruncatedBodyText string    `json:"truncated_body_text"`
+	Type              string    `json:"type"`
+	Views             int       `json:"views"`
+}
+
+// SubstackDB is a database of Substack posts stored to a TOML file.
+type SubstackDB struct {
+	Posts []*SubstackPost `toml:"posts"`
+}
+
+// SubstackPost is a single Substack post stored to a TOML file.
+type SubstackPost struct {
+	AudioURL string `toml:"audio_url"`
+
+	// ClickRate and OpenRate are fractions of the recipients of the post's
+	// email between 0 and 1.
+	ClickRate float64 `toml:"click_rate"`
+
+	ID int `toml:"id"`
+
+	// Likes is the number of reactions to the post.
+	Likes int `toml:"likes"`
+
+	OpenRate    float64   `toml:"open_rate"`
+	PodcastURL  string    `toml:"podcast_url"`
+	PublishedAt time.Time `toml:"published_at"`
+	Slug        string    `toml:"slug"`
+	Title       string    `toml:"title"`
+
+	// TruncatedBody is the beginning of the post's body as plain text.
+	TruncatedBody string `toml:"truncated_body"`
+
+	// Type is the kind of post like "newsletter", "podcast", or "thread".
+	Type string `toml:"type"`
+
+	Views int `toml:"views"`
+}
+
+//
+// Telegram
+//
+
+// TelegramAPIChat is a chat from Telegram's Bot API, like a channel.
+type TelegramAPIChat struct {
+	ID       int64  `json:"id"`
+	Title    string `json:"title"`
+	Username string `json:"username"`
+}
+
+// TelegramAPIDocument is a file attached to a message from Telegram's Bot
+// API.
+type TelegramAPIDocument struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name"`
+}
+
+// TelegramAPIFile is a file ready to be downloaded from Telegram's Bot API.
+type TelegramAPIFile struct {
+	FileID   string `json:"file_id"`
+	FilePath string `json:"file_path"`
+}
+
+// TelegramAPIMessage is a message from Telegram's Bot API.
+type TelegramAPIMessage struct {
+	Caption  string               `json:"caption"`
+	Chat     *TelegramAPIChat     `json:"chat"`
+	Date     int64                `json:"date"`
+	Document *TelegramAPIDocument `json:"document"`
+
+	// ForwardDate, ForwardFromChat, and ForwardFromMessageID are set on
+	// messages that were forwarded from a channel, and describe the
+	// original message.
+	ForwardDate          int64            `json:"forward_date"`
+	ForwardFromChat      *TelegramAPIChat `json:"forward_from_chat"`
+	ForwardFromMessageID int              `json:"forward_from_message_id"`
+
+	MessageID int `json:"message_id"`
+
+	// Photo contains the available sizes of an attached photo, smallest
+	// first.
+	Photo []*TelegramAPIPhotoSize `json:"photo"`
+
+	Text string `json:"text"`
+}
+
+// TelegramAPIPhotoSize is one size of a photo from Telegram's Bot API.
+type TelegramAPIPhotoSize struct {
+	FileID string `json:"file_id"`
+	Height int    `json:"height"`
+	Width  int    `json:"width"`
+}
+
+// TelegramAPIResponse is the envelope of every response from Telegram's Bot
+// API. Result is only set if OK is true.
+type TelegramAPIResponse struct {
+	Description string          `json:"description"`
+	ErrorCode   int             `json:"error_code"`
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// TelegramAPIUpdate is an update from Telegram's Bot API. Only one of its
+// messages is set.
+type TelegramAPIUpdate struct {
+	ChannelPost *TelegramAPIMessage `json:"channel_post"`
+	Message     *TelegramAPIMessage `json:"message"`
+	UpdateID    int                 `json:"update_id"`
+}
+
+// TelegramDB is a database of Telegram messages stored to a TOML file.
+type TelegramDB struct {
+	Messages []*TelegramMessage `toml:"messages"`
+}
+
+// TelegramMessage is a single Telegram channel message stored to a TOML file.
+type TelegramMessage struct {
+	Caption string    `toml:"caption"`
+	Date    time.Time `toml:"date"`
+
+	// DocumentURL is the path of an attached file, relative to
+	// `https://api.telegram.org/file/bot<token>/`. The bot token isn't
+	// stored so that it doesn't end up in the data file.
+	DocumentURL string `toml:"document_url"`
+
+	// ForwardFromChannel is the title of the channel that the message was
+	// originally posted to if it was forwarded into this one.
+	ForwardFromChannel string `toml:"forward_from_channel"`
+
+	MessageID int `toml:"message_id"`
+
+	// PhotoURL is the path of the largest size of an attached photo, relative
+	// to the same URL as DocumentURL.
+	PhotoURL string `toml:"photo_url"`
+
+	Text string `toml:"text"`
+
+	// Views isn't available from the Bot API, and is always zero.
+	Views int `toml:"views"`
+}
+
+//
+// Toggl
+//
+
+// TogglAPIProject is a project from the Toggl API.
+type TogglAPIProject struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	WorkspaceID int64  `json:"workspace_id"`
+}
+
+// TogglAPITimeEntry is a time entry from the Toggl API.
+type TogglAPITimeEntry struct {
+	Billable    bool   `json:"billable"`
+	Description string `json:"description"`
+
+	// Duration is in seconds. It's negative for an entry that's still
+	// running.
+	Duration int `json:"duration"`
+
+	ID          int64      `json:"id"`
+	ProjectID   *int64     `json:"project_id"`
+	Start       time.Time  `json:"start"`
+	Stop        *time.Time `json:"stop"`
+	Tags        []string   `json:"tags"`
+	WorkspaceID int64      `json:"workspace_id"`
+}
+
+// TogglDB is a database of Toggl time entries stored to a TOML file.
+type TogglDB struct {
+	Entries []*TogglEntry `toml:"entries"`
+}
+
+// TogglEntry is a single Toggl time entry stored to a TOML file.
+type TogglEntry struct {
+	Billable        bool      `toml:"billable"`
+	Description     string    `toml:"description"`
+	DurationSeconds int       `toml:"duration_seconds"`
+	ID              int64     `toml:"id"`
+	ProjectID       int64     `toml:"project_id"`
+	ProjectName     string    `toml:"project_name"`
+	StartedAt       time.Time `toml:"started_at"`
+	StoppedAt       time.Time `toml:"stopped_at"`
+	Tags            []string  `toml:"tags"`
+	WorkspaceID     int64     `toml:"workspace_id"`
+}
+
+//
+// Twitter
+//
+
+// LikedTweetDB is a database of tweets liked by the user stored to a TOML
+// file.
+type LikedTweetDB struct {
+	Tweets []*LikedTweet `toml:"tweets"`
+
+	// Version is the schema version that the database was written with. See
+	// SchemaVersion.
+	Version int `toml:"version"`
+}
+
+// LikedTweet is a single tweet liked by the user stored to a TOML file.
+type LikedTweet struct {
+	Tweet
+
+	// LikedAt is when the like was first seen by a sync. Twitter's API
+	// doesn't say when a tweet was liked, so it's kept from the existing
+	// data file when merging.
+	LikedAt time.Time `toml:"liked_at"`
+
+	// User and UserID identify the tweet's author, who is usually someone
+	// other than the user.
+	User   string `toml:"user"`
+	UserID int64  `toml:"user_id"`
+}
+
+// TweetDB is a database of tweets stored to a TOML file.
+type TweetDB struct {
+	Tweets []*Tweet `toml:"tweets"`
+
+	// Version is the schema version that the database was written with. See
+	// SchemaVersion.
+	Version int `toml:"version"`
+}
+
+// Tweet is a single tweet stored to a TOML file.
+type Tweet struct {
+	// Card is the Twitter Card attached to the tweet, like a rich preview of
+	// a linked article. It's only populated when syncing with
+	// --twitter-fetch-cards, and is kept from previous syncs otherwise.
+	Card *TweetCard `toml:"card,omitempty"`
+
+	CreatedAt time.Time `toml:"created_at"`
+
+	// CreatedAtUnix is CreatedAt as a Unix timestamp in seconds, for
+	// consumers that want to order tweets by time without parsing TOML
+	// dates. CreatedAt is kept for readability.
+	CreatedAtUnix int64 `toml:"created_at_unix"`
+
+	Entities      *TweetEntities `toml:"entities"`
+	FavoriteCount int            `toml:"favorite_count,omitempty"`
+	Geo           *TweetGeo      `toml:"geo,omitempty"`
+	ID            int64          `toml:"id"`
+	Quote         *TweetQuote    `toml:"quote"`
+	Reply         *TweetReply    `toml:"reply"`
+	Retweet       *TweetRetweet  `toml:"retweet"`
+	RetweetCount  int            `toml:"retweet_count,omitempty"`
+	Text          string         `toml:"text"`
+
+	// ConversationRootText is the text of the tweet that started the thread
+	// that the tweet belongs to, for tweets that reply to one of the user's
+	// own tweets. It's truncated to tweetConversationRootTextMaxLen
+	// characters, and is empty if the root tweet isn't in the archive. See
+	// setConversationRootTexts.
+	ConversationRootText string `toml:"conversation_root_text,omitempty"`
+
+	// IsAd is true for promoted tweets, which occasionally turn up in the
+	// timeline. They're only stored with --include-ads.
+	IsAd bool `toml:"is_ad,omitempty"`
+
+	// LikesByFollowers is the tweet's favorite count divided by the user's
+	// follower count at the time of the sync. It's only populated when
+	// syncing with --compute-engagement.
+	LikesByFollowers float64 `toml:"likes_by_followers,omitempty"`
+
+	// EngagementScore is a weighted sum of the tweet's likes, retweets,
+	// replies, and bookmarks, for ranking tweets by overall impact. See
+	// --engagement-weights.
+	EngagementScore float64 `toml:"engagement_score,omitempty"`
+
+	// BookmarkCount is only available from Twitter's v2 API, so it's only
+	// populated when syncing with --twitter-api-v2.
+	BookmarkCount int `toml:"bookmark_count,omitempty"`
+
+	// ReplyCount is only available from Twitter's v2 API, so it's only
+	// populated when syncing with --twitter-api-v2.
+	ReplyCount int `toml:"reply_count,omitempty"`
+
+	// ViewCount is the number of impressions of the tweet. Like ReplyCount,
+	// it's only populated when syncing with --twitter-api-v2.
+	ViewCount int64 `toml:"view_count,omitempty"`
+
+	// TextHashSHA1 is the hex-encoded SHA-1 of Text, used to check whether
+	// text has changed between syncs without comparing it in full. It's
+	// recomputed when --expand-urls rewrites Text.
+	TextHashSHA1 string `toml:"text_hash_sha1,omitempty"`
+
+	// NormalizedText is Text with Unicode NFKC normalization applied, which
+	// folds lookalike characters like fullwidth letters into their plain
+	// equivalents and combines diacritics with the letters they modify, so
+	// that the text can be searched reliably. It's only stored when it
+	// differs from Text, and never with --no-normalize-text.
+	NormalizedText string `toml:"normalized_text,omitempty"`
+
+	// WithheldInCountries are two-letter country codes of countries in which
+	// the tweet has been withheld.
+	WithheldInCountries []string `toml:"withheld_in_countries,omitempty"`
+}
+
+// TweetAnnotation is an entity recognized in a tweet's text by Twitter.
+type TweetAnnotation struct {
+	NormalizedText string `toml:"normalized_text"`
+
+	// Probability is Twitter's confidence in the annotation. It's only stored
+	// when above 0.5 (see tweetAnnotationMinProbability) to keep low
+	// confidence noise out of the data file, and is zero otherwise.
+	Probability float64 `toml:"probability,omitempty"`
+
+	// Type is the kind of entity like "Person", "Place", or "Product".
+	Type string `toml:"type"`
+}
+
+// TweetCard is a Twitter Card attached to a tweet.
+type TweetCard struct {
+	CardDescription string `toml:"card_description,omitempty"`
+	CardTitle       string `toml:"card_title,omitempty"`
+
+	// CardType is the name of the card's type like "summary",
+	// "summary_large_image", "player", or "app".
+	CardType string `toml:"card_type"`
+}
+
+// TweetEntities contains various multimedia entries that may be contained in a
+// tweet.
+type TweetEntities struct {
+	// Annotations are entities like people and places recognized in the
+	// tweet's text. They're only available from Twitter's v2 API, so they're
+	// only populated when syncing with --twitter-api-v2.
+	Annotations []*TweetAnnotation `toml:"annotations,omitempty"`
+
+	Medias       []*TweetEntitiesMedia       `toml:"medias"`
+	URLs         []*TweetEntitiesURL         `toml:"urls"`
+	UserMentions []*TweetEntitiesUserMention `toml:"user_mentions"`
+}
+
+// TweetEntitiesMedia is an image or video stored in a tweet.
+type TweetEntitiesMedia struct {
+	ID   int64  `toml:"id"`
+	Type string `toml:"type"`
+	URL  string `toml:"url"`
+
+	// Height and Width are the dimensions of the media's largest available
+	// size in pixels, which give its aspect ratio. They're zero for tweets
+	// synced by older versions of qself, or if Twitter didn't include sizes.
+	Height int `toml:"height,omitempty"`
+	Width  int `toml:"width,omitempty"`
+
+	// ThumbnailURL is the still image shown for a video or animated GIF
+	// before it plays. It's empty for photos, whose URL is the image itself.
+	ThumbnailURL string `toml:"thumbnail_url,omitempty"`
+
+	// Variants are the available encodings of a video or animated GIF,
+	// sorted by descending bitrate. For media with variants, URL is the
+	// first (highest bitrate) of them rather than the media's thumbnail.
+	Variants []*TweetEntitiesMediaVariant `toml:"variants,omitempty"`
+}
+
+// TweetEntitiesMediaVariant is one encoding of a video or animated GIF stored
+// in a tweet.
+type TweetEntitiesMediaVariant struct {
+	// Bitrate is in bits per second. It's zero for streaming playlists like
+	// HLS, which adapt their bitrate.
+	Bitrate int `toml:"bitrate,omitempty"`
+
+	ContentType string `toml:"content_type"`
+	URL         string `toml:"url"`
+}
+
+// TweetEntitiesURL is a URL referenced in a tweet.
+type TweetEntitiesURL struct {
+	DisplayURL  string `toml:"display_url"`
+	ExpandedURL string `toml:"expanded_url"`
+
+	// Status is the HTTP status code returned by ExpandedURL when it was last
+	// checked with `--check-urls`. It's zero if it was never checked or the
+	// request failed without a response.
+	Status int `toml:"status,omitempty"`
+
+	URL string `toml:"url"`
+}
+
+// TweetEntitiesUserMention is another user being mentioned in a tweet.
+type TweetEntitiesUserMention struct {
+	User   string `toml:"user"`
+	UserID int64  `toml:"user_id"`
+}
+
+// TweetGeo is populated with location information for when a tweet was
+// geo-tagged.
+type TweetGeo struct {
+	Country     string  `toml:"country"`
+	CountryCode string  `toml:"country_code"`
+	Latitude    float64 `toml:"latitude"`
+	Longitude   float64 `toml:"longitude"`
+	PlaceName   string  `toml:"place_name"`
+	PlaceType   string  `toml:"place_type"`
+}
+
+// TweetQuote is populated with quote information for when a tweet quotes
+// another tweet.
+type TweetQuote struct {
+	// OriginalCreatedAt and OriginalText are the time and text of the quoted
+	// tweet, kept so that it can still be read if it's deleted. They're empty
+	// if the quoted tweet was already deleted (or made private) when the
+	// quoting tweet was first synced.
+	OriginalCreatedAt time.Time `toml:"original_created_at,omitempty"`
+	OriginalText      string    `toml:"original_text,omitempty"`
+
+	StatusID int64  `toml:"status_id"`
+	User     string `toml:"user,omitempty"`
+	UserID   int64  `toml:"user_id,omitempty"`
+}
+
+// TweetReply is populated with reply information for when a tweet is a
+// reply.
+type TweetReply struct {
+	StatusID int64  `toml:"status_id"`
+	User     string `toml:"user"`
+	UserID   int64  `toml:"user_id"`
+}
+
+// TweetRetweet is populated with retweet information for when a tweet is a
+// retweet.
+type TweetRetweet struct {
+	StatusID int64  `toml:"status_id"`
+	User     string `toml:"user"`
+	UserID   int64  `toml:"user_id"`
+}
+
+// TwitterAPICard is a Twitter Card attached to a tweet from Twitter's v1.1
+// API. Cards are only included when specifically requested, and aren't
+// exposed by go-twitter.
+type TwitterAPICard struct {
+	BindingValues map[string]*TwitterAPICardBindingValue `json:"binding_values"`
+	Name          string                                 `json:"name"`
+}
+
+// TwitterAPICardBindingValue is a single value of a Twitter Card, like its
+// title. Only string values are included.
+type TwitterAPICardBindingValue struct {
+	StringValue string `json:"string_value"`
+	Type        string `json:"type"`
+}
+
+// TwitterAPICardTweet is a tweet from a Twitter v1.1 lookup that includes
+// cards. Only the fields needed to attach cards to tweets are included.
+type TwitterAPICardTweet struct {
+	Card *TwitterAPICard `json:"card"`
+	ID   int64           `json:"id"`
+}
+
+// TwitterAPIV2Annotation is an entity recognized in a tweet's text from
+// Twitter's v2 API.
+type TwitterAPIV2Annotation struct {
+	NormalizedText string  `json:"normalized_text"`
+	Probability    float64 `json:"probability"`
+	Type           string  `json:"type"`
+}
+
+// TwitterAPIV2Entities are the entities of a tweet from Twitter's v2 API.
+// Only annotations are included because the rest are already available from
+// the v1.1 API.
+type TwitterAPIV2Entities struct {
+	Annotations []*TwitterAPIV2Annotation `json:"annotations"`
+}
+
+// TwitterAPIV2PublicMetrics are the public engagement metrics of a tweet from
+// Twitter's v2 API.
+type TwitterAPIV2PublicMetrics struct {
+	BookmarkCount   int   `json:"bookmark_count"`
+	ImpressionCount int64 `json:"impression_count"`
+	LikeCount       int   `json:"like_count"`
+	QuoteCount      int   `json:"quote_count"`
+	ReplyCount      int   `json:"reply_count"`
+	RetweetCount    int   `json:"retweet_count"`
+}
+
+// TwitterAPIV2Tweet is a tweet from Twitter's v2 API. Only the fields needed
+// to enrich tweets from the v1.1 API are included.
+type TwitterAPIV2Tweet struct {
+	Entities      *TwitterAPIV2Entities      `json:"entities"`
+	ID            string                     `json:"id"`
+	PublicMetrics *TwitterAPIV2PublicMetrics `json:"public_metrics"`
+}
+
+// TwitterAPIV2TweetsRoot is the root document for a Twitter v2 tweets lookup
+// API request.
+type TwitterAPIV2TweetsRoot struct {
+	Data []*TwitterAPIV2Tweet `json:"data"`
+}
+
+//
+// WakaTime
+//
+
+// WakaTimeAPIGrandTotal is the total coding time of a WakaTime summary from
+// the API.
+type WakaTimeAPIGrandTotal struct {
+	TotalSeconds float64 `json:"total_seconds"`
+}
+
+// WakaTimeAPIRange is the date range of a WakaTime summary from the API.
+type WakaTimeAPIRange struct {
+	Date string `json:"date"`
+}
+
+// WakaTimeAPIStat is a single named entry in one of the breakdowns (languages,
+// projects, etc.) of a WakaTime summary from the API.
+type WakaTimeAPIStat struct {
+	Name         string  `json:"name"`
+	TotalSeconds float64 `json:"total_seconds"`
+}
+
+// WakaTimeAPISummariesRoot is the root document for a WakaTime summaries API
+// request.
+type WakaTimeAPISummariesRoot struct {
+	Data []*WakaTimeAPISummary `json:"data"`
+}
+
+// WakaTimeAPISummary is a summary of a single day's coding activity from the
+// WakaTime API.
+type WakaTimeAPISummary struct {
+	Editors          []*WakaTimeAPIStat     `json:"editors"`
+	GrandTotal       *WakaTimeAPIGrandTotal `json:"grand_total"`
+	Languages        []*WakaTimeAPIStat     `json:"languages"`
+	OperatingSystems []*WakaTimeAPIStat     `json:"operating_systems"`
+	Projects         []*WakaTimeAPIStat     `json:"projects"`
+	Range            *WakaTimeAPIRange      `json:"range"`
+}
+
+// WakaTimeAPIUser is the current user from the WakaTime API.
+type WakaTimeAPIUser struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WakaTimeAPIUserRoot is the root document for a WakaTime current user API
+// request.
+type WakaTimeAPIUserRoot struct {
+	Data *WakaTimeAPIUser `json:"data"`
+}
+
+// WakaTimeDay is a single day of WakaTime coding activity stored to a TOML
+// file.
+type WakaTimeDay struct {
+	Date         time.Time          `toml:"date"`
+	Editors      []*WakaTimeEditor  `toml:"editors"`
+	Languages    []*WakaTimeLang    `toml:"languages"`
+	OSes         []*WakaTimeOS      `toml:"oses"`
+	Projects     []*WakaTimeProject `toml:"projects"`
+	TotalSeconds int                `toml:"total_seconds"`
+}
+
+// WakaTimeDB is a database of WakaTime days stored to a TOML file.
+type WakaTimeDB struct {
+	Days []*WakaTimeDay `toml:"days"`
+}
+
+// WakaTimeEditor is time spent in a single editor stored to a TOML file.
+type WakaTimeEditor struct {
+	Name    string `toml:"name"`
+	Seconds int    `toml:"seconds"`
+}
+
+// WakaTimeLang is time spent in a single language stored to a TOML file.
+type WakaTimeLang struct {
+	Name    string `toml:"name"`
+	Seconds int    `toml:"seconds"`
+}
+
+// WakaTimeOS is time spent on a single operating system stored to a TOML
+// file.
+type WakaTimeOS struct {
+	Name    string `toml:"name"`
+	Seconds int    `toml:"seconds"`
+}
+
+// WakaTimeProject is time spent on a single project stored to a TOML file.
+type WakaTimeProject struct {
+	Name    string `toml:"name"`
+	Seconds int    `toml:"seconds"`
+}
+
+//
+// WaniKani
+//
+
+// WaniKaniDB is a database of WaniKani objects stored to a TOML file.
+type WaniKaniDB struct {
+	ReviewsUpdatedAt  time.Time `toml:"reviews_updated_at"`
+	SubjectsUpdatedAt time.Time `toml:"subjects_updated_at"`
+
+	Reviews  []*WaniKaniReview  `toml:"reviews"`
+	Subjects []*WaniKaniSubject `toml:"subject"`
+}
+
+// WaniKaniReview is a single WaniKani review stored to a TOML file.
+type WaniKaniReview struct {
+	AssignmentID int64     `toml:"assignment_id"`
+	CreatedAt    time.Time `toml:"created_at"`
+	ID           int64     `toml:"id"`
+	SubjectID    int64     `toml:"subject_id"`
+}
+
+// WaniKaniSubject is a single WaniKani subject stored to a TOML file.
+type WaniKaniSubject struct {
+	ID               int64   `toml:"id"`
+	Level            int     `toml:"level"`
+	Meaning          string  `toml:"meaning"`
+	RadicalCharacter *string `toml:"radical_character,omitempty"`
+	Slug             string  `toml:"slug"`
+	Type             string  `toml:"type"`
+}
+
+//
+// Weather Underground
+//
+
+// PWSDayRecord is a single day of observations from a personal weather
+// station stored to a TOML file.
+type PWSDayRecord struct {
+	AvgHumidity     float64   `toml:"avg_humidity"`
+	AvgTempC        float64   `toml:"avg_temp_c"`
+	Date            time.Time `toml:"date"`
+	MaxWindSpeedKmh float64   `toml:"max_wind_speed_kmh"`
+	PrecipitationMM float64   `toml:"precipitation_mm"`
+
+	// AvgPressureHpa is the midpoint of the day's high and low pressure
+	// because Weather Underground's daily summaries don't include an average.
+	AvgPressureHpa float64 `toml:"avg_pressure_hpa"`
+
+	// MaxUVIndex is the day's highest UV index. Daily summaries don't include
+	// an average UV index, and unlike for pressure, a midpoint wouldn't be
+	// meaningful since the UV index is zero every night.
+	MaxUVIndex float64 `toml:"max_uv_index"`
+}
+
+// PWSDB is a database of personal weather station days stored to a TOML file.
+type PWSDB struct {
+	Days []*PWSDayRecord `toml:"days"`
+}
+
+// WUAPIHistoryRoot is the root document for a Weather Underground PWS history
+// API request.
+type WUAPIHistoryRoot struct {
+	Observations []*WUAPIObservation `json:"observations"`
+}
+
+// WUAPIObservation is a daily summary of observations from a personal weather
+// station from the Weather Underground API.
+type WUAPIObservation struct {
+	HumidityAvg  float64                 `json:"humidityAvg"`
+	Metric       *WUAPIObservationMetric `json:"metric"`
+	ObsTimeLocal string                  `json:"obsTimeLocal"`
+	UVHigh       float64                 `json:"uvHigh"`
+}
+
+// WUAPIObservationMetric contains the unit-dependent values of a Weather
+// Underground observation, requested in metric units.
+type WUAPIObservationMetric struct {
+	PrecipTotal   float64 `json:"precipTotal"`
+	PressureMax   float64 `json:"pressureMax"`
+	PressureMin   float64 `json:"pressureMin"`
+	TempAvg       float64 `json:"tempAvg"`
+	WindspeedHigh float64 `json:"windspeedHigh"`
+}
+
+//
+// Withings
+//
+
+// WithingsAPIMeasure is a single measure in a measure group from the Withings
+// API. Its real value is Value * 10^Unit.
+type WithingsAPIMeasure struct {
+	Type  int   `json:"type"`
+	Unit  int   `json:"unit"`
+	Value int64 `json:"value"`
+}
+
+// WithingsAPIMeasureGroup is a group of measures taken at the same time from
+// the Withings API.
+type WithingsAPIMeasureGroup struct {
+	Date     int64                 `json:"date"`
+	Measures []*WithingsAPIMeasure `json:"measures"`
+}
+
+// WithingsAPIMeasuresBody is the body of a Withings getmeas API response.
+type WithingsAPIMeasuresBody struct {
+	MeasureGroups []*WithingsAPIMeasureGroup `json:"measuregrps"`
+
+	// More is 1 if there are more measure groups to be fetched starting from
+	// Offset.
+	More   int `json:"more"`
+	Offset int `json:"offset"`
+}
+
+// WithingsAPIResponse is the envelope around every Withings API response.
+// Withings responds with HTTP 200 even for errors, so Status is the one to
+// check.
+type WithingsAPIResponse struct {
+	Body   json.RawMessage `json:"body"`
+	Error  string          `json:"error"`
+	Status int             `json:"status"`
+}
+
+// WithingsAPITokenBody is the body of a Withings token refresh API response.
+type WithingsAPITokenBody struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// WithingsDB is a database of Withings measurements stored to a TOML file.
+type WithingsDB struct {
+	Measurements []*WithingsMeasurement `toml:"measurements"`
+}
+
+// WithingsMeasurement is a set of body measurements taken at the same time
+// stored to a TOML file. Measurements that weren't taken are zero.
+type WithingsMeasurement struct {
+	BodyFatPct   float64   `toml:"body_fat_pct"`
+	BoneMassKg   float64   `toml:"bone_mass_kg"`
+	Date         time.Time `toml:"date"`
+	HeartRateBPM int       `toml:"heart_rate_bpm"`
+	WeightKg     float64   `toml:"weight_kg"`
+
+	// HydrationPct and MuscleMassPct are percentages of WeightKg. Withings
+	// measures them in kilograms, so they're zero if weight wasn't measured
+	// at the same time.
+	HydrationPct  float64 `toml:"hydration_pct"`
+	MuscleMassPct float64 `toml:"muscle_mass_pct"`
+}
+
+// WithingsToken is a set of Withings OAuth tokens saved to a file next to the
+// data file after they're refreshed. See withingsTokenPath.
+type WithingsToken struct {
+	AccessToken  string    `toml:"access_token"`
+	ExpiresAt    time.Time `toml:"expires_at"`
+	RefreshToken string    `toml:"refresh_token"`
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Variables
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+var logger = &LeveledLogger{Level: LevelInfo}
+
+// Default for --log-file-max-size-mb.
+const defaultLogFileMaxSizeMB = 10
+
+// Migrations that upgrade a ReadingDB from one schema version to the next.
+// The migration at index i upgrades from version i to version i+1, so there
+// should always be SchemaVersion of them.
+var readingDBMigrations = []func(readingDB *ReadingDB) error{
+	migrateReadingDBV0ToV1,
+	migrateReadingDBV1ToV2,
+}
+
+// Migrations that upgrade a TweetDB from one schema version to the next. See
+// readingDBMigrations.
+var tweetDBMigrations = []func(tweetDB *TweetDB) error{
+	migrateTweetDBV0ToV1,
+	migrateTweetDBV1ToV2,
+}
+
+// Migrations that upgrade a LikedTweetDB from one schema version to the next.
+// See readingDBMigrations.
+var likedTweetDBMigrations = []func(likedTweetDB *LikedTweetDB) error{
+	migrateLikedTweetDBV0ToV1,
+	migrateLikedTweetDBV1ToV2,
+}
+
+// Templates for the `export-html` command, embedded so that the binary can
+// export without any files alongside it.
+//
+//go:embed templates/export.html.tmpl
+var exportHTMLTemplates embed.FS
+
+// Templates for the `export-opml` command. See exportHTMLTemplates.
+//
+//go:embed templates/export.opml.tmpl
+var exportOPMLTemplates embed.FS
+
+// Options set on the root command, which are available to all subcommands.
+var rootOptions RootOptions
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+func absInt[T int | int64](x T) T {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Stores notes or a recommender on the reading with the given review ID in a
+// Goodreads data file, replacing any that it already has.
+func annotate(opts *AnnotateOptions) error {
+	if opts.ReadingsPath == "PATH" {
+		return fmt.Errorf("--readings-path is required")
+	}
+
+	if opts.ReviewID == 0 {
+		return fmt.Errorf("--review-id is required")
+	}
+
+	if !opts.NotesSet && !opts.RecommendedBySet {
+		return fmt.Errorf("--notes or --recommended-by is required")
+	}
+
+	readingDB, err := readReadingDB(opts.ReadingsPath)
+	if err != nil {
+		return err
+	}
+
+	var reading *Reading
+	for _, r := range readingDB.Readings {
+		if r.ReviewID == opts.ReviewID {
+			reading = r
+			break
+		}
+	}
+
+	if reading == nil {
+		return fmt.Errorf("no reading with review ID %v in '%s'", opts.ReviewID, opts.ReadingsPath)
+	}
+
+	if opts.NotesSet {
+		reading.Notes = opts.Notes
+	}
+
+	if opts.RecommendedBySet {
+		reading.RecommendedBy = opts.RecommendedBy
+	}
+
+	logger.Infof("(goodreads) Writing annotations for review %v ('%s') to '%s'",
+		reading.ReviewID, reading.Title, opts.ReadingsPath)
+
+	return writeTOMLFile(opts.ReadingsPath, readingDB)
+}
+
+func applyMediumAPIPost(post *MediumPost, apiPost *MediumAPIPost) {
+	if apiPost.Content != nil {
+		post.Subtitle = apiPost.Content.Subtitle
+	}
+
+	if apiPost.Virtuals != nil {
+		post.ClapCount = apiPost.Virtuals.TotalClapCount
+	}
+}
+
+// Copies Twitter Cards fetched from Twitter's v1.1 API onto tweets. Tweets
+// that weren't returned or have no card are left unchanged.
+func applyTwitterAPICards(tweets []*Tweet, apiCardTweets []*TwitterAPICardTweet) {
+	cardsByID := make(map[int64]*TwitterAPICard)
+	for _, apiCardTweet := range apiCardTweets {
+		if apiCardTweet.Card != nil {
+			cardsByID[apiCardTweet.ID] = apiCardTweet.Card
+		}
+	}
+
+	for _, tweet := range tweets {
+		apiCard, ok := cardsByID[tweet.ID]
+		if !ok {
+			continue
+		}
+
+		tweet.Card = &TweetCard{
+			CardDescription: twitterAPICardStringValue(apiCard, "description"),
+			CardTitle:       twitterAPICardStringValue(apiCard, "title"),
+			CardType:        apiCard.Name,
+		}
+	}
+}
+
+// Annotations with a probability at or below this have their probability
+// left out when stored. See TweetAnnotation.
+const tweetAnnotationMinProbability = 0.5
+
+func applyTwitterAPIV2Annotations(tweets []*Tweet, apiV2Tweets []*TwitterAPIV2Tweet) {
+	entitiesByID := make(map[string]*TwitterAPIV2Entities)
+	for _, apiV2Tweet := range apiV2Tweets {
+		if apiV2Tweet.Entities != nil {
+			entitiesByID[apiV2Tweet.ID] = apiV2Tweet.Entities
+		}
+	}
+
+	for _, tweet := range tweets {
+		entities, ok := entitiesByID[strconv.FormatInt(tweet.ID, 10)]
+		if !ok || len(entities.Annotations) < 1 {
+			continue
+		}
+
+		if tweet.Entities == nil {
+			tweet.Entities = &TweetEntities{}
+		}
+
+		tweet.Entities.Annotations = nil
+		for _, apiAnnotation := range entities.Annotations {
+			annotation := &TweetAnnotation{
+				NormalizedText: apiAnnotation.NormalizedText,
+				Type:           apiAnnotation.Type,
+			}
+
+			if apiAnnotation.Probability > tweetAnnotationMinProbability {
+				annotation.Probability = apiAnnotation.Probability
+			}
+
+			tweet.Entities.Annotations = append(tweet.Entities.Annotations, annotation)
+		}
+	}
+}
+
+// Copies metrics only available from Twitter's v2 API onto tweets fetched from
+// v1.1. Tweets that v2 didn't return (e.g. because they were deleted in the
+// meantime) are left unchanged.
+func applyTwitterAPIV2Metrics(tweets []*Tweet, apiV2Tweets []*TwitterAPIV2Tweet) {
+	metricsByID := make(map[string]*TwitterAPIV2PublicMetrics)
+	for _, apiV2Tweet := range apiV2Tweets {
+		if apiV2Tweet.PublicMetrics != nil {
+			metricsByID[apiV2Tweet.ID] = apiV2Tweet.PublicMetrics
+		}
+	}
+
+	for _, tweet := range tweets {
+		metrics, ok := metricsByID[strconv.FormatInt(tweet.ID, 10)]
+		if !ok {
+			continue
+		}
+
+		tweet.BookmarkCount = metrics.BookmarkCount
+		tweet.ReplyCount = metrics.ReplyCount
+		tweet.ViewCount = metrics.ImpressionCount
+	}
+}
+
+// Encodings in which TOML files may be written.
+const (
+	outputEncodingLatin1  = "latin-1"
+	outputEncodingUTF8    = "utf-8"
+	outputEncodingUTF8BOM = "utf-8-bom"
+)
+
+// Matches the ID of a record in marshaled TOML, like a tweet's `id` or a
+// reading's `review_id`.
+var tomlIDRE = regexp.MustCompile(`^\s*(?:id|review_id) = "?([^"]+)"?$`)
+
+// Comment prepended to files written as outputEncodingLatin1. Latin-1 text
+// can also be valid UTF-8 (e.g. "\xC3\xA9" is both "Ã©" and "é"), so the
+// encoding can't be reliably detected without it.
+var latin1Header = []byte("# encoding: latin-1\n")
+
+// Byte order mark prepended to files written as outputEncodingUTF8BOM.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Matches a link shortened by Twitter, which are replaced by --expand-urls.
+var tcoURLRE = regexp.MustCompile(`https?://t\.co/[A-Za-z0-9]+`)
+
+var htmlBoldRE = regexp.MustCompile(`</?(?:b|strong)(?:\s[^>]*)?>`)
+
+var htmlBlockCloseRE = regexp.MustCompile(`</(?:blockquote|h[1-6]|li|ol|ul)>`)
+
+var htmlBlockOpenRE = regexp.MustCompile(`<(?:blockquote|h[1-6]|ol|ul)(?:\s[^>]*)?>`)
+
+var htmlItalicRE = regexp.MustCompile(`</?(?:em|i)(?:\s[^>]*)?>`)
+
+var htmlLineBreakRE = regexp.MustCompile(`<br ?/?>`)
+
+var htmlLinkRE = regexp.MustCompile(`<a .*?href="(.*?)".*?>.*?</a>`)
+
+var htmlLinkWithTextRE = regexp.MustCompile(`<a .*?href="(.*?)".*?>(.*?)</a>`)
+
+var htmlListItemOpenRE = regexp.MustCompile(`<li(?:\s[^>]*)?>`)
+
+var htmlParagraphCloseRE = regexp.MustCompile(`</p>`)
+
+var htmlParagraphOpenRE = regexp.MustCompile(`<p(?:\s[^>]*)?>`)
+
+var htmlSpanRE = regexp.MustCompile(`</?span(?:\s[^>]*)?>`)
+
+// Matches any tag left over once the ones that affect formatting have been
+// handled.
+var htmlTagRE = regexp.MustCompile(`<[^>]+>`)
+
+var multipleBlankLinesRE = regexp.MustCompile(`\n\s*\n(?:\s*\n)+`)
+
+// Finds the center of a place's bounding box. Twitter orders coordinates as
+// longitude then latitude.
+func boundingBoxCenter(box *twitter.BoundingBox) (float64, float64) {
+	var lat, lon float64
+	var numPoints int
+
+	for _, polygon := range box.Coordinates {
+		for _, point := range polygon {
+			lon += point[0]
+			lat += point[1]
+			numPoints++
+		}
+	}
+
+	if numPoints == 0 {
+		return 0, 0
+	}
+
+	return lat / float64(numPoints), lon / float64(numPoints)
+}
+
+// Results that Chess.com assigns to a player for a game that was drawn.
+var chessComDrawResults = map[string]bool{
+	"50move":             true,
+	"agreed":             true,
+	"insufficient":       true,
+	"repetition":         true,
+	"stalemate":          true,
+	"timevsinsufficient": true,
+}
+
+const (
+	chessColorBlack = "black"
+	chessColorWhite = "white"
+
+	chessPlatformChessCom = "chess.com"
+	chessPlatformLichess  = "lichess"
+
+	chessResultDraw = "draw"
+	chessResultLoss = "loss"
+	chessResultWin  = "win"
+)
+
+func beeminderGoalFromAPIGoal(goal *BeeminderAPIGoal, dataPoints []*BeeminderAPIDataPoint) *BeeminderGoal {
+	beeminderGoal := &BeeminderGoal{
+		CurrentRate: goal.Rate,
+		Metric:      goal.GUnits,
+		Pledge:      goal.Pledge,
+		Safebuf:     goal.Safebuf,
+		Slug:        goal.Slug,
+		Title:       goal.Title,
+	}
+
+	if goal.GoalDate != nil {
+		beeminderGoal.GoalDate = time.Unix(*goal.GoalDate, 0).UTC()
+	}
+
+	for _, dataPoint := range dataPoints {
+		beeminderGoal.DataPoints = append(beeminderGoal.DataPoints, &BeeminderDataPoint{
+			Comment:   dataPoint.Comment,
+			ID:        dataPoint.ID,
+			Timestamp: time.Unix(dataPoint.Timestamp, 0).UTC(),
+			Value:     dataPoint.Value,
+		})
+	}
+
+	return beeminderGoal
+}
+
+// Number of days of past events fetched from Google Calendar on the first
+// sync.
+const calBackfillDays = 365
+
+// Events can be edited after they've happened (to fix a title for example),
+// so when syncing incrementally we start this many days before the last one
+// that's stored.
+const calRefetchDays = 30
+
+// Read-only OAuth scope for the Google Calendar API.
+const calScope = "https://www.googleapis.com/auth/calendar.readonly"
+
+func calEventFromAPIEvent(event *CalAPIEvent) (*CalEvent, error) {
+	if event.Start == nil || event.End == nil {
+		return nil, fmt.Errorf("event has no start or end")
+	}
+
+	var attendees []string
+	for _, attendee := range event.Attendees {
+		attendees = append(attendees, attendee.Email)
+	}
+
+	var organizer string
+	if event.Organizer != nil {
+		organizer = event.Organizer.Email
+	}
+
+	calEvent := &CalEvent{
+		Attendees:   attendees,
+		Description: event.Description,
+		ID:          event.ID,
+		Location:    event.Location,
+		Organizer:   organizer,
+		Status:      event.Status,
+		Title:       event.Summary,
+	}
+
+	if event.Start.Date != "" {
+		startAt, err := time.Parse(calDateFormat, event.Start.Date)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing start date: %w", err)
+		}
+
+		endAt, err := time.Parse(calDateFormat, event.End.Date)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing end date: %w", err)
+		}
+
+		calEvent.EndAt = endAt
+		calEvent.IsAllDay = true
+		calEvent.StartAt = startAt
+	} else {
+		calEvent.EndAt = event.End.DateTime.UTC()
+		calEvent.StartAt = event.Start.DateTime.UTC()
+	}
+
+	return calEvent, nil
+}
+
+// Format of the dates of all-day events in the Google Calendar API.
+const calDateFormat = "2006-01-02"
+
+func checkGoodreadsSort(sort string) error {
+	if sort == "" {
+		return nil
+	}
+
+	for _, s := range goodreadsSorts {
+		if sort == s {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown Goodreads sort '%s' (should be one of: %s)",
+		sort, strings.Join(goodreadsSorts, ", "))
+}
+
+func checkOutputEncoding(encoding string) error {
+	switch encoding {
+	case outputEncodingLatin1, outputEncodingUTF8, outputEncodingUTF8BOM:
+		return nil
+	}
+	return fmt.Errorf("unknown output encoding '%s' (should be '%s', '%s', or '%s')",
+		encoding, outputEncodingUTF8, outputEncodingUTF8BOM, outputEncodingLatin1)
+}
+
+func checkMergeStrategy(strategy string) error {
+	switch strategy {
+	case "", mergeStrategyPreferExisting, mergeStrategyPreferNew, mergeStrategySmart:
+		return nil
+	}
+	return fmt.Errorf("unknown merge strategy '%s' (should be '%s', '%s', or '%s')",
+		strategy, mergeStrategyPreferNew, mergeStrategyPreferExisting, mergeStrategySmart)
+}
+
+func checkSortOrder(order string) error {
+	switch order {
+	case "", sortOrderAsc, sortOrderDesc:
+		return nil
+	}
+	return fmt.Errorf("unknown sort order '%s' (should be '%s' or '%s')",
+		order, sortOrderAsc, sortOrderDesc)
+}
+
+// Maximum time that checking a single URL with --check-urls may take,
+// including any redirects.
+const urlCheckTimeout = 10 * time.Second
+
+// Makes a HEAD request to every distinct expanded URL referenced by the given
+// tweets, running at most concurrency requests at once, and stores the status
+// code of each response. URLs whose requests fail without a response are left
+// with a status of zero.
+func checkTweetURLs(ctx context.Context, client *http.Client, tweets []*Tweet, concurrency int) error {
+	var urls []string
+	statuses := make(map[string]int)
+	for _, tweet := range tweets {
+		if tweet.Entities == nil {
+			continue
+		}
+
+		for _, entityURL := range tweet.Entities.URLs {
+			if _, ok := statuses[entityURL.ExpandedURL]; !ok {
+				statuses[entityURL.ExpandedURL] = 0
+				urls = append(urls, entityURL.ExpandedURL)
+			}
+		}
+	}
+
+	logger.Infof("(twitter) Checking %v URL(s) with concurrency %v", len(urls), concurrency)
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for _, rawURL := range urls {
+		rawURL := rawURL
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			req, err := http.NewRequestWithContext(ctx, "HEAD", rawURL, nil)
+			if err != nil {
+				logger.Warnf("(twitter) Couldn't check URL '%v': %v", rawURL, err)
+				return
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				logger.Warnf("(twitter) Couldn't check URL '%v': %v", rawURL, err)
+				return
+			}
+			resp.Body.Close()
+
+			mutex.Lock()
+			statuses[rawURL] = resp.StatusCode
+			mutex.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, tweet := range tweets {
+		if tweet.Entities == nil {
+			continue
+		}
+
+		for _, entityURL := range tweet.Entities.URLs {
+			entityURL.Status = statuses[entityURL.ExpandedURL]
+		}
+	}
+
+	return nil
+}
+
+// Chess.com doesn't include an opening name with games, but does link to a
+// page for the opening whose path is the name with dashes for spaces.
+func chessComOpening(ecoURL string) string {
+	u, err := url.Parse(ecoURL)
+	if err != nil || !strings.HasPrefix(u.Path, "/openings/") {
+		return ""
+	}
+
+	return strings.ReplaceAll(strings.TrimPrefix(u.Path, "/openings/"), "-", " ")
+}
+
+func chessGameFromChessComAPIGame(game *ChessComAPIGame, username string) (*ChessGame, error) {
+	if game.White == nil || game.Black == nil {
+		return nil, fmt.Errorf("game %s missing a player", game.URL)
+	}
+
+	var me, opponent *ChessComAPIPlayer
+	var myColor string
+
+	switch {
+	case strings.EqualFold(game.White.Username, username):
+		me, opponent, myColor = game.White, game.Black, chessColorWhite
+	case strings.EqualFold(game.Black.Username, username):
+		me, opponent, myColor = game.Black, game.White, chessColorBlack
+	default:
+		return nil, fmt.Errorf("user '%s' didn't play in game %s", username, game.URL)
+	}
+
+	result := chessResultLoss
+	switch {
+	case me.Result == "win":
+		result = chessResultWin
+	case chessComDrawResults[me.Result]:
+		result = chessResultDraw
+	}
+
+	return &ChessGame{
+		ID:               game.UUID,
+		MyColor:          myColor,
+		MyRating:         me.Rating,
+		Opening:          chessComOpening(game.ECOURL),
+		OpponentRating:   opponent.Rating,
+		OpponentUsername: opponent.Username,
+		PGN:              game.PGN,
+		Platform:         chessPlatformChessCom,
+		PlayedAt:         time.Unix(game.EndTime, 0).UTC(),
+		Result:           result,
+		TimeControl:      game.TimeControl,
+	}, nil
+}
+
+func chessGameFromLichessAPIGame(game *LichessAPIGame, username string) (*ChessGame, error) {
+	if game.Players == nil || game.Players.White == nil || game.Players.Black == nil {
+		return nil, fmt.Errorf("game %s missing a player", game.ID)
+	}
+
+	isUser := func(player *LichessAPIPlayer) bool {
+		return player.User != nil && strings.EqualFold(player.User.Name, username)
+	}
+
+	var me, opponent *LichessAPIPlayer
+	var myColor string
+
+	switch {
+	case isUser(game.Players.White):
+		me, opponent, myColor = game.Players.White, game.Players.Black, chessColorWhite
+	case isUser(game.Players.Black):
+		me, opponent, myColor = game.Players.Black, game.Players.White, chessColorBlack
+	default:
+		return nil, fmt.Errorf("user '%s' didn't play in game %s", username, game.ID)
+	}
+
+	// Lichess omits a winner for drawn games.
+	result := chessResultDraw
+	switch game.Winner {
+	case myColor:
+		result = chessResultWin
+	case chessColorBlack, chessColorWhite:
+		result = chessResultLoss
+	}
+
+	var opening string
+	if game.Opening != nil {
+		opening = game.Opening.Name
+	}
+
+	var opponentUsername string
+	switch {
+	case opponent.User != nil:
+		opponentUsername = opponent.User.Name
+	case opponent.AILevel > 0:
+		opponentUsername = fmt.Sprintf("Stockfish level %d", opponent.AILevel)
+	}
+
+	// Format time controls the same way as Chess.com for consistency.
+	var timeControl string
+	switch {
+	case game.Clock != nil && game.Clock.Increment > 0:
+		timeControl = fmt.Sprintf("%d+%d", game.Clock.Initial, game.Clock.Increment)
+	case game.Clock != nil:
+		timeControl = strconv.Itoa(game.Clock.Initial)
+	case game.DaysPerTurn > 0:
+		timeControl = fmt.Sprintf("1/%d", game.DaysPerTurn*24*60*60)
+	}
+
+	return &ChessGame{
+		ID:               game.ID,
+		MyColor:          myColor,
+		MyRating:         me.Rating,
+		Opening:          opening,
+		OpponentRating:   opponent.Rating,
+		OpponentUsername: opponentUsername,
+		PGN:              game.PGN,
+		Platform:         chessPlatformLichess,
+		PlayedAt:         time.Unix(0, game.LastMoveAt*int64(time.Millisecond)).UTC(),
+		Result:           result,
+		TimeControl:      timeControl,
+	}, nil
+}
+
+// Cache statuses of requests that were served without going to the origin.
+var cloudflareCachedStatuses = map[string]bool{
+	"hit":         true,
+	"revalidated": true,
+	"stale":       true,
+	"updating":    true,
+}
+
+const cloudflareDateFormat = "2006-01-02"
+
+// Maximum number of groups that Cloudflare returns for a single query.
+const cloudflareGroupsLimit = 10000
+
+// Cloudflare only keeps adaptive analytics for a limited time (which depends
+// on the zone's plan), so a fresh sync goes back this many days.
+const cloudflareLookbackDays = 30
+
+// Recent days are still accumulating traffic when they're synced, so when
+// syncing incrementally we start this many days before the last one that's
+// stored.
+const cloudflareRefetchDays = 2
+
+// Security actions of requests that are counted as threats.
+var cloudflareThreatActions = map[string]bool{
+	"block":             true,
+	"challenge":         true,
+	"jschallenge":       true,
+	"managed_challenge": true,
+}
+
+// Maximum number of countries stored in CloudflareDay.TopCountries.
+const cloudflareTopCountriesLimit = 10
+
+// Aggregates a day's groups of HTTP requests into a single day. A zone's
+// traffic is served from however many of Cloudflare's data centers are
+// closest to its visitors, and groups are split by country, cache status, and
+// security action, so every group is summed regardless of where it came from.
+func cloudflareDayFromAPIGroups(date time.Time, groups []*CloudflareAPIHTTPRequestsGroup) *CloudflareDay {
+	day := &CloudflareDay{Date: date}
+
+	countries := make(map[string]int64)
+	for _, group := range groups {
+		day.Bandwidth += group.Sum.EdgeResponseBytes
+		day.Requests += group.Count
+		day.UniqueVisitors += group.Sum.Visits
+
+		if cloudflareCachedStatuses[group.Dimensions.CacheStatus] {
+			day.CachedRequests += group.Count
+		}
+
+		if cloudflareThreatActions[group.Dimensions.SecurityAction] {
+			day.Threats += group.Count
+		}
+
+		if group.Dimensions.ClientCountryName != "" {
+			countries[group.Dimensions.ClientCountryName] += group.Count
+		}
+	}
+
+	names := make([]string, 0, len(countries))
+	for name := range countries {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if countries[names[i]] != countries[names[j]] {
+			return countries[names[i]] > countries[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	if len(names) > 0 {
+		day.TopCountries = make(map[string]int64)
+		for _, name := range names {
+			if len(day.TopCountries) >= cloudflareTopCountriesLimit {
+				break
+			}
+			day.TopCountries[name] = countries[name]
+		}
+	}
+
+	return day
+}
+
+func clockifyEntryFromAPITimeEntry(entry *ClockifyAPITimeEntry, projects map[string]*ClockifyAPIProject, tags map[string]*ClockifyAPITag) *ClockifyEntry {
+	clockifyEntry := &ClockifyEntry{
+		Billable:    entry.Billable,
+		Description: entry.Description,
+		ID:          entry.ID,
+		ProjectID:   entry.ProjectID,
+		WorkspaceID: entry.WorkspaceID,
+	}
+
+	if project, ok := projects[entry.ProjectID]; ok {
+		clockifyEntry.ProjectName = project.Name
+	}
+
+	// Tags that have since been deleted can't be named, so they're left out.
+	for _, tagID := range entry.TagIDs {
+		if tag, ok := tags[tagID]; ok {
+			clockifyEntry.Tags = append(clockifyEntry.Tags, tag.Name)
+		}
+	}
+
+	if entry.TimeInterval != nil {
+		clockifyEntry.StartedAt = entry.TimeInterval.Start.UTC()
+
+		if entry.TimeInterval.End != nil {
+			clockifyEntry.EndedAt = entry.TimeInterval.End.UTC()
+			clockifyEntry.DurationSeconds = int(clockifyEntry.EndedAt.Sub(clockifyEntry.StartedAt).Seconds())
+		}
+	}
+
+	return clockifyEntry
+}
+
+// Re-parses marshaled TOML into a generic map, prunes any keys with zero
+// values, and marshals it again. Because the target structs decode missing
+// keys to zero values, this is lossless as long as nothing depends on the
+// difference between an empty and a nil table.
+func compactTOML(data []byte) ([]byte, error) {
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing toml: %w", err)
+	}
+
+	m := tree.ToMap()
+	pruneZeroTOMLValues(m)
+
+	tree, err = toml.TreeFromMap(m)
+	if err != nil {
+		return nil, fmt.Errorf("error building toml tree: %w", err)
+	}
+
+	compacted, err := tree.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling toml: %w", err)
+	}
+
+	return compacted, nil
+}
+
+// Copies the clap count and subtitle of stored posts onto freshly fetched ones
+// whose post API request failed, so that a temporary failure doesn't zero
+// them.
+func copyMediumPostAPIFields(posts, existingPosts []*MediumPost, unfetchedIDs map[string]bool) {
+	existingByID := make(map[string]*MediumPost, len(existingPosts))
+	for _, post := range existingPosts {
+		existingByID[post.ID] = post
+	}
+
+	for _, post := range posts {
+		existing, ok := existingByID[post.ID]
+		if !ok || !unfetchedIDs[post.ID] {
+			continue
+		}
+
+		post.ClapCount = existing.ClapCount
+		post.Subtitle = existing.Subtitle
+	}
+}
+
+// Copies the statuses of URLs checked by a previous sync onto freshly fetched
+// tweets so that they're retained when URLs aren't checked again. Statuses
+// are matched by tweet ID and expanded URL.
+func copyTweetURLStatuses(tweets, existingTweets []*Tweet) {
+	statuses := make(map[int64]map[string]int)
+	for _, tweet := range existingTweets {
+		if tweet.Entities == nil {
+			continue
+		}
+
+		for _, entityURL := range tweet.Entities.URLs {
+			if entityURL.Status == 0 {
+				continue
+			}
+
+			if statuses[tweet.ID] == nil {
+				statuses[tweet.ID] = make(map[string]int)
+			}
+			statuses[tweet.ID][entityURL.ExpandedURL] = entityURL.Status
+		}
+	}
+
+	for _, tweet := range tweets {
+		if tweet.Entities == nil || statuses[tweet.ID] == nil {
+			continue
+		}
+
+		for _, entityURL := range tweet.Entities.URLs {
+			if status, ok := statuses[tweet.ID][entityURL.ExpandedURL]; ok {
+				entityURL.Status = status
+			}
+		}
+	}
+}
+
+// Copies metrics only available from Twitter's v2 API from stored tweets onto
+// freshly fetched ones, for syncs without --twitter-api-v2 whose tweets don't
+// have them. Otherwise the stored metrics would be zeroed. Engagement scores
+// are recomputed to account for them.
+func copyTweetAPIV2Metrics(tweets, existingTweets []*Tweet, weights []float64) {
+	existingByID := make(map[int64]*Tweet)
+	for _, tweet := range existingTweets {
+		existingByID[tweet.ID] = tweet
+	}
+
+	for _, tweet := range tweets {
+		existing, ok := existingByID[tweet.ID]
+		if !ok || (existing.BookmarkCount == 0 && existing.ReplyCount == 0 && existing.ViewCount == 0) {
+			continue
+		}
+
+		tweet.BookmarkCount = existing.BookmarkCount
+		tweet.ReplyCount = existing.ReplyCount
+		tweet.ViewCount = existing.ViewCount
+		tweet.EngagementScore = computeEngagementScore(tweet, weights)
+	}
+}
+
+// Copies annotations stored by a previous sync onto freshly fetched tweets
+// that don't have any. Annotations are only returned by Twitter's v2 API, so
+// without this they'd be lost on every sync without --twitter-api-v2.
+func copyTweetAnnotations(tweets, existingTweets []*Tweet) {
+	annotations := make(map[int64][]*TweetAnnotation)
+	for _, tweet := range existingTweets {
+		if tweet.Entities != nil && len(tweet.Entities.Annotations) > 0 {
+			annotations[tweet.ID] = tweet.Entities.Annotations
+		}
+	}
+
+	for _, tweet := range tweets {
+		tweetAnnotations, ok := annotations[tweet.ID]
+		if !ok || (tweet.Entities != nil && len(tweet.Entities.Annotations) > 0) {
+			continue
+		}
+
+		if tweet.Entities == nil {
+			tweet.Entities = &TweetEntities{}
+		}
+		tweet.Entities.Annotations = tweetAnnotations
+	}
+}
+
+// Copies Twitter Cards stored by a previous sync onto freshly fetched tweets
+// that don't have one so that they're retained when cards aren't fetched
+// again.
+func copyTweetCards(tweets, existingTweets []*Tweet) {
+	cards := make(map[int64]*TweetCard)
+	for _, tweet := range existingTweets {
+		if tweet.Card != nil {
+			cards[tweet.ID] = tweet.Card
+		}
+	}
+
+	for _, tweet := range tweets {
+		if card, ok := cards[tweet.ID]; ok && tweet.Card == nil {
+			tweet.Card = card
+		}
+	}
+}
+
+// Copies LikesByFollowers from stored tweets onto freshly fetched ones, for
+// syncs without --compute-engagement whose tweets don't have it. Otherwise
+// the stored ratio would be zeroed.
+func copyTweetLikesByFollowers(tweets, existingTweets []*Tweet) {
+	ratios := make(map[int64]float64)
+	for _, tweet := range existingTweets {
+		if tweet.LikesByFollowers != 0 {
+			ratios[tweet.ID] = tweet.LikesByFollowers
+		}
+	}
+
+	for _, tweet := range tweets {
+		if ratio, ok := ratios[tweet.ID]; ok {
+			tweet.LikesByFollowers = ratio
+		}
+	}
+}
+
+// Copies the text of quoted tweets stored by a previous sync onto freshly
+// fetched tweets whose quoted tweet has since been deleted, so that its text
+// isn't lost.
+func copyTweetQuotes(tweets, existingTweets []*Tweet) {
+	quotes := make(map[int64]*TweetQuote)
+	for _, tweet := range existingTweets {
+		if tweet.Quote != nil && tweet.Quote.OriginalText != "" {
+			quotes[tweet.ID] = tweet.Quote
+		}
+	}
+
+	for _, tweet := range tweets {
+		if quote, ok := quotes[tweet.ID]; ok && tweet.Quote != nil && tweet.Quote.OriginalText == "" {
+			tweet.Quote = quote
+		}
+	}
+}
+
+// Counts the occurrences of each author, sorting the result so that the most
+// read authors come first.
+func countAuthors(authors []*ReadingAuthor) []*AuthorCount {
+	countsByKey := make(map[string]*AuthorCount)
+	var counts []*AuthorCount
+
+	for _, author := range authors {
+		key := author.Name + "\x00" + author.Role
+		count, ok := countsByKey[key]
+		if !ok {
+			count = &AuthorCount{Name: author.Name, Role: author.Role}
+			countsByKey[key] = count
+			counts = append(counts, count)
+		}
+		count.Count++
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+
+	return counts
+}
+
+func computeTweetStats(allTweets []*Tweet, noRetweets, noReplies bool) *TweetStats {
+	var numExcludedReplies, numExcludedRetweets int
+	tweets := Filter(allTweets, func(tweet *Tweet) bool {
+		switch {
+		case noRetweets && tweet.Retweet != nil:
+			numExcludedRetweets++
+			return false
+		case noReplies && tweet.Reply != nil:
+			numExcludedReplies++
+			return false
+		}
+		return true
+	})
+
+	return &TweetStats{
+		NumExcludedReplies:  numExcludedReplies,
+		NumExcludedRetweets: numExcludedRetweets,
+		NumTweets:           len(tweets),
+		TweetsByMonth: countPeriods(GroupBy(tweets, func(tweet *Tweet) string {
+			return tweet.CreatedAt.Format("2006-01")
+		})),
+	}
+}
+
+// Counts the records in each group produced by GroupBy, sorting the result so
+// that the earliest periods come first.
+func countPeriods[T any](groups map[string][]T) []*PeriodCount {
+	counts := make([]*PeriodCount, 0, len(groups))
+	for period, group := range groups {
+		counts = append(counts, &PeriodCount{Count: len(group), Period: period})
+	}
+
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Period < counts[j].Period })
+
+	return counts
+}
+
+// Returns a tweet's engagement score, the sum of its likes, retweets,
+// replies, and bookmarks multiplied by the given weights (in that order).
+// defaultEngagementWeights is used if weights is nil.
+func computeEngagementScore(tweet *Tweet, weights []float64) float64 {
+	if weights == nil {
+		weights = defaultEngagementWeights
+	}
+
+	return float64(tweet.FavoriteCount)*weights[0] +
+		float64(tweet.RetweetCount)*weights[1] +
+		float64(tweet.ReplyCount)*weights[2] +
+		float64(tweet.BookmarkCount)*weights[3]
+}
+
+// Sets each tweet's LikesByFollowers based on the user's current follower
+// count. Left at zero if the user doesn't have any followers.
+func computeLikesByFollowers(tweets []*Tweet, followersCount int) {
+	if followersCount == 0 {
+		return
+	}
+
+	for _, tweet := range tweets {
+		tweet.LikesByFollowers = float64(tweet.FavoriteCount) / float64(followersCount)
+	}
+}
+
+func computeNomadStats(stays []*NomadStay, homeCountryCode string) *NomadStats {
+	var totalDaysAbroad int
+	for _, stay := range stays {
+		if homeCountryCode != "" && strings.EqualFold(stay.CountryCode, homeCountryCode) {
+			continue
+		}
+		totalDaysAbroad += stay.DurationDays
+	}
+
+	return &NomadStats{
+		NumStays:        len(stays),
+		TotalDaysAbroad: totalDaysAbroad,
+	}
+}
+
+func computePodcastStats(episodes []*PodcastEpisode) *PodcastStats {
+	var numPlayed, secondsListened int
+	for _, episode := range episodes {
+		if !episode.PlayedAt.IsZero() {
+			numPlayed++
+		}
+		secondsListened += episode.Progress
+	}
+
+	return &PodcastStats{
+		HoursListened: float64(secondsListened) / 3600,
+		NumEpisodes:   len(episodes),
+		NumPlayed:     numPlayed,
+	}
+}
+
+func computeReadingStats(allReadings []*Reading) *ReadingStats {
+	var abandonedReadings, readings []*Reading
+	for _, reading := range allReadings {
+		if reading.Abandoned {
+			abandonedReadings = append(abandonedReadings, reading)
+		} else {
+			readings = append(readings, reading)
+		}
+	}
+
+	var abandonRate float64
+	if len(allReadings) > 0 {
+		abandonRate = float64(len(abandonedReadings)) / float64(len(allReadings))
+	}
+
+	var primaryAuthors, otherContributors []*ReadingAuthor
+	for _, reading := range readings {
+		for _, author := range reading.Authors {
+			if isPrimaryAuthor(author) {
+				// Normalize role so that authors with and without an
+				// explicit "Author" role are counted together.
+				primaryAuthors = append(primaryAuthors,
+					&ReadingAuthor{ID: author.ID, Name: author.Name})
+			} else {
+				otherContributors = append(otherContributors, author)
+			}
+		}
+	}
+
+	// A rating of zero means that the book wasn't rated, so leave those out
+	// of the correlation.
+	var personalRatings, communityRatings []float64
+	for _, reading := range readings {
+		if reading.Rating == 0 || reading.CommunityRating == 0 {
+			continue
+		}
+
+		personalRatings = append(personalRatings, float64(reading.Rating))
+		communityRatings = append(communityRatings, reading.CommunityRating)
+	}
+
+	ratingCorrelation, ratingCorrelationOK := pearsonCorrelation(personalRatings, communityRatings)
+
+	buckets := make([]*ReadingSpeedBucket, len(statsReadingSpeedBounds)+1)
+	for i := range buckets {
+		bucket := &ReadingSpeedBucket{}
+		if i > 0 {
+			bucket.Min = statsReadingSpeedBounds[i-1]
+		}
+		if i < len(statsReadingSpeedBounds) {
+			bucket.Max = statsReadingSpeedBounds[i]
+		}
+		buckets[i] = bucket
+	}
+
+	var numReadingSpeeds int
+	var readingSpeedSum float64
+	for _, reading := range readings {
+		if reading.ReadingSpeedPPD == 0 {
+			continue
+		}
+
+		numReadingSpeeds++
+		readingSpeedSum += reading.ReadingSpeedPPD
+
+		for _, bucket := range buckets {
+			if bucket.Max == 0 || reading.ReadingSpeedPPD < bucket.Max {
+				bucket.Count++
+				break
+			}
+		}
+	}
+
+	var readingSpeedAvg float64
+	if numReadingSpeeds > 0 {
+		readingSpeedAvg = readingSpeedSum / float64(numReadingSpeeds)
+	}
+
+	var wordsRead int
+	for _, reading := range readings {
+		wordsRead += reading.WordCount
+	}
+
+	var rereadBooks []*BookCount
+	for _, bookReadings := range GroupBy(readings, func(reading *Reading) int { return reading.ID }) {
+		if len(bookReadings) > 1 {
+			rereadBooks = append(rereadBooks,
+				&BookCount{Count: len(bookReadings), Title: bookReadings[0].Title})
+		}
+	}
+
+	sort.Slice(rereadBooks, func(i, j int) bool {
+		if rereadBooks[i].Count != rereadBooks[j].Count {
+			return rereadBooks[i].Count > rereadBooks[j].Count
+		}
+		return rereadBooks[i].Title < rereadBooks[j].Title
+	})
+
+	var formatReadings, languageReadings, readReadings, recommendedReadings, sentimentReadings []*Reading
+	for _, reading := range readings {
+		if reading.Format != "" {
+			formatReadings = append(formatReadings, reading)
+		}
+
+		if reading.Language != "" {
+			languageReadings = append(languageReadings, reading)
+		}
+
+		if reading.RecommendedBy != "" {
+			recommendedReadings = append(recommendedReadings, reading)
+		}
+
+		if !reading.ReadAt.IsZero() {
+			readReadings = append(readReadings, reading)
+
+			if reading.MeanSentimentComputed {
+				sentimentReadings = append(sentimentReadings, reading)
+			}
+		}
+	}
+
+	var readingsByFormat []*FormatCount
+	for format, formatGroup := range GroupBy(formatReadings, func(reading *Reading) string { return reading.Format }) {
+		readingsByFormat = append(readingsByFormat, &FormatCount{Count: len(formatGroup), Format: format})
+	}
+
+	sort.Slice(readingsByFormat, func(i, j int) bool {
+		if readingsByFormat[i].Count != readingsByFormat[j].Count {
+			return readingsByFormat[i].Count > readingsByFormat[j].Count
+		}
+		return readingsByFormat[i].Format < readingsByFormat[j].Format
+	})
+
+	var readingsByLanguage []*LanguageCount
+	for language, languageGroup := range GroupBy(languageReadings, func(reading *Reading) string { return reading.Language }) {
+		readingsByLanguage = append(readingsByLanguage, &LanguageCount{Count: len(languageGroup), Language: language})
+	}
+
+	sort.Slice(readingsByLanguage, func(i, j int) bool {
+		if readingsByLanguage[i].Count != readingsByLanguage[j].Count {
+			return readingsByLanguage[i].Count > readingsByLanguage[j].Count
+		}
+		return readingsByLanguage[i].Language < readingsByLanguage[j].Language
+	})
+
+	var readingsByRecommender []*RecommenderCount
+	for name, recommenderGroup := range GroupBy(recommendedReadings, func(reading *Reading) string { return reading.RecommendedBy }) {
+		readingsByRecommender = append(readingsByRecommender, &RecommenderCount{Count: len(recommenderGroup), Name: name})
+	}
+
+	sort.Slice(readingsByRecommender, func(i, j int) bool {
+		if readingsByRecommender[i].Count != readingsByRecommender[j].Count {
+			return readingsByRecommender[i].Count > readingsByRecommender[j].Count
+		}
+		return readingsByRecommender[i].Name < readingsByRecommender[j].Name
+	})
+
+	var sentimentByYear []*SentimentDistribution
+	for year, yearGroup := range GroupBy(sentimentReadings, func(reading *Reading) string { return reading.ReadAt.Format("2006") }) {
+		distribution := &SentimentDistribution{
+			Counts: make([]int, len(statsSentimentBounds)+1),
+			Period: year,
+		}
+
+		var sentimentSum float64
+		for _, reading := range yearGroup {
+			sentimentSum += reading.MeanSentiment
+
+			i := sort.SearchFloat64s(statsSentimentBounds, reading.MeanSentiment)
+			if i < len(statsSentimentBounds) && statsSentimentBounds[i] == reading.MeanSentiment {
+				i++
+			}
+			distribution.Counts[i]++
+		}
+		distribution.Mean = sentimentSum / float64(len(yearGroup))
+
+		sentimentByYear = append(sentimentByYear, distribution)
+	}
+
+	sort.Slice(sentimentByYear, func(i, j int) bool { return sentimentByYear[i].Period < sentimentByYear[j].Period })
+
+	return &ReadingStats{
+		AbandonRate:           abandonRate,
+		NumAbandonedReadings:  len(abandonedReadings),
+		NumRatedReadings:      len(personalRatings),
+		NumReadingSpeeds:      numReadingSpeeds,
+		NumReadings:           len(readings),
+		OtherContributors:     countAuthors(otherContributors),
+		PrimaryAuthors:        countAuthors(primaryAuthors),
+		RatingCorrelation:     ratingCorrelation,
+		RatingCorrelationOK:   ratingCorrelationOK,
+		ReadingSpeedAvgPPD:    readingSpeedAvg,
+		ReadingSpeedBuckets:   buckets,
+		ReadingsByFormat:      readingsByFormat,
+		ReadingsByLanguage:    readingsByLanguage,
+		ReadingsByRecommender: readingsByRecommender,
+		ReadingsByYear: countPeriods(GroupBy(readReadings, func(reading *Reading) string {
+			return reading.ReadAt.Format("2006")
+		})),
+		RereadBooks:     rereadBooks,
+		SentimentByYear: sentimentByYear,
+		WordsRead:       wordsRead,
+	}
+}
+
+//go:embed lexicons/sentiment.tsv
+var sentimentLexiconData string
+
+// Words and phrases and their sentiment from -5 (very negative) to 5 (very
+// positive), used to compute Reading.MeanSentiment. It's the AFINN lexicon;
+// see lexicons/sentiment.tsv.
+var sentimentLexicon = parseSentimentLexicon(sentimentLexiconData)
+
+// The number of words in the longest phrases in sentimentLexicon, like "does
+// not work".
+const sentimentMaxPhraseWords = 3
+
+// Words that flip the sentiment of the word following them, as in "not good".
+// Contractions ending in "n't" do the same.
+var sentimentNegators = map[string]bool{
+	"never":   true,
+	"no":      true,
+	"nor":     true,
+	"not":     true,
+	"without": true,
+}
+
+// Computes the sentiment of text from -1 (very negative) to 1 (very positive)
+// as the mean of the scores of its words and phrases that are in
+// sentimentLexicon. Returns zero if none of them are.
+func computeSentiment(text string) float64 {
+	text = strings.ReplaceAll(strings.ToLower(text), "’", "'")
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for i, word := range words {
+		words[i] = strings.Trim(word, "'")
+	}
+
+	var numScored, scoreSum int
+	var negated bool
+	for i := 0; i < len(words); i++ {
+		// Phrases are matched before the words in them, longest first.
+		for n := sentimentMaxPhraseWords; n > 0; n-- {
+			if i+n > len(words) {
+				continue
+			}
+
+			score, ok := sentimentLexicon[strings.Join(words[i:i+n], " ")]
+			if !ok {
+				continue
+			}
+
+			if negated {
+				score = -score
+			}
+			numScored++
+			scoreSum += score
+			i += n - 1
+			break
+		}
+
+		negated = sentimentNegators[words[i]] || strings.HasSuffix(words[i], "n't")
+	}
+
+	if numScored == 0 {
+		return 0
+	}
+
+	return float64(scoreSum) / float64(numScored) / 5
+}
+
+// Logs message as an error and exits. It goes through logger so that it also
+// reaches --log-file, which is closed before exiting because deferred calls
+// don't run on os.Exit.
+func die(message string) {
+	logger.Errorf("%s", message)
+	logger.Close()
+	os.Exit(1)
+}
+
+// Downloads the cover image of every distinct book among the given readings
+// to {dir}/{book_id}.jpg (or .png if that's what's served), running at most
+// concurrency downloads at once, and stores its path in
+// Reading.CoverLocalPath. Covers that are already on disk aren't downloaded
+// again. Covers whose downloads fail are logged and left without a path.
+func downloadGoodreadsCovers(ctx context.Context, client *http.Client, readings []*Reading, dir string, concurrency int) error {
+	var bookIDs []int
+	coverURLs := make(map[int]string)
+	localPaths := make(map[int]string)
+	for _, reading := range readings {
+		if reading.CoverURL == "" {
+			continue
+		}
+
+		if _, ok := coverURLs[reading.ID]; ok {
+			continue
+		}
+		coverURLs[reading.ID] = reading.CoverURL
+
+		if localPath := findGoodreadsCover(dir, reading.ID); localPath != "" {
+			localPaths[reading.ID] = localPath
+			continue
+		}
+
+		bookIDs = append(bookIDs, reading.ID)
+	}
+
+	logger.Infof("(goodreads) Downloading %v cover(s) with concurrency %v", len(bookIDs), concurrency)
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for _, bookID := range bookIDs {
+		bookID := bookID
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			localPath, err := downloadGoodreadsCover(ctx, client, coverURLs[bookID], dir, bookID)
+			if err != nil {
+				logger.Warnf("(goodreads) Couldn't download cover for book %v: %v", bookID, err)
+				return
+			}
+
+			mutex.Lock()
+			localPaths[bookID] = localPath
+			mutex.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, reading := range readings {
+		if localPath, ok := localPaths[reading.ID]; ok {
+			reading.CoverLocalPath = localPath
+		}
+	}
+
+	return nil
+}
+
+// Downloads a single cover image into dir, returning the path it was written
+// to. The file's extension is chosen based on the response's Content-Type.
+func downloadGoodreadsCover(ctx context.Context, client *http.Client, coverURL, dir string, bookID int) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", coverURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error requesting cover: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading body from cover: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %v", resp.StatusCode)
+	}
+
+	ext := ".jpg"
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "image/png") {
+		ext = ".png"
+	}
+
+	localPath := filepath.Join(dir, strconv.Itoa(bookID)+ext)
+	if err := ioutil.WriteFile(localPath, data, 0644); err != nil {
+		return "", fmt.Errorf("error writing cover: %w", err)
+	}
+
+	return localPath, nil
+}
+
+// Compares freshly fetched NomadList stays against stored ones, returning
+// stays that are new and stored stays that are no longer in the profile.
+func diffNomadStays(apiStays, existingStays []*NomadStay) ([]*NomadStay, []*NomadStay) {
+	apiIDs := make(map[string]struct{}, len(apiStays))
+	for _, stay := range apiStays {
+		apiIDs[stay.ID] = struct{}{}
+	}
+
+	existingIDs := make(map[string]struct{}, len(existingStays))
+	for _, stay := range existingStays {
+		existingIDs[stay.ID] = struct{}{}
+	}
+
+	var added, removed []*NomadStay
+	for _, stay := range apiStays {
+		if _, ok := existingIDs[stay.ID]; !ok {
+			added = append(added, stay)
+		}
+	}
+	for _, stay := range existingStays {
+		if _, ok := apiIDs[stay.ID]; !ok {
+			removed = append(removed, stay)
+		}
+	}
+
+	return added, removed
+}
+
+// Decodes the contents of a TOML file written with any of the supported
+// output encodings back to UTF-8. A byte order mark is stripped, and files
+// starting with latin1Header are decoded from Latin-1. Anything else that
+// isn't valid UTF-8 is assumed to be Latin-1 as well.
+func decodeOutput(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, utf8BOM) {
+		return bytes.TrimPrefix(data, utf8BOM), nil
+	}
+
+	isLatin1 := bytes.HasPrefix(data, latin1Header)
+	if !isLatin1 && utf8.Valid(data) {
+		return data, nil
+	}
+
+	decoded, err := charmap.ISO8859_1.NewDecoder().Bytes(bytes.TrimPrefix(data, latin1Header))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding latin-1: %w", err)
+	}
+
+	return decoded, nil
+}
+
+// Encodes marshaled TOML with the given output encoding. When encoding to
+// Latin-1, latin1Header is prepended and characters that can't be represented
+// are replaced with "?". A warning is logged with the number of characters
+// replaced and the closest preceding ID of each so that the affected records
+// can be found.
+func encodeOutput(data []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case "", outputEncodingUTF8:
+		return data, nil
+
+	case outputEncodingUTF8BOM:
+		return append(append([]byte{}, utf8BOM...), data...), nil
+
+	case outputEncodingLatin1:
+		var lastID string
+		var numReplaced int
+		var replacedIDs []string
+		lines := strings.Split(string(data), "\n")
+
+		for i, line := range lines {
+			if match := tomlIDRE.FindStringSubmatch(line); match != nil {
+				lastID = match[1]
+			}
+
+			lines[i] = strings.Map(func(r rune) rune {
+				if r <= unicode.MaxLatin1 {
+					return r
+				}
+
+				numReplaced++
+				if len(replacedIDs) < 1 || replacedIDs[len(replacedIDs)-1] != lastID {
+					replacedIDs = append(replacedIDs, lastID)
+				}
+				return '?'
+			}, line)
+		}
+
+		if numReplaced > 0 {
+			logger.Warnf("Replaced %v character(s) that can't be represented in latin-1 (record IDs: %v)",
+				numReplaced, strings.Join(replacedIDs, ", "))
+		}
+
+		encoded, err := charmap.ISO8859_1.NewEncoder().Bytes([]byte(strings.Join(lines, "\n")))
+		if err != nil {
+			return nil, fmt.Errorf("error encoding latin-1: %w", err)
+		}
+
+		return append(append([]byte{}, latin1Header...), encoded...), nil
+	}
+
+	return nil, fmt.Errorf("unknown output encoding '%s'", encoding)
+}
+
+// Replaces t.co links in each tweet's text with the expanded URLs from its
+// entities. Links without a matching entity are left alone. It's safe to run
+// more than once since expanded URLs aren't matched again.
+func expandTweetURLs(tweets []*Tweet) {
+	for _, tweet := range tweets {
+		if tweet.Entities == nil || len(tweet.Entities.URLs) < 1 {
+			continue
+		}
+
+		expandedURLs := make(map[string]string, len(tweet.Entities.URLs))
+		for _, entityURL := range tweet.Entities.URLs {
+			if entityURL.ExpandedURL != "" {
+				expandedURLs[entityURL.URL] = entityURL.ExpandedURL
+			}
+		}
+
+		expand := func(tcoURL string) string {
+			if expandedURL, ok := expandedURLs[tcoURL]; ok {
+				return expandedURL
+			}
+			return tcoURL
+		}
+
+		// Normalization leaves t.co links alone, so they're expanded the
+		// same way in normalized text.
+		tweet.NormalizedText = tcoURLRE.ReplaceAllStringFunc(tweet.NormalizedText, expand)
+		tweet.Text = tcoURLRE.ReplaceAllStringFunc(tweet.Text, expand)
+
+		// Existing tweets are expanded before merging too, so their hashes
+		// still match the API's as long as the text hasn't changed.
+		tweet.TextHashSHA1 = hashSHA1(tweet.Text)
+	}
+}
+
+// Writes readings and tweets as a standalone HTML document. Readings are
+// written in the order they're stored, and tweets oldest first.
+func exportHTML(w io.Writer, opts *ExportHTMLOptions) error {
+	var data ExportHTMLData
+
+	if opts.GoodreadsPath != "PATH" {
+		readingDB, err := readReadingDB(opts.GoodreadsPath)
+		if err != nil {
+			return err
+		}
+		data.Readings = readingDB.Readings
+	}
+
+	if opts.TwitterPath != "PATH" {
+		tweetDB, err := readTweetDB(opts.TwitterPath)
+		if err != nil {
+			return err
+		}
+
+		data.Tweets = make([]*Tweet, len(tweetDB.Tweets))
+		copy(data.Tweets, tweetDB.Tweets)
+		sort.SliceStable(data.Tweets, func(i, j int) bool {
+			return data.Tweets[i].ID < data.Tweets[j].ID
+		})
+	}
+
+	tmpl, err := template.New("export.html.tmpl").Funcs(template.FuncMap{
+		"excerpt":        exportHTMLExcerpt,
+		"formatDate":     func(t time.Time) string { return t.Format("January 2, 2006") },
+		"ratingStars":    exportHTMLRatingStars,
+		"readingAuthors": exportHTMLReadingAuthors,
+	}).ParseFS(exportHTMLTemplates, "templates/export.html.tmpl")
+	if err != nil {
+		return fmt.Errorf("error parsing HTML template: %w", err)
+	}
+
+	return tmpl.Execute(w, &data)
+}
+
+// Maximum number of characters of a review shown by the `export-html`
+// command before it's truncated.
+const exportHTMLExcerptMaxLength = 280
+
+// Truncates a review to exportHTMLExcerptMaxLength characters, breaking at a
+// word boundary where possible.
+func exportHTMLExcerpt(s string) string {
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) <= exportHTMLExcerptMaxLength {
+		return string(runes)
+	}
+
+	excerpt := string(runes[:exportHTMLExcerptMaxLength])
+	if i := strings.LastIndexAny(excerpt, " \n"); i > 0 {
+		excerpt = excerpt[:i]
+	}
+	return strings.TrimRightFunc(excerpt, unicode.IsSpace) + "…"
+}
+
+// Creates (or replaces) the file at targetPath and writes the `export-html`
+// command's output to it.
+func exportHTMLFile(targetPath string, opts *ExportHTMLOptions) error {
+	var buf bytes.Buffer
+	if err := exportHTML(&buf, opts); err != nil {
+		return err
+	}
+
+	logger.Infof("Writing HTML export to '%s'", targetPath)
+	return ioutil.WriteFile(targetPath, buf.Bytes(), 0644)
+}
+
+// Formats a reading's rating out of five as filled and empty stars like
+// "★★★☆☆".
+func exportHTMLRatingStars(rating int) string {
+	if rating < 0 {
+		rating = 0
+	} else if rating > 5 {
+		rating = 5
+	}
+	return strings.Repeat("★", rating) + strings.Repeat("☆", 5-rating)
+}
+
+// Joins the names of a reading's primary authors for display.
+func exportHTMLReadingAuthors(reading *Reading) string {
+	var names []string
+	for _, author := range reading.Authors {
+		if isPrimaryAuthor(author) {
+			names = append(names, author.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// Writes readings as an OPML document in the order they're stored. OPML is
+// XML, but it's rendered with text/template (which doesn't escape anything) so
+// that it's output exactly as written, and so every value is escaped in the
+// template with exportOPMLEscape.
+func exportOPML(w io.Writer, opts *ExportOPMLOptions) error {
+	var data ExportOPMLData
+
+	if opts.GoodreadsPath != "PATH" {
+		readingDB, err := readReadingDB(opts.GoodreadsPath)
+		if err != nil {
+			return err
+		}
+		data.Readings = readingDB.Readings
+	}
+
+	tmpl, err := texttemplate.New("export.opml.tmpl").Funcs(texttemplate.FuncMap{
+		"formatDate":     func(t time.Time) string { return t.Format("2006-01-02") },
+		"readingAuthors": exportHTMLReadingAuthors,
+		"xml":            exportOPMLEscape,
+	}).ParseFS(exportOPMLTemplates, "templates/export.opml.tmpl")
+	if err != nil {
+		return fmt.Errorf("error parsing OPML template: %w", err)
+	}
+
+	return tmpl.Execute(w, &data)
+}
+
+// Escapes a string for use in XML text or a quoted attribute value.
+func exportOPMLEscape(s string) (string, error) {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Creates (or replaces) the file at targetPath and writes the `export-opml`
+// command's output to it.
+func exportOPMLFile(targetPath string, opts *ExportOPMLOptions) error {
+	var buf bytes.Buffer
+	if err := exportOPML(&buf, opts); err != nil {
+		return err
+	}
+
+	logger.Infof("Writing OPML export to '%s'", targetPath)
+	return ioutil.WriteFile(targetPath, buf.Bytes(), 0644)
+}
+
+// Maximum number of goals whose data points are fetched from Beeminder at
+// once.
+const beeminderConcurrency = 4
+
+func fetchBeeminder(ctx context.Context, conf *BeeminderConf, client *http.Client, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		"https://www.beeminder.com/api/v1/users/"+url.PathEscape(conf.BeeminderUsername)+path, nil)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("auth_token", conf.BeeminderAuthToken)
+	req.URL.RawQuery = params.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from Beeminder: %v (%s)", resp.StatusCode, data)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+	}
+
+	return nil
+}
+
+// Fetches a page of events from the Google Calendar API that start between
+// timeMin and timeMax. Recurring events are expanded into their individual
+// occurrences.
+func fetchCalEvents(ctx context.Context, conf *CalConf, client *http.Client, accessToken string, timeMin, timeMax time.Time, pageToken string) (*CalAPIEvents, error) {
+	v := url.Values{}
+	v.Set("maxResults", "2500") // maximum 2500
+	v.Set("orderBy", "startTime")
+	v.Set("singleEvents", "true")
+	v.Set("timeMax", timeMax.Format(time.RFC3339))
+	v.Set("timeMin", timeMin.Format(time.RFC3339))
+	if pageToken != "" {
+		v.Set("pageToken", pageToken)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		"https://www.googleapis.com/calendar/v3/calendars/"+url.PathEscape(conf.CalCalendarID)+"/events?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error listing events: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading events body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Google Calendar: %v (%s)", resp.StatusCode, data)
+	}
+
+	var events CalAPIEvents
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("error unmarshaling events from JSON: %w", err)
+	}
+
+	return &events, nil
+}
+
+// Exchanges a JWT signed with a service account's private key for an access
+// token with calScope. See:
+//
+// https://developers.google.com/identity/protocols/oauth2/service-account#httprest
+func fetchCalAccessToken(ctx context.Context, client *http.Client, account *CalAPIServiceAccount) (string, error) {
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("service account has no PEM encoded private key")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("error parsing service account private key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("service account private key isn't an RSA key")
+	}
+
+	tokenURI := account.TokenURI
+	if tokenURI == "" {
+		tokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	now := time.Now()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud":   tokenURI,
+		"exp":   now.Add(time.Hour).Unix(),
+		"iat":   now.Unix(),
+		"iss":   account.ClientEmail,
+		"scope": calScope,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing JWT: %w", err)
+	}
+
+	v := url.Values{}
+	v.Set("assertion", signingInput+"."+base64.RawURLEncoding.EncodeToString(signature))
+	v.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURI, strings.NewReader(v.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error requesting access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading access token body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code from Google OAuth: %v (%s)", resp.StatusCode, data)
+	}
+
+	var token CalAPIToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return "", fmt.Errorf("error unmarshaling access token from JSON: %w", err)
+	}
+
+	return token.AccessToken, nil
+}
+
+func fetchChessCom(ctx context.Context, client *http.Client, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.chess.com"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	// Chess.com asks that API clients identify themselves.
+	req.Header.Set("User-Agent", "qself (https://github.com/brandur/qself)")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from Chess.com: %v (%s)", resp.StatusCode, data)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+	}
+
+	return nil
+}
+
+// Number of results requested from Clockify in a single page.
+const clockifyPageSize = 200
+
+// Clockify entries may be edited after they're stopped, so when syncing
+// incrementally we start this many days before the last one that's stored.
+const clockifyRefetchDays = 7
+
+func fetchClockify(ctx context.Context, conf *ClockifyConf, client *http.Client, path string, params url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.clockify.me/api/v1"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-Api-Key", conf.ClockifyAPIKey)
+	req.URL.RawQuery = params.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from Clockify: %v (%s)", resp.StatusCode, data)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+	}
+
+	return nil
+}
+
+// Fetches every page of a Clockify list, which ends at the first page that
+// comes back with fewer than clockifyPageSize results. params may be nil.
+func fetchClockifyList[T any](ctx context.Context, conf *ClockifyConf, client *http.Client, path string, params url.Values) ([]T, error) {
+	var all []T
+
+	for page := 1; ; page++ {
+		v := url.Values{}
+		for name, values := range params {
+			v[name] = values
+		}
+		v.Set("page", strconv.Itoa(page))
+		v.Set("page-size", strconv.Itoa(clockifyPageSize))
+
+		var pageItems []T
+		if err := fetchClockify(ctx, conf, client, path, v, &pageItems); err != nil {
+			return nil, err
+		}
+
+		all = append(all, pageItems...)
+
+		if len(pageItems) < clockifyPageSize {
+			return all, nil
+		}
+	}
+}
+
+// Number of days of values fetched from Exist on the first sync.
+const existBackfillDays = 365
+
+// Format of dates in the Exist API.
+const existDateFormat = "2006-01-02"
+
+// Maximum number of results requested in a single page from Exist.
+const existPageLimit = 100
+
+// Query for a day of a zone's HTTP requests, grouped by the dimensions that
+// CloudflareDay breaks them down by.
+const cloudflareGraphQLQuery = `query ($zoneTag: string, $date: Date, $limit: uint64!) {
+  viewer {
+    zones(filter: {zoneTag: $zoneTag}) {
+      httpRequestsAdaptiveGroups(limit: $limit, filter: {date: $date}) {
+        count
+        dimensions {
+          cacheStatus
+          clientCountryName
+          date
+          securityAction
+        }
+        sum {
+          edgeResponseBytes
+          visits
+        }
+      }
+    }
+  }
+}`
+
+// Fetches every group of HTTP requests made to the zone on the given day.
+func fetchCloudflareGroups(ctx context.Context, conf *CloudflareConf, client *http.Client, date time.Time) ([]*CloudflareAPIHTTPRequestsGroup, error) {
+	body, err := json.Marshal(&CloudflareAPIGraphQLRequest{
+		Query: cloudflareGraphQLQuery,
+		Variables: map[string]interface{}{
+			"date":    date.Format(cloudflareDateFormat),
+			"limit":   cloudflareGroupsLimit,
+			"zoneTag": conf.CFZoneID,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.cloudflare.com/client/v4/graphql", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+conf.CFAPIToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting analytics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body from analytics: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Cloudflare: %v (%s)", resp.StatusCode, data)
+	}
+
+	var graphQLResp CloudflareAPIGraphQLResponse
+	err = json.Unmarshal(data, &graphQLResp)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling analytics from JSON: %w", err)
+	}
+
+	if len(graphQLResp.Errors) > 0 {
+		return nil, fmt.Errorf("error from Cloudflare: %s", graphQLResp.Errors[0].Message)
+	}
+
+	if graphQLResp.Data == nil || len(graphQLResp.Data.Viewer.Zones) < 1 {
+		return nil, fmt.Errorf("zone '%s' not found in Cloudflare", conf.CFZoneID)
+	}
+
+	return graphQLResp.Data.Viewer.Zones[0].HTTPRequestsAdaptiveGroups, nil
+}
+
+// Exist revises values for recent days as connected services sync to it, so
+// when syncing incrementally we start this many days before the last one
+// that's stored.
+const existRefetchDays = 7
+
+// Values decoded from JSON numbers are float64, which would be written to TOML
+// with a fractional part even for counts like steps. Whole numbers are
+// converted to int64 so that they're written as integers.
+func existValueForTOML(value interface{}) interface{} {
+	if f, ok := value.(float64); ok && f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+		return int64(f)
+	}
+	return value
+}
+
+func fetchExist(ctx context.Context, conf *ExistConf, client *http.Client, path string, params url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://exist.io/api/2"+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+conf.ExistAccessToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from Exist: %v (%s)", resp.StatusCode, data)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+	}
+
+	return nil
+}
+
+// Fetches the names of every attribute that the user has in Exist.
+func fetchExistAttributeNames(ctx context.Context, conf *ExistConf, client *http.Client) ([]string, error) {
+	var names []string
+
+	for page := 1; ; page++ {
+		v := url.Values{}
+		v.Set("limit", strconv.Itoa(existPageLimit))
+		v.Set("page", strconv.Itoa(page))
+
+		var apiPage ExistAPIPage[*ExistAPIAttribute]
+		if err := fetchExist(ctx, conf, client, "/attributes/", v, &apiPage); err != nil {
+			return nil, err
+		}
+
+		for _, attribute := range apiPage.Results {
+			names = append(names, attribute.Name)
+		}
+
+		if apiPage.Next == nil {
+			break
+		}
+	}
+
+	return names, nil
+}
+
+// Fetches an attribute's values on or after startDate. Exist returns values
+// newest first, so paging stops at the first page that reaches back past
+// startDate.
+func fetchExistValues(ctx context.Context, conf *ExistConf, client *http.Client, attribute string, startDate time.Time) ([]*ExistAPIValue, error) {
+	var values []*ExistAPIValue
+
+	for page := 1; ; page++ {
+		v := url.Values{}
+		v.Set("attribute", attribute)
+		v.Set("limit", strconv.Itoa(existPageLimit))
+		v.Set("page", strconv.Itoa(page))
+
+		var apiPage ExistAPIPage[*ExistAPIValue]
+		if err := fetchExist(ctx, conf, client, "/attributes/values/", v, &apiPage); err != nil {
+			return nil, err
+		}
+
+		reachedStart := false
+		for _, value := range apiPage.Results {
+			date, err := time.Parse(existDateFormat, value.Date)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing date of '%s' value: %w", attribute, err)
+			}
+
+			if date.Before(startDate) {
+				reachedStart = true
+				continue
+			}
+
+			values = append(values, value)
+		}
+
+		if reachedStart || apiPage.Next == nil {
+			break
+		}
+	}
+
+	return values, nil
+}
+
+// Fetches the reading challenges that the given Goodreads user has joined.
+func fetchGoodreadsChallenges(ctx context.Context, conf *GoodreadsConf, client *http.Client) ([]*APIChallenge, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://www.goodreads.com/reading_challenges/user_challenges.xml", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	v.Set("id", conf.GoodreadsID)
+	v.Set("key", conf.GoodreadsKey)
+	req.URL.RawQuery = v.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error listing challenges: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body from challenges list: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Goodreads: %v (%s)", resp.StatusCode, data)
+	}
+
+	var root APIChallengesRoot
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("error unmarshaling challenges from XML: %w", err)
+	}
+
+	return root.Challenges, nil
+}
+
+// Fetches the given Goodreads user's friends, paging until maxFriends have
+// been found or the list runs out.
+func fetchGoodreadsFriends(ctx context.Context, conf *GoodreadsConf, client *http.Client, maxFriends int) ([]*APIFriend, error) {
+	var friends []*APIFriend
+
+	for page := 1; len(friends) < maxFriends; page++ {
+		logger.Infof("(goodreads) Paging friends; num friends accumulated: %v, page: %v", len(friends), page)
+
+		req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://www.goodreads.com/friend/user/%s.xml", conf.GoodreadsID), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		v := url.Values{}
+		v.Set("format", "xml")
+		v.Set("key", conf.GoodreadsKey)
+		v.Set("page", strconv.Itoa(page))
+		req.URL.RawQuery = v.Encode()
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error listing friends: %w", err)
+		}
+
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading body from friends list: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code from Goodreads: %v (%s)", resp.StatusCode, data)
+		}
+
+		var root APIFriendsRoot
+		if err := xml.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("error unmarshaling friends from XML: %w", err)
+		}
+
+		if root.Friends == nil {
+			break
+		}
+
+		friends = append(friends, root.Friends.Users...)
+
+		if len(root.Friends.Users) < 1 || len(friends) >= root.Friends.Total {
+			break
+		}
+	}
+
+	if len(friends) > maxFriends {
+		friends = friends[:maxFriends]
+	}
+
+	return friends, nil
+}
+
+// Fetches a single Goodreads page and returns all the reviews on it. If
+// opts.CacheDir is set, the page is served from the cache when possible.
+func fetchGoodreadsPage(ctx context.Context, conf *GoodreadsConf, client *http.Client, shelf string, page int, opts *SyncGoodreadsOptions) ([]*APIReview, error) {
+	var cachePath string
+	var data []byte
+	var err error
+
+	if opts.CacheDir != "" {
+		cachePath = goodreadsCachePath(opts.CacheDir, conf.GoodreadsID, shelf, opts.APISort, page)
+
+		data, err = readGoodreadsCache(cachePath, opts.CacheTTL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if data == nil {
+		data, err = requestGoodreadsPage(ctx, conf, client, shelf, opts.APISort, page)
+		if err != nil {
+			return nil, err
+		}
+
+		if cachePath != "" {
+			if err := ioutil.WriteFile(cachePath, data, 0644); err != nil {
+				return nil, fmt.Errorf("error writing Goodreads cache file: %w", err)
+			}
+		}
+	}
+
+	var root APIReviewsRoot
+	err = xml.Unmarshal(data, &root)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling reviews from XML: %w", err)
+	}
+
+	return root.Reviews, nil
+}
+
+// sort may be empty, in which case goodreadsSortDateRead is used.
+func requestGoodreadsPage(ctx context.Context, conf *GoodreadsConf, client *http.Client, shelf, sort string, page int) ([]byte, error) {
+	if sort == "" {
+		sort = goodreadsSortDateRead
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://www.goodreads.com/review/list/%s.xml", conf.GoodreadsID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	v.Set("key", conf.GoodreadsKey)
+	v.Set("page", strconv.Itoa(page))
+	v.Set("per_page", "20")
+	v.Set("shelf", shelf)
+	v.Set("sort", sort)
+	v.Set("v", "2")
+	req.URL.RawQuery = v.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error listing reviews: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body from reviews list: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Goodreads: %v (%s)", resp.StatusCode, data)
+	}
+
+	return data, nil
+}
+
+// Fetches every review on the given Goodreads shelf, returning them as
+// readings along with the number of reviews that were skipped because they
+// couldn't be processed.
+// Fetches every reading on a Goodreads shelf. Also returns the IDs of reviews
+// that were skipped because they couldn't be processed, so that their stored
+// readings can be kept.
+func fetchGoodreadsShelf(ctx context.Context, conf *GoodreadsConf, client *http.Client, shelf string, opts *SyncGoodreadsOptions) ([]*Reading, []int, error) {
+	var readings []*Reading
+
+	// Unluckily, the Goodreads API is very slow. Luckily, it supports offset
+	// based pagination, making it quite easy for us to parallelize.
+	const numSegments = 6
+	var anyErr error
+	var knownEndPage int
+	var mutex sync.RWMutex
+	var skippedReviewIDs []int
+	var wg sync.WaitGroup
+	wg.Add(numSegments)
+
+	for i := 1; i <= numSegments; i++ {
+		segmentNum := i
+
+		go func() {
+			page := segmentNum
+
+			for {
+				logger.Infof("(goodreads) (%v) (segment %v) Paging; num readings accumulated: %v, page: %v",
+					shelf, segmentNum, len(readings), page)
+
+				if knownEndPage != 0 && page >= knownEndPage {
+					logger.Infof("(goodreads) (segment %v) Page %v beyond known end of %v; stopping",
+						segmentNum, page, knownEndPage)
+					break
+				}
+
+				apiReviews, err := fetchGoodreadsPage(ctx, conf, client, shelf, page, opts)
+				if err != nil {
+					logger.Errorf("(goodreads) (segment %v) %v", segmentNum, err)
+					anyErr = err
+					break
+				}
+
+				if len(apiReviews) < 1 {
+					// If we know this page is beyond bounds, mark it as such
+					// to maybe save some API requests.
+					mutex.Lock()
+					if knownEndPage == 0 || page < knownEndPage {
+						logger.Infof("(goodreads) (segment %v) Setting known end page: %v (previously %v)",
+							segmentNum, page, knownEndPage)
+						knownEndPage = page
+					}
+					mutex.Unlock()
+
+					break
+				}
+
+				var pageReadings []*Reading
+				var pageSkippedReviewIDs []int
+				for _, apiReview := range apiReviews {
+					reading, err := readingFromAPIReview(apiReview, opts)
+					if err != nil {
+						logger.Errorf("(goodreads) (segment %v) Skipping review %v: %v",
+							segmentNum, apiReview.ID, err)
+						pageSkippedReviewIDs = append(pageSkippedReviewIDs, apiReview.ID)
+						continue
+					}
+
+					pageReadings = append(pageReadings, reading)
+				}
+
+				mutex.Lock()
+				readings = append(readings, pageReadings...)
+				skippedReviewIDs = append(skippedReviewIDs, pageSkippedReviewIDs...)
+				mutex.Unlock()
+
+				page += numSegments
+			}
+
+			wg.Done()
+		}()
+	}
+
+	wg.Wait()
+
+	if anyErr != nil {
+		return nil, nil, anyErr
+	}
+
+	return readings, skippedReviewIDs, nil
+}
+
+// Makes a request to the Google Photos Library API and unmarshals its response
+// into v. body is sent as JSON if it isn't nil.
+func fetchGooglePhotos(ctx context.Context, client *http.Client, accessToken, method, path string, body interface{}, v interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, "https://photoslibrary.googleapis.com"+path, reqBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from Google Photos: %v (%s)", resp.StatusCode, data)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+	}
+
+	return nil
+}
+
+// Exchanges the refresh token in a user's credentials for an access token.
+// See:
+//
+// https://developers.google.com/identity/protocols/oauth2/web-server#offline
+func fetchGooglePhotosAccessToken(ctx context.Context, client *http.Client, credentials *GooglePhotosAPICredentials) (string, error) {
+	tokenURI := credentials.TokenURI
+	if tokenURI == "" {
+		tokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	v := url.Values{}
+	v.Set("client_id", credentials.ClientID)
+	v.Set("client_secret", credentials.ClientSecret)
+	v.Set("grant_type", "refresh_token")
+	v.Set("refresh_token", credentials.RefreshToken)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURI, strings.NewReader(v.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error requesting access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading access token body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code from Google OAuth: %v (%s)", resp.StatusCode, data)
+	}
+
+	var token CalAPIToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return "", fmt.Errorf("error unmarshaling access token from JSON: %w", err)
+	}
+
+	return token.AccessToken, nil
+}
+
+// Returns the IDs of the albums that each photo is in, keyed by photo ID.
+// Listed photos don't say which albums they're in, so every album is searched
+// for its photos instead.
+func fetchGooglePhotosAlbumIDs(ctx context.Context, client *http.Client, accessToken string) (map[string][]string, error) {
+	var albums []*GooglePhotosAPIAlbum
+	var pageToken string
+
+	for {
+		v := url.Values{}
+		v.Set("pageSize", strconv.Itoa(googlePhotosAlbumPageSize))
+		if pageToken != "" {
+			v.Set("pageToken", pageToken)
+		}
+
+		var page GooglePhotosAPIAlbums
+		if err := fetchGooglePhotos(ctx, client, accessToken, "GET", "/v1/albums?"+v.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+
+		albums = append(albums, page.Albums...)
+
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	albumIDs := make(map[string][]string)
+
+	for _, album := range albums {
+		logger.Infof("(googlephotos) Listing photos in album '%s'", album.Title)
+
+		pageToken = ""
+		for {
+			body := map[string]interface{}{
+				"albumId":  album.ID,
+				"pageSize": googlePhotosPageSize,
+			}
+			if pageToken != "" {
+				body["pageToken"] = pageToken
+			}
+
+			var page GooglePhotosAPIMediaItems
+			if err := fetchGooglePhotos(ctx, client, accessToken, "POST", "/v1/mediaItems:search", body, &page); err != nil {
+				return nil, err
+			}
+
+			for _, item := range page.MediaItems {
+				albumIDs[item.ID] = append(albumIDs[item.ID], album.ID)
+			}
+
+			if page.NextPageToken == "" {
+				break
+			}
+			pageToken = page.NextPageToken
+		}
+	}
+
+	return albumIDs, nil
+}
+
+// Streams games for a user from Lichess, which are returned as newline
+// delimited JSON, invoking fn for each one. Only games started after since are
+// fetched, unless it's zero.
+func fetchLichessGames(ctx context.Context, client *http.Client, username string, since time.Time, fn func(game *LichessAPIGame) error) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://lichess.org/api/games/user/"+url.PathEscape(username), nil)
+	if err != nil {
+		return err
+	}
+
+	v := url.Values{}
+	v.Set("opening", "true")
+	v.Set("pgnInJson", "true")
+	if !since.IsZero() {
+		v.Set("since", strconv.FormatInt(since.UnixNano()/int64(time.Millisecond), 10))
+	}
+
+	req.Header.Set("Accept", "application/x-ndjson")
+	req.URL.RawQuery = v.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting Lichess games: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code from Lichess: %v (%s)", resp.StatusCode, data)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var game LichessAPIGame
+		err := decoder.Decode(&game)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error decoding Lichess game: %w", err)
+		}
+
+		if err := fn(&game); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Makes a request to the LinkedIn API and unmarshals the response into v.
+// rawQuery is used as is because Rest.li list parameters like
+// `List(urn%3Ali%3Aperson%3A123)` would be mangled by url.Values. Every
+// request is counted towards LinkedIn's daily limit in numRequests.
+func fetchLinkedIn(ctx context.Context, conf *LinkedInConf, client *http.Client, path, rawQuery string, numRequests *int, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.linkedin.com/v2"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+conf.LinkedInAccessToken)
+	req.Header.Set("X-Restli-Protocol-Version", "2.0.0")
+	req.URL.RawQuery = rawQuery
+
+	*numRequests++
+	if *numRequests == linkedInRequestWarnThreshold {
+		logger.Warnf("(linkedin) Made %v requests; approaching LinkedIn's limit of %v a day",
+			*numRequests, linkedInDailyRequestLimit)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", path, err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("rate limited by LinkedIn after %v request(s); its limit is %v a day: %s",
+			*numRequests, linkedInDailyRequestLimit, data)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from LinkedIn: %v (%s)", resp.StatusCode, data)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+	}
+
+	return nil
+}
+
+func fetchMediumFeed(ctx context.Context, client *http.Client, username string) ([]*MediumRSSItem, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://medium.com/feed/@"+url.PathEscape(username), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading feed body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Medium: %v (%s)", resp.StatusCode, data)
+	}
+
+	var root MediumRSSRoot
+	err = xml.Unmarshal(data, &root)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling feed from XML: %w", err)
+	}
+
+	return root.Items, nil
+}
+
+func fetchMediumPost(ctx context.Context, client *http.Client, id string) (*MediumAPIPost, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://medium.com/_/api/posts/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting post %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body from post %s: %w", id, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Medium: %v (%s)", resp.StatusCode, data)
+	}
+
+	return parseMediumAPIPost(data)
+}
+
+func fetchMonzo(ctx context.Context, conf *MonzoConf, client *http.Client, path string, params url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.monzo.com"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+conf.MonzoAccessToken)
+	req.URL.RawQuery = params.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", path, err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("unauthorized by Monzo; access token may have expired (see `qself sync-monzo --help`): %s", data)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from Monzo: %v (%s)", resp.StatusCode, data)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+	}
+
+	return nil
+}
+
+func fetchNomadList(ctx context.Context, client *http.Client, username string) (*NomadListAPIProfile, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://nomadlist.com/@"+url.PathEscape(username)+".json", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting profile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading profile body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from NomadList: %v (%s)", resp.StatusCode, data)
+	}
+
+	var profile NomadListAPIProfile
+	err = json.Unmarshal(data, &profile)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling profile from JSON: %w", err)
+	}
+
+	return &profile, nil
+}
+
+// Fetches the weather at the given time from OpenWeather's One Call API. Only
+// a single data point is returned for each request.
+func fetchOpenWeatherTimeMachine(ctx context.Context, conf *OpenWeatherConf, client *http.Client, t time.Time) (*OpenWeatherAPITimeMachineRoot, error) {
+	v := url.Values{}
+	v.Set("appid", conf.OpenWeatherAPIKey)
+	v.Set("dt", strconv.FormatInt(t.Unix(), 10))
+	v.Set("lat", strconv.FormatFloat(conf.OpenWeatherLat, 'f', -1, 64))
+	v.Set("lon", strconv.FormatFloat(conf.OpenWeatherLon, 'f', -1, 64))
+	v.Set("units", "metric")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.openweathermap.org/data/3.0/onecall/timemachine?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting weather: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading weather body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from OpenWeather: %v (%s)", resp.StatusCode, data)
+	}
+
+	var root OpenWeatherAPITimeMachineRoot
+	err = json.Unmarshal(data, &root)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling weather from JSON: %w", err)
+	}
+
+	return &root, nil
+}
+
+// Pages through an Oura collection from the given start date until today,
+// invoking fn with the raw data of each page.
+//
+// Oura's API requires a date range for its collections, and while it also
+// paginates with a token, we request data in modestly sized windows of dates
+// so that no single request gets too large.
+func fetchOuraCollection(ctx context.Context, conf *OuraConf, client *http.Client, collection string, startDate time.Time, fn func(data json.RawMessage) error) error {
+	now := time.Now()
+
+	for windowStart := startDate; windowStart.Before(now); windowStart = windowStart.AddDate(0, 0, ouraWindowDays) {
+		windowEnd := windowStart.AddDate(0, 0, ouraWindowDays)
+
+		var nextToken string
+		for {
+			logger.Infof("(oura) Paging %s; window: %v to %v",
+				collection, windowStart.Format(ouraDateFormat), windowEnd.Format(ouraDateFormat))
+
+			page, err := fetchOuraPage(ctx, conf, client, collection, windowStart, windowEnd, nextToken)
+			if err != nil {
+				return err
+			}
+
+			if err := fn(page.Data); err != nil {
+				return err
+			}
+
+			if page.NextToken == nil || *page.NextToken == "" {
+				break
+			}
+			nextToken = *page.NextToken
+		}
+	}
+
+	return nil
+}
+
+// Fetches a single page of an Oura collection.
+func fetchOuraPage(ctx context.Context, conf *OuraConf, client *http.Client, collection string, startDate, endDate time.Time, nextToken string) (*OuraAPIPage, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.ouraring.com/v2/usercollection/"+collection, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+conf.OuraAccessToken)
+
+	v := url.Values{}
+	v.Set("end_date", endDate.Format(ouraDateFormat))
+	if nextToken != "" {
+		v.Set("next_token", nextToken)
+	}
+	v.Set("start_date", startDate.Format(ouraDateFormat))
+	req.URL.RawQuery = v.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s: %w", collection, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body from %s list: %w", collection, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Oura: %v (%s)", resp.StatusCode, data)
+	}
+
+	var page OuraAPIPage
+	err = json.Unmarshal(data, &page)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling %s from JSON: %w", collection, err)
+	}
+
+	return &page, nil
+}
+
+// Number of projects requested from Toggl in a single page (the maximum it
+// allows).
+const togglProjectsPerPage = 200
+
+// Fetches a resource from Runkeeper's Health Graph API and unmarshals it into
+// v. The API requires that the type of the resource is named in the Accept
+// header. path may include a query string, like the paths of subsequent pages
+// that the activity feed returns.
+func fetchRunkeeper(ctx context.Context, conf *RunkeeperConf, client *http.Client, path, mediaType string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.runkeeper.com"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", mediaType)
+	req.Header.Set("Authorization", "Bearer "+conf.RunkeeperAccessToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from Runkeeper: %v (%s)", resp.StatusCode, data)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+	}
+
+	return nil
+}
+
+func fetchSteamOwnedGames(ctx context.Context, conf *SteamConf, client *http.Client) ([]*SteamAPIGame, error) {
+	v := url.Values{}
+	v.Set("format", "json")
+	v.Set("include_appinfo", "1")
+	v.Set("include_played_free_games", "1")
+	v.Set("key", conf.SteamAPIKey)
+	v.Set("steamid", conf.SteamUserID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.steampowered.com/IPlayerService/GetOwnedGames/v0001/?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Leave out the URL, which contains the API key.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("error requesting owned games: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading owned games body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Steam: %v (%s)", resp.StatusCode, data)
+	}
+
+	var root SteamAPIOwnedGamesRoot
+	err = json.Unmarshal(data, &root)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling owned games from JSON: %w", err)
+	}
+
+	return root.Response.Games, nil
+}
+
+// Fetches every object in a Stripe list created at or after createdGTE (or
+// every object if it's zero), following starting_after cursors until there
+// are no more pages. Stripe returns objects newest first.
+func fetchStripeList[T any](ctx context.Context, conf *StripeConf, client *http.Client, path string, createdGTE time.Time, idFunc func(T) string) ([]T, error) {
+	var objects []T
+	var startingAfter string
+
+	for {
+		logger.Infof("(stripe) Paging %s; num accumulated: %v", path, len(objects))
+
+		v := url.Values{}
+		v.Set("limit", strconv.Itoa(stripePageLimit))
+		if !createdGTE.IsZero() {
+			v.Set("created[gte]", strconv.FormatInt(createdGTE.Unix(), 10))
+		}
+		if startingAfter != "" {
+			v.Set("starting_after", startingAfter)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", "https://api.stripe.com"+path+"?"+v.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.SetBasicAuth(conf.StripeSecretKey, "")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error requesting %s: %w", path, err)
+		}
+
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading body from %s: %w", path, err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code from Stripe: %v (%s)", resp.StatusCode, data)
+		}
+
+		var list StripeAPIList[T]
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+		}
+
+		objects = append(objects, list.Data...)
+
+		if !list.HasMore || len(list.Data) < 1 {
+			break
+		}
+		startingAfter = idFunc(list.Data[len(list.Data)-1])
+	}
+
+	return objects, nil
+}
+
+// Fetches a page of posts from Substack's undocumented posts API. Errors leave
+// out the session cookie.
+func fetchSubstackPosts(ctx context.Context, conf *SubstackConf, client *http.Client, offset int) ([]*SubstackAPIPost, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		fmt.Sprintf("https://%s.substack.com/api/v1/posts", conf.SubstackSubdomain), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	v.Set("limit", strconv.Itoa(substackPageLimit))
+	v.Set("offset", strconv.Itoa(offset))
+	req.URL.RawQuery = v.Encode()
+
+	req.AddCookie(&http.Cookie{Name: "substack.sid", Value: conf.SubstackCookie})
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting posts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body from posts: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Substack: %v (%s)", resp.StatusCode, data)
+	}
+
+	var posts []*SubstackAPIPost
+	err = json.Unmarshal(data, &posts)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling posts from JSON: %w", err)
+	}
+
+	return posts, nil
+}
+
+// Calls a method of Telegram's Bot API and unmarshals its result into v. Errors
+// leave out the request's URL because it contains the bot token.
+func fetchTelegram(ctx context.Context, conf *TelegramConf, client *http.Client, method string, params url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.telegram.org/bot"+conf.TelegramBotToken+"/"+method, nil)
+	if err != nil {
+		return fmt.Errorf("error building request for %s", method)
+	}
+
+	req.URL.RawQuery = params.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("error requesting %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", method, err)
+	}
+
+	var apiResp TelegramAPIResponse
+	err = json.Unmarshal(data, &apiResp)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON (status %v): %w", method, resp.StatusCode, err)
+	}
+
+	if !apiResp.OK {
+		return fmt.Errorf("error from Telegram calling %s: %v (%s)", method, apiResp.ErrorCode, apiResp.Description)
+	}
+
+	err = json.Unmarshal(apiResp.Result, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s result from JSON: %w", method, err)
+	}
+
+	return nil
+}
+
+// Looks up the path from which a file can be downloaded. Files larger than
+// 20 MB can't be downloaded by bots, and produce an error.
+func fetchTelegramFilePath(ctx context.Context, conf *TelegramConf, client *http.Client, fileID string) (string, error) {
+	v := url.Values{}
+	v.Set("file_id", fileID)
+
+	var file TelegramAPIFile
+	if err := fetchTelegram(ctx, conf, client, "getFile", v, &file); err != nil {
+		return "", err
+	}
+
+	return file.FilePath, nil
+}
+
+func fetchToggl(ctx context.Context, conf *TogglConf, client *http.Client, path string, params url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.track.toggl.com/api/v9"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.SetBasicAuth(conf.TogglAPIToken, "api_token")
+	req.URL.RawQuery = params.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from Toggl: %v (%s)", resp.StatusCode, data)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+	}
+
+	return nil
+}
+
+// Fetches every project in the given workspace, returning them keyed by ID.
+func fetchTogglProjects(ctx context.Context, conf *TogglConf, client *http.Client, workspaceID int64, projects map[int64]*TogglAPIProject) error {
+	for page := 1; ; page++ {
+		v := url.Values{}
+		v.Set("page", strconv.Itoa(page))
+		v.Set("per_page", strconv.Itoa(togglProjectsPerPage))
+
+		var pageProjects []*TogglAPIProject
+		err := fetchToggl(ctx, conf, client, fmt.Sprintf("/workspaces/%v/projects", workspaceID), v, &pageProjects)
+		if err != nil {
+			return err
+		}
+
+		for _, project := range pageProjects {
+			projects[project.ID] = project
+		}
+
+		if len(pageProjects) < togglProjectsPerPage {
+			return nil
+		}
+	}
+}
+
+// Maximum number of tweets that can be looked up in a single request to
+// Twitter's v1.1 API.
+const twitterLookupMaxIDs = 100
+
+// Looks up the given tweets with Twitter's v1.1 API, asking for their cards.
+// Cards are an undocumented part of the API that must be requested for a
+// specific platform, and are otherwise left out.
+func fetchTwitterAPICards(ctx context.Context, client *http.Client, tweets []*Tweet) ([]*TwitterAPICardTweet, error) {
+	ids := make([]string, len(tweets))
+	for i, tweet := range tweets {
+		ids[i] = strconv.FormatInt(tweet.ID, 10)
+	}
+
+	v := url.Values{}
+	v.Set("cards_platform", "Web-12")
+	v.Set("id", strings.Join(ids, ","))
+	v.Set("include_cards", "1")
+	v.Set("trim_user", "true")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.twitter.com/1.1/statuses/lookup.json?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting tweet cards: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tweet cards body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Twitter: %v (%s)", resp.StatusCode, data)
+	}
+
+	var apiCardTweets []*TwitterAPICardTweet
+	err = json.Unmarshal(data, &apiCardTweets)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling tweet cards from JSON: %w", err)
+	}
+
+	return apiCardTweets, nil
+}
+
+// Maximum number of tweets that can be looked up at once in Twitter's v2 API.
+const twitterAPIV2MaxIDs = 100
+
+// Looks up the given tweets in Twitter's v2 API. The client should be one
+// that's already been authenticated with OAuth 1.0a, which v2 accepts as well.
+func fetchTwitterAPIV2Tweets(ctx context.Context, client *http.Client, tweets []*Tweet) ([]*TwitterAPIV2Tweet, error) {
+	ids := make([]string, len(tweets))
+	for i, tweet := range tweets {
+		ids[i] = strconv.FormatInt(tweet.ID, 10)
+	}
+
+	v := url.Values{}
+	v.Set("ids", strings.Join(ids, ","))
+	v.Set("tweet.fields", "entities,public_metrics")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.twitter.com/2/tweets?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting v2 tweets: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading v2 tweets body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Twitter v2: %v (%s)", resp.StatusCode, data)
+	}
+
+	var root TwitterAPIV2TweetsRoot
+	err = json.Unmarshal(data, &root)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling v2 tweets from JSON: %w", err)
+	}
+
+	return root.Data, nil
+}
+
+func fetchWUHistory(ctx context.Context, conf *WUConf, client *http.Client, startDate, endDate time.Time) ([]*WUAPIObservation, error) {
+	v := url.Values{}
+	v.Set("apiKey", conf.WUAPIKey)
+	v.Set("endDate", endDate.Format(wuDateFormat))
+	v.Set("format", "json")
+	v.Set("startDate", startDate.Format(wuDateFormat))
+	v.Set("stationId", conf.WUStationID)
+	v.Set("units", "m")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.weather.com/v2/pws/history/daily?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting history: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading history body: %w", err)
+	}
+
+	// Weather Underground responds with an empty 204 for ranges without any
+	// observations.
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from Weather Underground: %v (%s)", resp.StatusCode, data)
+	}
+
+	var root WUAPIHistoryRoot
+	err = json.Unmarshal(data, &root)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling history from JSON: %w", err)
+	}
+
+	return root.Observations, nil
+}
+
+// Fetches a single WakaTime API resource and decodes it into v.
+func fetchWakaTime(ctx context.Context, conf *WakaTimeConf, client *http.Client, path string, params url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://wakatime.com/api/v1"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(conf.WakaTimeAPIKey)))
+	req.URL.RawQuery = params.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from WakaTime: %v (%s)", resp.StatusCode, data)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+	}
+
+	return nil
+}
+
+// Withings measure types requested from the API.
+const (
+	withingsMeasureTypeWeight     = 1
+	withingsMeasureTypeFatRatio   = 6
+	withingsMeasureTypeHeartPulse = 11
+	withingsMeasureTypeMuscleMass = 76
+	withingsMeasureTypeHydration  = 77
+	withingsMeasureTypeBoneMass   = 88
+)
+
+// Status in a Withings response body indicating that the access token is
+// invalid or has expired.
+const withingsStatusInvalidToken = 401
+
+// Makes a request to the Withings API and decodes the response body into v.
+// Parameters are sent as a form. If accessToken is empty, the request is made
+// without authorization, which is what the token endpoint expects.
+//
+// Returns errWithingsInvalidToken if Withings rejected the access token so
+// that the caller can refresh it.
+func fetchWithings(ctx context.Context, client *http.Client, accessToken, path string, params url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://wbsapi.withings.net"+path, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading body from %s: %w", path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from Withings: %v (%s)", resp.StatusCode, data)
+	}
+
+	var apiResp WithingsAPIResponse
+	err = json.Unmarshal(data, &apiResp)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s from JSON: %w", path, err)
+	}
+
+	if apiResp.Status == withingsStatusInvalidToken {
+		return fmt.Errorf("%w: %s", errWithingsInvalidToken, apiResp.Error)
+	}
+
+	if apiResp.Status != 0 {
+		return fmt.Errorf("unexpected status from Withings calling %s: %v (%s)", path, apiResp.Status, apiResp.Error)
+	}
+
+	err = json.Unmarshal(apiResp.Body, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling %s body from JSON: %w", path, err)
+	}
+
+	return nil
+}
+
+// Returned by fetchWithings when Withings rejects the access token.
+var errWithingsInvalidToken = errors.New("access token rejected by Withings")
+
+// Returns the path of a previously downloaded cover for the given book in
+// dir, or an empty string if there isn't one.
+func findGoodreadsCover(dir string, bookID int) string {
+	for _, ext := range []string{".jpg", ".png"} {
+		localPath := filepath.Join(dir, strconv.Itoa(bookID)+ext)
+		if _, err := os.Stat(localPath); err == nil {
+			return localPath
+		}
+	}
+
+	return ""
+}
+
+func findPrimaryMeaning(meanings []*wanikaniapi.SubjectMeaningObject) *wanikaniapi.SubjectMeaningObject {
+	for _, meaning := range meanings {
+		if meaning.Primary {
+			return meaning
+		}
+	}
+
+	panic("no primary meaning")
+}
+
+// Removes tweets with text matching re, returning the tweets that are left
+// and the number that were removed.
+func filterTweets(tweets []*Tweet, re *regexp.Regexp) ([]*Tweet, int) {
+	kept := Filter(tweets, func(tweet *Tweet) bool { return !re.MatchString(tweet.Text) })
+	return kept, len(tweets) - len(kept)
+}
+
+// Removes tweets with fewer than minFavorites favorites or fewer than
+// minRetweets retweets, returning the tweets that are left and the number that
+// were removed.
+func filterTweetsByEngagement(tweets []*Tweet, minFavorites, minRetweets int) ([]*Tweet, int) {
+	kept := Filter(tweets, func(tweet *Tweet) bool {
+		return tweet.FavoriteCount >= minFavorites && tweet.RetweetCount >= minRetweets
+	})
+	return kept, len(tweets) - len(kept)
+}
+
+// Removes tweets created before start or after end, returning the tweets that
+// are left and the number that were removed. A zero start or end leaves that
+// side of the range open.
+func filterTweetsByRange(tweets []*Tweet, start, end time.Time) ([]*Tweet, int) {
+	kept := Filter(tweets, func(tweet *Tweet) bool {
+		return !tweet.CreatedAt.Before(start) && (end.IsZero() || !tweet.CreatedAt.After(end))
+	})
+	return kept, len(tweets) - len(kept)
+}
+
+// Default for --engagement-threshold. For an account with 1000 followers, this
+// is equivalent to a single like.
+const defaultEngagementThreshold = 0.001
+
+// Default for --engagement-weights, applied to likes, retweets, replies, and
+// bookmarks respectively. Retweets put a tweet in front of a whole new
+// audience, so they count for the most.
+var defaultEngagementWeights = []float64{1.0, 2.0, 1.5, 0.5}
+
+// Default for --trivial-view-threshold. Impressions are much noisier than
+// likes or retweets, so they need a far higher bar before a change is worth
+// rewriting a tweet for.
+const defaultTrivialViewThreshold = 100
+
+// Default for --url-check-concurrency.
+const defaultURLCheckConcurrency = 10
+
+// Default for --cover-download-concurrency.
+const defaultCoverDownloadConcurrency = 4
+
+// Default for --max-friends.
+const defaultMaxFriends = 50
+
+// Because we track a tweet's number of favorites and retweets, a problem with
+// the current system is that we update the data file constantly as these
+// numbers change trivially. Even if you're not a super popular persona on
+// Twitter who's getting new likes and retweets all the time, there's still an
+// ambient level of background changes as numbers on old tweets increment or
+// decrement by a few at a time. My guess is that it's from people deleting
+// their accounts or setting them private, but I'm not sure.
+//
+// Try to keep the system churning less by preferring the data that we already
+// have if the change detected is "trivial", meaning the likes and retweets
+// only changed by a small amount.
+func flipDuplicateTweetsOnTrivialChanges(tweets []*Tweet, engagementThreshold float64, viewThreshold int64) {
+	for i, j := 0, 1; j < len(tweets); i, j = i+1, j+1 {
+		if tweets[i].ID != tweets[j].ID {
+			continue
+		}
+
+		if tweetTextHashSHA1(tweets[i]) != tweetTextHashSHA1(tweets[j]) {
+			continue
+		}
+
+		// Entities include annotations, so newly recognized people and
+		// places are never considered trivial.
+		if !reflect.DeepEqual(tweets[i].Entities, tweets[j].Entities) {
+			continue
+		}
+
+		// Likewise for a newly fetched card or quoted tweet.
+		if !reflect.DeepEqual(tweets[i].Card, tweets[j].Card) {
+			continue
+		}
+		if !reflect.DeepEqual(tweets[i].Quote, tweets[j].Quote) {
+			continue
+		}
+
+		favoriteDiff := absInt(tweets[i].FavoriteCount - tweets[j].FavoriteCount)
+		replyDiff := absInt(tweets[i].ReplyCount - tweets[j].ReplyCount)
+		retweetDiff := absInt(tweets[i].RetweetCount - tweets[j].RetweetCount)
+
+		// Engagement is a ratio, so it's compared against a threshold instead
+		// of a fixed number of likes.
+		engagementDiff := math.Abs(tweets[i].LikesByFollowers - tweets[j].LikesByFollowers)
+
+		viewDiff := absInt(tweets[i].ViewCount - tweets[j].ViewCount)
+
+		if favoriteDiff < 3 && replyDiff < 3 && retweetDiff < 3 && engagementDiff <= engagementThreshold &&
+			viewDiff <= viewThreshold {
+			// The stored tweet may be from before NormalizedText or
+			// TextHashSHA1 existed.
+			tweets[j].NormalizedText = tweets[i].NormalizedText
+			if tweets[j].TextHashSHA1 == "" {
+				tweets[j].TextHashSHA1 = tweets[i].TextHashSHA1
+			}
+
+			tweets[i], tweets[j] = tweets[j], tweets[i]
+		}
+	}
+}
+
+// Returns the path at which a page of the given user's Goodreads shelf is
+// cached. The "read" shelf omits its name so that its pages are simply
+// numbered. Pages fetched in an order other than the default one are suffixed
+// with it, since they hold different reviews.
+func goodreadsCachePath(dir, userID, shelf, sort string, page int) string {
+	var sortSuffix string
+	if sort != "" && sort != goodreadsSortDateRead {
+		sortSuffix = "_" + sort
+	}
+
+	if shelf == goodreadsShelfRead {
+		return filepath.Join(dir, fmt.Sprintf("goodreads_%s_page_%v%s.xml", userID, page, sortSuffix))
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("goodreads_%s_%s_page_%v%s.xml", userID, shelf, page, sortSuffix))
+}
+
+// Builds a GoodreadsConf from the environment, with any credentials set in
+// opts taking precedence. Both a user ID and key are required from one source
+// or the other.
+func goodreadsConfFromOptions(opts *SyncGoodreadsOptions) (*GoodreadsConf, error) {
+	var conf GoodreadsConf
+	err := envdecode.Decode(&conf)
+	if err != nil && !errors.Is(err, envdecode.ErrNoTargetFieldsAreSet) {
+		return nil, fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	if opts.UserID != "" {
+		conf.GoodreadsID = opts.UserID
+	}
+	if opts.Key != "" {
+		conf.GoodreadsKey = opts.Key
+	}
+
+	if conf.GoodreadsID == "" {
+		return nil, fmt.Errorf("Goodreads user ID should be set with --goodreads-user-id or GOODREADS_ID")
+	}
+	if conf.GoodreadsKey == "" {
+		return nil, fmt.Errorf("Goodreads API key should be set with --goodreads-key or GOODREADS_KEY")
+	}
+
+	return &conf, nil
+}
+
+// Maximum number of albums requested in a single page from Google Photos.
+const googlePhotosAlbumPageSize = 50
+
+// Maximum number of photos requested in a single page from Google Photos.
+const googlePhotosPageSize = 100
+
+func googlePhotoFromAPIMediaItem(item *GooglePhotosAPIMediaItem, albumIDs []string) (*GooglePhoto, error) {
+	if item.MediaMetadata == nil {
+		return nil, fmt.Errorf("media item has no metadata")
+	}
+
+	photo := &GooglePhoto{
+		AlbumIDs:  albumIDs,
+		CreatedAt: item.MediaMetadata.CreationTime,
+		Filename:  item.Filename,
+		ID:        item.ID,
+		MimeType:  item.MimeType,
+	}
+
+	var err error
+
+	if item.MediaMetadata.Height != "" {
+		photo.Height, err = strconv.Atoi(item.MediaMetadata.Height)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing height: %w", err)
+		}
+	}
+
+	if item.MediaMetadata.Width != "" {
+		photo.Width, err = strconv.Atoi(item.MediaMetadata.Width)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing width: %w", err)
+		}
+	}
+
+	if meta := item.MediaMetadata.Photo; meta != nil {
+		photo.ApertureFNumber = meta.ApertureFNumber
+		photo.CameraMake = meta.CameraMake
+		photo.CameraModel = meta.CameraModel
+		photo.ExposureTime = meta.ExposureTime
+		photo.FocalLength = meta.FocalLength
+		photo.ISOEquivalent = meta.ISOEquivalent
+	}
+
+	return photo, nil
+}
+
+// Returns the hex-encoded SHA-1 of a string's UTF-8 bytes.
+func hashSHA1(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Goodreads leaves role empty for most authors, but occasionally it's set to
+// "Author" explicitly when other contributors are present.
+func isPrimaryAuthor(author *ReadingAuthor) bool {
+	return author.Role == "" || author.Role == "Author"
+}
+
+// Maximum number of authors, contributors, or re-read books shown in each list
+// by the `stats` command.
+const statsMaxAuthors = 10
+
+// Upper bounds (in pages per day) of the buckets that reading speeds are
+// grouped into, with a final bucket for anything faster.
+var statsReadingSpeedBounds = []float64{10, 25, 50, 100}
+
+// Upper bounds of the buckets that review sentiments from -1 to 1 are grouped
+// into, with a final bucket for anything more positive.
+var statsSentimentBounds = []float64{-0.5, -0.1, 0.1, 0.5}
+
+// Computes the Pearson correlation coefficient of two equally sized samples.
+// Returns false if it's undefined because there are fewer than two values or
+// either sample has no variance.
+func pearsonCorrelation(xs, ys []float64) (float64, bool) {
+	n := len(xs)
+	if n < 2 || n != len(ys) {
+		return 0, false
+	}
+
+	var sumX, sumY float64
+	for i := 0; i < n; i++ {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/float64(n), sumY/float64(n)
+
+	var covariance, varianceX, varianceY float64
+	for i := 0; i < n; i++ {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+
+	if varianceX == 0 || varianceY == 0 {
+		return 0, false
+	}
+
+	return covariance / math.Sqrt(varianceX*varianceY), true
+}
+
+func printNomadStats(w io.Writer, stats *NomadStats) {
+	fmt.Fprintf(w, "NomadList\n")
+	fmt.Fprintf(w, "=========\n\n")
+	fmt.Fprintf(w, "Stays: %v\n", stats.NumStays)
+	fmt.Fprintf(w, "Days abroad: %v\n", stats.TotalDaysAbroad)
+}
+
+func printPodcastStats(w io.Writer, stats *PodcastStats) {
+	fmt.Fprintf(w, "Overcast\n")
+	fmt.Fprintf(w, "========\n\n")
+	fmt.Fprintf(w, "Episodes: %v\n", stats.NumEpisodes)
+	fmt.Fprintf(w, "Played: %v\n", stats.NumPlayed)
+	fmt.Fprintf(w, "Hours listened: %.1f\n", stats.HoursListened)
+}
+
+func printReadingStats(w io.Writer, stats *ReadingStats) {
+	fmt.Fprintf(w, "Goodreads\n")
+	fmt.Fprintf(w, "=========\n\n")
+	fmt.Fprintf(w, "Readings: %v\n", stats.NumReadings)
+	fmt.Fprintf(w, "Abandoned: %v (%.1f%% abandon rate)\n",
+		stats.NumAbandonedReadings, stats.AbandonRate*100)
+	fmt.Fprintf(w, "Estimated words read: %v\n", stats.WordsRead)
+
+	fmt.Fprintf(w, "\nPrimary authors:\n")
+	for i, count := range stats.PrimaryAuthors {
+		if i >= statsMaxAuthors {
+			break
+		}
+		fmt.Fprintf(w, "    %4d  %s\n", count.Count, count.Name)
+	}
+
+	fmt.Fprintf(w, "\nOther contributors:\n")
+	for i, count := range stats.OtherContributors {
+		if i >= statsMaxAuthors {
+			break
+		}
+		fmt.Fprintf(w, "    %4d  %s (%s)\n", count.Count, count.Name, count.Role)
+	}
+
+	fmt.Fprintf(w, "\nCorrelation with community ratings: ")
+	if stats.RatingCorrelationOK {
+		fmt.Fprintf(w, "%.2f (%v rated readings)\n", stats.RatingCorrelation, stats.NumRatedReadings)
+	} else {
+		fmt.Fprintf(w, "n/a (%v rated readings)\n", stats.NumRatedReadings)
+	}
+
+	fmt.Fprintf(w, "\nReadings by year:\n")
+	for _, count := range stats.ReadingsByYear {
+		fmt.Fprintf(w, "    %4d  %s\n", count.Count, count.Period)
+	}
+
+	fmt.Fprintf(w, "\nReadings by format:\n")
+	for _, count := range stats.ReadingsByFormat {
+		fmt.Fprintf(w, "    %4d  %s\n", count.Count, count.Format)
+	}
+
+	fmt.Fprintf(w, "\nReadings by language:\n")
+	for _, count := range stats.ReadingsByLanguage {
+		fmt.Fprintf(w, "    %4d  %s\n", count.Count, count.Language)
+	}
+
+	fmt.Fprintf(w, "\nReadings by recommender:\n")
+	for i, count := range stats.ReadingsByRecommender {
+		if i >= statsMaxAuthors {
+			break
+		}
+		fmt.Fprintf(w, "    %4d  %s\n", count.Count, count.Name)
+	}
+
+	fmt.Fprintf(w, "\nMost re-read books:\n")
+	for i, count := range stats.RereadBooks {
+		if i >= statsMaxAuthors {
+			break
+		}
+		fmt.Fprintf(w, "    %4d  %s\n", count.Count, count.Title)
+	}
+
+	fmt.Fprintf(w, "\nReading speed: ")
+	if stats.NumReadingSpeeds > 0 {
+		fmt.Fprintf(w, "%.1f pages/day on average (%v readings)\n",
+			stats.ReadingSpeedAvgPPD, stats.NumReadingSpeeds)
+		for _, bucket := range stats.ReadingSpeedBuckets {
+			var label string
+			if bucket.Max == 0 {
+				label = fmt.Sprintf("%g+", bucket.Min)
+			} else {
+				label = fmt.Sprintf("%g-%g", bucket.Min, bucket.Max)
+			}
+			fmt.Fprintf(w, "    %4d  %s pages/day\n", bucket.Count, label)
+		}
+	} else {
+		fmt.Fprintf(w, "n/a (0 readings)\n")
+	}
+
+	if len(stats.SentimentByYear) > 0 {
+		fmt.Fprintf(w, "\nReview sentiment by year:\n")
+		fmt.Fprintf(w, "        ")
+		for i := 0; i <= len(statsSentimentBounds); i++ {
+			min, max := -1.0, 1.0
+			if i > 0 {
+				min = statsSentimentBounds[i-1]
+			}
+			if i < len(statsSentimentBounds) {
+				max = statsSentimentBounds[i]
+			}
+			fmt.Fprintf(w, "  %12s", fmt.Sprintf("%g to %g", min, max))
+		}
+		fmt.Fprintf(w, "   mean\n")
+
+		for _, distribution := range stats.SentimentByYear {
+			fmt.Fprintf(w, "    %4s", distribution.Period)
+			for _, count := range distribution.Counts {
+				fmt.Fprintf(w, "  %12d", count)
+			}
+			fmt.Fprintf(w, "  %5.2f\n", distribution.Mean)
+		}
+	}
+}
+
+// Removes keys with zero values from a map produced by toml.Tree.ToMap,
+// recursing into subtables and arrays of tables. Tables left empty after
+// pruning are removed as well, but tables within arrays are kept so that
+// array positions aren't disturbed.
+func pruneZeroTOMLValues(m map[string]interface{}) {
+	for key, val := range m {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			pruneZeroTOMLValues(v)
+			if len(v) == 0 {
+				delete(m, key)
+			}
+
+		case []interface{}:
+			if len(v) == 0 {
+				delete(m, key)
+				continue
+			}
+			for _, elem := range v {
+				if table, ok := elem.(map[string]interface{}); ok {
+					pruneZeroTOMLValues(table)
+				}
+			}
+
+		case bool:
+			if !v {
+				delete(m, key)
+			}
+
+		case float64:
+			if v == 0 {
+				delete(m, key)
+			}
+
+		case int64:
+			if v == 0 {
+				delete(m, key)
+			}
+
+		case string:
+			if v == "" {
+				delete(m, key)
+			}
+
+		case time.Time:
+			if v.IsZero() {
+				delete(m, key)
+			}
+
+		case nil:
+			delete(m, key)
+		}
+	}
+}
+
+func printTweetStats(w io.Writer, stats *TweetStats) {
+	fmt.Fprintf(w, "Twitter\n")
+	fmt.Fprintf(w, "=======\n\n")
+	fmt.Fprintf(w, "Tweets: %v\n", stats.NumTweets)
+	if stats.NumExcludedRetweets > 0 || stats.NumExcludedReplies > 0 {
+		fmt.Fprintf(w, "Excluded: %v retweets, %v replies\n",
+			stats.NumExcludedRetweets, stats.NumExcludedReplies)
+	}
+
+	fmt.Fprintf(w, "\nTweets by month:\n")
+	for _, count := range stats.TweetsByMonth {
+		fmt.Fprintf(w, "    %4d  %s\n", count.Count, count.Period)
+	}
+}
+
+// Reads highlights and notes from the Bookmark table of a Kobo database.
+// Bookmarks without any text, like dog-ears marking a page, are left out, as
+// are hidden ones, which were deleted on the device but not yet synced with
+// Kobo.
+func readKoboAnnotations(db *SQLiteDB, titles map[string]string) ([]*KoboAnnotation, int, error) {
+	rows, err := db.ReadTable("Bookmark")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var annotations []*KoboAnnotation
+	var numSkipped int
+	for _, row := range rows {
+		if row.String("Text") == "" && row.String("Annotation") == "" {
+			continue
+		}
+
+		// Kobo stores booleans as the text 'true' or 'false'.
+		if strings.EqualFold(row.String("Hidden"), "true") || row.Int("Hidden") == 1 {
+			continue
+		}
+
+		createdAt, err := parseKoboTime(row.String("DateCreated"))
+		if err != nil || row.String("BookmarkID") == "" {
+			numSkipped++
+			continue
+		}
+
+		// Chapters are content of their own, but a bookmark's content may
+		// also be the book itself.
+		var chapterTitle string
+		if row.String("ContentID") != row.String("VolumeID") {
+			chapterTitle = titles[row.String("ContentID")]
+		}
+
+		annotations = append(annotations, &KoboAnnotation{
+			Annotation:   row.String("Annotation"),
+			BookTitle:    titles[row.String("VolumeID")],
+			ChapterTitle: chapterTitle,
+			CreatedAt:    createdAt,
+			ID:           row.String("BookmarkID"),
+			Text:         strings.TrimSpace(row.String("Text")),
+		})
+	}
+
+	return annotations, numSkipped, nil
+}
+
+// Reads the titles of books and chapters from the content table of a Kobo
+// database, keyed by content ID.
+func readKoboContentTitles(db *SQLiteDB) (map[string]string, error) {
+	rows, err := db.ReadTable("content")
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[string]string, len(rows))
+	for _, row := range rows {
+		titles[row.String("ContentID")] = row.String("Title")
+	}
+
+	return titles, nil
+}
+
+// Type of the event in a Kobo's AnalyticsEvents table that's logged when a
+// book is closed.
+const koboEventLeaveContent = "LeaveContent"
+
+// Reads reading sessions from the AnalyticsEvents table of a Kobo database.
+// The device logs a LeaveContent event when a book is closed, with how long
+// it was read for in its metrics. Sessions are taken to have started that
+// long before the event, so time left idle isn't included. Events where
+// nothing was read are left out.
+func readKoboSessions(db *SQLiteDB, titles map[string]string) ([]*KoboSession, int, error) {
+	rows, err := db.ReadTable("AnalyticsEvents")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var sessions []*KoboSession
+	var numSkipped int
+	for _, row := range rows {
+		if row.String("Type") != koboEventLeaveContent {
+			continue
+		}
+
+		endedAt, err := parseKoboTime(row.String("Timestamp"))
+		if err != nil || row.String("Id") == "" {
+			numSkipped++
+			continue
+		}
+
+		var attributes, metrics map[string]interface{}
+		if err := json.Unmarshal([]byte(row.String("Attributes")), &attributes); err != nil {
+			numSkipped++
+			continue
+		}
+		if err := json.Unmarshal([]byte(row.String("Metrics")), &metrics); err != nil {
+			numSkipped++
+			continue
+		}
+
+		// Depending on the firmware, values are either JSON strings or
+		// numbers.
+		secondsRead, err := strconv.Atoi(fmt.Sprint(metrics["SecondsRead"]))
+		if err != nil || secondsRead < 0 {
+			numSkipped++
+			continue
+		}
+		if secondsRead == 0 {
+			continue
+		}
+
+		volumeID, _ := attributes["volumeid"].(string)
+
+		sessions = append(sessions, &KoboSession{
+			BookTitle:   titles[volumeID],
+			DurationSec: secondsRead,
+			EndedAt:     endedAt,
+			ID:          row.String("Id"),
+			StartedAt:   endedAt.Add(-time.Duration(secondsRead) * time.Second),
+		})
+	}
+
+	return sessions, numSkipped, nil
+}
+
+func readLikedTweetDB(path string) (*LikedTweetDB, error) {
+	var likedTweetDB LikedTweetDB
+	if err := readTOMLFile(path, &likedTweetDB); err != nil {
+		return nil, err
+	}
+
+	err := migrateDB(path, &likedTweetDB, likedTweetDB.Version, likedTweetDBMigrations)
+	if err != nil {
+		return nil, err
+	}
+	likedTweetDB.Version = SchemaVersion
+
+	return &likedTweetDB, nil
+}
+
+func readReadingDB(path string) (*ReadingDB, error) {
+	var readingDB ReadingDB
+	if err := readTOMLFile(path, &readingDB); err != nil {
+		return nil, err
+	}
+
+	err := migrateDB(path, &readingDB, readingDB.Version, readingDBMigrations)
+	if err != nil {
+		return nil, err
+	}
+	readingDB.Version = SchemaVersion
+
+	return &readingDB, nil
+}
+
+func readTweetDB(path string) (*TweetDB, error) {
+	var tweetDB TweetDB
+	if err := readTOMLFile(path, &tweetDB); err != nil {
+		return nil, err
+	}
+
+	err := migrateDB(path, &tweetDB, tweetDB.Version, tweetDBMigrations)
+	if err != nil {
+		return nil, err
+	}
+	tweetDB.Version = SchemaVersion
+
+	return &tweetDB, nil
+}
+
+// Exchanges a Withings refresh token for a new access token. Withings also
+// issues a new refresh token, and the old one stops working.
+func refreshWithingsToken(ctx context.Context, conf *WithingsConf, client *http.Client, refreshToken string) (*WithingsToken, error) {
+	v := url.Values{}
+	v.Set("action", "requesttoken")
+	v.Set("client_id", conf.WithingsClientID)
+	v.Set("client_secret", conf.WithingsClientSecret)
+	v.Set("grant_type", "refresh_token")
+	v.Set("refresh_token", refreshToken)
+
+	var body WithingsAPITokenBody
+	if err := fetchWithings(ctx, client, "", "/v2/oauth2", v, &body); err != nil {
+		return nil, fmt.Errorf("error refreshing token: %w", err)
+	}
+
+	return &WithingsToken{
+		AccessToken:  body.AccessToken,
+		ExpiresAt:    time.Now().UTC().Add(time.Duration(body.ExpiresIn) * time.Second),
+		RefreshToken: body.RefreshToken,
+	}, nil
+}
+
+// Default for --interval-hours.
+const defaultScheduleIntervalHours = 6
+
+// Label of the job installed by `schedule`. It names the LaunchAgent and
+// Scheduled Task, and marks the crontab entry so that it can be found again.
+const scheduleLabel = "com.qself.sync"
+
+// Returns a ScheduleEnv for the running binary and OS.
+func newScheduleEnv() (*ScheduleEnv, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("error finding qself executable: %w", err)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("error finding home directory: %w", err)
+	}
+
+	return &ScheduleEnv{
+		Executable: executable,
+		GOOS:       runtime.GOOS,
+		HomeDir:    homeDir,
+		RunCommand: runScheduleCommand,
+	}, nil
+}
+
+// Runs an OS command for ScheduleEnv.RunCommand.
+func runScheduleCommand(ctx context.Context, stdin string, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("error running '%s': %w (%s)",
+			name, err, strings.TrimSpace(string(out)))
+	}
+
+	return string(out), nil
+}
+
+// Installs a job that runs `sync-all` every opts.IntervalHours, replacing
+// one that was installed previously.
+func scheduleInstall(ctx context.Context, env *ScheduleEnv, opts *ScheduleOptions) error {
+	if opts.IntervalHours < 1 {
+		return fmt.Errorf("--interval-hours should be at least 1 (was %v)", opts.IntervalHours)
+	}
+
+	command := append([]string{env.Executable, "sync-all"}, opts.SyncAllArgs...)
+
+	switch env.GOOS {
+	case "darwin":
+		return scheduleInstallLaunchAgent(ctx, env, opts, command)
+	case "windows":
+		return scheduleInstallScheduledTask(ctx, env, opts, command)
+	default:
+		return scheduleInstallCrontab(ctx, env, opts, command)
+	}
+}
+
+func scheduleInstallCrontab(ctx context.Context, env *ScheduleEnv, opts *ScheduleOptions, command []string) error {
+	if opts.IntervalHours > 24 {
+		return fmt.Errorf("--interval-hours can be at most 24 with cron (was %v)", opts.IntervalHours)
+	}
+
+	lines, _, err := scheduleReadCrontab(ctx, env, opts.CronUser)
+	if err != nil {
+		return err
+	}
+
+	// Cron runs at fixed hours rather than at an interval, so intervals that
+	// don't divide evenly into a day have a shorter gap at midnight.
+	hours := "*"
+	switch {
+	case opts.IntervalHours == 24:
+		hours = "0"
+	case opts.IntervalHours > 1:
+		hours = fmt.Sprintf("*/%v", opts.IntervalHours)
+	}
+
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = shellQuote(arg)
+	}
+
+	entry := fmt.Sprintf("0 %s * * * %s", hours, strings.Join(quoted, " "))
+	if opts.LogPath != "" {
+		entry += " >> " + shellQuote(opts.LogPath) + " 2>&1"
+	}
+
+	// A '%' in a crontab command is read as a newline unless it's escaped.
+	entry = strings.ReplaceAll(entry, "%", `\%`)
+
+	entry += " # " + scheduleLabel
+
+	if err := scheduleWriteCrontab(ctx, env, opts.CronUser, append(lines, entry)); err != nil {
+		return err
+	}
+
+	logger.Infof("(schedule) Installed crontab entry: %s", entry)
+	return nil
+}
+
+func scheduleInstallLaunchAgent(ctx context.Context, env *ScheduleEnv, opts *ScheduleOptions, command []string) error {
+	if opts.CronUser != "" {
+		logger.Warnf("(schedule) LaunchAgents run as the current user; ignoring --cron-user")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	buf.WriteString(`<plist version="1.0">` + "\n")
+	buf.WriteString("<dict>\n")
+
+	writeKeyString := func(key, value string) {
+		fmt.Fprintf(&buf, "\t<key>%s</key>\n\t<string>", key)
+		_ = xml.EscapeText(&buf, []byte(value))
+		buf.WriteString("</string>\n")
+	}
+
+	writeKeyString("Label", scheduleLabel)
+
+	buf.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range command {
+		buf.WriteString("\t\t<string>")
+		_ = xml.EscapeText(&buf, []byte(arg))
+		buf.WriteString("</string>\n")
+	}
+	buf.WriteString("\t</array>\n")
+
+	fmt.Fprintf(&buf, "\t<key>StartInterval</key>\n\t<integer>%v</integer>\n", opts.IntervalHours*60*60)
+
+	if opts.LogPath != "" {
+		writeKeyString("StandardErrorPath", opts.LogPath)
+		writeKeyString("StandardOutPath", opts.LogPath)
+	}
+
+	buf.WriteString("</dict>\n")
+	buf.WriteString("</plist>\n")
+
+	plistPath := scheduleLaunchAgentPath(env)
+
+	// launchd won't pick up changes to an agent that's already loaded, so
+	// unload any previous version first.
+	if _, err := os.Stat(plistPath); err == nil {
+		if _, err := env.RunCommand(ctx, "", "launchctl", "unload", "-w", plistPath); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(plistPath), 0o755); err != nil {
+		return fmt.Errorf("error creating directory for '%s': %w", plistPath, err)
+	}
+
+	if err := ioutil.WriteFile(plistPath, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("error writing '%s': %w", plistPath, err)
+	}
+
+	if _, err := env.RunCommand(ctx, "", "launchctl", "load", "-w", plistPath); err != nil {
+		return err
+	}
+
+	logger.Infof("(schedule) Installed LaunchAgent: %s", plistPath)
+	return nil
+}
+
+func scheduleInstallScheduledTask(ctx context.Context, env *ScheduleEnv, opts *ScheduleOptions, command []string) error {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = windowsQuote(arg)
+	}
+
+	// Scheduled Tasks don't redirect output themselves, so go through the
+	// shell when it needs to be logged.
+	taskRun := strings.Join(quoted, " ")
+	if opts.LogPath != "" {
+		taskRun = fmt.Sprintf(`cmd /c "%s >> %s 2>&1"`, taskRun, windowsQuote(opts.LogPath))
+	}
+
+	// An hourly schedule can only repeat every 1 to 23 hours.
+	schedule, modifier := "HOURLY", opts.IntervalHours
+	if opts.IntervalHours%24 == 0 {
+		schedule, modifier = "DAILY", opts.IntervalHours/24
+	} else if opts.IntervalHours > 24 {
+		return fmt.Errorf("--interval-hours should be under 24 or a multiple of it with Scheduled Tasks (was %v)",
+			opts.IntervalHours)
+	}
+
+	args := []string{
+		"/create",
+		"/tn", scheduleLabel,
+		"/tr", taskRun,
+		"/sc", schedule,
+		"/mo", strconv.Itoa(modifier),
+		"/f",
+	}
+	if opts.CronUser != "" {
+		args = append(args, "/ru", opts.CronUser)
+	}
+
+	if _, err := env.RunCommand(ctx, "", "schtasks", args...); err != nil {
+		return err
+	}
+
+	logger.Infof("(schedule) Installed Scheduled Task '%s': %s", scheduleLabel, taskRun)
+	return nil
+}
+
+// Returns the path of the LaunchAgent installed on macOS.
+func scheduleLaunchAgentPath(env *ScheduleEnv) string {
+	return filepath.Join(env.HomeDir, "Library", "LaunchAgents", scheduleLabel+".plist")
+}
+
+// Reads a user's crontab, returning its lines without any entry installed by
+// `schedule`, and whether such an entry was found. A user without a crontab
+// has no lines.
+func scheduleReadCrontab(ctx context.Context, env *ScheduleEnv, user string) ([]string, bool, error) {
+	out, err := env.RunCommand(ctx, "", "crontab", scheduleCrontabArgs(user, "-l")...)
+	if err != nil {
+		if strings.Contains(out, "no crontab for") {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	var found bool
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if strings.HasSuffix(line, "# "+scheduleLabel) {
+			found = true
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, found, nil
+}
+
+// Returns arguments for `crontab`, targeting the given user's crontab if
+// there is one.
+func scheduleCrontabArgs(user string, args ...string) []string {
+	if user == "" {
+		return args
+	}
+	return append([]string{"-u", user}, args...)
+}
+
+// Removes the job installed by `schedule`.
+func scheduleRemove(ctx context.Context, env *ScheduleEnv, opts *ScheduleOptions) error {
+	switch env.GOOS {
+	case "darwin":
+		plistPath := scheduleLaunchAgentPath(env)
+		if _, err := os.Stat(plistPath); os.IsNotExist(err) {
+			logger.Infof("(schedule) No LaunchAgent at '%s'; nothing to remove", plistPath)
+			return nil
+		}
+
+		if _, err := env.RunCommand(ctx, "", "launchctl", "unload", "-w", plistPath); err != nil {
+			return err
+		}
+
+		if err := os.Remove(plistPath); err != nil {
+			return fmt.Errorf("error removing '%s': %w", plistPath, err)
+		}
+
+		logger.Infof("(schedule) Removed LaunchAgent: %s", plistPath)
+
+	case "windows":
+		if _, err := env.RunCommand(ctx, "", "schtasks", "/delete", "/tn", scheduleLabel, "/f"); err != nil {
+			return err
+		}
+
+		logger.Infof("(schedule) Removed Scheduled Task '%s'", scheduleLabel)
+
+	default:
+		lines, found, err := scheduleReadCrontab(ctx, env, opts.CronUser)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			logger.Infof("(schedule) No crontab entry found; nothing to remove")
+			return nil
+		}
+
+		if err := scheduleWriteCrontab(ctx, env, opts.CronUser, lines); err != nil {
+			return err
+		}
+
+		logger.Infof("(schedule) Removed crontab entry")
+	}
+
+	return nil
+}
+
+// Replaces a user's crontab with the given lines.
+func scheduleWriteCrontab(ctx context.Context, env *ScheduleEnv, user string, lines []string) error {
+	var crontab string
+	if len(lines) > 0 {
+		crontab = strings.Join(lines, "\n") + "\n"
+	}
+
+	_, err := env.RunCommand(ctx, crontab, "crontab", scheduleCrontabArgs(user, "-")...)
+	return err
+}
+
+// Matches arguments that a POSIX shell passes through as they are.
+var shellSafeRE = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// Quotes an argument for a POSIX shell if it contains anything that the
+// shell would interpret.
+func shellQuote(s string) string {
+	if s != "" && shellSafeRE.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Quotes an argument for the Windows command line if it contains spaces or
+// quotes.
+func windowsQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func stats(w io.Writer, opts *StatsOptions) error {
+	var printedAny bool
+
+	if opts.GoodreadsPath != "PATH" {
+		readingDB, err := readReadingDB(opts.GoodreadsPath)
+		if err != nil {
+			return err
+		}
+
+		printReadingStats(w, computeReadingStats(readingDB.Readings))
+		printedAny = true
+	}
+
+	if opts.NomadListPath != "PATH" {
+		var nomadListDB NomadListDB
+		if err := readTOMLFile(opts.NomadListPath, &nomadListDB); err != nil {
+			return err
+		}
+
+		if printedAny {
+			fmt.Fprintf(w, "\n")
+		}
+		printNomadStats(w, computeNomadStats(nomadListDB.Stays, opts.HomeCountryCode))
+		printedAny = true
+	}
+
+	if opts.OvercastPath != "PATH" {
+		var overcastDB OvercastDB
+		if err := readTOMLFile(opts.OvercastPath, &overcastDB); err != nil {
+			return err
+		}
+
+		if printedAny {
+			fmt.Fprintf(w, "\n")
+		}
+		printPodcastStats(w, computePodcastStats(overcastDB.Episodes))
+		printedAny = true
+	}
+
+	if opts.TwitterPath != "PATH" {
+		tweetDB, err := readTweetDB(opts.TwitterPath)
+		if err != nil {
+			return err
+		}
+
+		if printedAny {
+			fmt.Fprintf(w, "\n")
+		}
+		printTweetStats(w, computeTweetStats(tweetDB.Tweets, opts.NoRetweets, opts.NoReplies))
+	}
+
+	return nil
+}
+
+func syncAll(ctx context.Context, opts *SyncAllOptions) error {
+	// With FailFast, cancel the rest of the syncs as soon as any one of them
+	// fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	var beeminderErr error
+	if opts.BeeminderPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			beeminderErr = syncBeeminder(ctx, opts.BeeminderPath)
+			if beeminderErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var calErr error
+	if opts.CalPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			calErr = syncCal(ctx, opts.CalPath)
+			if calErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var chessErr error
+	if opts.ChessPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			chessErr = syncChess(ctx, opts.ChessPath)
+			if chessErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var clockifyErr error
+	if opts.ClockifyPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			clockifyErr = syncClockify(ctx, opts.ClockifyPath)
+			if clockifyErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var cloudflareErr error
+	if opts.CloudflarePath != "PATH" {
+		wg.Add(1)
+		go func() {
+			cloudflareErr = syncCloudflare(ctx, opts.CloudflarePath)
+			if cloudflareErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var existErr error
+	if opts.ExistPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			existErr = syncExist(ctx, opts.ExistPath, &SyncExistOptions{})
+			if existErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var goodreadsErr error
+	if opts.GoodreadsPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			goodreadsErr = syncGoodreads(ctx, opts.GoodreadsPath, &SyncGoodreadsOptions{
+				AbandonedShelf: opts.GoodreadsAbandonedShelf,
+				DateFormat:     opts.GoodreadsDateFormat,
+				NoHTMLDecode:   opts.NoHTMLDecode,
+				Strict:         opts.Strict,
+			})
+			if goodreadsErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var googlePhotosErr error
+	if opts.GooglePhotosPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			googlePhotosErr = syncGooglePhotos(ctx, opts.GooglePhotosPath)
+			if googlePhotosErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var linkedInErr error
+	if opts.LinkedInPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			linkedInErr = syncLinkedIn(ctx, opts.LinkedInPath)
+			if linkedInErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var linkedInArticlesErr error
+	if opts.LinkedInArticlesPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			linkedInArticlesErr = syncLinkedInArticles(ctx, opts.LinkedInArticlesPath)
+			if linkedInArticlesErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var mediumErr error
+	if opts.MediumPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			mediumErr = syncMedium(ctx, opts.MediumPath)
+			if mediumErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var monzoErr error
+	if opts.MonzoPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			monzoErr = syncMonzo(ctx, opts.MonzoPath)
+			if monzoErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var nomadListErr error
+	if opts.NomadListPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			nomadListErr = syncNomadList(ctx, opts.NomadListPath)
+			if nomadListErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var ouraErr error
+	if opts.OuraReadinessPath != "PATH" && opts.OuraSleepPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			ouraErr = syncOura(ctx, opts.OuraSleepPath, opts.OuraReadinessPath)
+			if ouraErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var runkeeperErr error
+	if opts.RunkeeperPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			runkeeperErr = syncRunkeeper(ctx, opts.RunkeeperPath)
+			if runkeeperErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var steamErr error
+	if opts.SteamPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			steamErr = syncSteam(ctx, opts.SteamPath)
+			if steamErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var stripeErr error
+	if opts.StripeChargesPath != "PATH" && opts.StripePayoutsPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			stripeErr = syncStripe(ctx, opts.StripeChargesPath, opts.StripePayoutsPath)
+			if stripeErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var substackErr error
+	if opts.SubstackPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			substackErr = syncSubstack(ctx, opts.SubstackPath)
+			if substackErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var telegramErr error
+	if opts.TelegramPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			telegramErr = syncTelegram(ctx, opts.TelegramPath)
+			if telegramErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var togglErr error
+	if opts.TogglPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			togglErr = syncToggl(ctx, opts.TogglPath)
+			if togglErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var twitterErr error
+	if opts.TwitterPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			twitterErr = syncTwitter(ctx, opts.TwitterPath, &SyncTwitterOptions{
+				APIV2:                opts.TwitterAPIV2,
+				FilterRegexp:         opts.TweetFilterRegexp,
+				IncludeLikes:         opts.TwitterLikesPath != "PATH",
+				LikesPath:            opts.TwitterLikesPath,
+				NoHTMLDecode:         opts.NoHTMLDecode,
+				Strict:               opts.Strict,
+				TrivialViewThreshold: defaultTrivialViewThreshold,
+			})
+			if twitterErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var wakaTimeErr error
+	if opts.WakaTimePath != "PATH" {
+		wg.Add(1)
+		go func() {
+			wakaTimeErr = syncWakaTime(ctx, opts.WakaTimePath)
+			if wakaTimeErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var waniKaniErr error
+	if opts.WaniKaniPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			waniKaniErr = syncWaniKani(ctx, opts.WaniKaniPath)
+			if waniKaniErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var weatherOpenWeatherErr error
+	if opts.WeatherOpenWeatherPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			weatherOpenWeatherErr = syncWeatherOpenWeather(ctx, opts.WeatherOpenWeatherPath)
+			if weatherOpenWeatherErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var weatherPWSErr error
+	if opts.WeatherPWSPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			weatherPWSErr = syncWeatherPWS(ctx, opts.WeatherPWSPath)
+			if weatherPWSErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	var withingsErr error
+	if opts.WithingsPath != "PATH" {
+		wg.Add(1)
+		go func() {
+			withingsErr = syncWithings(ctx, opts.WithingsPath)
+			if withingsErr != nil && opts.FailFast {
+				cancel()
+			}
+			wg.Done()
+		}()
+	}
+
+	wg.Wait()
+
+	errs := []struct {
+		source string
+		err    error
+	}{
+		{"beeminder", beeminderErr},
+		{"cal", calErr},
+		{"chess", chessErr},
+		{"clockify", clockifyErr},
+		{"cloudflare", cloudflareErr},
+		{"exist", existErr},
+		{"goodreads", goodreadsErr},
+		{"google-photos", googlePhotosErr},
+		{"linkedin", linkedInErr},
+		{"linkedin-articles", linkedInArticlesErr},
+		{"medium", mediumErr},
+		{"monzo", monzoErr},
+		{"nomadlist", nomadListErr},
+		{"oura", ouraErr},
+		{"runkeeper", runkeeperErr},
+		{"steam", steamErr},
+		{"stripe", stripeErr},
+		{"substack", substackErr},
+		{"telegram", telegramErr},
+		{"toggl", togglErr},
+		{"twitter", twitterErr},
+		{"wakatime", wakaTimeErr},
+		{"wanikani", waniKaniErr},
+		{"openweather", weatherOpenWeatherErr},
+		{"wu", weatherPWSErr},
+		{"withings", withingsErr},
+	}
+
+	if opts.FailFast {
+		// Siblings of a failed sync are canceled, so prefer returning the
+		// error that caused the cancellation over the ones that resulted
+		// from it.
+		for _, e := range errs {
+			if e.err != nil && !errors.Is(e.err, context.Canceled) {
+				return e.err
+			}
+		}
+		for _, e := range errs {
+			if e.err != nil {
+				return e.err
+			}
+		}
+
+		return nil
+	}
+
+	// Otherwise every sync has run to completion, so report all of the ones
+	// that failed rather than just the first.
+	var syncErrs []error
+	for _, e := range errs {
+		if e.err == nil {
+			continue
+		}
+
+		logger.Errorf("(%s) error syncing: %v", e.source, e.err)
+		syncErrs = append(syncErrs, fmt.Errorf("%s: %w", e.source, e.err))
+	}
+
+	if len(syncErrs) > 0 {
+		return &SyncAllError{Errs: syncErrs}
+	}
+
+	return nil
+}
+
+func syncBeeminder(ctx context.Context, targetPath string) error {
+	var conf BeeminderConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingGoals []*BeeminderGoal
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingBeeminderDB BeeminderDB
+		if err := readTOMLFile(targetPath, &existingBeeminderDB); err != nil {
+			return err
+		}
+
+		existingGoals = existingBeeminderDB.Goals
+
+		logger.Infof("(beeminder) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(beeminder) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	var apiGoals []*BeeminderAPIGoal
+	if err := fetchBeeminder(ctx, &conf, client, "/goals.json", &apiGoals); err != nil {
+		return err
+	}
+
+	logger.Infof("(beeminder) Fetching data points for %v goal(s) with concurrency %v",
+		len(apiGoals), beeminderConcurrency)
+
+	goals := make([]*BeeminderGoal, len(apiGoals))
+
+	var anyErr error
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, beeminderConcurrency)
+
+	for i, apiGoal := range apiGoals {
+		i, apiGoal := i, apiGoal
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			var apiDataPoints []*BeeminderAPIDataPoint
+			err := fetchBeeminder(ctx, &conf, client,
+				"/goals/"+url.PathEscape(apiGoal.Slug)+"/datapoints.json", &apiDataPoints)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				if anyErr == nil {
+					anyErr = err
+				}
+				return
+			}
+
+			// Each goal is stored to its own index, so results don't need to
+			// be put back in order.
+			goals[i] = beeminderGoalFromAPIGoal(apiGoal, apiDataPoints)
+		}()
+	}
+
+	wg.Wait()
+
+	if anyErr != nil {
+		return anyErr
+	}
+
+	goals = mergeBeeminderGoals(goals, existingGoals)
+
+	logger.Infof("(beeminder) Writing %v goal(s) to '%s'", len(goals), targetPath)
+
+	beeminderDB := &BeeminderDB{Goals: goals}
+	if err := writeTOMLFile(targetPath, beeminderDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncCal(ctx context.Context, targetPath string) error {
+	var conf CalConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(conf.CalCredentialsJSON)
+	if err != nil {
+		return fmt.Errorf("error reading credentials: %w", err)
+	}
+
+	var account CalAPIServiceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return fmt.Errorf("error unmarshaling credentials from JSON: %w", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingEvents []*CalEvent
+	now := time.Now().UTC()
+	timeMin := now.AddDate(0, 0, -calBackfillDays)
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingCalEventDB CalEventDB
+		if err := readTOMLFile(targetPath, &existingCalEventDB); err != nil {
+			return err
+		}
+
+		existingEvents = existingCalEventDB.Events
+		if len(existingEvents) > 0 {
+			lastStartAt := existingEvents[len(existingEvents)-1].StartAt.AddDate(0, 0, -calRefetchDays)
+			if lastStartAt.After(timeMin) {
+				timeMin = lastStartAt
+			}
+		}
+
+		logger.Infof("(cal) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(cal) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	accessToken, err := fetchCalAccessToken(ctx, client, &account)
+	if err != nil {
+		return err
+	}
+
+	var events []*CalEvent
+	var numSkipped int
+	var pageToken string
+
+	for {
+		logger.Infof("(cal) Paging; num events accumulated: %v, window: %v to %v",
+			len(events), timeMin.Format(time.RFC3339), now.Format(time.RFC3339))
+
+		page, err := fetchCalEvents(ctx, &conf, client, accessToken, timeMin, now, pageToken)
+		if err != nil {
+			return err
+		}
+
+		for _, apiEvent := range page.Items {
+			event, err := calEventFromAPIEvent(apiEvent)
+			if err != nil {
+				logger.Errorf("(cal) Skipping event %v: %v", apiEvent.ID, err)
+				numSkipped++
+				continue
+			}
+
+			events = append(events, event)
+		}
+
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	if numSkipped > 0 {
+		logger.Warnf("(cal) Skipped %v event(s) that couldn't be processed", numSkipped)
+	}
+
+	events = mergeCalEvents(events, existingEvents)
+
+	logger.Infof("(cal) Writing %v event(s) to '%s'", len(events), targetPath)
+
+	calEventDB := &CalEventDB{Events: events}
+	if err := writeTOMLFile(targetPath, calEventDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncChess(ctx context.Context, targetPath string) error {
+	var conf ChessConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingGames []*ChessGame
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingChessDB ChessDB
+		if err := readTOMLFile(targetPath, &existingChessDB); err != nil {
+			return err
+		}
+
+		existingGames = existingChessDB.Games
+
+		logger.Infof("(chess) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(chess) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	// Each platform picks up from the last game of its own that's stored.
+	var chessComStart, lichessStart time.Time
+	for _, game := range existingGames {
+		switch game.Platform {
+		case chessPlatformChessCom:
+			if game.PlayedAt.After(chessComStart) {
+				chessComStart = game.PlayedAt
+			}
+		case chessPlatformLichess:
+			if game.PlayedAt.After(lichessStart) {
+				lichessStart = game.PlayedAt
+			}
+		}
+	}
+
+	// Without any existing data, start from when the account was created.
+	if chessComStart.IsZero() {
+		var profile ChessComAPIProfile
+		err := fetchChessCom(ctx, client, "/pub/player/"+strings.ToLower(conf.ChessComUsername), &profile)
+		if err != nil {
+			return err
+		}
+
+		chessComStart = time.Unix(profile.Joined, 0).UTC()
+	}
+
+	var games []*ChessGame
+	now := time.Now().UTC()
+
+	// Chess.com archives are monthly, and the month of the last stored game
+	// is always fetched again because more games may have been played since.
+	for month := time.Date(chessComStart.Year(), chessComStart.Month(), 1, 0, 0, 0, 0, time.UTC); !month.After(now); month = month.AddDate(0, 1, 0) {
+		logger.Infof("(chess) Fetching Chess.com archive for %v; num games accumulated: %v",
+			month.Format("2006/01"), len(games))
+
+		var root ChessComAPIGamesRoot
+		err := fetchChessCom(ctx, client,
+			"/pub/player/"+strings.ToLower(conf.ChessComUsername)+"/games/"+month.Format("2006/01"), &root)
+		if err != nil {
+			return err
+		}
+
+		for _, apiGame := range root.Games {
+			game, err := chessGameFromChessComAPIGame(apiGame, conf.ChessComUsername)
+			if err != nil {
+				return err
+			}
+
+			games = append(games, game)
+		}
+	}
+
+	if conf.LichessUsername != "" {
+		logger.Infof("(chess) Fetching Lichess games")
+
+		err := fetchLichessGames(ctx, client, conf.LichessUsername, lichessStart, func(apiGame *LichessAPIGame) error {
+			// Games aborted before any moves were made don't have a result.
+			if apiGame.Status == "aborted" || apiGame.Status == "noStart" {
+				return nil
+			}
+
+			game, err := chessGameFromLichessAPIGame(apiGame, conf.LichessUsername)
+			if err != nil {
+				return err
+			}
+
+			games = append(games, game)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	games = mergeChessGames(games, existingGames)
+
+	logger.Infof("(chess) Writing %v game(s) to '%s'", len(games), targetPath)
+
+	chessDB := &ChessDB{Games: games}
+	if err := writeTOMLFile(targetPath, chessDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncClockify(ctx context.Context, targetPath string) error {
+	var conf ClockifyConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingEntries []*ClockifyEntry
+	var startAt time.Time
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingClockifyDB ClockifyDB
+		if err := readTOMLFile(targetPath, &existingClockifyDB); err != nil {
+			return err
+		}
+
+		existingEntries = existingClockifyDB.Entries
+		if len(existingEntries) > 0 {
+			startAt = existingEntries[len(existingEntries)-1].StartedAt.UTC().Truncate(24*time.Hour).AddDate(0, 0, -clockifyRefetchDays)
+		}
+
+		logger.Infof("(clockify) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(clockify) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	var user ClockifyAPIUser
+	if err := fetchClockify(ctx, &conf, client, "/user", nil, &user); err != nil {
+		return err
+	}
+
+	var workspaces []*ClockifyAPIWorkspace
+	if err := fetchClockify(ctx, &conf, client, "/workspaces", nil, &workspaces); err != nil {
+		return err
+	}
+
+	var entries []*ClockifyEntry
+	for _, workspace := range workspaces {
+		v := url.Values{}
+		if !startAt.IsZero() {
+			v.Set("start", startAt.Format(time.RFC3339))
+		}
+
+		logger.Infof("(clockify) Fetching time entries for workspace '%s'", workspace.Name)
+
+		apiEntries, err := fetchClockifyList[*ClockifyAPITimeEntry](ctx, &conf, client,
+			fmt.Sprintf("/workspaces/%s/user/%s/time-entries", workspace.ID, user.ID), v)
+		if err != nil {
+			return err
+		}
+
+		if len(apiEntries) < 1 {
+			continue
+		}
+
+		// Fetch the workspace's projects and tags once up front so that
+		// their names can be joined without a request per entry.
+		logger.Infof("(clockify) Fetching projects and tags for workspace '%s'", workspace.Name)
+
+		apiProjects, err := fetchClockifyList[*ClockifyAPIProject](ctx, &conf, client,
+			fmt.Sprintf("/workspaces/%s/projects", workspace.ID), nil)
+		if err != nil {
+			return err
+		}
+
+		projects := make(map[string]*ClockifyAPIProject)
+		for _, project := range apiProjects {
+			projects[project.ID] = project
+		}
+
+		apiTags, err := fetchClockifyList[*ClockifyAPITag](ctx, &conf, client,
+			fmt.Sprintf("/workspaces/%s/tags", workspace.ID), nil)
+		if err != nil {
+			return err
+		}
+
+		tags := make(map[string]*ClockifyAPITag)
+		for _, tag := range apiTags {
+			tags[tag.ID] = tag
+		}
+
+		for _, apiEntry := range apiEntries {
+			// Running entries don't have an end time yet, and will be
+			// picked up by a later sync once they're stopped.
+			if apiEntry.TimeInterval == nil || apiEntry.TimeInterval.End == nil {
+				continue
+			}
+
+			entries = append(entries, clockifyEntryFromAPITimeEntry(apiEntry, projects, tags))
+		}
+	}
+
+	entries = mergeClockifyEntries(entries, existingEntries)
+
+	logger.Infof("(clockify) Writing %v entries to '%s'", len(entries), targetPath)
+
+	clockifyDB := &ClockifyDB{Entries: entries}
+	if err := writeTOMLFile(targetPath, clockifyDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncCloudflare(ctx context.Context, targetPath string) error {
+	var conf CloudflareConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	startDate := today.AddDate(0, 0, -cloudflareLookbackDays)
+
+	var existingDays []*CloudflareDay
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingCloudflareDB CloudflareDB
+		if err := readTOMLFile(targetPath, &existingCloudflareDB); err != nil {
+			return err
+		}
+
+		existingDays = existingCloudflareDB.Days
+		if len(existingDays) > 0 {
+			refetchDate := existingDays[len(existingDays)-1].Date.AddDate(0, 0, -cloudflareRefetchDays)
+			if refetchDate.After(startDate) {
+				startDate = refetchDate
+			}
+		}
+
+		logger.Infof("(cloudflare) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(cloudflare) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	var days []*CloudflareDay
+	for date := startDate; !date.After(today); date = date.AddDate(0, 0, 1) {
+		logger.Infof("(cloudflare) Fetching traffic for %s", date.Format(cloudflareDateFormat))
+
+		groups, err := fetchCloudflareGroups(ctx, &conf, client, date)
+		if err != nil {
+			return err
+		}
+
+		// Days without any traffic are left out.
+		if len(groups) < 1 {
+			continue
+		}
+
+		if len(groups) >= cloudflareGroupsLimit {
+			logger.Warnf("(cloudflare) Got maximum of %v groups for %s; its totals may be incomplete",
+				cloudflareGroupsLimit, date.Format(cloudflareDateFormat))
+		}
+
+		days = append(days, cloudflareDayFromAPIGroups(date, groups))
+	}
+
+	days = mergeCloudflareDays(days, existingDays)
+
+	logger.Infof("(cloudflare) Writing %v day(s) to '%s'", len(days), targetPath)
+
+	cloudflareDB := &CloudflareDB{Days: days}
+	if err := writeTOMLFile(targetPath, cloudflareDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncExist(ctx context.Context, targetPath string, opts *SyncExistOptions) error {
+	var conf ExistConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingDays []*ExistDay
+	startDate := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -existBackfillDays)
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingExistDB ExistDB
+		if err := readTOMLFile(targetPath, &existingExistDB); err != nil {
+			return err
+		}
+
+		existingDays = existingExistDB.Days
+		if len(existingDays) > 0 {
+			startDate = existingDays[len(existingDays)-1].Date.AddDate(0, 0, -existRefetchDays)
+		}
+
+		logger.Infof("(exist) Found existing '%v'; running incremental update from %v",
+			targetPath, startDate.Format(existDateFormat))
+	} else if os.IsNotExist(err) {
+		logger.Infof("(exist) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	attributes := opts.Attributes
+	if len(attributes) < 1 {
+		var err error
+		attributes, err = fetchExistAttributeNames(ctx, &conf, client)
+		if err != nil {
+			return err
+		}
+	}
+
+	daysByDate := make(map[string]*ExistDay)
+	for _, attribute := range attributes {
+		logger.Infof("(exist) Fetching values for attribute '%s'", attribute)
+
+		values, err := fetchExistValues(ctx, &conf, client, attribute, startDate)
+		if err != nil {
+			return err
+		}
+
+		for _, value := range values {
+			if value.Value == nil {
+				continue
+			}
+
+			day, ok := daysByDate[value.Date]
+			if !ok {
+				// Already validated while fetching.
+				date, _ := time.Parse(existDateFormat, value.Date)
+				day = &ExistDay{Attributes: make(map[string]interface{}), Date: date}
+				daysByDate[value.Date] = day
+			}
+
+			day.Attributes[attribute] = existValueForTOML(value.Value)
+		}
+	}
+
+	days := make([]*ExistDay, 0, len(daysByDate))
+	for _, day := range daysByDate {
+		days = append(days, day)
+	}
+
+	days = mergeExistDays(days, existingDays)
+
+	logger.Infof("(exist) Writing %v day(s) to '%s'", len(days), targetPath)
+
+	existDB := &ExistDB{Days: days}
+	if err := writeTOMLFile(targetPath, existDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncGoodreads(ctx context.Context, targetPath string, opts *SyncGoodreadsOptions) error {
+	if err := checkSortOrder(opts.Sort); err != nil {
+		return err
+	}
+
+	if err := checkGoodreadsSort(opts.APISort); err != nil {
+		return err
+	}
+
+	if opts.CoverDownloadDir != "" && opts.CoverDownloadConcurrency < 1 {
+		return fmt.Errorf("cover download concurrency should be at least 1 (was %v)", opts.CoverDownloadConcurrency)
+	}
+
+	conf, err := goodreadsConfFromOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.CacheDir != "" {
+		if err := os.MkdirAll(opts.CacheDir, 0755); err != nil {
+			return fmt.Errorf("error creating Goodreads cache directory: %w", err)
+		}
+	}
+
+	if opts.CoverDownloadDir != "" {
+		if err := os.MkdirAll(opts.CoverDownloadDir, 0755); err != nil {
+			return fmt.Errorf("error creating Goodreads cover directory: %w", err)
+		}
+	}
+
+	client := newHTTPClient()
+
+	readings, skippedReviewIDs, err := fetchGoodreadsShelf(ctx, conf, client, goodreadsShelfRead, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.AbandonedShelf != "" {
+		abandonedReadings, abandonedSkippedReviewIDs, err := fetchGoodreadsShelf(ctx, conf, client, opts.AbandonedShelf, opts)
+		if err != nil {
+			return err
+		}
+
+		// A book that's on both shelves was presumably picked up again and
+		// finished, so prefer the completed reading.
+		readReviewIDs := make(map[int]struct{}, len(readings))
+		for _, reading := range readings {
+			readReviewIDs[reading.ReviewID] = struct{}{}
+		}
+
+		for _, reading := range abandonedReadings {
+			if _, ok := readReviewIDs[reading.ReviewID]; !ok {
+				readings = append(readings, reading)
+			}
+		}
+
+		skippedReviewIDs = append(skippedReviewIDs, abandonedSkippedReviewIDs...)
+	}
+
+	// Nothing is written in strict mode, so that a bad record can be fixed
+	// before the data file changes.
+	if len(skippedReviewIDs) > 0 {
+		logger.Warnf("(goodreads) Skipped %v review(s) that couldn't be processed", len(skippedReviewIDs))
+
+		if opts.Strict {
+			return fmt.Errorf("skipped %v review(s) in strict mode", len(skippedReviewIDs))
+		}
+	}
+
+	if _, err := os.Stat(targetPath); err == nil {
+		existingReadingDB, err := readReadingDB(targetPath)
+		if err != nil {
+			return err
+		}
+
+		logger.Infof("(goodreads) Found existing '%v'; attempting merge of %v existing readings(s) with %v current readings(s)",
+			targetPath, len(existingReadingDB.Readings), len(readings))
+
+		// A skipped review is still on Goodreads, so its stored reading is
+		// kept rather than removed like a deleted one. Abandoned readings are
+		// kept too when their shelf wasn't fetched.
+		skipped := make(map[int]bool, len(skippedReviewIDs))
+		for _, reviewID := range skippedReviewIDs {
+			skipped[reviewID] = true
+		}
+		keepMissing := func(reading *Reading) bool {
+			return skipped[reading.ReviewID] || (opts.AbandonedShelf == "" && reading.Abandoned)
+		}
+
+		readings = mergeReadings(readings, existingReadingDB.Readings, opts.Sort, keepMissing, nil)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(goodreads) Existing DB at '%v' not found; starting fresh", targetPath)
+
+		// Pages are fetched concurrently, so readings need sorting even
+		// without a merge.
+		sortReadings(readings, opts.Sort)
+	} else {
+		return err
+	}
+
+	setRereadCounts(readings)
+
+	if opts.CoverDownloadDir != "" {
+		if err := downloadGoodreadsCovers(ctx, client, readings, opts.CoverDownloadDir, opts.CoverDownloadConcurrency); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("(goodreads) Writing %v readings(s) to '%s'", len(readings), targetPath)
+
+	readingDB := &ReadingDB{Readings: readings, Version: SchemaVersion}
+	if err := writeTOMLFile(targetPath, readingDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Stores the IDs of the reading challenges that each book counted towards on
+// its readings in an existing Goodreads data file. Challenges only list books,
+// so every reading of a book that was read more than once gets the same IDs.
+func syncGoodreadsChallenges(ctx context.Context, readingsPath string, opts *SyncGoodreadsChallengesOptions) error {
+	conf, err := goodreadsConfFromOptions(&SyncGoodreadsOptions{Key: opts.Key, UserID: opts.UserID})
+	if err != nil {
+		return err
+	}
+
+	readingDB, err := readReadingDB(readingsPath)
+	if err != nil {
+		return err
+	}
+
+	client := newHTTPClient()
+
+	logger.Infof("(goodreads) Fetching reading challenges")
+
+	challenges, err := fetchGoodreadsChallenges(ctx, conf, client)
+	if err != nil {
+		return err
+	}
+
+	challengeIDsByBookID := make(map[int][]int)
+	for _, challenge := range challenges {
+		for _, bookID := range challenge.BookIDs {
+			challengeIDsByBookID[bookID] = append(challengeIDsByBookID[bookID], challenge.ID)
+		}
+	}
+
+	var numAnnotated int
+	for _, reading := range readingDB.Readings {
+		reading.ChallengeIDs = challengeIDsByBookID[reading.ID]
+		if len(reading.ChallengeIDs) > 0 {
+			sort.Ints(reading.ChallengeIDs)
+			numAnnotated++
+		}
+	}
+
+	logger.Infof("(goodreads) Writing challenges for %v of %v readings(s) from %v challenge(s) to '%s'",
+		numAnnotated, len(readingDB.Readings), len(challenges), readingsPath)
+
+	if err := writeTOMLFile(readingsPath, readingDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncGoodreadsFriends(ctx context.Context, targetPath string, opts *SyncGoodreadsFriendsOptions) error {
+	conf, err := goodreadsConfFromOptions(&SyncGoodreadsOptions{Key: opts.Key, UserID: opts.UserID})
+	if err != nil {
+		return err
+	}
+
+	client := newHTTPClient()
+
+	var existingReadings []*FriendReading
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingFriendReadingDB FriendReadingDB
+		if err := readTOMLFile(targetPath, &existingFriendReadingDB); err != nil {
+			return err
+		}
+
+		existingReadings = existingFriendReadingDB.Readings
+
+		logger.Infof("(goodreads) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(goodreads) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	friends, err := fetchGoodreadsFriends(ctx, conf, client, opts.MaxFriends)
+	if err != nil {
+		return err
+	}
+
+	var readings []*FriendReading
+	for _, friend := range friends {
+		logger.Infof("(goodreads) Fetching recent readings for friend %v ('%s')", friend.ID, friend.Name)
+
+		// The first page of a friend's read shelf holds their most recently
+		// read books. Everything about fetching it is the same as for the
+		// user's own reviews other than whose they are.
+		friendConf := *conf
+		friendConf.GoodreadsID = strconv.Itoa(friend.ID)
+
+		apiReviews, err := fetchGoodreadsPage(ctx, &friendConf, client, goodreadsShelfRead, 1, &SyncGoodreadsOptions{})
+		if err != nil {
+			return err
+		}
+
+		if len(apiReviews) > goodreadsFriendRecentReadings {
+			apiReviews = apiReviews[:goodreadsFriendRecentReadings]
+		}
+
+		for _, apiReview := range apiReviews {
+			reading, err := readingFromAPIReview(apiReview, &SyncGoodreadsOptions{})
+			if err != nil {
+				logger.Errorf("(goodreads) Skipping review %v of friend %v: %v", apiReview.ID, friend.ID, err)
+				continue
+			}
+
+			readings = append(readings, &FriendReading{
+				Reading:    *reading,
+				FriendID:   friend.ID,
+				FriendName: friend.Name,
+			})
+		}
+	}
+
+	readings = mergeFriendReadings(readings, existingReadings)
+
+	logger.Infof("(goodreads) Writing %v friend readings(s) to '%s'", len(readings), targetPath)
+
+	friendReadingDB := &FriendReadingDB{Readings: readings}
+	if err := writeTOMLFile(targetPath, friendReadingDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncGooglePhotos(ctx context.Context, targetPath string) error {
+	var conf GooglePhotosConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(conf.GooglePhotosCredentialsJSON)
+	if err != nil {
+		return fmt.Errorf("error reading credentials: %w", err)
+	}
+
+	var credentials GooglePhotosAPICredentials
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return fmt.Errorf("error unmarshaling credentials from JSON: %w", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingPhotos []*GooglePhoto
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingGooglePhotosDB GooglePhotosDB
+		if err := readTOMLFile(targetPath, &existingGooglePhotosDB); err != nil {
+			return err
+		}
+
+		existingPhotos = existingGooglePhotosDB.Photos
+
+		logger.Infof("(googlephotos) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(googlephotos) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	accessToken, err := fetchGooglePhotosAccessToken(ctx, client, &credentials)
+	if err != nil {
+		return err
+	}
+
+	albumIDs, err := fetchGooglePhotosAlbumIDs(ctx, client, accessToken)
+	if err != nil {
+		return err
+	}
+
+	var photos []*GooglePhoto
+	var numSkipped int
+	var pageToken string
+
+	for {
+		logger.Infof("(googlephotos) Paging; num photos accumulated: %v", len(photos))
+
+		v := url.Values{}
+		v.Set("pageSize", strconv.Itoa(googlePhotosPageSize))
+		if pageToken != "" {
+			v.Set("pageToken", pageToken)
+		}
+
+		var page GooglePhotosAPIMediaItems
+		if err := fetchGooglePhotos(ctx, client, accessToken, "GET", "/v1/mediaItems?"+v.Encode(), nil, &page); err != nil {
+			return err
+		}
+
+		for _, item := range page.MediaItems {
+			photo, err := googlePhotoFromAPIMediaItem(item, albumIDs[item.ID])
+			if err != nil {
+				logger.Errorf("(googlephotos) Skipping photo %v: %v", item.ID, err)
+				numSkipped++
+				continue
+			}
+
+			photos = append(photos, photo)
+		}
+
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	if numSkipped > 0 {
+		logger.Warnf("(googlephotos) Skipped %v photo(s) that couldn't be processed", numSkipped)
+	}
+
+	photos = mergeGooglePhotos(photos, existingPhotos)
+
+	logger.Infof("(googlephotos) Writing %v photo(s) to '%s'", len(photos), targetPath)
+
+	googlePhotosDB := &GooglePhotosDB{Photos: photos}
+	if err := writeTOMLFile(targetPath, googlePhotosDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncKobo(dbPath, annotationsPath, sessionsPath string) error {
+	db, err := openSQLiteDB(dbPath)
+	if err != nil {
+		return fmt.Errorf("error opening Kobo database: %w", err)
+	}
+
+	titles, err := readKoboContentTitles(db)
+	if err != nil {
+		return err
+	}
+
+	if err := syncKoboAnnotations(db, titles, annotationsPath); err != nil {
+		return err
+	}
+
+	return syncKoboSessions(db, titles, sessionsPath)
+}
+
+func syncKoboAnnotations(db *SQLiteDB, titles map[string]string, targetPath string) error {
+	annotations, numSkipped, err := readKoboAnnotations(db, titles)
+	if err != nil {
+		return err
+	}
+
+	if numSkipped > 0 {
+		logger.Warnf("(kobo) Skipped %v bookmark(s) that couldn't be processed", numSkipped)
+	}
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingAnnotationDB KoboAnnotationDB
+		if err := readTOMLFile(targetPath, &existingAnnotationDB); err != nil {
+			return err
+		}
+
+		logger.Infof("(kobo) Found existing '%v'; merging %v existing annotation(s) with %v current annotation(s)",
+			targetPath, len(existingAnnotationDB.Annotations), len(annotations))
+
+		annotations = mergeKoboAnnotations(annotations, existingAnnotationDB.Annotations)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(kobo) Existing DB at '%v' not found; starting fresh", targetPath)
+
+		annotations = mergeKoboAnnotations(annotations, nil)
+	} else {
+		return err
+	}
+
+	logger.Infof("(kobo) Writing %v annotation(s) to '%s'", len(annotations), targetPath)
+
+	annotationDB := &KoboAnnotationDB{Annotations: annotations}
+	if err := writeTOMLFile(targetPath, annotationDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncKoboSessions(db *SQLiteDB, titles map[string]string, targetPath string) error {
+	sessions, numSkipped, err := readKoboSessions(db, titles)
+	if err != nil {
+		return err
+	}
+
+	if numSkipped > 0 {
+		logger.Warnf("(kobo) Skipped %v analytics event(s) that couldn't be processed", numSkipped)
+	}
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingSessionDB KoboSessionDB
+		if err := readTOMLFile(targetPath, &existingSessionDB); err != nil {
+			return err
+		}
+
+		logger.Infof("(kobo) Found existing '%v'; merging %v existing session(s) with %v current session(s)",
+			targetPath, len(existingSessionDB.Sessions), len(sessions))
+
+		sessions = mergeKoboSessions(sessions, existingSessionDB.Sessions)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(kobo) Existing DB at '%v' not found; starting fresh", targetPath)
+
+		sessions = mergeKoboSessions(sessions, nil)
+	} else {
+		return err
+	}
+
+	logger.Infof("(kobo) Writing %v session(s) to '%s'", len(sessions), targetPath)
+
+	sessionDB := &KoboSessionDB{Sessions: sessions}
+	if err := writeTOMLFile(targetPath, sessionDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncLinkedIn(ctx context.Context, targetPath string) error {
+	var conf LinkedInConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingPosts []*LinkedInPost
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingLinkedInDB LinkedInDB
+		if err := readTOMLFile(targetPath, &existingLinkedInDB); err != nil {
+			return err
+		}
+
+		existingPosts = existingLinkedInDB.Posts
+
+		logger.Infof("(linkedin) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(linkedin) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	var numRequests int
+
+	var me LinkedInAPIMe
+	err := fetchLinkedIn(ctx, &conf, client, "/me", "", &numRequests, &me)
+	if err != nil {
+		return err
+	}
+
+	personURN := "urn:li:person:" + me.ID
+
+	var posts []*LinkedInPost
+	for start := 0; ; {
+		logger.Infof("(linkedin) Paging; num posts accumulated: %v, start: %v", len(posts), start)
+
+		var root LinkedInAPIPostsRoot
+		err := fetchLinkedIn(ctx, &conf, client, "/ugcPosts",
+			fmt.Sprintf("q=authors&authors=List(%s)&start=%v&count=%v",
+				url.QueryEscape(personURN), start, linkedInPageLimit),
+			&numRequests, &root)
+		if err != nil {
+			return err
+		}
+
+		for _, apiPost := range root.Elements {
+			var socialActions LinkedInAPISocialActions
+			err := fetchLinkedIn(ctx, &conf, client, "/socialActions/"+url.QueryEscape(apiPost.ID), "",
+				&numRequests, &socialActions)
+			if err != nil {
+				return err
+			}
+
+			posts = append(posts, linkedInPostFromAPIPost(apiPost, &socialActions))
+		}
+
+		start += len(root.Elements)
+		if len(root.Elements) < 1 || root.Paging == nil || start >= root.Paging.Total {
+			break
+		}
+	}
+
+	logger.Infof("(linkedin) Made %v request(s) of LinkedIn's limit of %v a day",
+		numRequests, linkedInDailyRequestLimit)
+
+	posts = mergeLinkedInPosts(posts, existingPosts)
+
+	logger.Infof("(linkedin) Writing %v post(s) to '%s'", len(posts), targetPath)
+
+	linkedInDB := &LinkedInDB{Posts: posts}
+	if err := writeTOMLFile(targetPath, linkedInDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncLinkedInArticles(ctx context.Context, targetPath string) error {
+	var conf LinkedInArticleConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+	linkedInConf := &LinkedInConf{LinkedInAccessToken: conf.LinkedInAccessToken}
+
+	var existingArticles []*LinkedInArticle
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingLinkedInArticleDB LinkedInArticleDB
+		if err := readTOMLFile(targetPath, &existingLinkedInArticleDB); err != nil {
+			return err
+		}
+
+		existingArticles = existingLinkedInArticleDB.Articles
+
+		logger.Infof("(linkedin) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(linkedin) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	var numRequests int
+
+	var me LinkedInAPIMe
+	err := fetchLinkedIn(ctx, linkedInConf, client, "/me", "", &numRequests, &me)
+	if err != nil {
+		return err
+	}
+
+	personURN := "urn:li:person:" + me.ID
+
+	var articles []*LinkedInArticle
+	for start := 0; ; {
+		logger.Infof("(linkedin) Paging; num articles accumulated: %v, start: %v", len(articles), start)
+
+		var root LinkedInAPIArticlesRoot
+		err := fetchLinkedIn(ctx, linkedInConf, client, "/articles",
+			fmt.Sprintf("q=authors&authors=List(%s)&start=%v&count=%v",
+				url.QueryEscape(personURN), start, linkedInPageLimit),
+			&numRequests, &root)
+		if err != nil {
+			return err
+		}
+
+		for _, apiArticle := range root.Elements {
+			var socialActions LinkedInAPISocialActions
+			err := fetchLinkedIn(ctx, linkedInConf, client, "/socialActions/"+url.QueryEscape(apiArticle.ID), "",
+				&numRequests, &socialActions)
+			if err != nil {
+				return err
+			}
+
+			articles = append(articles, linkedInArticleFromAPIArticle(apiArticle, &socialActions))
+		}
+
+		start += len(root.Elements)
+		if len(root.Elements) < 1 || root.Paging == nil || start >= root.Paging.Total {
+			break
+		}
+	}
+
+	logger.Infof("(linkedin) Made %v request(s) of LinkedIn's limit of %v a day",
+		numRequests, linkedInDailyRequestLimit)
+
+	articles = mergeLinkedInArticles(articles, existingArticles)
+
+	logger.Infof("(linkedin) Writing %v article(s) to '%s'", len(articles), targetPath)
+
+	linkedInArticleDB := &LinkedInArticleDB{Articles: articles}
+	if err := writeTOMLFile(targetPath, linkedInArticleDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncMedium(ctx context.Context, targetPath string) error {
+	var conf MediumConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingPosts []*MediumPost
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingMediumDB MediumDB
+		if err := readTOMLFile(targetPath, &existingMediumDB); err != nil {
+			return err
+		}
+
+		existingPosts = existingMediumDB.Posts
+
+		logger.Infof("(medium) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(medium) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	logger.Infof("(medium) Fetching feed for @%v", conf.MediumUsername)
+
+	items, err := fetchMediumFeed(ctx, client, conf.MediumUsername)
+	if err != nil {
+		return err
+	}
+
+	var posts []*MediumPost
+	unfetchedIDs := make(map[string]bool)
+	for _, item := range items {
+		post, err := mediumPostFromRSSItem(item)
+		if err != nil {
+			return err
+		}
+
+		logger.Infof("(medium) Fetching post %v", post.ID)
+
+		// The post API is undocumented and may go away at any time, so carry
+		// on without it rather than losing the rest of the post.
+		apiPost, err := fetchMediumPost(ctx, client, post.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+
+			logger.Warnf("(medium) Couldn't fetch subtitle and clap count for post %v: %v", post.ID, err)
+			unfetchedIDs[post.ID] = true
+		} else {
+			applyMediumAPIPost(post, apiPost)
+		}
+
+		posts = append(posts, post)
+	}
+
+	copyMediumPostAPIFields(posts, existingPosts, unfetchedIDs)
+
+	posts = mergeMediumPosts(posts, existingPosts)
+
+	logger.Infof("(medium) Writing %v post(s) to '%s'", len(posts), targetPath)
+
+	mediumDB := &MediumDB{Posts: posts}
+	if err := writeTOMLFile(targetPath, mediumDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncMonzo(ctx context.Context, targetPath string) error {
+	var conf MonzoConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingTransactions []*MonzoTransaction
+	var since time.Time
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingMonzoDB MonzoDB
+		if err := readTOMLFile(targetPath, &existingMonzoDB); err != nil {
+			return err
+		}
+
+		existingTransactions = existingMonzoDB.Transactions
+		if len(existingTransactions) > 0 {
+			since = existingTransactions[len(existingTransactions)-1].CreatedAt.AddDate(0, 0, -monzoRefetchDays)
+		}
+
+		logger.Infof("(monzo) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(monzo) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	var accountsRoot MonzoAPIAccountsRoot
+	err := fetchMonzo(ctx, &conf, client, "/accounts", url.Values{}, &accountsRoot)
+	if err != nil {
+		return err
+	}
+
+	var transactions []*MonzoTransaction
+
+	for _, account := range accountsRoot.Accounts {
+		if account.Closed {
+			continue
+		}
+
+		// Monzo returns transactions oldest first, so page forwards. since
+		// takes either a timestamp or a transaction ID, and the ID of the
+		// newest transaction in each page is the cursor for the next.
+		var cursor string
+		if !since.IsZero() {
+			cursor = since.Format(time.RFC3339Nano)
+		}
+
+		for {
+			logger.Infof("(monzo) Paging account %v; num transactions accumulated: %v, since: %v",
+				account.ID, len(transactions), cursor)
+
+			v := url.Values{}
+			v.Set("account_id", account.ID)
+			v.Set("expand[]", "merchant")
+			v.Set("limit", strconv.Itoa(monzoPageLimit))
+			if cursor != "" {
+				v.Set("since", cursor)
+			}
+
+			var root MonzoAPITransactionsRoot
+			err := fetchMonzo(ctx, &conf, client, "/transactions", v, &root)
+			if err != nil {
+				return err
+			}
+
+			for _, apiTransaction := range root.Transactions {
+				transactions = append(transactions, monzoTransactionFromAPITransaction(apiTransaction))
+			}
+
+			if len(root.Transactions) < monzoPageLimit {
+				break
+			}
+
+			cursor = root.Transactions[len(root.Transactions)-1].ID
+		}
+	}
+
+	transactions = mergeMonzoTransactions(transactions, existingTransactions)
+
+	logger.Infof("(monzo) Writing %v transaction(s) to '%s'", len(transactions), targetPath)
+
+	monzoDB := &MonzoDB{Transactions: transactions}
+	if err := writeTOMLFile(targetPath, monzoDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncNomadList(ctx context.Context, targetPath string) error {
+	var conf NomadListConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingStays []*NomadStay
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingNomadListDB NomadListDB
+		if err := readTOMLFile(targetPath, &existingNomadListDB); err != nil {
+			return err
+		}
+
+		existingStays = existingNomadListDB.Stays
+
+		logger.Infof("(nomadlist) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(nomadlist) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	logger.Infof("(nomadlist) Fetching profile for @%v", conf.NomadListUsername)
+
+	profile, err := fetchNomadList(ctx, client, conf.NomadListUsername)
+	if err != nil {
+		return err
+	}
+
+	var stays []*NomadStay
+	for _, trip := range profile.Trips {
+		stay, err := nomadStayFromAPITrip(trip)
+		if err != nil {
+			return err
+		}
+
+		stays = append(stays, stay)
+	}
+
+	// The profile always contains every stay, so comparing it against what's
+	// stored shows exactly what changed since the last sync.
+	added, removed := diffNomadStays(stays, existingStays)
+	for _, stay := range added {
+		logger.Infof("(nomadlist) New stay %v: %v, %v (%v to %v)", stay.ID, stay.City, stay.Country,
+			stay.DateStart.Format(nomadListDateFormat), stay.DateEnd.Format(nomadListDateFormat))
+	}
+	for _, stay := range removed {
+		logger.Warnf("(nomadlist) Stay %v no longer in profile; keeping it: %v, %v (%v to %v)", stay.ID, stay.City, stay.Country,
+			stay.DateStart.Format(nomadListDateFormat), stay.DateEnd.Format(nomadListDateFormat))
+	}
+
+	stays = mergeNomadStays(stays, existingStays)
+
+	logger.Infof("(nomadlist) Writing %v stay(s) to '%s'", len(stays), targetPath)
+
+	nomadListDB := &NomadListDB{Stays: stays}
+	if err := writeTOMLFile(targetPath, nomadListDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncOura(ctx context.Context, sleepPath, readinessPath string) error {
+	var conf OuraConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	// Sleep and readiness are separate collections in separate files, so
+	// sync them in parallel.
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	var readinessErr error
+	go func() {
+		readinessErr = syncOuraReadiness(ctx, &conf, client, readinessPath)
+		wg.Done()
+	}()
+
+	var sleepErr error
+	go func() {
+		sleepErr = syncOuraSleep(ctx, &conf, client, sleepPath)
+		wg.Done()
+	}()
+
+	wg.Wait()
+
+	if readinessErr != nil {
+		return readinessErr
+	}
+	if sleepErr != nil {
+		return sleepErr
+	}
+
+	return nil
+}
+
+func syncOuraReadiness(ctx context.Context, conf *OuraConf, client *http.Client, targetPath string) error {
+	var existingDays []*OuraReadinessDay
+	startDate := ouraEpoch
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingReadinessDB OuraReadinessDB
+		if err := readTOMLFile(targetPath, &existingReadinessDB); err != nil {
+			return err
+		}
+
+		existingDays = existingReadinessDB.ReadinessDays
+		if len(existingDays) > 0 {
+			startDate = existingDays[len(existingDays)-1].Date.AddDate(0, 0, -ouraRefetchDays)
+		}
+
+		logger.Infof("(oura) Found existing '%v'; running incremental update from %v",
+			targetPath, startDate.Format(ouraDateFormat))
+	} else if os.IsNotExist(err) {
+		logger.Infof("(oura) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	var days []*OuraReadinessDay
+	err := fetchOuraCollection(ctx, conf, client, "daily_readiness", startDate, func(data json.RawMessage) error {
+		var apiReadinesses []*OuraAPIReadiness
+		if err := json.Unmarshal(data, &apiReadinesses); err != nil {
+			return fmt.Errorf("error unmarshaling readiness from JSON: %w", err)
+		}
+
+		for _, apiReadiness := range apiReadinesses {
+			day, err := ouraReadinessDayFromAPIReadiness(apiReadiness)
+			if err != nil {
+				return err
+			}
+			days = append(days, day)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error paging oura readiness: %w", err)
+	}
+
+	days = mergeOuraReadinessDays(days, existingDays)
+
+	logger.Infof("(oura) Writing %v readiness day(s) to '%s'", len(days), targetPath)
+
+	readinessDB := &OuraReadinessDB{ReadinessDays: days}
+	if err := writeTOMLFile(targetPath, readinessDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncOuraSleep(ctx context.Context, conf *OuraConf, client *http.Client, targetPath string) error {
+	var existingDays []*OuraSleepDay
+	startDate := ouraEpoch
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingSleepDB OuraSleepDB
+		if err := readTOMLFile(targetPath, &existingSleepDB); err != nil {
+			return err
+		}
+
+		existingDays = existingSleepDB.SleepDays
+		if len(existingDays) > 0 {
+			startDate = existingDays[len(existingDays)-1].Date.AddDate(0, 0, -ouraRefetchDays)
+		}
+
+		logger.Infof("(oura) Found existing '%v'; running incremental update from %v",
+			targetPath, startDate.Format(ouraDateFormat))
+	} else if os.IsNotExist(err) {
+		logger.Infof("(oura) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	// Scores are found in the daily sleep summaries, but durations are only
+	// available on sleep periods, so we need both.
+	var dailySleeps []*OuraAPIDailySleep
+	err := fetchOuraCollection(ctx, conf, client, "daily_sleep", startDate, func(data json.RawMessage) error {
+		var apiDailySleeps []*OuraAPIDailySleep
+		if err := json.Unmarshal(data, &apiDailySleeps); err != nil {
+			return fmt.Errorf("error unmarshaling daily sleep from JSON: %w", err)
+		}
+
+		dailySleeps = append(dailySleeps, apiDailySleeps...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error paging oura daily sleep: %w", err)
+	}
+
+	// A day may have multiple sleep periods (e.g. naps), so keep only the
+	// longest one, which is the one that'll represent the night.
+	periodsByDay := make(map[string]*OuraAPISleepPeriod)
+	err = fetchOuraCollection(ctx, conf, client, "sleep", startDate, func(data json.RawMessage) error {
+		var apiPeriods []*OuraAPISleepPeriod
+		if err := json.Unmarshal(data, &apiPeriods); err != nil {
+			return fmt.Errorf("error unmarshaling sleep periods from JSON: %w", err)
+		}
+
+		for _, apiPeriod := range apiPeriods {
+			existing, ok := periodsByDay[apiPeriod.Day]
+			if !ok || apiPeriod.TotalSleepDuration > existing.TotalSleepDuration {
+				periodsByDay[apiPeriod.Day] = apiPeriod
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error paging oura sleep periods: %w", err)
+	}
+
+	var days []*OuraSleepDay
+	for _, dailySleep := range dailySleeps {
+		day, err := ouraSleepDayFromAPIDailySleep(dailySleep, periodsByDay[dailySleep.Day])
+		if err != nil {
+			return err
+		}
+		days = append(days, day)
+	}
+
+	days = mergeOuraSleepDays(days, existingDays)
+
+	logger.Infof("(oura) Writing %v sleep day(s) to '%s'", len(days), targetPath)
+
+	sleepDB := &OuraSleepDB{SleepDays: days}
+	if err := writeTOMLFile(targetPath, sleepDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncOvercast(opmlPath, targetPath string) error {
+	f, err := os.Open(opmlPath)
+	if err != nil {
+		return fmt.Errorf("error opening OPML export: %w", err)
+	}
+	defer f.Close()
+
+	episodes, numSkipped, err := parseOvercastOPML(f)
+	if err != nil {
+		return err
+	}
+
+	if numSkipped > 0 {
+		logger.Warnf("(overcast) Skipped %v episode(s) that couldn't be processed", numSkipped)
+	}
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingOvercastDB OvercastDB
+		if err := readTOMLFile(targetPath, &existingOvercastDB); err != nil {
+			return err
+		}
+
+		logger.Infof("(overcast) Found existing '%v'; merging %v existing episode(s) with %v exported episode(s)",
+			targetPath, len(existingOvercastDB.Episodes), len(episodes))
+
+		episodes = mergePodcastEpisodes(episodes, existingOvercastDB.Episodes)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(overcast) Existing DB at '%v' not found; starting fresh", targetPath)
+
+		episodes = mergePodcastEpisodes(episodes, nil)
+	} else {
+		return err
+	}
+
+	logger.Infof("(overcast) Writing %v episode(s) to '%s'", len(episodes), targetPath)
+
+	overcastDB := &OvercastDB{Episodes: episodes}
+	if err := writeTOMLFile(targetPath, overcastDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncRunkeeper(ctx context.Context, targetPath string) error {
+	var conf RunkeeperConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingActivities []*RunkeeperActivity
+	var since time.Time
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingRunkeeperDB RunkeeperDB
+		if err := readTOMLFile(targetPath, &existingRunkeeperDB); err != nil {
+			return err
+		}
+
+		existingActivities = existingRunkeeperDB.Activities
+		if len(existingActivities) > 0 {
+			since = existingActivities[len(existingActivities)-1].StartTime.AddDate(0, 0, -runkeeperRefetchDays)
+		}
+
+		logger.Infof("(runkeeper) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(runkeeper) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	v := url.Values{}
+	v.Set("pageSize", strconv.Itoa(runkeeperPageSize))
+	if !since.IsZero() {
+		v.Set("noEarlierThan", since.Format(runkeeperDateFormat))
+	}
+
+	var activities []*RunkeeperActivity
+	path := "/fitnessActivities?" + v.Encode()
+
+	for path != "" {
+		logger.Infof("(runkeeper) Paging; num activities accumulated: %v, path: %v", len(activities), path)
+
+		var root RunkeeperAPIFeedRoot
+		err := fetchRunkeeper(ctx, &conf, client, path, runkeeperMediaTypeFeed, &root)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range root.Items {
+			var apiActivity RunkeeperAPIActivity
+			err := fetchRunkeeper(ctx, &conf, client, item.URI, runkeeperMediaTypeActivity, &apiActivity)
+			if err != nil {
+				return err
+			}
+
+			activity, err := runkeeperActivityFromAPIActivity(&apiActivity)
+			if err != nil {
+				return err
+			}
+
+			activities = append(activities, activity)
+		}
+
+		path = root.Next
+	}
+
+	activities = mergeRunkeeperActivities(activities, existingActivities)
+
+	logger.Infof("(runkeeper) Writing %v activities to '%s'", len(activities), targetPath)
+
+	runkeeperDB := &RunkeeperDB{Activities: activities}
+	if err := writeTOMLFile(targetPath, runkeeperDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncRunningAhead(csvPath, targetPath string) error {
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return fmt.Errorf("error opening CSV export: %w", err)
+	}
+	defer f.Close()
+
+	workouts, numSkipped, err := parseRunningAheadCSV(f)
+	if err != nil {
+		return err
+	}
+
+	if numSkipped > 0 {
+		logger.Warnf("(runningahead) Skipped %v row(s) that couldn't be processed", numSkipped)
+	}
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingRunningAheadDB RunningAheadDB
+		if err := readTOMLFile(targetPath, &existingRunningAheadDB); err != nil {
+			return err
+		}
+
+		logger.Infof("(runningahead) Found existing '%v'; merging %v existing workout(s) with %v exported workout(s)",
+			targetPath, len(existingRunningAheadDB.Workouts), len(workouts))
+
+		workouts = mergeRunningWorkouts(workouts, existingRunningAheadDB.Workouts)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(runningahead) Existing DB at '%v' not found; starting fresh", targetPath)
+
+		workouts = mergeRunningWorkouts(workouts, nil)
+	} else {
+		return err
+	}
+
+	logger.Infof("(runningahead) Writing %v workout(s) to '%s'", len(workouts), targetPath)
+
+	runningAheadDB := &RunningAheadDB{Workouts: workouts}
+	if err := writeTOMLFile(targetPath, runningAheadDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncSleepCycle(csvPath, targetPath string) error {
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return fmt.Errorf("error opening CSV export: %w", err)
+	}
+	defer f.Close()
+
+	nights, numSkipped, err := parseSleepCycleCSV(f)
+	if err != nil {
+		return err
+	}
+
+	if numSkipped > 0 {
+		logger.Warnf("(sleepcycle) Skipped %v row(s) that couldn't be processed", numSkipped)
+	}
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingSleepCycleDB SleepCycleDB
+		if err := readTOMLFile(targetPath, &existingSleepCycleDB); err != nil {
+			return err
+		}
+
+		logger.Infof("(sleepcycle) Found existing '%v'; merging %v existing night(s) with %v exported night(s)",
+			targetPath, len(existingSleepCycleDB.Nights), len(nights))
+
+		nights = mergeSleepCycleNights(nights, existingSleepCycleDB.Nights)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(sleepcycle) Existing DB at '%v' not found; starting fresh", targetPath)
+
+		nights = mergeSleepCycleNights(nights, nil)
+	} else {
+		return err
+	}
+
+	logger.Infof("(sleepcycle) Writing %v night(s) to '%s'", len(nights), targetPath)
+
+	sleepCycleDB := &SleepCycleDB{Nights: nights}
+	if err := writeTOMLFile(targetPath, sleepCycleDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncSteam(ctx context.Context, targetPath string) error {
+	var conf SteamConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingGames []*SteamGame
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingSteamDB SteamDB
+		if err := readTOMLFile(targetPath, &existingSteamDB); err != nil {
+			return err
+		}
+
+		existingGames = existingSteamDB.Games
+
+		logger.Infof("(steam) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(steam) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	logger.Infof("(steam) Fetching owned games")
+
+	apiGames, err := fetchSteamOwnedGames(ctx, &conf, client)
+	if err != nil {
+		return err
+	}
+
+	games := make([]*SteamGame, len(apiGames))
+	for i, apiGame := range apiGames {
+		games[i] = steamGameFromAPIGame(apiGame)
+	}
+
+	games = mergeSteamGames(games, existingGames)
+
+	logger.Infof("(steam) Writing %v game(s) to '%s'", len(games), targetPath)
+
+	steamDB := &SteamDB{Games: games}
+	if err := writeTOMLFile(targetPath, steamDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncStripe(ctx context.Context, chargesPath, payoutsPath string) error {
+	var conf StripeConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	if err := syncStripeCharges(ctx, &conf, client, chargesPath); err != nil {
+		return err
+	}
+
+	return syncStripePayouts(ctx, &conf, client, payoutsPath)
+}
+
+func syncStripeCharges(ctx context.Context, conf *StripeConf, client *http.Client, targetPath string) error {
+	var existingCharges []*StripeCharge
+	var createdGTE time.Time
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingChargeDB StripeChargeDB
+		if err := readTOMLFile(targetPath, &existingChargeDB); err != nil {
+			return err
+		}
+
+		existingCharges = existingChargeDB.Charges
+		if len(existingCharges) > 0 {
+			createdGTE = existingCharges[len(existingCharges)-1].CreatedAt.AddDate(0, 0, -stripeRefetchDays)
+		}
+
+		logger.Infof("(stripe) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(stripe) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	apiCharges, err := fetchStripeList(ctx, conf, client, "/v1/charges", createdGTE,
+		func(charge *StripeAPICharge) string { return charge.ID })
+	if err != nil {
+		return err
+	}
+
+	var charges []*StripeCharge
+	for _, apiCharge := range apiCharges {
+		charges = append(charges, stripeChargeFromAPICharge(apiCharge))
+	}
+
+	charges = mergeStripeCharges(charges, existingCharges)
+
+	logger.Infof("(stripe) Writing %v charge(s) to '%s'", len(charges), targetPath)
+
+	chargeDB := &StripeChargeDB{Charges: charges}
+	if err := writeTOMLFile(targetPath, chargeDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncStripePayouts(ctx context.Context, conf *StripeConf, client *http.Client, targetPath string) error {
+	var existingPayouts []*StripeBalance
+	var createdGTE time.Time
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingBalanceDB StripeBalanceDB
+		if err := readTOMLFile(targetPath, &existingBalanceDB); err != nil {
+			return err
+		}
+
+		existingPayouts = existingBalanceDB.Payouts
+		if len(existingPayouts) > 0 {
+			createdGTE = existingPayouts[len(existingPayouts)-1].CreatedAt.AddDate(0, 0, -stripeRefetchDays)
+		}
+
+		logger.Infof("(stripe) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(stripe) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	apiPayouts, err := fetchStripeList(ctx, conf, client, "/v1/payouts", createdGTE,
+		func(payout *StripeAPIPayout) string { return payout.ID })
+	if err != nil {
+		return err
+	}
+
+	var payouts []*StripeBalance
+	for _, apiPayout := range apiPayouts {
+		payouts = append(payouts, stripeBalanceFromAPIPayout(apiPayout))
+	}
+
+	payouts = mergeStripeBalances(payouts, existingPayouts)
+
+	logger.Infof("(stripe) Writing %v payout(s) to '%s'", len(payouts), targetPath)
+
+	balanceDB := &StripeBalanceDB{Payouts: payouts}
+	if err := writeTOMLFile(targetPath, balanceDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncSubstack(ctx context.Context, targetPath string) error {
+	var conf SubstackConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingPosts []*SubstackPost
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingSubstackDB SubstackDB
+		if err := readTOMLFile(targetPath, &existingSubstackDB); err != nil {
+			return err
+		}
+
+		existingPosts = existingSubstackDB.Posts
+
+		logger.Infof("(substack) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(substack) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	// Stats like views keep changing long after a post is published, so
+	// every post is fetched on each sync.
+	var posts []*SubstackPost
+	for offset := 0; ; {
+		logger.Infof("(substack) Paging; num posts accumulated: %v, offset: %v", len(posts), offset)
+
+		apiPosts, err := fetchSubstackPosts(ctx, &conf, client, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, apiPost := range apiPosts {
+			posts = append(posts, substackPostFromAPIPost(apiPost))
+		}
+
+		offset += len(apiPosts)
+		if len(apiPosts) < substackPageLimit {
+			break
+		}
+	}
+
+	posts = mergeSubstackPosts(posts, existingPosts)
+
+	logger.Infof("(substack) Writing %v post(s) to '%s'", len(posts), targetPath)
+
+	substackDB := &SubstackDB{Posts: posts}
+	if err := writeTOMLFile(targetPath, substackDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncTelegram(ctx context.Context, targetPath string) error {
+	var conf TelegramConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingMessages []*TelegramMessage
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingTelegramDB TelegramDB
+		if err := readTOMLFile(targetPath, &existingTelegramDB); err != nil {
+			return err
+		}
+
+		existingMessages = existingTelegramDB.Messages
+
+		logger.Infof("(telegram) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(telegram) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	messages := existingMessages
+	var numMessages, offset int
+
+	for {
+		logger.Infof("(telegram) Paging; num messages accumulated: %v, offset: %v", numMessages, offset)
+
+		v := url.Values{}
+		v.Set("allowed_updates", `["channel_post","message"]`)
+		v.Set("limit", strconv.Itoa(telegramPageLimit))
+		v.Set("offset", strconv.Itoa(offset))
+
+		var updates []*TelegramAPIUpdate
+		err := fetchTelegram(ctx, &conf, client, "getUpdates", v, &updates)
+		if err != nil {
+			return err
+		}
+
+		var pageMessages []*TelegramMessage
+		for _, update := range updates {
+			// Requesting an offset past an update confirms it, so it's not
+			// returned again.
+			offset = update.UpdateID + 1
+
+			message := telegramMessageFromAPIUpdate(update, conf.TelegramChannelID)
+			if message == nil {
+				continue
+			}
+
+			apiMessage := update.ChannelPost
+			if apiMessage == nil {
+				apiMessage = update.Message
+			}
+
+			// Files are only worth a warning because the message itself is
+			// more important than its attachments.
+			if len(apiMessage.Photo) > 0 {
+				message.PhotoURL, err = fetchTelegramFilePath(ctx, &conf, client, apiMessage.Photo[len(apiMessage.Photo)-1].FileID)
+				if err != nil {
+					if ctx.Err() != nil {
+						return err
+					}
+					logger.Warnf("(telegram) Couldn't get photo for message %v: %v", message.MessageID, err)
+				}
+			}
+
+			if apiMessage.Document != nil {
+				message.DocumentURL, err = fetchTelegramFilePath(ctx, &conf, client, apiMessage.Document.FileID)
+				if err != nil {
+					if ctx.Err() != nil {
+						return err
+					}
+					logger.Warnf("(telegram) Couldn't get document for message %v: %v", message.MessageID, err)
+				}
+			}
+
+			pageMessages = append(pageMessages, message)
+		}
+
+		// The next call to getUpdates confirms this page, after which
+		// Telegram won't return it again, so it's written first in case that
+		// call or anything after it fails.
+		numMessages += len(pageMessages)
+		messages = mergeTelegramMessages(pageMessages, messages)
+
+		logger.Infof("(telegram) Writing %v message(s) to '%s'", len(messages), targetPath)
+
+		telegramDB := &TelegramDB{Messages: messages}
+		if err := writeTOMLFile(targetPath, telegramDB); err != nil {
+			return err
+		}
+
+		if len(updates) < 1 {
+			break
+		}
+	}
+
+	return nil
+}
+
+func syncToggl(ctx context.Context, targetPath string) error {
+	var conf TogglConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingEntries []*TogglEntry
+	startDate := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -togglLookbackDays)
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingTogglDB TogglDB
+		if err := readTOMLFile(targetPath, &existingTogglDB); err != nil {
+			return err
+		}
+
+		existingEntries = existingTogglDB.Entries
+		if len(existingEntries) > 0 {
+			lastStartDate := existingEntries[len(existingEntries)-1].StartedAt.UTC().Truncate(24*time.Hour).AddDate(0, 0, -togglRefetchDays)
+			if lastStartDate.After(startDate) {
+				startDate = lastStartDate
+			}
+		}
+
+		logger.Infof("(toggl) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(toggl) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	var apiEntries []*TogglAPITimeEntry
+	now := time.Now().UTC()
+
+	for windowStart := startDate; windowStart.Before(now); windowStart = windowStart.AddDate(0, 0, togglWindowDays) {
+		// End date is exclusive.
+		windowEnd := windowStart.AddDate(0, 0, togglWindowDays)
+
+		logger.Infof("(toggl) Paging; num entries accumulated: %v, window: %v to %v",
+			len(apiEntries), windowStart.Format(togglDateFormat), windowEnd.Format(togglDateFormat))
+
+		v := url.Values{}
+		v.Set("end_date", windowEnd.Format(togglDateFormat))
+		v.Set("start_date", windowStart.Format(togglDateFormat))
+
+		var windowEntries []*TogglAPITimeEntry
+		err := fetchToggl(ctx, &conf, client, "/me/time_entries", v, &windowEntries)
+		if err != nil {
+			return err
+		}
+
+		apiEntries = append(apiEntries, windowEntries...)
+	}
+
+	// Fetch the project lists of each workspace that entries were found in
+	// once up front so that project names can be joined without a request
+	// per entry.
+	projects := make(map[int64]*TogglAPIProject)
+	workspaceIDs := make(map[int64]struct{})
+	for _, apiEntry := range apiEntries {
+		if _, ok := workspaceIDs[apiEntry.WorkspaceID]; ok {
+			continue
+		}
+		workspaceIDs[apiEntry.WorkspaceID] = struct{}{}
+
+		logger.Infof("(toggl) Fetching projects for workspace %v", apiEntry.WorkspaceID)
+
+		if err := fetchTogglProjects(ctx, &conf, client, apiEntry.WorkspaceID, projects); err != nil {
+			return err
+		}
+	}
+
+	var entries []*TogglEntry
+	for _, apiEntry := range apiEntries {
+		// Running entries don't have a stop time or duration yet, and will
+		// be picked up by a later sync once they're stopped.
+		if apiEntry.Stop == nil || apiEntry.Duration < 0 {
+			continue
+		}
+
+		entries = append(entries, togglEntryFromAPITimeEntry(apiEntry, projects))
+	}
+
+	entries = mergeTogglEntries(entries, existingEntries)
+
+	logger.Infof("(toggl) Writing %v entries to '%s'", len(entries), targetPath)
+
+	togglDB := &TogglDB{Entries: entries}
+	if err := writeTOMLFile(targetPath, togglDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncWakaTime(ctx context.Context, targetPath string) error {
+	var conf WakaTimeConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingDays []*WakaTimeDay
+	var startDate time.Time
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingWakaTimeDB WakaTimeDB
+		if err := readTOMLFile(targetPath, &existingWakaTimeDB); err != nil {
+			return err
+		}
+
+		existingDays = existingWakaTimeDB.Days
+		if len(existingDays) > 0 {
+			startDate = existingDays[len(existingDays)-1].Date.AddDate(0, 0, -wakaTimeRefetchDays)
+		}
+
+		logger.Infof("(wakatime) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(wakatime) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	// Without any existing data, start from when the account was created.
+	if startDate.IsZero() {
+		var userRoot WakaTimeAPIUserRoot
+		err := fetchWakaTime(ctx, &conf, client, "/users/current", url.Values{}, &userRoot)
+		if err != nil {
+			return err
+		}
+
+		startDate = userRoot.Data.CreatedAt
+	}
+
+	var days []*WakaTimeDay
+	now := time.Now()
+
+	for windowStart := startDate; windowStart.Before(now); windowStart = windowStart.AddDate(0, 0, wakaTimeWindowDays) {
+		// Start and end are both inclusive.
+		windowEnd := windowStart.AddDate(0, 0, wakaTimeWindowDays-1)
+
+		logger.Infof("(wakatime) Paging; num days accumulated: %v, window: %v to %v",
+			len(days), windowStart.Format(wakaTimeDateFormat), windowEnd.Format(wakaTimeDateFormat))
+
+		v := url.Values{}
+		v.Set("end", windowEnd.Format(wakaTimeDateFormat))
+		v.Set("start", windowStart.Format(wakaTimeDateFormat))
+
+		var root WakaTimeAPISummariesRoot
+		err := fetchWakaTime(ctx, &conf, client, "/users/current/summaries", v, &root)
+		if err != nil {
+			return err
+		}
+
+		for _, summary := range root.Data {
+			day, err := wakaTimeDayFromAPISummary(summary)
+			if err != nil {
+				return err
+			}
+
+			// WakaTime returns a summary for every day in range, so skip
+			// those where nothing happened to keep the file small.
+			if day.TotalSeconds == 0 {
+				continue
+			}
+
+			days = append(days, day)
+		}
+	}
+
+	days = mergeWakaTimeDays(days, existingDays)
+
+	logger.Infof("(wakatime) Writing %v day(s) to '%s'", len(days), targetPath)
+
+	wakaTimeDB := &WakaTimeDB{Days: days}
+	if err := writeTOMLFile(targetPath, wakaTimeDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncWeatherOpenWeather(ctx context.Context, targetPath string) error {
+	var conf OpenWeatherConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingDays []*OpenWeatherDay
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingOpenWeatherDB OpenWeatherDB
+		if err := readTOMLFile(targetPath, &existingOpenWeatherDB); err != nil {
+			return err
+		}
+
+		existingDays = existingOpenWeatherDB.Days
+
+		logger.Infof("(openweather) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(openweather) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	// Days are in the location's time zone, so request the current weather
+	// first to find out what it is. DST changes in the synced range aren't
+	// accounted for.
+	now := time.Now().UTC().Truncate(time.Hour)
+	current, err := fetchOpenWeatherTimeMachine(ctx, &conf, client, now)
+	if err != nil {
+		return err
+	}
+	location := time.FixedZone("", current.TimezoneOffset)
+
+	nowLocal := now.In(location)
+	startDate := time.Date(nowLocal.Year(), nowLocal.Month(), nowLocal.Day(), 0, 0, 0, 0, location).
+		AddDate(0, 0, -openWeatherBackfillDays+1)
+
+	// The last stored day may have been synced before it was over, so it's
+	// always fetched again.
+	if len(existingDays) > 0 {
+		lastDate := existingDays[len(existingDays)-1].Date
+		startDate = time.Date(lastDate.Year(), lastDate.Month(), lastDate.Day(), 0, 0, 0, 0, location)
+	}
+
+	var hourTimes []time.Time
+	for t := startDate; t.Before(now); t = t.Add(time.Hour) {
+		hourTimes = append(hourTimes, t)
+	}
+
+	logger.Infof("(openweather) Fetching %v more hour(s) from %v with concurrency %v",
+		len(hourTimes), startDate.Format(openWeatherDateFormat), openWeatherConcurrency)
+
+	hoursByDate := map[string][]*OpenWeatherAPIHour{
+		nowLocal.Format(openWeatherDateFormat): current.Data,
+	}
+
+	var anyErr error
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, openWeatherConcurrency)
+
+	for _, hourTime := range hourTimes {
+		hourTime := hourTime
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			root, err := fetchOpenWeatherTimeMachine(ctx, &conf, client, hourTime)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				if anyErr == nil {
+					anyErr = err
+				}
+				return
+			}
+
+			date := hourTime.In(location).Format(openWeatherDateFormat)
+			hoursByDate[date] = append(hoursByDate[date], root.Data...)
+		}()
+	}
+
+	wg.Wait()
+
+	if anyErr != nil {
+		return anyErr
+	}
+
+	var days []*OpenWeatherDay
+	for date, hours := range hoursByDate {
+		// Hours are appended as requests finish, so put them back in order.
+		sort.Slice(hours, func(i, j int) bool { return hours[i].Dt < hours[j].Dt })
+
+		t, err := time.Parse(openWeatherDateFormat, date)
+		if err != nil {
+			return err
+		}
+
+		days = append(days, openWeatherDayFromAPIHours(t, hours))
+	}
+
+	days = mergeOpenWeatherDays(days, existingDays)
+
+	logger.Infof("(openweather) Writing %v day(s) to '%s'", len(days), targetPath)
+
+	openWeatherDB := &OpenWeatherDB{Days: days}
+	if err := writeTOMLFile(targetPath, openWeatherDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncWeatherPWS(ctx context.Context, targetPath string) error {
+	var conf WUConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	var existingDays []*PWSDayRecord
+	var startDate time.Time
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingPWSDB PWSDB
+		if err := readTOMLFile(targetPath, &existingPWSDB); err != nil {
+			return err
+		}
+
+		existingDays = existingPWSDB.Days
+		if len(existingDays) > 0 {
+			startDate = existingDays[len(existingDays)-1].Date.AddDate(0, 0, -wuRefetchDays)
+		}
+
+		logger.Infof("(wu) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(wu) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	var days []*PWSDayRecord
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	addObservations := func(observations []*WUAPIObservation) error {
+		for _, observation := range observations {
+			day, err := pwsDayRecordFromWUAPIObservation(observation)
+			if err != nil {
+				return err
+			}
+
+			days = append(days, day)
+		}
+		return nil
+	}
+
+	if startDate.IsZero() {
+		// There's no way to ask Weather Underground when a station started
+		// reporting, so without existing data, walk backwards from today
+		// until a window comes back empty.
+		for windowEnd := today; ; windowEnd = windowEnd.AddDate(0, 0, -wuWindowDays) {
+			// Start and end are both inclusive.
+			windowStart := windowEnd.AddDate(0, 0, -wuWindowDays+1)
+
+			logger.Infof("(wu) Paging backwards; num days accumulated: %v, window: %v to %v",
+				len(days), windowStart.Format(wuDateFormat), windowEnd.Format(wuDateFormat))
+
+			observations, err := fetchWUHistory(ctx, &conf, client, windowStart, windowEnd)
+			if err != nil {
+				return err
+			}
+
+			if len(observations) == 0 {
+				break
+			}
+
+			if err := addObservations(observations); err != nil {
+				return err
+			}
+		}
+	} else {
+		for windowStart := startDate; !windowStart.After(today); windowStart = windowStart.AddDate(0, 0, wuWindowDays) {
+			// Start and end are both inclusive.
+			windowEnd := windowStart.AddDate(0, 0, wuWindowDays-1)
+
+			logger.Infof("(wu) Paging; num days accumulated: %v, window: %v to %v",
+				len(days), windowStart.Format(wuDateFormat), windowEnd.Format(wuDateFormat))
+
+			observations, err := fetchWUHistory(ctx, &conf, client, windowStart, windowEnd)
+			if err != nil {
+				return err
+			}
+
+			if err := addObservations(observations); err != nil {
+				return err
+			}
+		}
+	}
+
+	days = mergePWSDayRecords(days, existingDays)
+
+	logger.Infof("(wu) Writing %v day(s) to '%s'", len(days), targetPath)
+
+	pwsDB := &PWSDB{Days: days}
+	if err := writeTOMLFile(targetPath, pwsDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncWaniKani(ctx context.Context, targetPath string) error {
+	var conf WaniKaniConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := wanikaniapi.NewClient(&wanikaniapi.ClientConfig{
+		APIToken: conf.WaniKaniAPIToken,
+		Logger:   logger,
+	})
+
+	//
+	// Read previous file
+	//
+
+	var existingReviews []*WaniKaniReview
+	var existingSubjects []*WaniKaniSubject
+
+	var reviewsUpdatedAt *time.Time
+	var subjectsUpdatedAt *time.Time
+
+	// WaniKani provides very good facilities for doing incremental updates, so
+	// don't hit them more often than we need to by reusing existing data where
+	// appropriate.
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingWaniKaniDB WaniKaniDB
+		if err := readTOMLFile(targetPath, &existingWaniKaniDB); err != nil {
+			return err
+		}
+
+		existingReviews = existingWaniKaniDB.Reviews
+		existingSubjects = existingWaniKaniDB.Subjects
+
+		reviewsUpdatedAt = &existingWaniKaniDB.ReviewsUpdatedAt
+		subjectsUpdatedAt = &existingWaniKaniDB.SubjectsUpdatedAt
+
+		logger.Infof("(wanikani) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(wanikani) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	//
+	// Reviews
+	//
+
+	var reviews []*WaniKaniReview
+	reviewsStartedAt := time.Now()
+	err := client.PageFully(func(id *wanikaniapi.WKID) (*wanikaniapi.PageObject, error) {
+		idDisplay := "(empty)"
+		if id != nil {
+			idDisplay = strconv.FormatInt(int64(*id), 10)
+		}
+		logger.Infof("(wanikani) Paging; num reviews accumulated: %v, page after ID: %v",
+			len(reviews), idDisplay)
+
+		page, err := client.ReviewList(&wanikaniapi.ReviewListParams{
+			ListParams: wanikaniapi.ListParams{
+				PageAfterID: id,
+			},
+			Params: wanikaniapi.Params{
+				Context: &ctx,
+			},
+			UpdatedAfter: (*wanikaniapi.WKTime)(reviewsUpdatedAt),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, apiReview := range page.Data {
+			reviews = append(reviews, waniKaniReviewFromAPIReview(apiReview))
+		}
+
+		return &page.PageObject, nil
+	})
+	if err != nil {
+		return fmt.Errorf("error paging wanikani reviews: %v", err)
+	}
+
+	numNewReviews := len(reviews)
+	logger.Infof("(wanikani) Total num reviews accumulated: %v", numNewReviews)
+	if existingReviews != nil {
+		reviews = append(existingReviews, reviews...)
+	}
+	sort.Slice(reviews, func(i, j int) bool { return reviews[i].ID < reviews[j].ID })
+
+	//
+	// Subjects
+	//
+
+	var subjects []*WaniKaniSubject
+	subjectsStartedAt := time.Now()
+	err = client.PageFully(func(id *wanikaniapi.WKID) (*wanikaniapi.PageObject, error) {
+		idDisplay := "(empty)"
+		if id != nil {
+			idDisplay = strconv.FormatInt(int64(*id), 10)
+		}
+		logger.Infof("(wanikani) Paging; num subjects accumulated: %v, page after ID: %v",
+			len(subjects), idDisplay)
+
+		page, err := client.SubjectList(&wanikaniapi.SubjectListParams{
+			ListParams: wanikaniapi.ListParams{
+				PageAfterID: id,
+			},
+			Params: wanikaniapi.Params{
+				Context: &ctx,
+			},
+			UpdatedAfter: (*wanikaniapi.WKTime)(subjectsUpdatedAt),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, apiSubject := range page.Data {
+			subjects = append(subjects, waniKaniSubjectFromAPISubject(apiSubject))
+		}
+
+		return &page.PageObject, nil
+	})
+	if err != nil {
+		return fmt.Errorf("error paging wanikani subjects: %v", err)
+	}
+
+	numNewSubjects := len(subjects)
+	logger.Infof("(wanikani) Total num subjects accumulated: %v", numNewSubjects)
+	if existingReviews != nil {
+		subjects = mergeSubjects(subjects, existingSubjects)
+	}
+
+	//
+	// Write new file
+	//
+
+	if numNewReviews > 0 || numNewSubjects > 0 {
+		logger.Infof("(wanikani) Writing %v review(s) / %v subjects(s) to '%s'",
+			len(reviews), len(subjects), targetPath)
+
+		waniKaniDB := &WaniKaniDB{
+			ReviewsUpdatedAt:  reviewsStartedAt,
+			SubjectsUpdatedAt: subjectsStartedAt,
+
+			Reviews:  reviews,
+			Subjects: subjects,
+		}
+		if err := writeTOMLFile(targetPath, waniKaniDB); err != nil {
+			return err
+		}
+	} else {
+		logger.Infof("(wanikani) No new data; not writing file")
+	}
+
+	return nil
+}
+
+func syncWithings(ctx context.Context, targetPath string) error {
+	var conf WithingsConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	client := newHTTPClient()
+
+	// Tokens saved by a previous refresh are preferred because the refresh
+	// token in the environment will have been used up.
+	token := &WithingsToken{AccessToken: conf.WithingsAccessToken, RefreshToken: conf.WithingsRefreshToken}
+	tokenPath := withingsTokenPath(targetPath)
+
+	if _, err := os.Stat(tokenPath); err == nil {
+		if err := readTOMLFile(tokenPath, token); err != nil {
+			return err
+		}
+
+		logger.Infof("(withings) Using tokens saved to '%v'", tokenPath)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	refresh := func() error {
+		if token.RefreshToken == "" || conf.WithingsClientID == "" || conf.WithingsClientSecret == "" {
+			return fmt.Errorf("access token expired, and can't be refreshed without WITHINGS_CLIENT_ID, WITHINGS_CLIENT_SECRET, and WITHINGS_REFRESH_TOKEN (see `qself sync-withings --help`)")
+		}
+
+		logger.Infof("(withings) Refreshing access token")
+
+		newToken, err := refreshWithingsToken(ctx, &conf, client, token.RefreshToken)
+		if err != nil {
+			return err
+		}
+
+		token = newToken
+
+		logger.Infof("(withings) Saving refreshed tokens to '%v'", tokenPath)
+		return writeWithingsToken(tokenPath, token)
+	}
+
+	var existingMeasurements []*WithingsMeasurement
+	var startDate time.Time
+
+	if _, err := os.Stat(targetPath); err == nil {
+		var existingWithingsDB WithingsDB
+		if err := readTOMLFile(targetPath, &existingWithingsDB); err != nil {
+			return err
+		}
+
+		existingMeasurements = existingWithingsDB.Measurements
+		if len(existingMeasurements) > 0 {
+			startDate = existingMeasurements[len(existingMeasurements)-1].Date.AddDate(0, 0, -withingsRefetchDays)
+		}
+
+		logger.Infof("(withings) Found existing '%v'; running incremental update", targetPath)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(withings) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	if !token.ExpiresAt.IsZero() && time.Now().After(token.ExpiresAt) {
+		if err := refresh(); err != nil {
+			return err
+		}
+	}
+
+	var groups []*WithingsAPIMeasureGroup
+	var offset int
+	var refreshed bool
+
+	for {
+		logger.Infof("(withings) Paging; num measure groups accumulated: %v, offset: %v", len(groups), offset)
+
+		v := url.Values{}
+		v.Set("action", "getmeas")
+		v.Set("category", "1") // real measurements rather than goals
+		v.Set("meastypes", strings.Join([]string{
+			strconv.Itoa(withingsMeasureTypeWeight),
+			strconv.Itoa(withingsMeasureTypeFatRatio),
+			strconv.Itoa(withingsMeasureTypeHeartPulse),
+			strconv.Itoa(withingsMeasureTypeMuscleMass),
+			strconv.Itoa(withingsMeasureTypeHydration),
+			strconv.Itoa(withingsMeasureTypeBoneMass),
+		}, ","))
+		if offset > 0 {
+			v.Set("offset", strconv.Itoa(offset))
+		}
+		if !startDate.IsZero() {
+			v.Set("startdate", strconv.FormatInt(startDate.Unix(), 10))
+		}
+
+		var body WithingsAPIMeasuresBody
+		err := fetchWithings(ctx, client, token.AccessToken, "/measure", v, &body)
+
+		// Tokens from the environment have no known expiry, so they're only
+		// found to have expired once Withings rejects them.
+		if errors.Is(err, errWithingsInvalidToken) && !refreshed {
+			if err := refresh(); err != nil {
+				return err
+			}
+			refreshed = true
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		groups = append(groups, body.MeasureGroups...)
+
+		if body.More == 0 {
+			break
+		}
+		offset = body.Offset
+	}
+
+	measurements := mergeWithingsMeasurements(withingsMeasurementsFromAPIGroups(groups), existingMeasurements)
+
+	logger.Infof("(withings) Writing %v measurement(s) to '%s'", len(measurements), targetPath)
+
+	withingsDB := &WithingsDB{Measurements: measurements}
+	if err := writeTOMLFile(targetPath, withingsDB); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func syncTwitter(ctx context.Context, targetPath string, opts *SyncTwitterOptions) error {
+	if err := checkMergeStrategy(opts.MergeStrategy); err != nil {
+		return err
+	}
+
+	if err := checkSortOrder(opts.Sort); err != nil {
+		return err
+	}
+
+	if opts.CheckURLs && opts.URLCheckConcurrency < 1 {
+		return fmt.Errorf("URL check concurrency should be at least 1 (was %v)", opts.URLCheckConcurrency)
+	}
+
+	if opts.IncludeLikes && opts.LikesPath == "" {
+		return fmt.Errorf("--twitter-likes-path is required with --twitter-include-likes")
+	}
+
+	if opts.EngagementWeights != nil && len(opts.EngagementWeights) != len(defaultEngagementWeights) {
+		return fmt.Errorf("--engagement-weights should have %v values (likes,retweets,replies,bookmarks), but had %v",
+			len(defaultEngagementWeights), len(opts.EngagementWeights))
+	}
+
+	var filterRE *regexp.Regexp
+	if opts.FilterRegexp != "" {
+		var err error
+		filterRE, err = regexp.Compile(opts.FilterRegexp)
+		if err != nil {
+			return fmt.Errorf("error compiling tweet filter regexp: %w", err)
+		}
+	}
+
+	var rangeEnd, rangeStart time.Time
+	if opts.RangeEnd != "" {
+		var err error
+		rangeEnd, err = time.Parse(time.RFC3339, opts.RangeEnd)
+		if err != nil {
+			return fmt.Errorf("error parsing --tweet-end: %w", err)
+		}
+	}
+	if opts.RangeStart != "" {
+		var err error
+		rangeStart, err = time.Parse(time.RFC3339, opts.RangeStart)
+		if err != nil {
+			return fmt.Errorf("error parsing --tweet-start: %w", err)
+		}
+	}
+	if !rangeEnd.IsZero() && rangeEnd.Before(rangeStart) {
+		return fmt.Errorf("--tweet-end should be after --tweet-start")
+	}
+	if opts.RangeSourcePath != "" {
+		if rangeEnd.IsZero() && rangeStart.IsZero() {
+			return fmt.Errorf("--tweet-range-source needs --tweet-start or --tweet-end")
+		}
+		if filepath.Clean(opts.RangeSourcePath) == filepath.Clean(targetPath) {
+			return fmt.Errorf("--tweet-range-source should be a different file than the one being synced")
+		}
+	}
+
+	var conf TwitterConf
+	if err := envdecode.Decode(&conf); err != nil {
+		return fmt.Errorf("error decoding conf from env: %v", err)
+	}
+
+	httpClient := newTwitterHTTPClient(ctx, &conf)
+
+	client := twitter.NewClient(httpClient)
+
+	user, _, err := client.Users.Show(&twitter.UserShowParams{
+		ScreenName: conf.TwitterUser,
+	})
+	if err != nil {
+		return fmt.Errorf("error getting user '%v': %w", conf.TwitterUser, err)
+	}
+	logger.Infof("(twitter) User ID: %v", user.ID)
+
+	var tweets []*Tweet
+	var numSkipped int
+
+	var maxTweetID, sinceTweetID int64
+
+	// With a range, paging can start and end at tweets in the source file
+	// just outside of it instead of going through the whole timeline. The
+	// data file being synced can't be used for this because tweets outside
+	// the range are removed from it.
+	var sourceTweets []*Tweet
+	if opts.RangeSourcePath != "" {
+		sourceTweetDB, err := readTweetDB(opts.RangeSourcePath)
+		if err != nil {
+			return err
+		}
+
+		sinceTweetID, maxTweetID = tweetRangeAnchorIDs(sourceTweetDB.Tweets, rangeStart, rangeEnd)
+		logger.Infof("(twitter) Paging within range; since tweet ID: %v, max tweet ID: %v", sinceTweetID, maxTweetID)
+
+		sourceTweets, _ = filterTweetsByRange(sourceTweetDB.Tweets, rangeStart, rangeEnd)
+	}
+
+	for {
+		logger.Infof("(twitter) Paging; num tweets accumulated: %v, max tweet ID: %v", len(tweets), maxTweetID)
+
+		apiTweets, _, err := client.Timelines.UserTimeline(&twitter.UserTimelineParams{
+			Count:     200, // maximum 200
+			MaxID:     maxTweetID,
+			SinceID:   sinceTweetID,
+			TweetMode: "extended", // non-truncated tweet content
+			UserID:    user.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("error listing user timeline: %w", err)
+		}
+
+		processedAnyTweets := false
+
+		for _, apiTweet := range apiTweets {
+			// Each page contains the last item from the previous page, so skip
+			// that
+			if maxTweetID != 0 && apiTweet.ID >= maxTweetID {
+				continue
+			}
+
+			processedAnyTweets = true
+
+			tweet, err := tweetFromAPITweet(&apiTweet, opts)
+			if err != nil {
+				logger.Errorf("(twitter) Skipping tweet %v: %v", apiTweet.ID, err)
+				numSkipped++
+				continue
+			}
+
+			if tweet.IsAd && !opts.IncludeAds {
+				logger.Infof("(twitter) Skipping promoted tweet %v", tweet.ID)
+				continue
+			}
+
+			if len(tweet.WithheldInCountries) > 0 {
+				logger.Infof("(twitter) Tweet %v withheld in countries: %v",
+					tweet.ID, strings.Join(tweet.WithheldInCountries, ", "))
+			}
+
+			tweets = append(tweets, tweet)
+		}
+
+		// No suitable tweets on the page to process which means that we're
+		// done pagination. Break out of the loop and finish.
+		if !processedAnyTweets {
+			break
+		}
+
+		maxTweetID = apiTweets[len(apiTweets)-1].ID
+	}
+
+	// Filter before anything else so that no more requests are made for
+	// tweets that won't be kept.
+	var numFiltered int
+	if filterRE != nil {
+		tweets, numFiltered = filterTweets(tweets, filterRE)
+	}
+
+	if opts.ExpandURLs {
+		logger.Warnf("(twitter) Expanding URLs; stored tweet text will differ from the API's")
+		expandTweetURLs(tweets)
+	}
+
+	if opts.ComputeEngagement {
+		computeLikesByFollowers(tweets, user.FollowersCount)
+	}
+
+	if opts.APIV2 {
+		for i := 0; i < len(tweets); i += twitterAPIV2MaxIDs {
+			end := i + twitterAPIV2MaxIDs
+			if end > len(tweets) {
+				end = len(tweets)
+			}
+
+			logger.Infof("(twitter) Fetching v2 metrics; num tweets enriched: %v", i)
+
+			apiV2Tweets, err := fetchTwitterAPIV2Tweets(ctx, httpClient, tweets[i:end])
+			if err != nil {
+				return err
+			}
+
+			applyTwitterAPIV2Annotations(tweets[i:end], apiV2Tweets)
+			applyTwitterAPIV2Metrics(tweets[i:end], apiV2Tweets)
+		}
+
+		// Replies and bookmarks weren't known when scores were first
+		// computed, so compute them again now that they are.
+		for _, tweet := range tweets {
+			tweet.EngagementScore = computeEngagementScore(tweet, opts.EngagementWeights)
+		}
+	}
+
+	if opts.FetchCards {
+		for i := 0; i < len(tweets); i += twitterLookupMaxIDs {
+			end := i + twitterLookupMaxIDs
+			if end > len(tweets) {
+				end = len(tweets)
+			}
+
+			logger.Infof("(twitter) Fetching cards; num tweets enriched: %v", i)
+
+			apiCardTweets, err := fetchTwitterAPICards(ctx, httpClient, tweets[i:end])
+			if err != nil {
+				return err
+			}
+
+			applyTwitterAPICards(tweets[i:end], apiCardTweets)
+		}
+	}
+
+	// Twitter returns a maximum of ~3200 tweets ever, so try to maintain older
+	// ones by merging any existing data that we already have.
+	var existingTweetDB *TweetDB
+	if _, err := os.Stat(targetPath); err == nil {
+		existingTweetDB, err = readTweetDB(targetPath)
+		if err != nil {
+			return err
+		}
+	} else if os.IsNotExist(err) {
+		logger.Infof("(twitter) Existing DB at '%v' not found; starting fresh", targetPath)
+	} else {
+		return err
+	}
+
+	// Tweets from the range source are merged as though they'd been stored,
+	// so that a range older than the API goes back still gets them.
+	if len(sourceTweets) > 0 {
+		if existingTweetDB == nil {
+			existingTweetDB = &TweetDB{}
+		}
+
+		storedIDs := make(map[int64]bool, len(existingTweetDB.Tweets))
+		for _, tweet := range existingTweetDB.Tweets {
+			storedIDs[tweet.ID] = true
+		}
+		for _, tweet := range sourceTweets {
+			if !storedIDs[tweet.ID] {
+				existingTweetDB.Tweets = append(existingTweetDB.Tweets, tweet)
+			}
+		}
+	}
+
+	if existingTweetDB != nil {
+		if filterRE != nil {
+			var numExistingFiltered int
+			existingTweetDB.Tweets, numExistingFiltered = filterTweets(existingTweetDB.Tweets, filterRE)
+			numFiltered += numExistingFiltered
+		}
+
+		logger.Infof("(twitter) Found existing '%v'; attempting merge of %v existing tweet(s) with %v current tweet(s)",
+			targetPath, len(existingTweetDB.Tweets), len(tweets))
+
+		// Existing tweets are expanded as well, both so that tweets the API
+		// no longer returns get it, and so that an expanded tweet's text
+		// isn't mistaken for a change when merging.
+		if opts.ExpandURLs {
+			expandTweetURLs(existingTweetDB.Tweets)
+		}
+
+		// Done before merging so that differing statuses don't stop trivial
+		// changes from being recognized.
+		copyTweetCards(tweets, existingTweetDB.Tweets)
+		copyTweetQuotes(tweets, existingTweetDB.Tweets)
+		copyTweetURLStatuses(tweets, existingTweetDB.Tweets)
+
+		tweets = mergeTweets(tweets, existingTweetDB.Tweets, opts)
+	} else {
+		sortTweets(tweets, opts.Sort)
+	}
+
+	// Done after merging so that threads can be followed back to roots that
+	// are older than the API returns.
+	setConversationRootTexts(tweets, conf.TwitterUser)
+
+	if filterRE != nil {
+		logger.Infof("(twitter) Filtered %v tweet(s) matching --tweet-filter-regexp", numFiltered)
+	}
+
+	// Done after merging rather than before so that all tweets are still
+	// considered, and one that's newly crossed a threshold gets added.
+	if opts.MinFavorites > 0 || opts.MinRetweets > 0 {
+		var numBelowMin int
+		tweets, numBelowMin = filterTweetsByEngagement(tweets, opts.MinFavorites, opts.MinRetweets)
+		logger.Infof("(twitter) Filtered %v tweet(s) below --tweet-min-favorites or --tweet-min-retweets", numBelowMin)
+	}
+
+	// Like the filters above, this is done after merging so that tweets
+	// stored by previous syncs are left out as well.
+	if !rangeEnd.IsZero() || !rangeStart.IsZero() {
+		var numOutOfRange int
+		tweets, numOutOfRange = filterTweetsByRange(tweets, rangeStart, rangeEnd)
+		logger.Infof("(twitter) Filtered %v tweet(s) outside --tweet-start and --tweet-end", numOutOfRange)
+	}
+
+	if opts.CheckURLs {
+		urlCheckClient := newHTTPClient()
+		urlCheckClient.Timeout = urlCheckTimeout
+
+		if err := checkTweetURLs(ctx, urlCheckClient, tweets, opts.URLCheckConcurrency); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("(twitter) Writing %v tweet(s) to '%s'", len(tweets), targetPath)
+
+	tweetDB := &TweetDB{Tweets: tweets, Version: SchemaVersion}
+	if err := writeTOMLFile(targetPath, tweetDB); err != nil {
+		return err
+	}
+
+	if opts.IncludeLikes {
+		numLikesSkipped, err := syncTwitterLikes(ctx, client, user.ID, opts.LikesPath, opts)
+		if err != nil {
+			return err
+		}
+		numSkipped += numLikesSkipped
+	}
+
+	if numSkipped > 0 {
+		logger.Warnf("(twitter) Skipped %v tweet(s) that couldn't be processed", numSkipped)
+
+		if opts.Strict {
+			return fmt.Errorf("skipped %v tweet(s) in strict mode", numSkipped)
+		}
+	}
+
+	return nil
+}
+
+// Syncs tweets that the user has liked to targetPath, returning the number of
+// tweets that were skipped because they couldn't be processed. Liked tweets
+// that are no longer returned, usually because they were deleted by their
+// author, are kept from the existing data file.
+func syncTwitterLikes(ctx context.Context, client *twitter.Client, userID int64, targetPath string, opts *SyncTwitterOptions) (int, error) {
+	var likes []*LikedTweet
+	var numSkipped int
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	var maxTweetID int64 = 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		logger.Infof("(twitter) Paging likes; num likes accumulated: %v, max tweet ID: %v", len(likes), maxTweetID)
+
+		apiTweets, resp, err := client.Favorites.List(&twitter.FavoriteListParams{
+			Count:     200, // maximum 200
+			MaxID:     maxTweetID,
+			TweetMode: "extended", // non-truncated tweet content
+			UserID:    userID,
+		})
+		if err != nil {
+			// Twitter may respond with a 404 when a liked tweet has been
+			// deleted by its author. Stop paging instead of failing the
+			// sync. Likes that weren't fetched are kept from the existing
+			// data file.
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				logger.Warnf("(twitter) Likes page not found; stopping paging: %v", err)
+				break
+			}
+
+			return 0, fmt.Errorf("error listing likes: %w", err)
+		}
+
+		processedAnyTweets := false
+
+		for _, apiTweet := range apiTweets {
+			// As with the user timeline, each page contains the last item
+			// from the previous page.
+			if maxTweetID != 0 && apiTweet.ID >= maxTweetID {
+				continue
+			}
+
+			processedAnyTweets = true
+
+			tweet, err := tweetFromAPITweet(&apiTweet, opts)
+			if err != nil {
+				logger.Errorf("(twitter) Skipping liked tweet %v: %v", apiTweet.ID, err)
+				numSkipped++
+				continue
+			}
+
+			like := &LikedTweet{Tweet: *tweet, LikedAt: now}
+			if apiTweet.User != nil {
+				like.User = apiTweet.User.ScreenName
+				like.UserID = apiTweet.User.ID
+			}
+
+			likes = append(likes, like)
+		}
+
+		if !processedAnyTweets {
+			break
+		}
+
+		maxTweetID = apiTweets[len(apiTweets)-1].ID
+	}
+
+	if _, err := os.Stat(targetPath); err == nil {
+		existingLikedTweetDB, err := readLikedTweetDB(targetPath)
+		if err != nil {
+			return 0, err
+		}
+
+		logger.Infof("(twitter) Found existing '%v'; attempting merge of %v existing like(s) with %v current like(s)",
+			targetPath, len(existingLikedTweetDB.Tweets), len(likes))
+
+		likes = mergeLikedTweets(likes, existingLikedTweetDB.Tweets, opts)
+	} else if os.IsNotExist(err) {
+		logger.Infof("(twitter) Existing DB at '%v' not found; starting fresh", targetPath)
+
+		likes = mergeLikedTweets(likes, nil, opts)
+	} else {
+		return 0, err
+	}
+
+	logger.Infof("(twitter) Writing %v liked tweet(s) to '%s'", len(likes), targetPath)
+
+	likedTweetDB := &LikedTweetDB{Tweets: likes, Version: SchemaVersion}
+	if err := writeTOMLFile(targetPath, likedTweetDB); err != nil {
+		return 0, err
+	}
+
+	return numSkipped, nil
+}
+
+func tweetEntitiesMediaFromAPIMedia(media *twitter.MediaEntity) *TweetEntitiesMedia {
+	entitiesMedia := &TweetEntitiesMedia{
+		Height: media.Sizes.Large.Height,
+		ID:     media.ID,
+		Type:   media.Type,
+		URL:    media.MediaURLHttps,
+		Width:  media.Sizes.Large.Width,
+	}
+
+	if media.Type == "animated_gif" || media.Type == "video" {
+		for _, variant := range media.VideoInfo.Variants {
+			entitiesMedia.Variants = append(entitiesMedia.Variants, &TweetEntitiesMediaVariant{
+				Bitrate:     variant.Bitrate,
+				ContentType: variant.ContentType,
+				URL:         variant.URL,
+			})
+		}
+
+		sort.SliceStable(entitiesMedia.Variants, func(i, j int) bool {
+			return entitiesMedia.Variants[i].Bitrate > entitiesMedia.Variants[j].Bitrate
+		})
+
+		if len(entitiesMedia.Variants) > 0 {
+			entitiesMedia.ThumbnailURL = entitiesMedia.URL
+			entitiesMedia.URL = entitiesMedia.Variants[0].URL
+		}
+	}
+
+	return entitiesMedia
+}
+
+func tweetFromAPITweet(tweet *twitter.Tweet, opts *SyncTwitterOptions) (*Tweet, error) {
+	// Tweet's ID. Always keep the identifier for the original tweet, even in
+	// the event of a retweet where we rewrite most of everything.
+	id := tweet.ID
+
+	// Content of the tweet. May be rewritten for a retweet.
+	text := tweet.FullText
+
+	// Countries where the tweet is withheld. Like the ID, keep this from the
+	// original tweet rather than a retweeted status.
+	withheldInCountries := tweet.WithheldInCountries
+
+	// Twitter doesn't flag promoted tweets directly, but they're posted from
+	// its ads tooling and carry scopes that ordinary tweets don't.
+	isAd := strings.Contains(tweet.Source, "Promoted") || len(tweet.Scopes) > 0
+
+	var entities *TweetEntities
+
+	createdAt, err := tweet.CreatedAtTime()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing created at time: %w", err)
+	}
+
+	// Do replies before retweets because strangely, some retweets show up as
+	// replies when you use the retweeted status. Prefer not to have that.
+	var reply *TweetReply
+	if tweet.InReplyToStatusID != 0 {
+		reply = &TweetReply{
+			StatusID: tweet.InReplyToStatusID,
+			User:     tweet.InReplyToScreenName,
+			UserID:   tweet.InReplyToUserID,
+		}
+	}
+
+	// Geo-tagged tweets may carry an exact point, a place (which is only a
+	// bounding box), or both. Place information is kept regardless, but an
+	// exact point is preferred over the center of the bounding box.
+	var geo *TweetGeo
+	if tweet.Coordinates != nil || tweet.Place != nil {
+		geo = &TweetGeo{}
+
+		if place := tweet.Place; place != nil {
+			geo.Country = place.Country
+			geo.CountryCode = place.CountryCode
+			geo.PlaceName = place.FullName
+			geo.PlaceType = place.PlaceType
+
+			if place.BoundingBox != nil {
+				geo.Latitude, geo.Longitude = boundingBoxCenter(place.BoundingBox)
+			}
+		}
+
+		// Twitter orders coordinates as longitude then latitude.
+		if tweet.Coordinates != nil {
+			geo.Latitude = tweet.Coordinates.Coordinates[1]
+			geo.Longitude = tweet.Coordinates.Coordinates[0]
+		}
+	}
+
+	// We do user mentions early because we want to
+	if len(tweet.Entities.UserMentions) > 0 {
+		if entities == nil {
+			entities = &TweetEntities{}
+		}
+
+		for _, userMention := range tweet.Entities.UserMentions {
+			entities.UserMentions = append(entities.UserMentions, &TweetEntitiesUserMention{
+				User:   userMention.ScreenName,
+				UserID: userMention.ID,
+			})
+		}
+	}
+
+	var retweet *TweetRetweet
+	if status := tweet.RetweetedStatus; status != nil {
+		retweet = &TweetRetweet{
+			StatusID: status.ID,
+			User:     status.User.ScreenName,
+			UserID:   status.User.ID,
+		}
+
+		// The standard text fields still truncates a retweeted status to 140
+		// characters (for ... reasons?), so we need to grab it elsewhere. Do
+		// some reconstruction to make it look more like a retweet.
+		text = fmt.Sprintf("RT @%s: %s", status.User.ScreenName, status.FullText)
+
+		// The retweet becomes the original tweet so that we can save its
+		// mentions, media entities, and URLs instead of the original's.
+		tweet = status
+
+		// Do user mentions again to pick up any others that might've been in
+		// the retweet.
+		for _, userMention := range tweet.Entities.UserMentions {
+			entities.UserMentions = append(entities.UserMentions, &TweetEntitiesUserMention{
+				User:   userMention.ScreenName,
+				UserID: userMention.ID,
+			})
+		}
+
+		// Unique in case of repeats in both sets.
+		if entities != nil {
+			entities.UserMentions = sliceUniq(entities.UserMentions, func(i int) interface{} {
+				return entities.UserMentions[i].UserID
+			}).([]*TweetEntitiesUserMention)
+		}
+	}
+
+	// Done after retweets so that a retweeted quote tweet stores the tweet
+	// that it quotes. The API leaves out quoted tweets that have been deleted
+	// (or made private), leaving only their ID.
+	var quote *TweetQuote
+	if status := tweet.QuotedStatus; status != nil {
+		quotedCreatedAt, err := status.CreatedAtTime()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing created at time of quoted tweet %v: %w", status.ID, err)
+		}
+
+		quote = &TweetQuote{
+			OriginalCreatedAt: quotedCreatedAt.UTC(),
+			OriginalText:      sanitizeTweetText(status.FullText, !opts.NoHTMLDecode),
+			StatusID:          status.ID,
+		}
+
+		if status.User != nil {
+			quote.User = status.User.ScreenName
+			quote.UserID = status.User.ID
+		}
+	} else if tweet.QuotedStatusID != 0 {
+		logger.Infof("(twitter) Quoted tweet %v of tweet %v is missing; it may have been deleted",
+			tweet.QuotedStatusID, id)
+
+		quote = &TweetQuote{StatusID: tweet.QuotedStatusID}
+	}
+
+	// The Twitter API is weird. "Extended" entities and entities are almost
+	// the same, except that the extended version will contain more than one
+	// photo where multiple were included, and it will assign a type that isn't
+	// "photo" for videos.
+	//
+	// Twitter doesn't go into specifics on why extended is even a thing, but
+	// it's probably related to the fact that features like videos and multiple
+	// photo uploads were added later on.
+	if tweet.ExtendedEntities != nil && len(tweet.ExtendedEntities.Media) > 0 {
+		if entities == nil {
+			entities = &TweetEntities{}
+		}
+
+		for _, media := range tweet.ExtendedEntities.Media {
+			entities.Medias = append(entities.Medias, tweetEntitiesMediaFromAPIMedia(&media))
+		}
+	} else if len(tweet.Entities.Media) > 0 {
+		if entities == nil {
+			entities = &TweetEntities{}
+		}
+
+		for _, media := range tweet.Entities.Media {
+			entities.Medias = append(entities.Medias, tweetEntitiesMediaFromAPIMedia(&media))
+		}
+	}
+
+	if len(tweet.Entities.Urls) > 0 {
+		if entities == nil {
+			entities = &TweetEntities{}
+		}
+
+		for _, url := range tweet.Entities.Urls {
+			entities.URLs = append(entities.URLs, &TweetEntitiesURL{
+				DisplayURL:  url.DisplayURL,
+				ExpandedURL: url.ExpandedURL,
+				URL:         url.URL,
+			})
+		}
+	}
+
+	text = sanitizeTweetText(text, !opts.NoHTMLDecode)
+
+	newTweet := &Tweet{
+		CreatedAt:     createdAt,
+		CreatedAtUnix: createdAt.Unix(),
+		Entities:      entities,
+		FavoriteCount: tweet.FavoriteCount,
+		Geo:           geo,
+		ID:            id,
+		IsAd:          isAd,
+		Quote:         quote,
+		Reply:         reply,
+		Retweet:       retweet,
+		RetweetCount:  tweet.RetweetCount,
+		Text:          text,
+		TextHashSHA1:  hashSHA1(text),
+
+		WithheldInCountries: withheldInCountries,
+	}
+
+	if !opts.NoNormalizeText {
+		if normalizedText := norm.NFKC.String(text); normalizedText != text {
+			newTweet.NormalizedText = normalizedText
+		}
+	}
+
+	// Reply and bookmark counts are only known with --twitter-api-v2, in
+	// which case the score is computed again after they've been fetched.
+	newTweet.EngagementScore = computeEngagementScore(newTweet, opts.EngagementWeights)
+
+	return newTweet, nil
+}
+
+// Returns the string value of the given binding of a Twitter Card, or an
+// empty string if the card doesn't have one.
+func twitterAPICardStringValue(card *TwitterAPICard, name string) string {
+	value, ok := card.BindingValues[name]
+	if !ok || value.Type != "STRING" {
+		return ""
+	}
+
+	return value.StringValue
+}
+
+// Finds the IDs of the stored tweets on either side of a range to use as
+// since_id and max_id when paging through the timeline. Tweet IDs increase
+// with the time that tweets were created, so these are found with a binary
+// search over the stored tweets in order of ID. sinceID is the newest tweet
+// created before start, and maxID the oldest created after end. Either is
+// zero if there's no such tweet (or its side of the range is open).
+func tweetRangeAnchorIDs(tweets []*Tweet, start, end time.Time) (sinceID, maxID int64) {
+	sorted := make([]*Tweet, len(tweets))
+	copy(sorted, tweets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	if !start.IsZero() {
+		i := sort.Search(len(sorted), func(i int) bool { return !sorted[i].CreatedAt.Before(start) })
+		if i > 0 {
+			sinceID = sorted[i-1].ID
+		}
+	}
+
+	if !end.IsZero() {
+		i := sort.Search(len(sorted), func(i int) bool { return sorted[i].CreatedAt.After(end) })
+		if i < len(sorted) {
+			maxID = sorted[i].ID
+		}
+	}
+
+	return sinceID, maxID
+}
+
+// Returns a tweet's TextHashSHA1, computing it for tweets that were stored
+// before the hash was.
+func tweetTextHashSHA1(tweet *Tweet) string {
+	if tweet.TextHashSHA1 != "" {
+		return tweet.TextHashSHA1
+	}
+	return hashSHA1(tweet.Text)
+}
+
+func validate(w io.Writer, opts *ValidateOptions) error {
+	var numWarnings int
+
+	if opts.GoodreadsPath != "PATH" {
+		readingDB, err := readReadingDB(opts.GoodreadsPath)
+		if err != nil {
+			return err
+		}
+
+		for _, warning := range validateReadings(readingDB.Readings) {
+			fmt.Fprintf(w, "(goodreads) %s\n", warning)
+			numWarnings++
+		}
+	}
+
+	if numWarnings == 0 {
+		fmt.Fprintf(w, "No problems found\n")
+	}
+
+	return nil
+}
+
+// Checks readings for likely problems, returning a warning for each one
+// that's found.
+func validateReadings(readings []*Reading) []string {
+	var warnings []string
+
+	for _, reading := range readings {
+		if reading.Abandoned && reading.AbandonedAt.IsZero() {
+			warnings = append(warnings, fmt.Sprintf("Review %v ('%s') is abandoned, but has no abandoned at time (its shelf has no read date)",
+				reading.ReviewID, reading.Title))
+		}
+
+		if reading.Format != "" && !goodreadsKnownFormats[reading.Format] {
+			warnings = append(warnings, fmt.Sprintf("Review %v ('%s') has unknown format '%s'",
+				reading.ReviewID, reading.Title, reading.Format))
+		}
+
+		if reading.Language != "" && !iso6391Codes[reading.Language] {
+			warnings = append(warnings, fmt.Sprintf("Review %v ('%s') has language '%s', which isn't an ISO 639-1 code",
+				reading.ReviewID, reading.Title, reading.Language))
+		}
+
+		if !reading.StartedAt.IsZero() && !reading.ReadAt.IsZero() && reading.StartedAt.After(reading.ReadAt) {
+			warnings = append(warnings, fmt.Sprintf("Review %v ('%s') was started at %s, after it was read at %s",
+				reading.ReviewID, reading.Title, reading.StartedAt.Format("2006-01-02"), reading.ReadAt.Format("2006-01-02")))
+		}
+
+		if reading.NumPages != 0 && reading.WordCount == 0 {
+			warnings = append(warnings, fmt.Sprintf("Review %v ('%s') has %v pages, but no word count (it may have been synced by an older version of qself)",
+				reading.ReviewID, reading.Title, reading.NumPages))
+		}
+	}
+
+	return warnings
+}
+
+// Goals are merged on slug, with the API's version of a goal taking precedence.
+// Data points are merged on ID so that ones deleted from Beeminder are kept,
+// and goals that are no longer returned by the API (e.g. because they were
+// archived) are kept as well. Data points are deduplicated before they're
+// sorted, like in mergeTogglEntries, because their timestamps can be edited.
+func mergeBeeminderGoals(apiGoals, existingGoals []*BeeminderGoal) []*BeeminderGoal {
+	existingBySlug := make(map[string]*BeeminderGoal)
+	for _, goal := range existingGoals {
+		existingBySlug[goal.Slug] = goal
+	}
+
+	for _, goal := range apiGoals {
+		existing, ok := existingBySlug[goal.Slug]
+		if !ok {
+			continue
+		}
+
+		s := append(goal.DataPoints, existing.DataPoints...)
+		dataPoints := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*BeeminderDataPoint)
+		sort.SliceStable(dataPoints, func(i, j int) bool { return dataPoints[i].Timestamp.Before(dataPoints[j].Timestamp) })
+		goal.DataPoints = dataPoints
+	}
+
+	s := append(apiGoals, existingGoals...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Slug < s[j].Slug })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].Slug }).([]*BeeminderGoal)
+	return sMerged
+}
+
+// Unlike most merges, duplicates are removed before sorting so that the API's
+// version of an event is kept even if it's been moved earlier.
+func mergeCalEvents(apiEvents, existingEvents []*CalEvent) []*CalEvent {
+	s := append(apiEvents, existingEvents...)
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*CalEvent)
+	sort.SliceStable(sMerged, func(i, j int) bool { return sMerged[i].StartAt.Before(sMerged[j].StartAt) })
+	return sMerged
+}
+
+func mergeChessGames(apiGames, existingGames []*ChessGame) []*ChessGame {
+	s := append(apiGames, existingGames...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].PlayedAt.Before(s[j].PlayedAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*ChessGame)
+	return sMerged
+}
+
+// Entries are deduplicated before they're sorted, like in mergeTogglEntries.
+func mergeClockifyEntries(apiEntries, existingEntries []*ClockifyEntry) []*ClockifyEntry {
+	s := append(apiEntries, existingEntries...)
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*ClockifyEntry)
+	sort.SliceStable(sMerged, func(i, j int) bool { return sMerged[i].StartedAt.Before(sMerged[j].StartedAt) })
+	return sMerged
+}
+
+func mergeCloudflareDays(apiDays, existingDays []*CloudflareDay) []*CloudflareDay {
+	s := append(apiDays, existingDays...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Date.Before(s[j].Date) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].Date.Format(cloudflareDateFormat) }).([]*CloudflareDay)
+	return sMerged
+}
+
+func mergePWSDayRecords(apiDays, existingDays []*PWSDayRecord) []*PWSDayRecord {
+	s := append(apiDays, existingDays...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Date.Before(s[j].Date) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].Date.Format(wuDateFormat) }).([]*PWSDayRecord)
+	return sMerged
+}
+
+// Merges days from the API with existing ones. Attribute values are merged
+// individually so that a sync limited to some attributes with
+// --exist-attributes doesn't drop the others, with the API's values
+// preferred.
+func mergeExistDays(apiDays, existingDays []*ExistDay) []*ExistDay {
+	// Keyed by formatted date rather than time.Time since times decoded from
+	// TOML may be in a different (but equivalent) location.
+	daysByDate := make(map[string]*ExistDay, len(existingDays))
+	for _, day := range existingDays {
+		daysByDate[day.Date.Format(existDateFormat)] = day
+	}
+
+	for _, apiDay := range apiDays {
+		date := apiDay.Date.Format(existDateFormat)
+
+		existingDay, ok := daysByDate[date]
+		if !ok {
+			daysByDate[date] = apiDay
+			continue
+		}
+
+		attributes := make(map[string]interface{}, len(existingDay.Attributes)+len(apiDay.Attributes))
+		for name, value := range existingDay.Attributes {
+			attributes[name] = value
+		}
+		for name, value := range apiDay.Attributes {
+			attributes[name] = value
+		}
+		daysByDate[date] = &ExistDay{Attributes: attributes, Date: apiDay.Date}
+	}
+
+	days := make([]*ExistDay, 0, len(daysByDate))
+	for _, day := range daysByDate {
+		days = append(days, day)
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days
+}
+
+// Merges friends' readings on the combination of friend and review ID, since
+// a single review ID is only unique for a single friend's shelf. Readings are
+// ordered by friend, and then newest review first.
+func mergeFriendReadings(apiReadings, existingReadings []*FriendReading) []*FriendReading {
+	s := append(apiReadings, existingReadings...)
+	sort.SliceStable(s, func(i, j int) bool {
+		if s[i].FriendID != s[j].FriendID {
+			return s[i].FriendID < s[j].FriendID
+		}
+		return s[i].ReviewID > s[j].ReviewID
+	})
+	sMerged := sliceUniq(s, func(i int) interface{} { return [2]int{s[i].FriendID, s[i].ReviewID} }).([]*FriendReading)
+	return sMerged
+}
+
+// Merge two sets of readings together.
+//
+// The first slice should be new readings from the Goodreads API, the second
+// should be any existing readings. This matters because we remove any readings
+// in the existing set which are no longer in the API (because that means they
+// were deleted).
+//
+// The API's version of a reading is preferred, but a few things are carried
+// over from the existing one: review text and its sentiment when the API's
+// text is stale, sentiment through syncs without --compute-sentiment, Notes,
+// ChallengeIDs, and CoverLocalPath when the API's version doesn't have them,
+// RecommendedBy always, and the rating history.
+//
+// Existing readings missing from the API that keepMissing returns true for are
+// kept anyway, like those of reviews that were skipped because they couldn't
+// be processed. keepMissing may be nil.
+//
+// If conflictLog isn't nil, a JSON line describing each field that differed
+// between the two versions of a reading, and which version was chosen, is
+// written to it (see ReadingMergeConflict) so that merges can be audited.
+func mergeReadings(apiReadings, existingReadings []*Reading, order string, keepMissing func(reading *Reading) bool, conflictLog io.Writer) []*Reading {
+	var keptReadings []*Reading
+	if keepMissing != nil {
+		for _, reading := range existingReadings {
+			if keepMissing(reading) {
+				keptReadings = append(keptReadings, reading)
+			}
+		}
+	}
+
+	existingReadings = sliceKeepOnly(existingReadings, apiReadings,
+		func(i int) interface{} { return existingReadings[i].ReviewID },
+		func(i int) interface{} { return apiReadings[i].ReviewID },
+	).([]*Reading)
+	existingReadings = append(existingReadings, keptReadings...)
+	s := append(apiReadings, existingReadings...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].ReviewID < s[j].ReviewID })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ReviewID }).([]*Reading)
+
+	// The API's version of a reading is preferred, except for review text,
+	// which is only taken from the API if the review was edited since the
+	// existing version was stored (see readingReviewIsStale).
+	existingByReviewID := make(map[int]*Reading)
+	for _, reading := range existingReadings {
+		existingByReviewID[reading.ReviewID] = reading
+	}
+	now := time.Now().UTC()
+	for _, reading := range sMerged {
+		existing, ok := existingByReviewID[reading.ReviewID]
+		if !ok {
+			continue
+		}
+
+		if conflictLog != nil {
+			logReadingMergeConflicts(conflictLog, reading, existing)
+		}
+
+		if readingReviewIsStale(reading, existing) {
+			reading.MeanSentiment = existing.MeanSentiment
+			reading.MeanSentimentComputed = existing.MeanSentimentComputed
+			reading.Review = existing.Review
+			reading.UpdatedAt = existing.UpdatedAt
+		}
+
+		// Sentiment is only computed with --compute-sentiment, so keep it
+		// through syncs without the flag as long as the review is unchanged.
+		if !reading.MeanSentimentComputed && reading.Review == existing.Review {
+			reading.MeanSentiment = existing.MeanSentiment
+			reading.MeanSentimentComputed = existing.MeanSentimentComputed
+		}
+
+		if reading.Notes == "" {
+			reading.Notes = existing.Notes
+		}
+
+		reading.RecommendedBy = existing.RecommendedBy
+
+		if len(reading.ChallengeIDs) < 1 {
+			reading.ChallengeIDs = existing.ChallengeIDs
+		}
+
+		if reading.CoverLocalPath == "" {
+			reading.CoverLocalPath = existing.CoverLocalPath
+		}
+
+		reconcileRatingHistory(reading, existing, now)
+	}
+
+	sortReadings(sMerged, order)
+	return sMerged
+}
+
+// Writes a ReadingMergeConflict to w for each field that mergeReadings decides
+// between the API's and the existing version of a reading, and that differs
+// between them. Must be called before either version is modified.
+func logReadingMergeConflicts(w io.Writer, reading, existing *Reading) {
+	encoder := json.NewEncoder(w)
+
+	logConflict := func(field, apiValue, existingValue string, existingChosen bool) {
+		if apiValue == existingValue {
+			return
+		}
+
+		chosen := readingMergeChosenAPI
+		if existingChosen {
+			chosen = readingMergeChosenExisting
+		}
+
+		err := encoder.Encode(&ReadingMergeConflict{
+			APIValue:      apiValue,
+			Chosen:        chosen,
+			ExistingValue: existingValue,
+			Field:         field,
+			ReviewID:      reading.ReviewID,
+		})
+		if err != nil {
+			logger.Warnf("(goodreads) Couldn't write merge conflict for review %v: %v", reading.ReviewID, err)
+		}
+	}
+
+	logConflict("review", reading.Review, existing.Review,
+		readingReviewIsStale(reading, existing))
+	logConflict("notes", reading.Notes, existing.Notes,
+		reading.Notes == "")
+	logConflict("recommended_by", reading.RecommendedBy, existing.RecommendedBy,
+		true)
+	logConflict("challenge_ids", fmt.Sprint(reading.ChallengeIDs), fmt.Sprint(existing.ChallengeIDs),
+		len(reading.ChallengeIDs) < 1)
+	logConflict("cover_local_path", reading.CoverLocalPath, existing.CoverLocalPath,
+		reading.CoverLocalPath == "")
+}
+
+// Returns true if the API's version of a reading has review text that differs
+// from the existing version's, but the review hasn't been edited since the
+// existing version was stored. The API's text is stale in that case, and the
+// existing text is kept. Text that differs with a newer UpdatedAt is always
+// taken from the API because the user edited their review, as is text from
+// reviews that the API didn't return an UpdatedAt for.
+//
+// This means that changes to how review text is processed, like a different
+// sanitizer or --no-html-decode, only reach stored reviews when they're next
+// updated on Goodreads.
+func readingReviewIsStale(reading, existing *Reading) bool {
+	return reading.Review != existing.Review && !reading.UpdatedAt.IsZero() &&
+		!reading.UpdatedAt.After(existing.UpdatedAt)
+}
+
+// Carries a reading's rating history over from its existing version, adding
+// a point recorded at now if its rating has changed since the last one. The
+// first time a rating changes, the rating that it replaced is recorded too,
+// as of the last time the review was updated, since that's the best guess
+// available of when it was given.
+func reconcileRatingHistory(reading, existing *Reading, now time.Time) {
+	reading.RatingHistory = existing.RatingHistory
+
+	lastRating := existing.Rating
+	if len(existing.RatingHistory) > 0 {
+		lastRating = existing.RatingHistory[len(existing.RatingHistory)-1].Rating
+	}
+
+	if reading.Rating == lastRating {
+		return
+	}
+
+	if len(reading.RatingHistory) < 1 {
+		reading.RatingHistory = append(reading.RatingHistory,
+			&RatingPoint{Rating: existing.Rating, RecordedAt: existing.UpdatedAt})
+	}
+
+	reading.RatingHistory = append(reading.RatingHistory,
+		&RatingPoint{Rating: reading.Rating, RecordedAt: now})
+}
+
+// Photos are deduplicated before they're sorted, like in mergeTogglEntries,
+// because the time a photo was taken can be edited in Google Photos.
+func mergeGooglePhotos(apiPhotos, existingPhotos []*GooglePhoto) []*GooglePhoto {
+	s := append(apiPhotos, existingPhotos...)
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*GooglePhoto)
+	sort.SliceStable(sMerged, func(i, j int) bool { return sMerged[i].CreatedAt.Before(sMerged[j].CreatedAt) })
+	return sMerged
+}
+
+func mergeKoboAnnotations(dbAnnotations, existingAnnotations []*KoboAnnotation) []*KoboAnnotation {
+	s := append(dbAnnotations, existingAnnotations...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].CreatedAt.Before(s[j].CreatedAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*KoboAnnotation)
+	return sMerged
+}
+
+func mergeKoboSessions(dbSessions, existingSessions []*KoboSession) []*KoboSession {
+	s := append(dbSessions, existingSessions...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].StartedAt.Before(s[j].StartedAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*KoboSession)
+	return sMerged
+}
+
+// Merges liked tweets from the API with existing ones in the same way as
+// mergeTweets, so that trivial changes don't cause likes to be rewritten
+// either. Whichever version of a tweet is kept, it has the existing LikedAt
+// time so that it reflects when the like was first seen.
+func mergeLikedTweets(apiLikes, existingLikes []*LikedTweet, opts *SyncTwitterOptions) []*LikedTweet {
+	existingLikedAts := make(map[int64]time.Time, len(existingLikes))
+	for _, like := range existingLikes {
+		existingLikedAts[like.ID] = like.LikedAt
+	}
+
+	for _, like := range apiLikes {
+		if likedAt, ok := existingLikedAts[like.ID]; ok && !likedAt.IsZero() {
+			like.LikedAt = likedAt
+		}
+	}
+
+	// mergeTweets works on the tweets embedded in likes, which are mapped
+	// back to their likes afterwards.
+	likesByTweet := make(map[*Tweet]*LikedTweet, len(apiLikes)+len(existingLikes))
+	likedTweets := func(likes []*LikedTweet) []*Tweet {
+		tweets := make([]*Tweet, len(likes))
+		for i, like := range likes {
+			tweets[i] = &like.Tweet
+			likesByTweet[tweets[i]] = like
+		}
+		return tweets
+	}
+
+	tweets := mergeTweets(likedTweets(apiLikes), likedTweets(existingLikes), opts)
+
+	sMerged := make([]*LikedTweet, len(tweets))
+	for i, tweet := range tweets {
+		sMerged[i] = likesByTweet[tweet]
+	}
+	return sMerged
+}
+
+func mergeLinkedInArticles(apiArticles, existingArticles []*LinkedInArticle) []*LinkedInArticle {
+	s := append(apiArticles, existingArticles...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].PublishedAt.Before(s[j].PublishedAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*LinkedInArticle)
+	return sMerged
+}
+
+func mergeLinkedInPosts(apiPosts, existingPosts []*LinkedInPost) []*LinkedInPost {
+	s := append(apiPosts, existingPosts...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].CreatedAt.Before(s[j].CreatedAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*LinkedInPost)
+	return sMerged
+}
+
+func mergeMediumPosts(apiPosts, existingPosts []*MediumPost) []*MediumPost {
+	s := append(apiPosts, existingPosts...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].PublishedAt.Before(s[j].PublishedAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*MediumPost)
+	return sMerged
+}
+
+func mergeMonzoTransactions(apiTransactions, existingTransactions []*MonzoTransaction) []*MonzoTransaction {
+	s := append(apiTransactions, existingTransactions...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].CreatedAt.Before(s[j].CreatedAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*MonzoTransaction)
+	return sMerged
+}
+
+// Stays are deduplicated before they're sorted, like in mergeTogglEntries,
+// because a stay's start date can be edited on NomadList.
+func mergeNomadStays(apiStays, existingStays []*NomadStay) []*NomadStay {
+	s := append(apiStays, existingStays...)
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*NomadStay)
+	sort.SliceStable(sMerged, func(i, j int) bool { return sMerged[i].DateStart.Before(sMerged[j].DateStart) })
+	return sMerged
+}
+
+func mergeOpenWeatherDays(apiDays, existingDays []*OpenWeatherDay) []*OpenWeatherDay {
+	s := append(apiDays, existingDays...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Date.Before(s[j].Date) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].Date.Format(openWeatherDateFormat) }).([]*OpenWeatherDay)
+	return sMerged
+}
+
+func mergeOuraReadinessDays(apiDays, existingDays []*OuraReadinessDay) []*OuraReadinessDay {
+	s := append(apiDays, existingDays...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Date.Before(s[j].Date) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].Date.Format(ouraDateFormat) }).([]*OuraReadinessDay)
+	return sMerged
+}
+
+func mergeOuraSleepDays(apiDays, existingDays []*OuraSleepDay) []*OuraSleepDay {
+	s := append(apiDays, existingDays...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Date.Before(s[j].Date) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].Date.Format(ouraDateFormat) }).([]*OuraSleepDay)
+	return sMerged
+}
+
+func mergePodcastEpisodes(exportedEpisodes, existingEpisodes []*PodcastEpisode) []*PodcastEpisode {
+	s := append(exportedEpisodes, existingEpisodes...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].AddedAt.Before(s[j].AddedAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].EpisodeURL }).([]*PodcastEpisode)
+	return sMerged
+}
+
+// Activities are deduplicated before they're sorted, like in
+// mergeTogglEntries, since their start times can be edited.
+func mergeRunkeeperActivities(apiActivities, existingActivities []*RunkeeperActivity) []*RunkeeperActivity {
+	s := append(apiActivities, existingActivities...)
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].URI }).([]*RunkeeperActivity)
+	sort.SliceStable(sMerged, func(i, j int) bool { return sMerged[i].StartTime.Before(sMerged[j].StartTime) })
+	return sMerged
+}
+
+func mergeRunningWorkouts(exportedWorkouts, existingWorkouts []*RunningWorkout) []*RunningWorkout {
+	s := append(exportedWorkouts, existingWorkouts...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Date.Before(s[j].Date) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*RunningWorkout)
+	return sMerged
+}
+
+func mergeSleepCycleNights(exportedNights, existingNights []*SleepCycleNight) []*SleepCycleNight {
+	s := append(exportedNights, existingNights...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].StartAt.Before(s[j].StartAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*SleepCycleNight)
+	return sMerged
+}
+
+// Merges games on their app ID, preferring the API's version of each, except
+// for its total playtime. Steam only ever adds to it, so a lower value from the
+// API is a sign of stale data and the stored one is kept instead.
+func mergeSteamGames(apiGames, existingGames []*SteamGame) []*SteamGame {
+	existingByAppID := make(map[int]*SteamGame, len(existingGames))
+	for _, game := range existingGames {
+		existingByAppID[game.AppID] = game
+	}
+	for _, game := range apiGames {
+		existing, ok := existingByAppID[game.AppID]
+		if !ok {
+			continue
+		}
+
+		if existing.PlaytimeForeverMin > game.PlaytimeForeverMin {
+			game.PlaytimeForeverMin = existing.PlaytimeForeverMin
+		}
+
+		if existing.LastPlayedAt.After(game.LastPlayedAt) {
+			game.LastPlayedAt = existing.LastPlayedAt
+		}
+	}
+
+	s := append(apiGames, existingGames...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].AppID < s[j].AppID })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].AppID }).([]*SteamGame)
+	return sMerged
+}
+
+func mergeStripeBalances(apiPayouts, existingPayouts []*StripeBalance) []*StripeBalance {
+	s := append(apiPayouts, existingPayouts...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].CreatedAt.Before(s[j].CreatedAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*StripeBalance)
+	return sMerged
+}
+
+func mergeStripeCharges(apiCharges, existingCharges []*StripeCharge) []*StripeCharge {
+	s := append(apiCharges, existingCharges...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].CreatedAt.Before(s[j].CreatedAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*StripeCharge)
+	return sMerged
+}
+
+func mergeSubstackPosts(apiPosts, existingPosts []*SubstackPost) []*SubstackPost {
+	s := append(apiPosts, existingPosts...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].PublishedAt.Before(s[j].PublishedAt) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*SubstackPost)
+	return sMerged
+}
+
+func mergeTelegramMessages(apiMessages, existingMessages []*TelegramMessage) []*TelegramMessage {
+	s := append(apiMessages, existingMessages...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].MessageID < s[j].MessageID })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].MessageID }).([]*TelegramMessage)
+	return sMerged
+}
+
+// Entries are deduplicated before they're sorted so that the API's version of
+// an entry is kept even if its start time was edited to be later than the
+// existing version's.
+func mergeTogglEntries(apiEntries, existingEntries []*TogglEntry) []*TogglEntry {
+	s := append(apiEntries, existingEntries...)
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*TogglEntry)
+	sort.SliceStable(sMerged, func(i, j int) bool { return sMerged[i].StartedAt.Before(sMerged[j].StartedAt) })
+	return sMerged
+}
+
+func mergeWakaTimeDays(apiDays, existingDays []*WakaTimeDay) []*WakaTimeDay {
+	s := append(apiDays, existingDays...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Date.Before(s[j].Date) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].Date.Format(wakaTimeDateFormat) }).([]*WakaTimeDay)
+	return sMerged
+}
+
+func mergeWithingsMeasurements(apiMeasurements, existingMeasurements []*WithingsMeasurement) []*WithingsMeasurement {
+	s := append(apiMeasurements, existingMeasurements...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Date.Before(s[j].Date) })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].Date.Unix() }).([]*WithingsMeasurement)
+	return sMerged
+}
+
+func mergeSubjects(apiSubjects, existingSubjects []*WaniKaniSubject) []*WaniKaniSubject {
+	s := append(existingSubjects, apiSubjects...)
+	sort.SliceStable(s, func(i, j int) bool { return s[i].ID < s[j].ID })
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*WaniKaniSubject)
+	return sMerged
 }
 
-// Reading is a single Goodreads book stored to a TOML file.
-type Reading struct {
-	Authors       []*ReadingAuthor `toml:"authors"`
-	ID            int              `toml:"id"`
-	ISBN          string           `toml:"isbn"`
-	ISBN13        string           `toml:"isbn13"`
-	NumPages      int              `toml:"num_pages"`
-	PublishedYear int              `toml:"published_year"`
-	ReadAt        time.Time        `toml:"read_at"`
-	Rating        int              `toml:"rating"`
-	Review        string           `toml:"review"`
-	ReviewID      int              `toml:"review_id"`
-	Title         string           `toml:"title"`
+// Strategies for choosing between the fetched and stored versions of a tweet
+// when merging.
+const (
+	// Always keep the version fetched from the API.
+	mergeStrategyPreferNew = "prefer-new"
+
+	// Always keep the stored version, so that tweets in an archive are never
+	// changed once they've been written. New tweets are still added.
+	mergeStrategyPreferExisting = "prefer-existing"
+
+	// Keep the version fetched from the API unless it only changed trivially
+	// (see flipDuplicateTweetsOnTrivialChanges).
+	mergeStrategySmart = "smart"
+)
+
+func mergeTweets(apiTweets, existingTweets []*Tweet, opts *SyncTwitterOptions) []*Tweet {
+	if !opts.APIV2 {
+		copyTweetAPIV2Metrics(apiTweets, existingTweets, opts.EngagementWeights)
+	}
+	if !opts.ComputeEngagement {
+		copyTweetLikesByFollowers(apiTweets, existingTweets)
+	}
+	copyTweetAnnotations(apiTweets, existingTweets)
+
+	var s []*Tweet
+	if opts.MergeStrategy == mergeStrategyPreferExisting {
+		s = append(existingTweets, apiTweets...)
+	} else {
+		s = append(apiTweets, existingTweets...)
+	}
+	sort.SliceStable(s, func(i, j int) bool { return s[i].ID < s[j].ID })
+	if opts.MergeStrategy == "" || opts.MergeStrategy == mergeStrategySmart {
+		flipDuplicateTweetsOnTrivialChanges(s, opts.EngagementThreshold, opts.TrivialViewThreshold)
+	}
+	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*Tweet)
+	sortTweets(sMerged, opts.Sort)
+	return sMerged
 }
 
-// ReadingAuthor is a single Goodreads author stored to a TOML file.
-type ReadingAuthor struct {
-	ID   int    `toml:"id"`
-	Name string `toml:"name"`
+// Maximum number of requests that LinkedIn allows an application to make on a
+// member's behalf each day.
+const linkedInDailyRequestLimit = 500
+
+// Maximum number of posts requested in a single page from LinkedIn.
+const linkedInPageLimit = 50
+
+// Number of requests after which a warning is logged that LinkedIn's daily
+// limit is being approached. Only requests made during the current sync are
+// counted, so the limit may be reached sooner if there were others today.
+const linkedInRequestWarnThreshold = 450
+
+func linkedInArticleFromAPIArticle(article *LinkedInAPIArticle, socialActions *LinkedInAPISocialActions) *LinkedInArticle {
+	return &LinkedInArticle{
+		CommentCount:  socialActions.CommentsSummary.AggregatedTotalComments,
+		Content:       sanitizeLinkedInArticle(article.Content.HTMLContent.HTMLText),
+		CoverImageURL: article.CoverImageURL,
+		ID:            article.ID,
+		LikeCount:     socialActions.LikesSummary.TotalLikes,
+		PublishedAt:   time.UnixMilli(article.PublishedAt).UTC(),
+		Title:         article.Title,
+		ViewCount:     article.Statistics.Views,
+	}
 }
 
-// ReadingDB is a database of Goodreads readings stored to a TOML file.
-type ReadingDB struct {
-	Readings []*Reading `toml:"readings"`
+func linkedInPostFromAPIPost(post *LinkedInAPIPost, socialActions *LinkedInAPISocialActions) *LinkedInPost {
+	return &LinkedInPost{
+		CommentCount: socialActions.CommentsSummary.AggregatedTotalComments,
+		CreatedAt:    time.UnixMilli(post.Created.Time).UTC(),
+		ID:           post.ID,
+		LikeCount:    socialActions.LikesSummary.TotalLikes,
+		Text:         post.SpecificContent.ShareContent.ShareCommentary.Text,
+		Visibility:   post.Visibility.MemberNetworkVisibility,
+	}
 }
 
-//
-// Twitter
-//
+// Maximum number of transactions Monzo returns in a single page.
+const monzoPageLimit = 100
 
-// TweetDB is a database of tweets stored to a TOML file.
-type TweetDB struct {
-	Tweets []*Tweet `toml:"tweets"`
+// Pending transactions may still change (e.g. their amounts when they settle,
+// or their categories and notes when edited), so when syncing incrementally we
+// start this many days before the last one that's stored.
+const monzoRefetchDays = 14
+
+// Medium's JSON APIs prefix responses with this to prevent JSON hijacking.
+const mediumJSONPrefix = "])}while(1);</x>"
+
+func mediumPostFromRSSItem(item *MediumRSSItem) (*MediumPost, error) {
+	// GUIDs look like "https://medium.com/p/{id}".
+	guid, err := url.Parse(item.GUID)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing GUID '%s' of post '%s': %w", item.GUID, item.Title, err)
+	}
+	id := path.Base(guid.Path)
+	if id == "" || id == "/" || id == "." {
+		return nil, fmt.Errorf("no ID in GUID '%s' of post '%s'", item.GUID, item.Title)
+	}
+
+	publishedAt, err := time.Parse(time.RFC1123, item.PubDate)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing published at time of post '%s': %w", item.Title, err)
+	}
+
+	var updatedAt time.Time
+	if item.Updated != "" {
+		updatedAt, err = time.Parse(time.RFC3339, item.Updated)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing updated at time of post '%s': %w", item.Title, err)
+		}
+	}
+
+	// Links carry tracking parameters like "?source=rss-...".
+	link, err := url.Parse(item.Link)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing link of post '%s': %w", item.Title, err)
+	}
+	link.RawQuery = ""
+
+	return &MediumPost{
+		ID:          id,
+		PublishedAt: publishedAt.UTC(),
+		Tags:        item.Categories,
+		Title:       item.Title,
+		UpdatedAt:   updatedAt.UTC(),
+		URL:         link.String(),
+	}, nil
 }
 
-// Tweet is a single tweet stored to a TOML file.
-type Tweet struct {
-	CreatedAt     time.Time      `toml:"created_at"`
-	Entities      *TweetEntities `toml:"entities"`
-	FavoriteCount int            `toml:"favorite_count,omitempty"`
-	ID            int64          `toml:"id"`
-	Reply         *TweetReply    `toml:"reply"`
-	Retweet       *TweetRetweet  `toml:"retweet"`
-	RetweetCount  int            `toml:"retweet_count,omitempty"`
-	Text          string         `toml:"text"`
+// SchemaVersion is the current version of the schema of Goodreads and Twitter
+// data files. It should be incremented along with a new migration in
+// readingDBMigrations, tweetDBMigrations, or likedTweetDBMigrations whenever a
+// change is made that existing files need to be upgraded for.
+const SchemaVersion = 2
+
+// Runs the migrations needed to bring a database read from path up from the
+// given schema version to SchemaVersion. A database with a version newer than
+// SchemaVersion was written by a newer version of qself, and rewriting it
+// could lose data, so it's an error.
+func migrateDB[T any](path string, db T, version int, migrations []func(T) error) error {
+	if version > SchemaVersion {
+		return fmt.Errorf("'%s' has schema version %v, but this version of qself only supports up to %v; upgrade qself to use it",
+			path, version, SchemaVersion)
+	}
+
+	for v := version; v < SchemaVersion; v++ {
+		logger.Infof("Migrating '%s' from schema version %v to %v", path, v, v+1)
+
+		if err := migrations[v](db); err != nil {
+			return fmt.Errorf("error migrating '%s' from schema version %v to %v: %w", path, v, v+1, err)
+		}
+	}
+
+	return nil
 }
 
-// TweetEntities contains various multimedia entries that may be contained in a
-// tweet.
-type TweetEntities struct {
-	Medias       []*TweetEntitiesMedia       `toml:"medias"`
-	URLs         []*TweetEntitiesURL         `toml:"urls"`
-	UserMentions []*TweetEntitiesUserMention `toml:"user_mentions"`
+// See migrateReadingDBV0ToV1.
+func migrateLikedTweetDBV0ToV1(likedTweetDB *LikedTweetDB) error {
+	return nil
 }
 
-// TweetEntitiesMedia is an image or video stored in a tweet.
-type TweetEntitiesMedia struct {
-	ID   int64  `toml:"id"`
-	Type string `toml:"type"`
-	URL  string `toml:"url"`
+// See migrateTweetDBV1ToV2.
+func migrateLikedTweetDBV1ToV2(likedTweetDB *LikedTweetDB) error {
+	for _, like := range likedTweetDB.Tweets {
+		if like.CreatedAtUnix == 0 && !like.CreatedAt.IsZero() {
+			like.CreatedAtUnix = like.CreatedAt.Unix()
+		}
+	}
+	return nil
 }
 
-// TweetEntitiesURL is a URL referenced in a tweet.
-type TweetEntitiesURL struct {
-	DisplayURL  string `toml:"display_url"`
-	ExpandedURL string `toml:"expanded_url"`
-	URL         string `toml:"url"`
+// Version 0 is a file written before schema versions were introduced. Every
+// field added up to version 1 decodes to its zero value when missing, so
+// there's nothing to do.
+func migrateReadingDBV0ToV1(readingDB *ReadingDB) error {
+	return nil
 }
 
-// TweetEntitiesUserMention is another user being mentioned in a tweet.
-type TweetEntitiesUserMention struct {
-	User   string `toml:"user"`
-	UserID int64  `toml:"user_id"`
+// Version 2 only changed tweets, so there's nothing to do for readings.
+func migrateReadingDBV1ToV2(readingDB *ReadingDB) error {
+	return nil
 }
 
-// TweetReply is populated with reply information for when a tweet is a
-// reply.
-type TweetReply struct {
-	StatusID int64  `toml:"status_id"`
-	User     string `toml:"user"`
-	UserID   int64  `toml:"user_id"`
+// See migrateReadingDBV0ToV1.
+func migrateTweetDBV0ToV1(tweetDB *TweetDB) error {
+	return nil
 }
 
-// TweetRetweet is populated with retweet information for when a tweet is a
-// retweet.
-type TweetRetweet struct {
-	StatusID int64  `toml:"status_id"`
-	User     string `toml:"user"`
-	UserID   int64  `toml:"user_id"`
+// Version 2 added Tweet.CreatedAtUnix, which is filled in from CreatedAt for
+// tweets stored before it existed.
+func migrateTweetDBV1ToV2(tweetDB *TweetDB) error {
+	for _, tweet := range tweetDB.Tweets {
+		if tweet.CreatedAtUnix == 0 && !tweet.CreatedAt.IsZero() {
+			tweet.CreatedAtUnix = tweet.CreatedAt.Unix()
+		}
+	}
+	return nil
 }
 
-//
-// WaniKani
-//
+func monzoTransactionFromAPITransaction(transaction *MonzoAPITransaction) *MonzoTransaction {
+	monzoTransaction := &MonzoTransaction{
+		Amount:      transaction.Amount,
+		Category:    transaction.Category,
+		CreatedAt:   transaction.Created,
+		Currency:    transaction.Currency,
+		Description: transaction.Description,
+		ID:          transaction.ID,
+		IsLoad:      transaction.IsLoad,
+		Notes:       transaction.Notes,
+	}
 
-// WaniKaniDB is a database of WaniKani objects stored to a TOML file.
-type WaniKaniDB struct {
-	ReviewsUpdatedAt  time.Time `toml:"reviews_updated_at"`
-	SubjectsUpdatedAt time.Time `toml:"subjects_updated_at"`
+	if transaction.Merchant != nil {
+		monzoTransaction.MerchantName = transaction.Merchant.Name
 
-	Reviews  []*WaniKaniReview  `toml:"reviews"`
-	Subjects []*WaniKaniSubject `toml:"subject"`
-}
+		if transaction.Merchant.Address != nil {
+			monzoTransaction.MerchantCity = transaction.Merchant.Address.City
+		}
+	}
 
-// WaniKaniReview is a single WaniKani review stored to a TOML file.
-type WaniKaniReview struct {
-	AssignmentID int64     `toml:"assignment_id"`
-	CreatedAt    time.Time `toml:"created_at"`
-	ID           int64     `toml:"id"`
-	SubjectID    int64     `toml:"subject_id"`
+	return monzoTransaction
 }
 
-// WaniKaniSubject is a single WaniKani subject stored to a TOML file.
-type WaniKaniSubject struct {
-	ID               int64   `toml:"id"`
-	Level            int     `toml:"level"`
-	Meaning          string  `toml:"meaning"`
-	RadicalCharacter *string `toml:"radical_character,omitempty"`
-	Slug             string  `toml:"slug"`
-	Type             string  `toml:"type"`
+// Format of dates in NomadList's API.
+const nomadListDateFormat = "2006-01-02"
+
+func nomadStayFromAPITrip(trip *NomadListAPITrip) (*NomadStay, error) {
+	dateStart, err := time.Parse(nomadListDateFormat, trip.DateStart)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing start date of stay %v: %w", trip.ID, err)
+	}
+
+	dateEnd, err := time.Parse(nomadListDateFormat, trip.DateEnd)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing end date of stay %v: %w", trip.ID, err)
+	}
+
+	return &NomadStay{
+		City:         trip.Place,
+		Country:      trip.Country,
+		CountryCode:  trip.CountryCode,
+		DateEnd:      dateEnd,
+		DateStart:    dateStart,
+		DurationDays: int(dateEnd.Sub(dateStart).Hours() / 24),
+		ID:           trip.ID,
+		Latitude:     trip.Latitude,
+		Longitude:    trip.Longitude,
+	}, nil
 }
 
-//////////////////////////////////////////////////////////////////////////////
-//
-//
-//
-// Variables
-//
-//
-//
-//////////////////////////////////////////////////////////////////////////////
+// Number of days of history synced from OpenWeather when there's no existing
+// data. Each day takes 24 requests, and OpenWeather's free tier allows 1000 a
+// day.
+const openWeatherBackfillDays = 30
 
-var logger = &LeveledLogger{Level: LevelInfo}
+// Maximum number of requests made to OpenWeather at once.
+const openWeatherConcurrency = 8
 
-//////////////////////////////////////////////////////////////////////////////
-//
-//
-//
-// Private functions
-//
-//
-//
-//////////////////////////////////////////////////////////////////////////////
+// Format of OpenWeather dates used as keys when merging.
+const openWeatherDateFormat = "2006-01-02"
 
-func absInt(x int) int {
-	if x < 0 {
-		return -x
+// Summarizes the hourly data points of a single day from OpenWeather.
+func openWeatherDayFromAPIHours(date time.Time, hours []*OpenWeatherAPIHour) *OpenWeatherDay {
+	day := &OpenWeatherDay{Date: date}
+	if len(hours) < 1 {
+		return day
 	}
-	return x
-}
 
-var htmlLineBreakRE = regexp.MustCompile(`<br ?/?>`)
+	day.FeelsLikeMaxC = hours[0].FeelsLike
+	day.TempMaxC = hours[0].Temp
+	day.TempMinC = hours[0].Temp
 
-var htmlLinkRE = regexp.MustCompile(`<a .*?href="(.*?)".*?>.*?</a>`)
+	var humiditySum int
+	weatherCounts := make(map[string]int)
+	weatherDescriptions := make(map[string]string)
 
-func die(message string) {
-	fmt.Fprintf(os.Stderr, message)
-	os.Exit(1)
+	for _, hour := range hours {
+		day.FeelsLikeMaxC = math.Max(day.FeelsLikeMaxC, hour.FeelsLike)
+		day.TempMaxC = math.Max(day.TempMaxC, hour.Temp)
+		day.TempMinC = math.Min(day.TempMinC, hour.Temp)
+		day.UVI = math.Max(day.UVI, hour.UVI)
+
+		humiditySum += hour.Humidity
+
+		if hour.Rain != nil {
+			day.PrecipitationMM += hour.Rain.OneHourMM
+		}
+		if hour.Snow != nil {
+			day.PrecipitationMM += hour.Snow.OneHourMM
+		}
+
+		if day.SunriseAt.IsZero() && hour.Sunrise != 0 {
+			day.SunriseAt = time.Unix(hour.Sunrise, 0).UTC()
+			day.SunsetAt = time.Unix(hour.Sunset, 0).UTC()
+		}
+
+		for _, weather := range hour.Weather {
+			weatherCounts[weather.Main]++
+			if _, ok := weatherDescriptions[weather.Main]; !ok {
+				weatherDescriptions[weather.Main] = weather.Description
+			}
+		}
+	}
+
+	day.Humidity = int(math.Round(float64(humiditySum) / float64(len(hours))))
+
+	// Ties go to the condition that comes first alphabetically so that the
+	// result doesn't depend on map ordering.
+	for main, count := range weatherCounts {
+		if count > weatherCounts[day.WeatherMain] || (count == weatherCounts[day.WeatherMain] && main < day.WeatherMain) {
+			day.WeatherMain = main
+		}
+	}
+	day.WeatherDescription = weatherDescriptions[day.WeatherMain]
+
+	return day
 }
 
-// Fetches a single Goodreads page and returns all the reviews on it.
-func fetchGoodreadsPage(conf *GoodreadsConf, client *http.Client, page int) ([]*APIReview, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://www.goodreads.com/review/list/%s.xml", conf.GoodreadsID), nil)
+// Format in which Oura returns and accepts dates.
+const ouraDateFormat = "2006-01-02"
+
+// Date before which Oura can't have any data, used as a starting point when
+// syncing for the first time.
+var ouraEpoch = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Oura revises data for recent days as more of it comes in, so when syncing
+// incrementally we start this many days before the last one that's stored.
+const ouraRefetchDays = 7
+
+// Number of days of data requested from Oura at once.
+const ouraWindowDays = 30
+
+func ouraReadinessDayFromAPIReadiness(readiness *OuraAPIReadiness) (*OuraReadinessDay, error) {
+	date, err := time.Parse(ouraDateFormat, readiness.Day)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error parsing readiness day: %w", err)
 	}
 
-	v := url.Values{}
-	v.Set("key", conf.GoodreadsKey)
-	v.Set("page", strconv.Itoa(page))
-	v.Set("per_page", "20")
-	v.Set("shelf", "read")
-	v.Set("sort", "date_read")
-	v.Set("v", "2")
-	req.URL.RawQuery = v.Encode()
+	day := &OuraReadinessDay{
+		Date:                 date,
+		Score:                readiness.Score,
+		TemperatureDeviation: readiness.TemperatureDeviation,
+	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error listing reviews: %w", err)
+	if contributors := readiness.Contributors; contributors != nil {
+		day.ActivityBalance = contributors.ActivityBalance
+		day.BodyTemperature = contributors.BodyTemperature
+		day.HRVBalance = contributors.HRVBalance
+		day.PreviousDayActivity = contributors.PreviousDayActivity
+		day.PreviousNight = contributors.PreviousNight
+		day.RecoveryIndex = contributors.RecoveryIndex
+		day.RestingHeartRate = contributors.RestingHeartRate
+		day.SleepBalance = contributors.SleepBalance
 	}
-	defer resp.Body.Close()
 
-	data, err := ioutil.ReadAll(resp.Body)
+	return day, nil
+}
+
+// Combines a daily sleep summary with the longest sleep period of the same day,
+// which may be nil if Oura didn't record one.
+func ouraSleepDayFromAPIDailySleep(dailySleep *OuraAPIDailySleep, period *OuraAPISleepPeriod) (*OuraSleepDay, error) {
+	date, err := time.Parse(ouraDateFormat, dailySleep.Day)
 	if err != nil {
-		return nil, fmt.Errorf("error reading body from reviews list: %w", err)
+		return nil, fmt.Errorf("error parsing sleep day: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code from Goodreads: %v (%s)", resp.StatusCode, data)
+	day := &OuraSleepDay{
+		Date:  date,
+		Score: dailySleep.Score,
 	}
 
-	var root APIReviewsRoot
-	err = xml.Unmarshal(data, &root)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshaling reviews from XML: %w", err)
+	if dailySleep.Contributors != nil {
+		day.TimingScore = dailySleep.Contributors.Timing
 	}
 
-	return root.Reviews, nil
-}
+	if period != nil {
+		day.DeepSleep = period.DeepSleepDuration
+		day.Efficiency = period.Efficiency
+		day.LatencyMin = period.Latency / 60
+		day.LightSleep = period.LightSleepDuration
+		day.REMSleep = period.REMSleepDuration
+		day.TotalSleep = period.TotalSleepDuration
 
-func findPrimaryMeaning(meanings []*wanikaniapi.SubjectMeaningObject) *wanikaniapi.SubjectMeaningObject {
-	for _, meaning := range meanings {
-		if meaning.Primary {
-			return meaning
+		if period.Readiness != nil {
+			day.TemperatureDelta = period.Readiness.TemperatureDeviation
 		}
 	}
 
-	panic("no primary meaning")
+	return day, nil
+}
+
+// Name of the Goodreads shelf holding books that have been read.
+const goodreadsShelfRead = "read"
+
+// Default order in which reviews are requested from the Goodreads API.
+const goodreadsSortDateRead = "date_read"
+
+// Orders in which reviews can be requested from the Goodreads API with
+// `--goodreads-sort`.
+var goodreadsSorts = []string{goodreadsSortDateRead, "date_added", "title", "author", "rating"}
+
+// Number of each friend's most recently read books synced by
+// `sync-goodreads-friends`.
+const goodreadsFriendRecentReadings = 10
+
+// Values of ReadingMergeConflict.Chosen.
+const (
+	readingMergeChosenAPI      = "api"
+	readingMergeChosenExisting = "existing"
+)
+
+// Formats of editions that Goodreads commonly returns. Readings in other
+// formats are still stored, but a warning is logged for them in case they're
+// a typo or a new format that stats should know about.
+var goodreadsKnownFormats = map[string]bool{
+	"Audiobook":             true,
+	"ebook":                 true,
+	"Hardcover":             true,
+	"Mass Market Paperback": true,
+	"Paperback":             true,
+}
+
+// Two-letter ISO 639-1 language codes, used by the `validate` command to
+// check Reading.Language.
+var iso6391Codes = map[string]bool{
+	"aa": true, "ab": true, "ae": true, "af": true, "ak": true, "am": true, "an": true, "ar": true, "as": true, "av": true, "ay": true, "az": true,
+	"ba": true, "be": true, "bg": true, "bh": true, "bi": true, "bm": true, "bn": true, "bo": true, "br": true, "bs": true,
+	"ca": true, "ce": true, "ch": true, "co": true, "cr": true, "cs": true, "cu": true, "cv": true, "cy": true,
+	"da": true, "de": true, "dv": true, "dz": true,
+	"ee": true, "el": true, "en": true, "eo": true, "es": true, "et": true, "eu": true,
+	"fa": true, "ff": true, "fi": true, "fj": true, "fo": true, "fr": true, "fy": true,
+	"ga": true, "gd": true, "gl": true, "gn": true, "gu": true, "gv": true,
+	"ha": true, "he": true, "hi": true, "ho": true, "hr": true, "ht": true, "hu": true, "hy": true, "hz": true,
+	"ia": true, "id": true, "ie": true, "ig": true, "ii": true, "ik": true, "io": true, "is": true, "it": true, "iu": true,
+	"ja": true, "jv": true,
+	"ka": true, "kg": true, "ki": true, "kj": true, "kk": true, "kl": true, "km": true, "kn": true, "ko": true, "kr": true, "ks": true, "ku": true, "kv": true, "kw": true, "ky": true,
+	"la": true, "lb": true, "lg": true, "li": true, "ln": true, "lo": true, "lt": true, "lu": true, "lv": true,
+	"mg": true, "mh": true, "mi": true, "mk": true, "ml": true, "mn": true, "mr": true, "ms": true, "mt": true, "my": true,
+	"na": true, "nb": true, "nd": true, "ne": true, "ng": true, "nl": true, "nn": true, "no": true, "nr": true, "nv": true, "ny": true,
+	"oc": true, "oj": true, "om": true, "or": true, "os": true,
+	"pa": true, "pi": true, "pl": true, "ps": true, "pt": true,
+	"qu": true,
+	"rm": true, "rn": true, "ro": true, "ru": true, "rw": true,
+	"sa": true, "sc": true, "sd": true, "se": true, "sg": true, "si": true, "sk": true, "sl": true, "sm": true, "sn": true, "so": true, "sq": true, "sr": true, "ss": true, "st": true, "su": true, "sv": true, "sw": true,
+	"ta": true, "te": true, "tg": true, "th": true, "ti": true, "tk": true, "tl": true, "tn": true, "to": true, "tr": true, "ts": true, "tt": true, "tw": true, "ty": true,
+	"ug": true, "uk": true, "ur": true, "uz": true,
+	"ve": true, "vi": true, "vo": true,
+	"wa": true, "wo": true,
+	"xh": true,
+	"yi": true, "yo": true,
+	"za": true, "zh": true, "zu": true,
+}
+
+// Returns an HTTP client for making API requests, which goes through the
+// proxy set with --http-proxy if there is one.
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: newHTTPTransport()}
+}
+
+// Returns a transport that routes requests through the proxy set with
+// --http-proxy, or nil (meaning http.DefaultTransport) if there isn't one.
+func newHTTPTransport() http.RoundTripper {
+	if rootOptions.HTTPProxyURL == nil {
+		return nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(rootOptions.HTTPProxyURL)
+	return transport
+}
+
+// Returns an HTTP client that signs requests to Twitter with OAuth1.
+func newTwitterHTTPClient(ctx context.Context, conf *TwitterConf) *http.Client {
+	config := oauth1.NewConfig(conf.TwitterConsumerKey, conf.TwitterConsumerSecret)
+	token := oauth1.NewToken(conf.TwitterAccessToken, conf.TwitterAccessSecret)
+
+	// go-twitter doesn't take a context, so attach it to every request in
+	// the transport underneath OAuth1 signing instead. Requests are signed
+	// before they reach it, so this is also where they're sent through a
+	// proxy.
+	return config.Client(context.WithValue(ctx, oauth1.HTTPClient, &http.Client{
+		Transport: &contextTransport{ctx: ctx, base: newHTTPTransport()},
+	}), token)
+}
+
+// Format which Goodreads returns time in implemented as a Go magic time
+// parsing string.
+const goodreadsTimeFormat = "Mon Jan 2 15:04:05 -0700 2006"
+
+// goodreadsLocale maps the abbreviated day and month names of a language that
+// Goodreads might localize dates into back to their English equivalents, which
+// are the only ones that Go's time parsing understands.
+type goodreadsLocale struct {
+	days   map[string]string
+	months map[string]string
+	name   string
+}
+
+// Locales tried in order when a Goodreads date doesn't parse as is. Keys are
+// lowercase and without any trailing period.
+var goodreadsLocales = []*goodreadsLocale{
+	{
+		name: "Spanish",
+		days: map[string]string{
+			"dom": "Sun", "lun": "Mon", "mar": "Tue", "mié": "Wed", "mie": "Wed",
+			"jue": "Thu", "vie": "Fri", "sáb": "Sat", "sab": "Sat",
+		},
+		months: map[string]string{
+			"ene": "Jan", "feb": "Feb", "mar": "Mar", "abr": "Apr", "may": "May", "jun": "Jun",
+			"jul": "Jul", "ago": "Aug", "sep": "Sep", "sept": "Sep", "oct": "Oct", "nov": "Nov", "dic": "Dec",
+		},
+	},
+	{
+		name: "French",
+		days: map[string]string{
+			"dim": "Sun", "lun": "Mon", "mar": "Tue", "mer": "Wed",
+			"jeu": "Thu", "ven": "Fri", "sam": "Sat",
+		},
+		months: map[string]string{
+			"jan": "Jan", "janv": "Jan", "fév": "Feb", "févr": "Feb", "mars": "Mar", "avr": "Apr",
+			"mai": "May", "juin": "Jun", "juil": "Jul", "aoû": "Aug", "août": "Aug",
+			"sep": "Sep", "sept": "Sep", "oct": "Oct", "nov": "Nov", "déc": "Dec",
+		},
+	},
+	{
+		name: "German",
+		days: map[string]string{
+			"so": "Sun", "mo": "Mon", "di": "Tue", "mi": "Wed",
+			"do": "Thu", "fr": "Fri", "sa": "Sat",
+		},
+		months: map[string]string{
+			"jan": "Jan", "feb": "Feb", "mär": "Mar", "mrz": "Mar", "apr": "Apr", "mai": "May", "jun": "Jun",
+			"jul": "Jul", "aug": "Aug", "sep": "Sep", "okt": "Oct", "nov": "Nov", "dez": "Dec",
+		},
+	},
+	{
+		name: "Italian",
+		days: map[string]string{
+			"dom": "Sun", "lun": "Mon", "mar": "Tue", "mer": "Wed",
+			"gio": "Thu", "ven": "Fri", "sab": "Sat",
+		},
+		months: map[string]string{
+			"gen": "Jan", "feb": "Feb", "mar": "Mar", "apr": "Apr", "mag": "May", "giu": "Jun",
+			"lug": "Jul", "ago": "Aug", "set": "Sep", "ott": "Oct", "nov": "Nov", "dic": "Dec",
+		},
+	},
+	{
+		name: "Portuguese",
+		days: map[string]string{
+			"dom": "Sun", "seg": "Mon", "ter": "Tue", "qua": "Wed",
+			"qui": "Thu", "sex": "Fri", "sáb": "Sat", "sab": "Sat",
+		},
+		months: map[string]string{
+			"jan": "Jan", "fev": "Feb", "mar": "Mar", "abr": "Apr", "mai": "May", "jun": "Jun",
+			"jul": "Jul", "ago": "Aug", "set": "Sep", "out": "Oct", "nov": "Nov", "dez": "Dec",
+		},
+	},
+	{
+		name: "Dutch",
+		days: map[string]string{
+			"zo": "Sun", "ma": "Mon", "di": "Tue", "wo": "Wed",
+			"do": "Thu", "vr": "Fri", "za": "Sat",
+		},
+		months: map[string]string{
+			"jan": "Jan", "feb": "Feb", "mrt": "Mar", "apr": "Apr", "mei": "May", "jun": "Jun",
+			"jul": "Jul", "aug": "Aug", "sep": "Sep", "okt": "Oct", "nov": "Nov", "dec": "Dec",
+		},
+	},
 }
 
-// Because we track a tweet's number of favorites and retweets, a problem with
-// the current system is that we update the data file constantly as these
-// numbers change trivially. Even if you're not a super popular persona on
-// Twitter who's getting new likes and retweets all the time, there's still an
-// ambient level of background changes as numbers on old tweets increment or
-// decrement by a few at a time. My guess is that it's from people deleting
-// their accounts or setting them private, but I'm not sure.
+// Parses a time from Goodreads using the given format. If that fails, each
+// known locale is tried in turn by translating its day and month names to
+// English before parsing again.
 //
-// Try to keep the system churning less by preferring the data that we already
-// have if the change detected is "trivial", meaning the likes and retweets
-// only changed by a small amount.
-func flipDuplicateTweetsOnTrivialChanges(tweets []*Tweet) {
-	for i, j := 0, 1; j < len(tweets); i, j = i+1, j+1 {
-		if tweets[i].ID != tweets[j].ID {
-			continue
-		}
+// Translation assumes the field ordering of goodreadsTimeFormat, with the day
+// name first and the month name second. This is necessary because some
+// languages share abbreviations between days and months (e.g. "mar" in
+// Spanish is both Tuesday and March).
+func parseGoodreadsTime(s, format string) (time.Time, error) {
+	if format == "" {
+		format = goodreadsTimeFormat
+	}
 
-		if tweets[i].Text != tweets[j].Text {
-			continue
-		}
+	t, err := time.Parse(format, s)
+	if err == nil {
+		return t, nil
+	}
 
-		if !reflect.DeepEqual(tweets[i].Entities, tweets[j].Entities) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return time.Time{}, err
+	}
+
+	for _, locale := range goodreadsLocales {
+		day, dayOK := locale.days[strings.TrimSuffix(strings.ToLower(fields[0]), ".")]
+		month, monthOK := locale.months[strings.TrimSuffix(strings.ToLower(fields[1]), ".")]
+		if !dayOK || !monthOK {
 			continue
 		}
 
-		favoriteDiff := absInt(tweets[i].FavoriteCount - tweets[j].FavoriteCount)
-		retweetDiff := absInt(tweets[i].RetweetCount - tweets[j].RetweetCount)
+		translated := strings.Join(append([]string{day, month}, fields[2:]...), " ")
 
-		if favoriteDiff < 3 && retweetDiff < 3 {
-			tweets[i], tweets[j] = tweets[j], tweets[i]
+		if t, localeErr := time.Parse(format, translated); localeErr == nil {
+			logger.Debugf("Parsed Goodreads time '%s' as %s", s, locale.name)
+			return t, nil
 		}
 	}
+
+	return time.Time{}, err
 }
 
-func syncAll(opts *SyncAllOptions) error {
-	var wg sync.WaitGroup
+// Formats of times in a Kobo database, tried in order. Depending on firmware
+// version, times may or may not have fractional seconds or a UTC offset.
+// Times without an offset are in UTC.
+var koboTimeFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
 
-	var goodreadsErr error
-	if opts.GoodreadsPath != "PATH" {
-		wg.Add(1)
-		go func() {
-			goodreadsErr = syncGoodreads(opts.GoodreadsPath)
-			wg.Done()
-		}()
+func parseKoboTime(s string) (time.Time, error) {
+	for _, format := range koboTimeFormats {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, nil
+		}
 	}
+	return time.Time{}, fmt.Errorf("unknown time format: '%s'", s)
+}
 
-	var twitterErr error
-	if opts.TwitterPath != "PATH" {
-		wg.Add(1)
-		go func() {
-			twitterErr = syncTwitter(opts.TwitterPath)
-			wg.Done()
-		}()
+func parseMediumAPIPost(data []byte) (*MediumAPIPost, error) {
+	var root MediumAPIPostRoot
+	err := json.Unmarshal(bytes.TrimPrefix(data, []byte(mediumJSONPrefix)), &root)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling post from JSON: %w", err)
 	}
 
-	var waniKaniErr error
-	if opts.WaniKaniPath != "PATH" {
-		wg.Add(1)
-		go func() {
-			waniKaniErr = syncWaniKani(opts.WaniKaniPath)
-			wg.Done()
-		}()
+	if root.Payload.Value == nil {
+		return nil, fmt.Errorf("no post in response")
 	}
 
-	wg.Wait()
+	return root.Payload.Value, nil
+}
 
-	if goodreadsErr != nil {
-		return goodreadsErr
+func parseHTTPProxy(s string) (*url.URL, error) {
+	proxyURL, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing proxy URL '%s': %w", s, err)
 	}
-	if twitterErr != nil {
-		return twitterErr
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy URL '%s' should have a scheme of 'http', 'https', or 'socks5'", s)
 	}
-	if waniKaniErr != nil {
-		return waniKaniErr
+
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy URL '%s' is missing a host", s)
 	}
 
-	return nil
+	return proxyURL, nil
 }
 
-func syncGoodreads(targetPath string) error {
-	var conf GoodreadsConf
-	if err := envdecode.Decode(&conf); err != nil {
-		return fmt.Errorf("error decoding conf from env: %v", err)
-	}
+// Names of the columns read from a RunningAHEAD CSV export, lowercased. Every
+// column other than the date is optional, and is left as a zero value if it's
+// missing.
+var runningAheadCSVColumns = map[string][]string{
+	"cadence":  {"cadence"},
+	"course":   {"course"},
+	"date":     {"date"},
+	"distance": {"distance(mi)", "distance (mi)"},
+	"hr":       {"hr", "avg hr"},
+	"notes":    {"notes"},
+	"shoe":     {"shoe"},
+	"time":     {"time", "duration"},
+	"type":     {"type"},
+}
 
-	var readings []*Reading
-	client := &http.Client{}
+// Formats of workout dates in RunningAHEAD CSV exports, tried in order. Dates
+// are formatted according to the account's locale, and only sometimes include
+// a time of day.
+var runningAheadDateFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"1/2/2006 15:04",
+	"1/2/2006",
+}
 
-	// Unluckily, the Goodreads API is very slow. Luckily, it supports offset
-	// based pagination, making it quite easy for us to parallelize.
-	const numSegments = 6
-	var anyErr error
-	var knownEndPage int
-	var mutex sync.RWMutex
-	var wg sync.WaitGroup
-	wg.Add(numSegments)
+// Kilometers in a mile, for converting RunningAHEAD's distances.
+const kmPerMile = 1.609344
 
-	for i := 1; i <= numSegments; i++ {
-		segmentNum := i
+// Parses podcast episodes from an Overcast extended OPML export. Returns the
+// number of episodes that were skipped because they couldn't be parsed.
+func parseOvercastOPML(r io.Reader) ([]*PodcastEpisode, int, error) {
+	var opml OvercastOPML
+	if err := xml.NewDecoder(r).Decode(&opml); err != nil {
+		return nil, 0, fmt.Errorf("error unmarshaling OPML export: %w", err)
+	}
 
-		go func() {
-			page := segmentNum
+	var episodes []*PodcastEpisode
+	var numSkipped int
 
-			for {
-				logger.Infof("(goodreads) (segment %v) Paging; num readings accumulated: %v, page: %v",
-					segmentNum, len(readings), page)
+	// Feeds are nested under a "feeds" outline (and playlists under
+	// another), so walk the whole tree looking for them.
+	var walk func(outlines []*OvercastOPMLOutline)
+	walk = func(outlines []*OvercastOPMLOutline) {
+		for _, outline := range outlines {
+			if outline.Type != "rss" {
+				walk(outline.Outlines)
+				continue
+			}
 
-				if knownEndPage != 0 && page >= knownEndPage {
-					logger.Infof("(goodreads) (segment %v) Page %v beyond known end of %v; stopping",
-						segmentNum, page, knownEndPage)
-					break
+			for _, episodeOutline := range outline.Outlines {
+				if episodeOutline.Type != "podcast-episode" {
+					continue
 				}
 
-				apiReviews, err := fetchGoodreadsPage(&conf, client, page)
+				episode, err := podcastEpisodeFromOvercastOutline(outline, episodeOutline)
 				if err != nil {
-					logger.Errorf("(goodreads) (segment %v) %v", segmentNum, err)
-					anyErr = err
-					break
+					logger.Warnf("(overcast) Skipping episode '%s': %v", episodeOutline.Title, err)
+					numSkipped++
+					continue
 				}
 
-				if len(apiReviews) < 1 {
-					// If we know this page is beyond bounds, mark it as such
-					// to maybe save some API requests.
-					mutex.Lock()
-					if knownEndPage == 0 || page < knownEndPage {
-						logger.Infof("(goodreads) (segment %v) Setting known end page: %v (previously %v)",
-							segmentNum, page, knownEndPage)
-						knownEndPage = page
-					}
-					mutex.Unlock()
+				episodes = append(episodes, episode)
+			}
+		}
+	}
+	walk(opml.Outlines)
 
-					break
-				}
+	return episodes, numSkipped, nil
+}
 
-				var pageReadings []*Reading
-				for _, apiReview := range apiReviews {
-					pageReadings = append(pageReadings, readingFromAPIReview(apiReview))
-				}
+// Parses workouts from a RunningAHEAD CSV export. Returns the number of rows
+// that were skipped because they couldn't be parsed.
+func parseRunningAheadCSV(r io.Reader) ([]*RunningWorkout, int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
 
-				mutex.Lock()
-				readings = append(readings, pageReadings...)
-				mutex.Unlock()
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, 0, fmt.Errorf("error parsing CSV export: %w", err)
+	}
+	if len(records) < 1 {
+		return nil, 0, fmt.Errorf("CSV export is empty")
+	}
 
-				page += numSegments
+	columnIndexes := make(map[string]int)
+	for i, name := range records[0] {
+		// Exports may start with a byte order mark.
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for column, alternatives := range runningAheadCSVColumns {
+			for _, alternative := range alternatives {
+				if name == alternative {
+					columnIndexes[column] = i
+				}
 			}
-
-			wg.Done()
-		}()
+		}
 	}
 
-	wg.Wait()
-
-	if anyErr != nil {
-		return anyErr
+	if _, ok := columnIndexes["date"]; !ok {
+		return nil, 0, fmt.Errorf("CSV export has no 'date' column")
 	}
 
-	if _, err := os.Stat(targetPath); err == nil {
-		existingData, err := ioutil.ReadFile(targetPath)
-		if err != nil {
-			return fmt.Errorf("error reading data file: %w", err)
+	var workouts []*RunningWorkout
+	var numSkipped int
+
+	for i, record := range records[1:] {
+		// Returns the trimmed value of a column, or an empty string if the
+		// column or value is missing.
+		value := func(column string) string {
+			index, ok := columnIndexes[column]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
 		}
 
-		var existingReadingDB ReadingDB
-		err = toml.Unmarshal(existingData, &existingReadingDB)
+		workout, err := runningWorkoutFromCSVValues(value)
 		if err != nil {
-			return fmt.Errorf("error unmarshaling toml: %w", err)
+			// Numbered like in a spreadsheet, where the header is row 1.
+			logger.Errorf("(runningahead) Skipping row %v: %v", i+2, err)
+			numSkipped++
+			continue
 		}
 
-		logger.Infof("(goodreads) Found existing '%v'; attempting merge of %v existing readings(s) with %v current readings(s)",
-			targetPath, len(existingReadingDB.Readings), len(readings))
-
-		readings = mergeReadings(readings, existingReadingDB.Readings)
-	} else if os.IsNotExist(err) {
-		logger.Infof("(goodreads) Existing DB at '%v' not found; starting fresh", targetPath)
-	} else {
-		return err
+		workouts = append(workouts, workout)
 	}
 
-	logger.Infof("(goodreads) Writing %v readings(s) to '%s'", len(readings), targetPath)
-
-	readingDB := &ReadingDB{Readings: readings}
-	data, err := toml.Marshal(readingDB)
-	if err != nil {
-		return fmt.Errorf("error marshaling toml: %w", err)
-	}
+	return workouts, numSkipped, nil
+}
 
-	err = ioutil.WriteFile(targetPath, data, 0644)
-	if err != nil {
-		return fmt.Errorf("error writing data file: %w", err)
+func parseRunningAheadDate(s string) (time.Time, error) {
+	for _, format := range runningAheadDateFormats {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, nil
+		}
 	}
-
-	return nil
+	return time.Time{}, fmt.Errorf("unknown date format: '%s'", s)
 }
 
-func syncWaniKani(targetPath string) error {
-	var conf WaniKaniConf
-	if err := envdecode.Decode(&conf); err != nil {
-		return fmt.Errorf("error decoding conf from env: %v", err)
+// Parses a workout's duration, formatted like "1:02:03" or "45:10", and
+// possibly with fractional seconds. Returns it in whole seconds.
+func parseRunningAheadDuration(s string) (int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("unknown duration format: '%s'", s)
 	}
 
-	client := wanikaniapi.NewClient(&wanikaniapi.ClientConfig{
-		APIToken: conf.WaniKaniAPIToken,
-		Logger:   logger,
-	})
+	var seconds float64
+	for i, part := range parts {
+		var n float64
+		var err error
+
+		// Only seconds, which are last, may be fractional.
+		if i == len(parts)-1 {
+			n, err = strconv.ParseFloat(part, 64)
+		} else {
+			var whole int
+			whole, err = strconv.Atoi(part)
+			n = float64(whole)
+		}
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("unknown duration format: '%s'", s)
+		}
 
-	//
-	// Read previous file
-	//
+		seconds = seconds*60 + n
+	}
 
-	var existingReviews []*WaniKaniReview
-	var existingSubjects []*WaniKaniSubject
+	return int(math.Round(seconds)), nil
+}
 
-	var reviewsUpdatedAt *time.Time
-	var subjectsUpdatedAt *time.Time
+// Parses a sentiment lexicon of lines holding a word and its score separated
+// by a tab. Blank lines and lines starting with "#" are skipped. The lexicon
+// is embedded in the binary, so it panics if it's malformed.
+func parseSentimentLexicon(data string) map[string]int {
+	lexicon := make(map[string]int)
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
 
-	// WaniKani provides very good facilities for doing incremental updates, so
-	// don't hit them more often than we need to by reusing existing data where
-	// appropriate.
-	if _, err := os.Stat(targetPath); err == nil {
-		existingData, err := ioutil.ReadFile(targetPath)
-		if err != nil {
-			return fmt.Errorf("error reading data file: %w", err)
+		parts := strings.Split(line, "\t")
+		if len(parts) != 2 {
+			panic(fmt.Sprintf("sentiment lexicon line %v should have a word and a score", i+1))
 		}
 
-		var existingWaniKaniDB WaniKaniDB
-		err = toml.Unmarshal(existingData, &existingWaniKaniDB)
+		score, err := strconv.Atoi(parts[1])
 		if err != nil {
-			return fmt.Errorf("error unmarshaling toml: %w", err)
+			panic(fmt.Sprintf("sentiment lexicon line %v has invalid score: %v", i+1, err))
 		}
 
-		existingReviews = existingWaniKaniDB.Reviews
-		existingSubjects = existingWaniKaniDB.Subjects
+		lexicon[parts[0]] = score
+	}
 
-		reviewsUpdatedAt = &existingWaniKaniDB.ReviewsUpdatedAt
-		subjectsUpdatedAt = &existingWaniKaniDB.SubjectsUpdatedAt
+	return lexicon
+}
 
-		logger.Infof("(wanikani) Found existing '%v'; running incremental update", targetPath)
-	} else if os.IsNotExist(err) {
-		logger.Infof("(wanikani) Existing DB at '%v' not found; starting fresh", targetPath)
-	} else {
-		return err
-	}
+// Names of the columns read from a SleepCycle CSV export, lowercased. Older
+// versions of the app used different names for some columns, so each has
+// alternatives. Columns that aren't found are left as zero values, except for
+// the start and end of the night, which are required.
+var sleepCycleCSVColumns = map[string][]string{
+	"end":        {"end"},
+	"heart_rate": {"heart rate", "heart rate (bpm)"},
+	"notes":      {"notes", "sleep notes"},
+	"quality":    {"quality", "sleep quality"},
+	"start":      {"start"},
+	"steps":      {"steps", "activity (steps)"},
+}
 
-	//
-	// Reviews
-	//
+// Formats of the start and end times of nights in SleepCycle CSV exports,
+// tried in order. Newer exports include a UTC offset. Times without one are
+// interpreted as UTC.
+var sleepCycleTimeFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05-07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
 
-	var reviews []*WaniKaniReview
-	reviewsStartedAt := time.Now()
-	err := client.PageFully(func(id *wanikaniapi.WKID) (*wanikaniapi.PageObject, error) {
-		idDisplay := "(empty)"
-		if id != nil {
-			idDisplay = strconv.FormatInt(int64(*id), 10)
-		}
-		logger.Infof("(wanikani) Paging; num reviews accumulated: %v, page after ID: %v",
-			len(reviews), idDisplay)
+// Parses nights from a SleepCycle CSV export, which may be delimited by either
+// semicolons (the app's default) or commas. Returns the number of rows that
+// were skipped because they couldn't be parsed.
+func parseSleepCycleCSV(r io.Reader) ([]*SleepCycleNight, int, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading CSV export: %w", err)
+	}
 
-		page, err := client.ReviewList(&wanikaniapi.ReviewListParams{
-			ListParams: wanikaniapi.ListParams{
-				PageAfterID: id,
-			},
-			UpdatedAfter: (*wanikaniapi.WKTime)(reviewsUpdatedAt),
-		})
-		if err != nil {
-			return nil, err
-		}
+	// Exports may start with a byte order mark.
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
 
-		for _, apiReview := range page.Data {
-			reviews = append(reviews, waniKaniReviewFromAPIReview(apiReview))
-		}
+	header, _, _ := bytes.Cut(data, []byte("\n"))
 
-		return &page.PageObject, nil
-	})
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
 	if err != nil {
-		return fmt.Errorf("error paging wanikani reviews: %v", err)
+		return nil, 0, fmt.Errorf("error parsing CSV export: %w", err)
+	}
+	if len(records) < 1 {
+		return nil, 0, fmt.Errorf("CSV export is empty")
 	}
 
-	numNewReviews := len(reviews)
-	logger.Infof("(wanikani) Total num reviews accumulated: %v", numNewReviews)
-	if existingReviews != nil {
-		reviews = append(existingReviews, reviews...)
+	columnIndexes := make(map[string]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for column, alternatives := range sleepCycleCSVColumns {
+			for _, alternative := range alternatives {
+				if name == alternative {
+					columnIndexes[column] = i
+				}
+			}
+		}
 	}
-	sort.Slice(reviews, func(i, j int) bool { return reviews[i].ID < reviews[j].ID })
 
-	//
-	// Subjects
-	//
+	for _, column := range []string{"start", "end"} {
+		if _, ok := columnIndexes[column]; !ok {
+			return nil, 0, fmt.Errorf("CSV export has no '%s' column", column)
+		}
+	}
 
-	var subjects []*WaniKaniSubject
-	subjectsStartedAt := time.Now()
-	err = client.PageFully(func(id *wanikaniapi.WKID) (*wanikaniapi.PageObject, error) {
-		idDisplay := "(empty)"
-		if id != nil {
-			idDisplay = strconv.FormatInt(int64(*id), 10)
+	var nights []*SleepCycleNight
+	var numSkipped int
+
+	for i, record := range records[1:] {
+		// Returns the trimmed value of a column, or an empty string if the
+		// column or value is missing.
+		value := func(column string) string {
+			index, ok := columnIndexes[column]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
 		}
-		logger.Infof("(wanikani) Paging; num subjects accumulated: %v, page after ID: %v",
-			len(subjects), idDisplay)
 
-		page, err := client.SubjectList(&wanikaniapi.SubjectListParams{
-			ListParams: wanikaniapi.ListParams{
-				PageAfterID: id,
-			},
-			UpdatedAfter: (*wanikaniapi.WKTime)(subjectsUpdatedAt),
-		})
+		night, err := sleepCycleNightFromCSVValues(value)
 		if err != nil {
-			return nil, err
+			// Numbered like in a spreadsheet, where the header is row 1.
+			logger.Errorf("(sleepcycle) Skipping row %v: %v", i+2, err)
+			numSkipped++
+			continue
 		}
 
-		for _, apiSubject := range page.Data {
-			subjects = append(subjects, waniKaniSubjectFromAPISubject(apiSubject))
-		}
+		nights = append(nights, night)
+	}
 
-		return &page.PageObject, nil
-	})
-	if err != nil {
-		return fmt.Errorf("error paging wanikani subjects: %v", err)
+	return nights, numSkipped, nil
+}
+
+func parseSleepCycleTime(s string) (time.Time, error) {
+	for _, format := range sleepCycleTimeFormats {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, nil
+		}
 	}
+	return time.Time{}, fmt.Errorf("unknown time format: '%s'", s)
+}
 
-	numNewSubjects := len(subjects)
-	logger.Infof("(wanikani) Total num subjects accumulated: %v", numNewSubjects)
-	if existingReviews != nil {
-		subjects = mergeSubjects(subjects, existingSubjects)
+func podcastEpisodeFromOvercastOutline(feed, outline *OvercastOPMLOutline) (*PodcastEpisode, error) {
+	episodeURL := outline.EnclosureURL
+	if episodeURL == "" {
+		episodeURL = outline.URL
+	}
+	if episodeURL == "" {
+		return nil, fmt.Errorf("episode has no URL")
 	}
 
-	//
-	// Write new file
-	//
+	addedAt, err := time.Parse(time.RFC3339, outline.PubDate)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing publish date: %w", err)
+	}
 
-	if numNewReviews > 0 || numNewSubjects > 0 {
-		logger.Infof("(wanikani) Writing %v review(s) / %v subjects(s) to '%s'",
-			len(reviews), len(subjects), targetPath)
+	// Overcast omits the title attribute of some feeds, but text is always
+	// set.
+	showTitle := feed.Title
+	if showTitle == "" {
+		showTitle = feed.Text
+	}
 
-		waniKaniDB := &WaniKaniDB{
-			ReviewsUpdatedAt:  reviewsStartedAt,
-			SubjectsUpdatedAt: subjectsStartedAt,
+	episode := &PodcastEpisode{
+		AddedAt:    addedAt,
+		EpisodeURL: episodeURL,
+		FeedURL:    feed.XMLURL,
+		ShowTitle:  showTitle,
+		Title:      outline.Title,
+	}
 
-			Reviews:  reviews,
-			Subjects: subjects,
-		}
-		data, err := toml.Marshal(waniKaniDB)
+	if outline.Played == "1" {
+		episode.PlayedAt, err = time.Parse(time.RFC3339, outline.UserUpdatedDate)
 		if err != nil {
-			return fmt.Errorf("error marshaling toml: %w", err)
+			return nil, fmt.Errorf("error parsing updated date: %w", err)
 		}
+	}
 
-		err = ioutil.WriteFile(targetPath, data, 0644)
+	if outline.Progress != "" {
+		episode.Progress, err = strconv.Atoi(outline.Progress)
 		if err != nil {
-			return fmt.Errorf("error writing data file: %w", err)
+			return nil, fmt.Errorf("error parsing progress: %w", err)
 		}
-	} else {
-		logger.Infof("(wanikani) No new data; not writing file")
 	}
 
-	return nil
+	return episode, nil
 }
 
-func syncTwitter(targetPath string) error {
-	var conf TwitterConf
-	if err := envdecode.Decode(&conf); err != nil {
-		return fmt.Errorf("error decoding conf from env: %v", err)
+// Format in which Weather Underground accepts dates.
+const wuDateFormat = "20060102"
+
+// Format of local observation times returned by Weather Underground.
+const wuObsTimeLocalFormat = "2006-01-02 15:04:05"
+
+// Weather Underground may update station data retroactively, so when syncing
+// incrementally we start this many days before the last one that's stored,
+// and always prefer the API's version of those days.
+const wuRefetchDays = 3
+
+// Number of days of data requested from Weather Underground at once.
+const wuWindowDays = 31
+
+func pwsDayRecordFromWUAPIObservation(observation *WUAPIObservation) (*PWSDayRecord, error) {
+	if observation.Metric == nil {
+		return nil, fmt.Errorf("observation missing metric values")
 	}
 
-	config := oauth1.NewConfig(conf.TwitterConsumerKey, conf.TwitterConsumerSecret)
-	token := oauth1.NewToken(conf.TwitterAccessToken, conf.TwitterAccessSecret)
-	httpClient := config.Client(oauth1.NoContext, token)
+	// Summaries are keyed on the station's local day, so keep that date
+	// rather than converting to UTC.
+	obsTime, err := time.Parse(wuObsTimeLocalFormat, observation.ObsTimeLocal)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing observation time: %w", err)
+	}
 
-	client := twitter.NewClient(httpClient)
+	return &PWSDayRecord{
+		AvgHumidity:     observation.HumidityAvg,
+		AvgPressureHpa:  (observation.Metric.PressureMax + observation.Metric.PressureMin) / 2,
+		AvgTempC:        observation.Metric.TempAvg,
+		Date:            time.Date(obsTime.Year(), obsTime.Month(), obsTime.Day(), 0, 0, 0, 0, time.UTC),
+		MaxUVIndex:      observation.UVHigh,
+		MaxWindSpeedKmh: observation.Metric.WindspeedHigh,
+		PrecipitationMM: observation.Metric.PrecipTotal,
+	}, nil
+}
 
-	user, _, err := client.Users.Show(&twitter.UserShowParams{
-		ScreenName: conf.TwitterUser,
-	})
+// Reads a cached Goodreads API response, returning nil if there isn't one or
+// it's older than ttl. A ttl of zero means that entries never expire.
+func readGoodreadsCache(path string, ttl time.Duration) ([]byte, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if ttl > 0 && time.Since(info.ModTime()) > ttl {
+		logger.Infof("(goodreads) Cache file '%v' is older than %v; refetching", path, ttl)
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("error getting user '%v': %w", conf.TwitterUser, err)
+		return nil, fmt.Errorf("error reading Goodreads cache file: %w", err)
 	}
-	logger.Infof("(twitter) User ID: %v", user.ID)
 
-	var tweets []*Tweet
+	return data, nil
+}
 
-	var maxTweetID int64 = 0
-	for {
-		logger.Infof("(twitter) Paging; num tweets accumulated: %v, max tweet ID: %v", len(tweets), maxTweetID)
+// Default for --words-per-page. A common approximation for a printed book.
+const defaultWordsPerPage = 250
 
-		apiTweets, _, err := client.Timelines.UserTimeline(&twitter.UserTimelineParams{
-			Count:     200, // maximum 200
-			MaxID:     maxTweetID,
-			TweetMode: "extended", // non-truncated tweet content
-			UserID:    user.ID,
+func readingFromAPIReview(review *APIReview, opts *SyncGoodreadsOptions) (*Reading, error) {
+	var authors []*ReadingAuthor
+	for _, author := range review.Book.Authors {
+		authors = append(authors, &ReadingAuthor{
+			ID:   author.ID,
+			Name: author.Name,
+			Role: author.Role,
 		})
-		if err != nil {
-			return fmt.Errorf("error listing user timeline: %w", err)
-		}
-
-		processedAnyTweets := false
+	}
 
-		for _, apiTweet := range apiTweets {
-			// Each page contains the last item from the previous page, so skip
-			// that
-			if maxTweetID != 0 && apiTweet.ID >= maxTweetID {
-				continue
+	var abandoned bool
+	if opts.AbandonedShelf != "" {
+		for _, shelf := range review.Shelves {
+			if shelf.Name == opts.AbandonedShelf {
+				abandoned = true
+				break
 			}
-
-			processedAnyTweets = true
-			tweets = append(tweets, tweetFromAPITweet(&apiTweet))
 		}
+	}
 
-		// No suitable tweets on the page to process which means that we're
-		// done pagination. Break out of the loop and finish.
-		if !processedAnyTweets {
-			break
+	var readAt time.Time
+	if review.ReadAt != "" {
+		t, err := parseGoodreadsTime(review.ReadAt, opts.DateFormat)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing read at time for book '%v': %w", review.Book.Title, err)
 		}
+		readAt = t
+	} else if !abandoned {
+		logger.Errorf("No read at time for book: %v", review.Book.Title)
+	}
 
-		maxTweetID = apiTweets[len(apiTweets)-1].ID
+	// Goodreads stores the date a book was abandoned as its read date, but
+	// it's kept separately so that abandoned books aren't counted as read.
+	var abandonedAt time.Time
+	if abandoned {
+		abandonedAt, readAt = readAt, time.Time{}
 	}
 
-	// Twitter returns a maximum of ~3200 tweets ever, so try to maintain older
-	// ones by merging any existing data that we already have.
-	if _, err := os.Stat(targetPath); err == nil {
-		existingData, err := ioutil.ReadFile(targetPath)
+	// Unlike the read date, a malformed date added only costs the reading an
+	// estimate of its speed, so it's not worth skipping the reading for.
+	var dateAdded time.Time
+	if review.DateAdded != "" {
+		t, err := parseGoodreadsTime(review.DateAdded, opts.DateFormat)
 		if err != nil {
-			return fmt.Errorf("error reading data file: %w", err)
+			logger.Warnf("(goodreads) Ignoring malformed date added for book '%v': %v", review.Book.Title, err)
+		} else {
+			dateAdded = t
 		}
+	}
 
-		var existingTweetDB TweetDB
-		err = toml.Unmarshal(existingData, &existingTweetDB)
+	var startedAt time.Time
+	if review.StartedAt != "" {
+		t, err := parseGoodreadsTime(review.StartedAt, opts.DateFormat)
 		if err != nil {
-			return fmt.Errorf("error unmarshaling toml: %w", err)
+			return nil, fmt.Errorf("error parsing started at time for book '%v': %w", review.Book.Title, err)
 		}
+		startedAt = t
+	}
 
-		logger.Infof("(twitter) Found existing '%v'; attempting merge of %v existing tweet(s) with %v current tweet(s)",
-			targetPath, len(existingTweetDB.Tweets), len(tweets))
+	var updatedAt time.Time
+	if review.UpdatedAt != "" {
+		t, err := parseGoodreadsTime(review.UpdatedAt, opts.DateFormat)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing updated at time for book '%v': %w", review.Book.Title, err)
+		}
+		updatedAt = t
+	}
 
-		tweets = mergeTweets(tweets, existingTweetDB.Tweets)
-	} else if os.IsNotExist(err) {
-		logger.Infof("(twitter) Existing DB at '%v' not found; starting fresh", targetPath)
-	} else {
-		return err
+	if review.Book.Format != "" && !goodreadsKnownFormats[review.Book.Format] {
+		logger.Warnf("(goodreads) Unknown format '%v' for book: %v", review.Book.Format, review.Book.Title)
 	}
 
-	logger.Infof("(twitter) Writing %v tweet(s) to '%s'", len(tweets), targetPath)
+	// Books are often added long before they're started, so the start date
+	// gives a much better speed when there is one.
+	speedStart := dateAdded
+	if !startedAt.IsZero() {
+		speedStart = startedAt
+	}
 
-	tweetDB := &TweetDB{Tweets: tweets}
-	data, err := toml.Marshal(tweetDB)
-	if err != nil {
-		return fmt.Errorf("error marshaling toml: %w", err)
+	speed := readingSpeedPPD(review.Book.NumPages, speedStart, readAt)
+	if speed > readingSpeedMaxPlausiblePPD {
+		logger.Warnf("(goodreads) Unrealistically fast reading speed of %.1f pages/day for book: %v",
+			speed, review.Book.Title)
+	} else if speed > 0 && speed < readingSpeedMinPlausiblePPD {
+		logger.Warnf("(goodreads) Implausibly slow reading speed of %.2f pages/day for book: %v",
+			speed, review.Book.Title)
 	}
 
-	err = ioutil.WriteFile(targetPath, data, 0644)
-	if err != nil {
-		return fmt.Errorf("error writing data file: %w", err)
+	wordsPerPage := opts.WordsPerPage
+	if wordsPerPage == 0 {
+		wordsPerPage = defaultWordsPerPage
 	}
 
-	return nil
-}
+	reviewText := sanitizeGoodreadsReview(review.Body, !opts.NoHTMLDecode)
 
-func tweetFromAPITweet(tweet *twitter.Tweet) *Tweet {
-	// Tweet's ID. Always keep the identifier for the original tweet, even in
-	// the event of a retweet where we rewrite most of everything.
-	id := tweet.ID
+	var meanSentiment float64
+	meanSentimentComputed := opts.ComputeSentiment && reviewText != ""
+	if meanSentimentComputed {
+		meanSentiment = computeSentiment(reviewText)
+	}
 
-	// Content of the tweet. May be rewritten for a retweet.
-	text := tweet.FullText
+	return &Reading{
+		Abandoned:             abandoned,
+		AbandonedAt:           abandonedAt,
+		Authors:               authors,
+		CommunityRating:       review.Book.CommunityRating,
+		CoverURL:              review.Book.ImageURL,
+		DateAdded:             dateAdded,
+		Format:                review.Book.Format,
+		ID:                    review.Book.ID,
+		ISBN:                  review.Book.ISBN,
+		ISBN13:                review.Book.ISBN13,
+		Language:              review.Book.LanguageCode,
+		MeanSentiment:         meanSentiment,
+		MeanSentimentComputed: meanSentimentComputed,
+		NumPages:              review.Book.NumPages,
+		PublishedYear:         review.Book.PublishedYear,
+		ReadAt:                readAt,
+		ReadingSpeedPPD:       speed,
+		Rating:                review.Rating,
+		Review:                reviewText,
+		ReviewID:              review.ID,
+		StartedAt:             startedAt,
+		Title:                 review.Book.Title,
+		UpdatedAt:             updatedAt,
+		WordCount:             review.Book.NumPages * wordsPerPage,
+	}, nil
+}
 
-	var entities *TweetEntities
+// Reading speeds outside of these bounds (in pages per day) are probably the
+// result of bad data, like a book that was added long before it was started.
+const (
+	readingSpeedMaxPlausiblePPD = 2000
+	readingSpeedMinPlausiblePPD = 0.1
+)
 
-	createdAt, err := tweet.CreatedAtTime()
-	if err != nil {
-		panic(err)
+// Computes reading speed in pages per day between two dates. Days are counted
+// by calendar date so that a book started and finished on the same day counts
+// as one day. Returns zero if anything needed is missing or the dates are out
+// of order.
+func readingSpeedPPD(numPages int, start, end time.Time) float64 {
+	if numPages == 0 || start.IsZero() || end.IsZero() {
+		return 0
 	}
 
-	// Do replies before retweets because strangely, some retweets show up as
-	// replies when you use the retweeted status. Prefer not to have that.
-	var reply *TweetReply
-	if tweet.InReplyToStatusID != 0 {
-		reply = &TweetReply{
-			StatusID: tweet.InReplyToStatusID,
-			User:     tweet.InReplyToScreenName,
-			UserID:   tweet.InReplyToUserID,
-		}
+	startDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+
+	days := int(endDate.Sub(startDate).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	if days == 0 {
+		days = 1
 	}
 
-	// We do user mentions early because we want to
-	if len(tweet.Entities.UserMentions) > 0 {
-		if entities == nil {
-			entities = &TweetEntities{}
-		}
+	return float64(numPages) / float64(days)
+}
 
-		for _, userMention := range tweet.Entities.UserMentions {
-			entities.UserMentions = append(entities.UserMentions, &TweetEntitiesUserMention{
-				User:   userMention.ScreenName,
-				UserID: userMention.ID,
-			})
-		}
+// Goodreads doesn't do a great job of keeping review bodies clean, and does
+// things like add HTML line breaks where the user has inserted newlines. Take
+// these out and leave the review looking roughly Markdown-esque.
+//
+// Reviews formatted with Goodreads' rich-text editor may also contain
+// paragraphs, spans, and bold or italic text. Paragraphs become blank lines
+// between text and the rest of the tags are stripped, leaving their content.
+func sanitizeGoodreadsReview(review string, decodeHTML bool) string {
+	review = htmlLineBreakRE.ReplaceAllString(review, "\n")
+
+	review = htmlParagraphOpenRE.ReplaceAllString(review, "\n\n")
+	review = htmlParagraphCloseRE.ReplaceAllString(review, "")
+
+	review = htmlBoldRE.ReplaceAllString(review, "")
+	review = htmlItalicRE.ReplaceAllString(review, "")
+	review = htmlSpanRE.ReplaceAllString(review, "")
+
+	review = htmlLinkRE.ReplaceAllString(review, "$1")
+
+	if decodeHTML {
+		review = html.UnescapeString(review)
 	}
 
-	var retweet *TweetRetweet
-	if status := tweet.RetweetedStatus; status != nil {
-		retweet = &TweetRetweet{
-			StatusID: status.ID,
-			User:     status.User.ScreenName,
-			UserID:   status.User.ID,
-		}
+	return strings.TrimSpace(review)
+}
 
-		// The standard text fields still truncates a retweeted status to 140
-		// characters (for ... reasons?), so we need to grab it elsewhere. Do
-		// some reconstruction to make it look more like a retweet.
-		text = fmt.Sprintf("RT @%s: %s", status.User.ScreenName, status.FullText)
+// Converts the HTML body of a LinkedIn article to plain text. Unlike Goodreads
+// reviews, articles make use of headings, lists, and quotes, so they're broken
+// out into paragraphs, and links keep their text. Any tags that remain are
+// dropped.
+func sanitizeLinkedInArticle(content string) string {
+	content = htmlLineBreakRE.ReplaceAllString(content, "\n")
 
-		// The retweet becomes the original tweet so that we can save its
-		// mentions, media entities, and URLs instead of the original's.
-		tweet = status
+	content = htmlParagraphOpenRE.ReplaceAllString(content, "\n\n")
+	content = htmlParagraphCloseRE.ReplaceAllString(content, "")
 
-		// Do user mentions again to pick up any others that might've been in
-		// the retweet.
-		for _, userMention := range tweet.Entities.UserMentions {
-			entities.UserMentions = append(entities.UserMentions, &TweetEntitiesUserMention{
-				User:   userMention.ScreenName,
-				UserID: userMention.ID,
-			})
-		}
+	content = htmlBlockOpenRE.ReplaceAllString(content, "\n\n")
+	content = htmlBlockCloseRE.ReplaceAllString(content, "")
+	content = htmlListItemOpenRE.ReplaceAllString(content, "\n* ")
 
-		// Unique in case of repeats in both sets.
-		if entities != nil {
-			entities.UserMentions = sliceUniq(entities.UserMentions, func(i int) interface{} {
-				return entities.UserMentions[i].UserID
-			}).([]*TweetEntitiesUserMention)
-		}
-	}
+	content = htmlLinkWithTextRE.ReplaceAllString(content, "$2 ($1)")
 
-	// The Twitter API is weird. "Extended" entities and entities are almost
-	// the same, except that the extended version will contain more than one
-	// photo where multiple were included, and it will assign a type that isn't
-	// "photo" for videos.
-	//
-	// Twitter doesn't go into specifics on why extended is even a thing, but
-	// it's probably related to the fact that features like videos and multiple
-	// photo uploads were added later on.
-	if tweet.ExtendedEntities != nil && len(tweet.ExtendedEntities.Media) > 0 {
-		if entities == nil {
-			entities = &TweetEntities{}
-		}
+	content = htmlTagRE.ReplaceAllString(content, "")
+	content = html.UnescapeString(content)
 
-		for _, media := range tweet.ExtendedEntities.Media {
-			entities.Medias = append(entities.Medias, &TweetEntitiesMedia{
-				ID:   media.ID,
-				Type: media.Type,
-				URL:  media.MediaURLHttps,
-			})
-		}
-	} else if len(tweet.Entities.Media) > 0 {
-		if entities == nil {
-			entities = &TweetEntities{}
-		}
+	content = multipleBlankLinesRE.ReplaceAllString(content, "\n\n")
 
-		for _, media := range tweet.Entities.Media {
-			entities.Medias = append(entities.Medias, &TweetEntitiesMedia{
-				ID:   media.ID,
-				Type: media.Type,
-				URL:  media.MediaURLHttps,
-			})
-		}
-	}
+	return strings.TrimSpace(content)
+}
 
-	if len(tweet.Entities.Urls) > 0 {
-		if entities == nil {
-			entities = &TweetEntities{}
-		}
+// Clean up anything from Twitter for tweet bodies.
+func sanitizeTweetText(text string, decodeHTML bool) string {
+	if !decodeHTML {
+		return text
+	}
+	return html.UnescapeString(text)
+}
 
-		for _, url := range tweet.Entities.Urls {
-			entities.URLs = append(entities.URLs, &TweetEntitiesURL{
-				DisplayURL:  url.DisplayURL,
-				ExpandedURL: url.ExpandedURL,
-				URL:         url.URL,
-			})
-		}
+// Maximum number of characters stored in Tweet.ConversationRootText.
+const tweetConversationRootTextMaxLen = 280
+
+// Prints every tweet whose text contains query. Tweets are searched by their
+// NormalizedText when they have one, and the query is normalized in the same
+// way so that lookalike characters match. Tweets without one are searched by
+// Text as it is, which is already normalized unless they were synced with
+// --no-normalize-text.
+func search(w io.Writer, query string, opts *SearchOptions) error {
+	if opts.TwitterPath == "PATH" {
+		return fmt.Errorf("Twitter source path should be set with --twitter-path")
 	}
 
-	return &Tweet{
-		CreatedAt:     createdAt,
-		Entities:      entities,
-		FavoriteCount: tweet.FavoriteCount,
-		ID:            id,
-		Reply:         reply,
-		Retweet:       retweet,
-		RetweetCount:  tweet.RetweetCount,
-		Text:          sanitizeTweetText(text),
+	tweetDB, err := readTweetDB(opts.TwitterPath)
+	if err != nil {
+		return err
 	}
-}
 
-// Merge two sets of readings together.
-//
-// The first slice should be new readings from the Goodreads API, the second
-// should be any existing readings. This matters because we remove any readings
-// in the existing set which are no longer in the API (because that means they
-// were deleted).
-//
-// This function is currently extreme overkill at the moment because, unlike
-// with Twitter, we never really keep anything from the existing set,
-// preferring what's in the API in all cases. I'm leaving it in for now because
-// it doesn't matter, and also I may want to alter this behavior at some point.
-func mergeReadings(apiReadings, existingReadings []*Reading) []*Reading {
-	existingReadings = sliceKeepOnly(existingReadings, apiReadings,
-		func(i int) interface{} { return existingReadings[i].ReviewID },
-		func(i int) interface{} { return apiReadings[i].ReviewID },
-	).([]*Reading)
-	s := append(apiReadings, existingReadings...)
-	sort.SliceStable(s, func(i, j int) bool { return s[i].ReviewID < s[j].ReviewID })
-	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ReviewID }).([]*Reading)
-	sliceReverse(sMerged)
-	return sMerged
-}
+	query = strings.ToLower(norm.NFKC.String(query))
+
+	var numMatches int
+	for _, tweet := range tweetDB.Tweets {
+		text := tweet.NormalizedText
+		if text == "" {
+			text = tweet.Text
+		}
 
-func mergeSubjects(apiSubjects, existingSubjects []*WaniKaniSubject) []*WaniKaniSubject {
-	s := append(existingSubjects, apiSubjects...)
-	sort.SliceStable(s, func(i, j int) bool { return s[i].ID < s[j].ID })
-	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*WaniKaniSubject)
-	return sMerged
-}
+		if !strings.Contains(strings.ToLower(text), query) {
+			continue
+		}
 
-func mergeTweets(apiTweets, existingTweets []*Tweet) []*Tweet {
-	s := append(apiTweets, existingTweets...)
-	sort.SliceStable(s, func(i, j int) bool { return s[i].ID < s[j].ID })
-	flipDuplicateTweetsOnTrivialChanges(s)
-	sMerged := sliceUniq(s, func(i int) interface{} { return s[i].ID }).([]*Tweet)
-	sliceReverse(sMerged)
-	return sMerged
+		fmt.Fprintf(w, "%v  %v\n", tweet.CreatedAt.Format("2006-01-02"), tweet.ID)
+		fmt.Fprintf(w, "    %s\n\n", strings.ReplaceAll(tweet.Text, "\n", "\n    "))
+		numMatches++
+	}
+
+	fmt.Fprintf(w, "%v tweet(s) found\n", numMatches)
+
+	return nil
 }
 
-// Format which Goodreads returns time in implemented as a Go magic time
-// parsing string.
-const goodreadsTimeFormat = "Mon Jan 2 15:04:05 -0700 2006"
+// Sets ConversationRootText on each tweet that replies to one of user's own
+// tweets by following its chain of replies up to the first tweet that doesn't
+// reply to the user. It's left empty if any tweet in the chain is missing
+// (e.g. because it was deleted before it was ever synced).
+func setConversationRootTexts(tweets []*Tweet, user string) {
+	tweetsByID := make(map[int64]*Tweet, len(tweets))
+	for _, tweet := range tweets {
+		tweetsByID[tweet.ID] = tweet
+	}
 
-func readingFromAPIReview(review *APIReview) *Reading {
-	var authors []*ReadingAuthor
-	for _, author := range review.Book.Authors {
-		authors = append(authors, &ReadingAuthor{
-			ID:   author.ID,
-			Name: author.Name,
-		})
+	isSelfReply := func(tweet *Tweet) bool {
+		return tweet.Reply != nil && strings.EqualFold(tweet.Reply.User, user)
 	}
 
-	var readAt time.Time
-	if review.ReadAt != "" {
-		t, err := time.Parse(goodreadsTimeFormat, review.ReadAt)
-		if err != nil {
-			panic(err)
+	for _, tweet := range tweets {
+		tweet.ConversationRootText = ""
+
+		if !isSelfReply(tweet) {
+			continue
 		}
-		readAt = t
-	} else {
-		logger.Errorf("No read at time for book: %v", review.Book.Title)
-	}
 
-	return &Reading{
-		Authors:       authors,
-		ID:            review.Book.ID,
-		ISBN:          review.Book.ISBN,
-		ISBN13:        review.Book.ISBN13,
-		NumPages:      review.Book.NumPages,
-		PublishedYear: review.Book.PublishedYear,
-		ReadAt:        readAt,
-		Rating:        review.Rating,
-		Review:        sanitizeGoodreadsReview(review.Body),
-		ReviewID:      review.ID,
-		Title:         review.Book.Title,
+		// Tracks visited tweets in case of a cycle, which shouldn't be
+		// possible, but would otherwise loop forever.
+		visited := map[int64]struct{}{tweet.ID: {}}
+
+		root := tweet
+		for root != nil && isSelfReply(root) {
+			root = tweetsByID[root.Reply.StatusID]
+
+			if root != nil {
+				if _, ok := visited[root.ID]; ok {
+					root = nil
+					break
+				}
+				visited[root.ID] = struct{}{}
+			}
+		}
+
+		if root == nil {
+			continue
+		}
+
+		text := []rune(root.Text)
+		if len(text) > tweetConversationRootTextMaxLen {
+			text = text[:tweetConversationRootTextMaxLen]
+		}
+		tweet.ConversationRootText = string(text)
 	}
 }
 
-// Goodreads doesn't do a great job of keeping review bodies clean, and does
-// things like add HTML line breaks where the user has inserted newlines. Take
-// these out and leave the review looking roughly Markdown-esque.
-func sanitizeGoodreadsReview(review string) string {
-	review = htmlLineBreakRE.ReplaceAllString(review, "\n")
-	review = htmlLinkRE.ReplaceAllString(review, "$1")
+// Sets RereadCount on each reading by numbering the readings of every book in
+// the order in which they were read. Ties are broken by review ID so that the
+// numbering is stable across syncs.
+func setRereadCounts(readings []*Reading) {
+	groups := GroupBy(readings, func(reading *Reading) int { return reading.ID })
+	for _, bookReadings := range groups {
+		sort.SliceStable(bookReadings, func(i, j int) bool {
+			if !bookReadings[i].ReadAt.Equal(bookReadings[j].ReadAt) {
+				return bookReadings[i].ReadAt.Before(bookReadings[j].ReadAt)
+			}
+			return bookReadings[i].ReviewID < bookReadings[j].ReviewID
+		})
 
-	review = html.UnescapeString(review)
+		var n int
+		for _, reading := range bookReadings {
+			if reading.Abandoned {
+				reading.RereadCount = 0
+				continue
+			}
 
-	return strings.TrimSpace(review)
+			n++
+			reading.RereadCount = n
+		}
+	}
 }
 
-// Clean up anything from Twitter for tweet bodies.
-func sanitizeTweetText(text string) string {
-	return html.UnescapeString(text)
+// Filter returns the elements of s for which pred returns true, in their
+// original order. s isn't modified. The result is nil if no elements pass.
+func Filter[T any](s []T, pred func(T) bool) []T {
+	var kept []T
+	for _, elem := range s {
+		if pred(elem) {
+			kept = append(kept, elem)
+		}
+	}
+	return kept
+}
+
+// GroupBy groups the elements of s by the key that key returns for each of
+// them. Elements within a group keep their relative order from s.
+func GroupBy[T any, K comparable](s []T, key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for _, elem := range s {
+		k := key(elem)
+		groups[k] = append(groups[k], elem)
+	}
+	return groups
 }
 
 func sliceReverse(s interface{}) {
@@ -1149,6 +14716,392 @@ func sliceUniq(s interface{}, selector func(int) interface{}) interface{} {
 	return sSlice.Slice(0, j).Interface()
 }
 
+// Returns true if a reading has no date that it was read or abandoned at, like
+// a book shelved as read without a date.
+func readingUndated(reading *Reading) bool {
+	return reading.ReadAt.IsZero() && reading.AbandonedAt.IsZero()
+}
+
+// Orders in which records may be sorted when written to a TOML file.
+const (
+	sortOrderAsc  = "asc"
+	sortOrderDesc = "desc"
+)
+
+// Sorts readings by review ID. Goodreads review IDs increase over time, so
+// descending order puts the newest readings first.
+//
+// Undated readings (ones that were neither read nor abandoned at a known
+// time) are put after all dated ones regardless of order, and are sorted by
+// review ID among themselves.
+func sortReadings(readings []*Reading, order string) {
+	sort.SliceStable(readings, func(i, j int) bool {
+		iUndated, jUndated := readingUndated(readings[i]), readingUndated(readings[j])
+		if iUndated != jUndated {
+			return jUndated
+		}
+
+		if order == sortOrderAsc {
+			return readings[i].ReviewID < readings[j].ReviewID
+		}
+		return readings[i].ReviewID > readings[j].ReviewID
+	})
+}
+
+// Sorts tweets by ID. Tweet IDs increase over time, so descending order puts
+// the newest tweets first.
+func sortTweets(tweets []*Tweet, order string) {
+	sort.SliceStable(tweets, func(i, j int) bool {
+		if order == sortOrderAsc {
+			return tweets[i].ID < tweets[j].ID
+		}
+		return tweets[i].ID > tweets[j].ID
+	})
+}
+
+// Format of the dates accepted by Runkeeper's activity feed filters.
+const runkeeperDateFormat = "2006-01-02"
+
+// Media types that Runkeeper's API requires in the Accept header.
+const (
+	runkeeperMediaTypeActivity = "application/vnd.com.runkeeper.FitnessActivity+json"
+	runkeeperMediaTypeFeed     = "application/vnd.com.runkeeper.FitnessActivityFeed+json"
+)
+
+// Number of activities requested from Runkeeper in a single page of the feed.
+const runkeeperPageSize = 100
+
+// Activities can be edited after they're recorded (to add notes for example),
+// so when syncing incrementally we start this many days before the last one
+// that's stored.
+const runkeeperRefetchDays = 7
+
+// Format of activity start times in Runkeeper's API. It's like RFC 2822's, but
+// without a time zone.
+const runkeeperStartTimeFormat = "Mon, 2 Jan 2006 15:04:05"
+
+func runkeeperActivityFromAPIActivity(activity *RunkeeperAPIActivity) (*RunkeeperActivity, error) {
+	zone := time.FixedZone("", int(activity.UTCOffset*60*60))
+
+	startTime, err := time.ParseInLocation(runkeeperStartTimeFormat, activity.StartTime, zone)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing start time of activity %v: %w", activity.URI, err)
+	}
+
+	var pace float64
+	if activity.TotalDistance > 0 {
+		pace = (activity.Duration / 60) / (activity.TotalDistance / 1000)
+	}
+
+	return &RunkeeperActivity{
+		AverageHeartrate:    activity.AverageHeartRate,
+		AveragePaceMinPerKm: pace,
+		Duration:            activity.Duration,
+		Notes:               activity.Notes,
+		StartTime:           startTime.UTC(),
+		TotalCalories:       activity.TotalCalories,
+		TotalDistanceM:      activity.TotalDistance,
+		Type:                activity.Type,
+		URI:                 activity.URI,
+	}, nil
+}
+
+func runningWorkoutFromCSVValues(value func(column string) string) (*RunningWorkout, error) {
+	date, err := parseRunningAheadDate(value("date"))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing date: %w", err)
+	}
+
+	workout := &RunningWorkout{
+		ActivityType: value("type"),
+		Date:         date,
+		ID:           hashSHA1(date.Format(time.RFC3339) + "\n" + value("course")),
+		Notes:        value("notes"),
+		ShoeID:       value("shoe"),
+	}
+
+	if s := value("cadence"); s != "" {
+		workout.CadenceRPM, err = strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing cadence: %w", err)
+		}
+	}
+
+	if s := value("distance"); s != "" {
+		miles, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing distance: %w", err)
+		}
+		workout.CourseKm = miles * kmPerMile
+	}
+
+	if s := value("hr"); s != "" {
+		workout.AvgHR, err = strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing heart rate: %w", err)
+		}
+	}
+
+	if s := value("time"); s != "" {
+		workout.DurationSec, err = parseRunningAheadDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing time: %w", err)
+		}
+	}
+
+	return workout, nil
+}
+
+func sleepCycleNightFromCSVValues(value func(column string) string) (*SleepCycleNight, error) {
+	startAt, err := parseSleepCycleTime(value("start"))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing start: %w", err)
+	}
+
+	endAt, err := parseSleepCycleTime(value("end"))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing end: %w", err)
+	}
+
+	night := &SleepCycleNight{
+		DurationMin: int(math.Round(endAt.Sub(startAt).Minutes())),
+		EndAt:       endAt,
+		ID:          startAt.Format(time.RFC3339),
+		Notes:       value("notes"),
+		StartAt:     startAt,
+	}
+
+	if s := value("heart_rate"); s != "" {
+		night.HeartRateBPM, err = strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing heart rate: %w", err)
+		}
+	}
+
+	// Quality is formatted like "85%".
+	if s := strings.TrimSuffix(value("quality"), "%"); s != "" {
+		night.QualityPct, err = strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing quality: %w", err)
+		}
+	}
+
+	if s := value("steps"); s != "" {
+		night.Steps, err = strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing steps: %w", err)
+		}
+	}
+
+	return night, nil
+}
+
+// Returns the URL of a game's icon given the hash that Steam's API returns for
+// it, or an empty string if the game has no icon.
+func steamIconURL(appID int, hash string) string {
+	if hash == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("https://media.steampowered.com/steamcommunity/public/images/apps/%v/%v.jpg", appID, hash)
+}
+
+func steamGameFromAPIGame(game *SteamAPIGame) *SteamGame {
+	var lastPlayedAt time.Time
+	if game.RTimeLastPlayed != 0 {
+		lastPlayedAt = time.Unix(game.RTimeLastPlayed, 0).UTC()
+	}
+
+	return &SteamGame{
+		AppID:               game.AppID,
+		ImgIconURL:          steamIconURL(game.AppID, game.ImgIconURL),
+		LastPlayedAt:        lastPlayedAt,
+		Name:                game.Name,
+		PlaytimeForeverMin:  game.PlaytimeForever,
+		PlaytimeTwoWeeksMin: game.PlaytimeTwoWeeks,
+	}
+}
+
+// Maximum number of objects requested in a single page from Stripe.
+const stripePageLimit = 100
+
+// Charges can be refunded and payouts change status after they're created, so
+// when syncing incrementally we start this many days before the last one
+// that's stored.
+const stripeRefetchDays = 90
+
+func stripeBalanceFromAPIPayout(payout *StripeAPIPayout) *StripeBalance {
+	return &StripeBalance{
+		AmountCents:          payout.Amount,
+		ArrivalAt:            time.Unix(payout.ArrivalDate, 0).UTC(),
+		BalanceTransactionID: payout.BalanceTransaction,
+		CreatedAt:            time.Unix(payout.Created, 0).UTC(),
+		Currency:             payout.Currency,
+		Description:          payout.Description,
+		ID:                   payout.ID,
+		Method:               payout.Method,
+		Status:               payout.Status,
+	}
+}
+
+func stripeChargeFromAPICharge(charge *StripeAPICharge) *StripeCharge {
+	customerEmail := charge.ReceiptEmail
+	if charge.BillingDetails != nil && charge.BillingDetails.Email != "" {
+		customerEmail = charge.BillingDetails.Email
+	}
+
+	return &StripeCharge{
+		AmountCents:          charge.Amount,
+		BalanceTransactionID: charge.BalanceTransaction,
+		CreatedAt:            time.Unix(charge.Created, 0).UTC(),
+		Currency:             charge.Currency,
+		CustomerEmail:        customerEmail,
+		Description:          charge.Description,
+		ID:                   charge.ID,
+		Refunded:             charge.Refunded,
+		RefundedAmountCents:  charge.AmountRefunded,
+	}
+}
+
+// Maximum number of posts requested in a single page from Substack.
+const substackPageLimit = 50
+
+func substackPostFromAPIPost(post *SubstackAPIPost) *SubstackPost {
+	return &SubstackPost{
+		AudioURL:      post.AudioURL,
+		ClickRate:     post.ClickRate,
+		ID:            post.ID,
+		Likes:         post.ReactionCount,
+		OpenRate:      post.OpenRate,
+		PodcastURL:    post.PodcastURL,
+		PublishedAt:   post.PostDate.UTC(),
+		Slug:          post.Slug,
+		Title:         post.Title,
+		TruncatedBody: post.TruncatedBodyText,
+		Type:          post.Type,
+		Views:         post.Views,
+	}
+}
+
+// Maximum number of updates that Telegram returns in a single page.
+const telegramPageLimit = 100
+
+// Converts an update into a message if it's from the given channel, either
+// because it was posted to the channel or because it was forwarded from the
+// channel to the bot. Returns nil otherwise.
+func telegramMessageFromAPIUpdate(update *TelegramAPIUpdate, channelID int64) *TelegramMessage {
+	if post := update.ChannelPost; post != nil && post.Chat != nil && post.Chat.ID == channelID {
+		message := &TelegramMessage{
+			Caption:   post.Caption,
+			Date:      time.Unix(post.Date, 0).UTC(),
+			MessageID: post.MessageID,
+			Text:      post.Text,
+		}
+
+		if post.ForwardFromChat != nil {
+			message.ForwardFromChannel = post.ForwardFromChat.Title
+		}
+
+		return message
+	}
+
+	if forward := update.Message; forward != nil && forward.ForwardFromChat != nil &&
+		forward.ForwardFromChat.ID == channelID {
+		// A forwarded message has its own ID and date in the bot's chat, so
+		// use the original message's instead.
+		return &TelegramMessage{
+			Caption:   forward.Caption,
+			Date:      time.Unix(forward.ForwardDate, 0).UTC(),
+			MessageID: forward.ForwardFromMessageID,
+			Text:      forward.Text,
+		}
+	}
+
+	return nil
+}
+
+// Format in which Toggl accepts dates.
+const togglDateFormat = "2006-01-02"
+
+// Toggl only serves time entries from roughly the last three months, so a
+// first sync starts from this many days ago.
+const togglLookbackDays = 89
+
+// Entries may be edited after they're stopped (e.g. to fix a description or
+// assign a project), so when syncing incrementally we start this many days
+// before the last one that's stored.
+const togglRefetchDays = 7
+
+const togglWindowDays = 30
+
+func togglEntryFromAPITimeEntry(entry *TogglAPITimeEntry, projects map[int64]*TogglAPIProject) *TogglEntry {
+	togglEntry := &TogglEntry{
+		Billable:        entry.Billable,
+		Description:     entry.Description,
+		DurationSeconds: entry.Duration,
+		ID:              entry.ID,
+		StartedAt:       entry.Start.UTC(),
+		Tags:            entry.Tags,
+		WorkspaceID:     entry.WorkspaceID,
+	}
+
+	if entry.ProjectID != nil {
+		togglEntry.ProjectID = *entry.ProjectID
+
+		if project, ok := projects[*entry.ProjectID]; ok {
+			togglEntry.ProjectName = project.Name
+		}
+	}
+
+	if entry.Stop != nil {
+		togglEntry.StoppedAt = entry.Stop.UTC()
+	}
+
+	return togglEntry
+}
+
+// Format in which WakaTime returns and accepts dates.
+const wakaTimeDateFormat = "2006-01-02"
+
+// WakaTime recalculates past days when heartbeats arrive late, so when
+// syncing incrementally we start this many days before the last one that's
+// stored, and always prefer the API's version of those days.
+const wakaTimeRefetchDays = 7
+
+// Number of days of data requested from WakaTime at once.
+const wakaTimeWindowDays = 30
+
+func wakaTimeDayFromAPISummary(summary *WakaTimeAPISummary) (*WakaTimeDay, error) {
+	if summary.Range == nil || summary.GrandTotal == nil {
+		return nil, fmt.Errorf("summary missing range or grand total")
+	}
+
+	date, err := time.Parse(wakaTimeDateFormat, summary.Range.Date)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing summary date: %w", err)
+	}
+
+	day := &WakaTimeDay{
+		Date:         date,
+		TotalSeconds: int(summary.GrandTotal.TotalSeconds),
+	}
+
+	for _, stat := range summary.Editors {
+		day.Editors = append(day.Editors, &WakaTimeEditor{Name: stat.Name, Seconds: int(stat.TotalSeconds)})
+	}
+	for _, stat := range summary.Languages {
+		day.Languages = append(day.Languages, &WakaTimeLang{Name: stat.Name, Seconds: int(stat.TotalSeconds)})
+	}
+	for _, stat := range summary.OperatingSystems {
+		day.OSes = append(day.OSes, &WakaTimeOS{Name: stat.Name, Seconds: int(stat.TotalSeconds)})
+	}
+	for _, stat := range summary.Projects {
+		day.Projects = append(day.Projects, &WakaTimeProject{Name: stat.Name, Seconds: int(stat.TotalSeconds)})
+	}
+
+	return day, nil
+}
+
 func waniKaniReviewFromAPIReview(review *wanikaniapi.Review) *WaniKaniReview {
 	return &WaniKaniReview{
 		AssignmentID: int64(review.Data.AssignmentID),
@@ -1192,3 +15145,132 @@ func waniKaniSubjectFromAPISubject(subject *wanikaniapi.Subject) *WaniKaniSubjec
 
 	panic("unknown subject type")
 }
+
+// Withings measurements are occasionally uploaded late (e.g. by a scale that
+// was offline), so when syncing incrementally we start this many days before
+// the last one that's stored.
+const withingsRefetchDays = 7
+
+// Converts measure groups into measurements. Withings may split measures
+// taken at the same time (like a weighing and a heart rate reading) across
+// groups, so groups with the same date are combined into one measurement.
+func withingsMeasurementsFromAPIGroups(groups []*WithingsAPIMeasureGroup) []*WithingsMeasurement {
+	var measurements []*WithingsMeasurement
+	measurementsByDate := make(map[int64]*WithingsMeasurement)
+
+	// Kilogram values that are converted to percentages of weight once every
+	// group has been seen.
+	hydrationKgByDate := make(map[int64]float64)
+	muscleMassKgByDate := make(map[int64]float64)
+
+	for _, group := range groups {
+		measurement, ok := measurementsByDate[group.Date]
+		if !ok {
+			measurement = &WithingsMeasurement{Date: time.Unix(group.Date, 0).UTC()}
+			measurementsByDate[group.Date] = measurement
+			measurements = append(measurements, measurement)
+		}
+
+		for _, measure := range group.Measures {
+			value := float64(measure.Value) * math.Pow10(measure.Unit)
+
+			switch measure.Type {
+			case withingsMeasureTypeBoneMass:
+				measurement.BoneMassKg = value
+			case withingsMeasureTypeFatRatio:
+				measurement.BodyFatPct = value
+			case withingsMeasureTypeHeartPulse:
+				measurement.HeartRateBPM = int(math.Round(value))
+			case withingsMeasureTypeHydration:
+				hydrationKgByDate[group.Date] = value
+			case withingsMeasureTypeMuscleMass:
+				muscleMassKgByDate[group.Date] = value
+			case withingsMeasureTypeWeight:
+				measurement.WeightKg = value
+			}
+		}
+	}
+
+	for date, measurement := range measurementsByDate {
+		if measurement.WeightKg == 0 {
+			continue
+		}
+
+		measurement.HydrationPct = hydrationKgByDate[date] / measurement.WeightKg * 100
+		measurement.MuscleMassPct = muscleMassKgByDate[date] / measurement.WeightKg * 100
+	}
+
+	return measurements
+}
+
+// Returns the path to which refreshed Withings tokens are saved, which is
+// next to the data file with "_token" appended to its name, like
+// withings_token.toml for withings.toml.
+func withingsTokenPath(targetPath string) string {
+	return strings.TrimSuffix(targetPath, filepath.Ext(targetPath)) + "_token.toml"
+}
+
+// Saves Withings tokens to path. Unlike data files, it's only readable by the
+// current user because it holds credentials.
+func writeWithingsToken(path string, token *WithingsToken) error {
+	data, err := toml.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("error marshaling toml: %w", err)
+	}
+
+	err = ioutil.WriteFile(path, data, 0600)
+	if err != nil {
+		return fmt.Errorf("error writing token file: %w", err)
+	}
+
+	return nil
+}
+
+// Reads the TOML file at path into v, decoding it from whichever of the
+// supported output encodings it was written with.
+func readTOMLFile(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading data file: %w", err)
+	}
+
+	data, err = decodeOutput(data)
+	if err != nil {
+		return err
+	}
+
+	err = toml.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling toml: %w", err)
+	}
+
+	return nil
+}
+
+// Marshals the given value to TOML and writes it to targetPath, compacting it
+// first if --compact-toml was given.
+func writeTOMLFile(targetPath string, v interface{}) error {
+	data, err := toml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshaling toml: %w", err)
+	}
+
+	if rootOptions.CompactTOML {
+		data, err = compactTOML(data)
+		if err != nil {
+			return fmt.Errorf("error compacting toml: %w", err)
+		}
+	}
+
+	data, err = encodeOutput(data, rootOptions.OutputEncoding)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(targetPath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing data file: %w", err)
+	}
+
+	return nil
+}
@@ -1,276 +1,6601 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/pelletier/go-toml"
 	assert "github.com/stretchr/testify/require"
 )
 
-func TestMergeReadings(t *testing.T) {
-	t.Run("Standard", func(t *testing.T) {
-		s1 := []*Reading{
-			{ReviewID: 125, Review: "s1 125"},
-			{ReviewID: 124, Review: "s1 124"},
-			{ReviewID: 123, Review: "s1 123"},
-			{ReviewID: 122, Review: "s1 122"},
-		}
-		s2 := []*Reading{
-			{ReviewID: 124, Review: "s2 124"},
-			{ReviewID: 123, Review: "s2 123"},
-		}
+// Pass -update to rewrite golden files in testdata with current output.
+var updateGolden = flag.Bool("update", false, "Update golden files")
 
-		s := mergeReadings(s1, s2)
+func TestComputeEngagementScore(t *testing.T) {
+	tweet := &Tweet{BookmarkCount: 8, FavoriteCount: 10, ReplyCount: 4, RetweetCount: 3}
 
-		assert.Equal(
-			t,
-			[]*Reading{
-				{ReviewID: 125, Review: "s1 125"},
-				{ReviewID: 124, Review: "s1 124"}, // s1 is preferred
-				{ReviewID: 123, Review: "s1 123"}, // s1 is preferred
-				{ReviewID: 122, Review: "s1 122"},
-			},
-			s,
-		)
+	t.Run("DefaultWeights", func(t *testing.T) {
+		// 10*1.0 + 3*2.0 + 4*1.5 + 8*0.5
+		assert.Equal(t, 26.0, computeEngagementScore(tweet, nil))
 	})
 
-	t.Run("RemoveOld", func(t *testing.T) {
-		s1 := []*Reading{
-			{ReviewID: 125},
-			{ReviewID: 123},
-		}
-		s2 := []*Reading{
-			{ReviewID: 125},
-			{ReviewID: 124},
-			{ReviewID: 123},
-		}
+	t.Run("CustomWeights", func(t *testing.T) {
+		// 10*0.0 + 3*1.0 + 4*2.0 + 8*0.25
+		assert.Equal(t, 13.0, computeEngagementScore(tweet, []float64{0.0, 1.0, 2.0, 0.25}))
+	})
+}
+
+func TestComputeLikesByFollowers(t *testing.T) {
+	t.Run("Followers", func(t *testing.T) {
+		tweets := []*Tweet{{FavoriteCount: 100}, {FavoriteCount: 0}}
+		computeLikesByFollowers(tweets, 1000)
+		assert.Equal(t, 0.1, tweets[0].LikesByFollowers)
+		assert.Equal(t, 0.0, tweets[1].LikesByFollowers)
+	})
+
+	t.Run("NoFollowers", func(t *testing.T) {
+		tweets := []*Tweet{{FavoriteCount: 100}}
+		computeLikesByFollowers(tweets, 0)
+		assert.Equal(t, 0.0, tweets[0].LikesByFollowers)
+	})
+}
+
+func TestComputeNomadStats(t *testing.T) {
+	stays := []*NomadStay{
+		{CountryCode: "PT", DurationDays: 14},
+		{CountryCode: "DE", DurationDays: 7},
+		{CountryCode: "US", DurationDays: 30},
+	}
+
+	t.Run("HomeCountry", func(t *testing.T) {
+		stats := computeNomadStats(stays, "us")
+		assert.Equal(t, 3, stats.NumStays)
+		assert.Equal(t, 21, stats.TotalDaysAbroad)
+	})
+
+	t.Run("NoHomeCountry", func(t *testing.T) {
+		stats := computeNomadStats(stays, "")
+		assert.Equal(t, 51, stats.TotalDaysAbroad)
+	})
+}
+
+func TestComputePodcastStats(t *testing.T) {
+	stats := computePodcastStats([]*PodcastEpisode{
+		{PlayedAt: time.Date(2021, 1, 6, 13, 30, 0, 0, time.UTC), Progress: 7200},
+		{Progress: 1800},
+		{},
+	})
+	assert.Equal(t, 3, stats.NumEpisodes)
+	assert.Equal(t, 1, stats.NumPlayed)
+	assert.Equal(t, 2.5, stats.HoursListened)
+}
+
+func TestComputeReadingStats(t *testing.T) {
+	readings := []*Reading{
+		{Authors: []*ReadingAuthor{
+			{Name: "Homer"},
+			{Name: "Robert Fagles", Role: "Translator"},
+		}},
+		{Authors: []*ReadingAuthor{
+			{Name: "Homer", Role: "Author"},
+			{Name: "Emily Wilson", Role: "Translator"},
+		}},
+		{Authors: []*ReadingAuthor{
+			{Name: "Virgil"},
+			{Name: "Robert Fagles", Role: "Translator"},
+		}},
+	}
+
+	stats := computeReadingStats(readings)
+
+	assert.Equal(t, 3, stats.NumReadings)
+	assert.Equal(
+		t,
+		[]*AuthorCount{
+			{Count: 2, Name: "Homer"},
+			{Count: 1, Name: "Virgil"},
+		},
+		stats.PrimaryAuthors,
+	)
+	assert.Equal(
+		t,
+		[]*AuthorCount{
+			{Count: 2, Name: "Robert Fagles", Role: "Translator"},
+			{Count: 1, Name: "Emily Wilson", Role: "Translator"},
+		},
+		stats.OtherContributors,
+	)
+
+	t.Run("AbandonRate", func(t *testing.T) {
+		stats := computeReadingStats([]*Reading{
+			{ReadAt: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)},
+			{ReadAt: time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)},
+			{ReadAt: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)},
+			{Abandoned: true, AbandonedAt: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), ReadingSpeedPPD: 5},
+		})
+
+		assert.Equal(t, 3, stats.NumReadings)
+		assert.Equal(t, 1, stats.NumAbandonedReadings)
+		assert.Equal(t, 0.25, stats.AbandonRate)
+		assert.Equal(t, 0, stats.NumReadingSpeeds)
+	})
+
+	t.Run("RatingCorrelation", func(t *testing.T) {
+		stats := computeReadingStats([]*Reading{
+			{Rating: 5, CommunityRating: 4.5},
+			{Rating: 3, CommunityRating: 3.5},
+			{Rating: 1, CommunityRating: 2.5},
+			{Rating: 0, CommunityRating: 4.0}, // unrated; ignored
+		})
+
+		assert.Equal(t, 3, stats.NumRatedReadings)
+		assert.True(t, stats.RatingCorrelationOK)
+		assert.InDelta(t, 1.0, stats.RatingCorrelation, 0.0001)
+	})
+
+	t.Run("RatingCorrelationUndefined", func(t *testing.T) {
+		stats := computeReadingStats([]*Reading{
+			{Rating: 4, CommunityRating: 4.5},
+		})
 
-		s := mergeReadings(s1, s2)
+		assert.False(t, stats.RatingCorrelationOK)
+	})
+
+	t.Run("ReadingsByFormat", func(t *testing.T) {
+		stats := computeReadingStats([]*Reading{
+			{Format: "Paperback"},
+			{Format: "ebook"},
+			{Format: "Hardcover"},
+			{Format: "ebook"},
+			{}, // no format; ignored
+		})
 
 		assert.Equal(
 			t,
-			[]*Reading{
-				{ReviewID: 125},
-				{ReviewID: 123},
+			[]*FormatCount{
+				{Count: 2, Format: "ebook"},
+				{Count: 1, Format: "Hardcover"},
+				{Count: 1, Format: "Paperback"},
 			},
-			s,
+			stats.ReadingsByFormat,
 		)
 	})
-}
-
-func TestMergeTweets(t *testing.T) {
-	t.Run("Standard", func(t *testing.T) {
-		s1 := []*Tweet{
-			{ID: 125, Text: "s1 125"},
-			{ID: 124, Text: "s1 124"},
-			{ID: 122, Text: "s1 122"},
-		}
-		s2 := []*Tweet{
-			{ID: 124, Text: "s2 124"},
-			{ID: 123, Text: "s2 123"},
-			{ID: 121, Text: "s2 121"},
-		}
 
-		s := mergeTweets(s1, s2)
+	t.Run("ReadingsByLanguage", func(t *testing.T) {
+		stats := computeReadingStats([]*Reading{
+			{Language: "fr"},
+			{Language: "en"},
+			{Language: "de"},
+			{Language: "en"},
+			{}, // no language; ignored
+		})
 
 		assert.Equal(
 			t,
-			[]*Tweet{
-				{ID: 125, Text: "s1 125"},
-				{ID: 124, Text: "s1 124"}, // s1 is preferred
-				{ID: 123, Text: "s2 123"},
-				{ID: 122, Text: "s1 122"},
-				{ID: 121, Text: "s2 121"},
+			[]*LanguageCount{
+				{Count: 2, Language: "en"},
+				{Count: 1, Language: "de"},
+				{Count: 1, Language: "fr"},
 			},
-			s,
+			stats.ReadingsByLanguage,
 		)
 	})
 
-	t.Run("NewPreferredOnNonTrivialChangesInc", func(t *testing.T) {
-		s1 := []*Tweet{
-			{ID: 125, Text: "s1 125"},
-			// Text should be the same
-			{ID: 124, Text: "sX 124", FavoriteCount: 10, RetweetCount: 10},
-		}
-		s2 := []*Tweet{
-			{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2},
-			{ID: 123, Text: "s2 123"},
-		}
-
-		s := mergeTweets(s1, s2)
+	t.Run("ReadingsByRecommender", func(t *testing.T) {
+		stats := computeReadingStats([]*Reading{
+			{RecommendedBy: "Bob"},
+			{RecommendedBy: "Alice"},
+			{RecommendedBy: "Carol"},
+			{RecommendedBy: "Carol"},
+			{}, // no recommender; ignored
+		})
 
 		assert.Equal(
 			t,
-			[]*Tweet{
-				{ID: 125, Text: "s1 125"},
-				{ID: 124, Text: "sX 124", FavoriteCount: 10, RetweetCount: 10}, // s1 is preferred
-				{ID: 123, Text: "s2 123"},
+			[]*RecommenderCount{
+				{Count: 2, Name: "Carol"},
+				{Count: 1, Name: "Alice"},
+				{Count: 1, Name: "Bob"},
 			},
-			s,
+			stats.ReadingsByRecommender,
 		)
 	})
 
-	t.Run("NewPreferredOnNonTrivialChangesDec", func(t *testing.T) {
-		s1 := []*Tweet{
-			{ID: 125, Text: "s1 125"},
-			// Text should be the same
-			{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2},
-		}
-		s2 := []*Tweet{
-			{ID: 124, Text: "sX 124", FavoriteCount: 10, RetweetCount: 10},
-			{ID: 123, Text: "s2 123"},
-		}
-
-		s := mergeTweets(s1, s2)
+	t.Run("ReadingsByYear", func(t *testing.T) {
+		stats := computeReadingStats([]*Reading{
+			{ReadAt: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)},
+			{ReadAt: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)},
+			{ReadAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{}, // not read; ignored
+		})
 
 		assert.Equal(
 			t,
-			[]*Tweet{
-				{ID: 125, Text: "s1 125"},
-				{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2}, // s1 is preferred
-				{ID: 123, Text: "s2 123"},
+			[]*PeriodCount{
+				{Count: 1, Period: "2019"},
+				{Count: 2, Period: "2021"},
 			},
-			s,
+			stats.ReadingsByYear,
 		)
 	})
 
-	t.Run("OldPreferredOnTrivialChanges", func(t *testing.T) {
-		s1 := []*Tweet{
-			{ID: 125, Text: "s1 125"},
-			// Text must be the same for this to work
-			{ID: 124, Text: "sX 124", FavoriteCount: 4, RetweetCount: 4},
-		}
-		s2 := []*Tweet{
-			{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2},
-			{ID: 123, Text: "s2 123"},
-		}
-
-		s := mergeTweets(s1, s2)
+	t.Run("ReadingSpeed", func(t *testing.T) {
+		stats := computeReadingStats([]*Reading{
+			{ReadingSpeedPPD: 5},
+			{ReadingSpeedPPD: 20},
+			{ReadingSpeedPPD: 25},
+			{ReadingSpeedPPD: 150},
+			{ReadingSpeedPPD: 0}, // unknown; ignored
+		})
 
+		assert.Equal(t, 4, stats.NumReadingSpeeds)
+		assert.Equal(t, 50.0, stats.ReadingSpeedAvgPPD)
 		assert.Equal(
 			t,
-			[]*Tweet{
-				{ID: 125, Text: "s1 125"},
-				{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2}, // s2 is preferred
-				{ID: 123, Text: "s2 123"},
+			[]*ReadingSpeedBucket{
+				{Count: 1, Min: 0, Max: 10},
+				{Count: 1, Min: 10, Max: 25},
+				{Count: 1, Min: 25, Max: 50},
+				{Count: 0, Min: 50, Max: 100},
+				{Count: 1, Min: 100},
 			},
-			s,
+			stats.ReadingSpeedBuckets,
 		)
 	})
 
-	t.Run("NewPreferredOnTrivialChangesIfEntitiesDifferent", func(t *testing.T) {
-		s1 := []*Tweet{
-			{ID: 125, Text: "s1 125"},
-			{ID: 124, Text: "sX 124", FavoriteCount: 4, RetweetCount: 4,
-				Entities: &TweetEntities{Medias: []*TweetEntitiesMedia{{URL: "https://foo.com"}}}},
-		}
-		s2 := []*Tweet{
-			{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2,
-				Entities: &TweetEntities{Medias: []*TweetEntitiesMedia{{URL: "https://bar.com"}}}},
-			{ID: 123, Text: "s2 123"},
-		}
-
-		s := mergeTweets(s1, s2)
+	t.Run("RereadBooks", func(t *testing.T) {
+		stats := computeReadingStats([]*Reading{
+			{ID: 1, Title: "The Odyssey"},
+			{ID: 1, Title: "The Odyssey"},
+			{ID: 1, Title: "The Odyssey"},
+			{ID: 2, Title: "The Aeneid"},
+			{ID: 2, Title: "The Aeneid"},
+			{ID: 3, Title: "The Iliad"},
+			{ID: 3, Title: "The Iliad", Abandoned: true}, // abandoned; ignored
+		})
 
 		assert.Equal(
 			t,
-			[]*Tweet{
-				{ID: 125, Text: "s1 125"},
-				{ID: 124, Text: "sX 124", FavoriteCount: 4, RetweetCount: 4,
-					Entities: &TweetEntities{Medias: []*TweetEntitiesMedia{{URL: "https://foo.com"}}}},
-				{ID: 123, Text: "s2 123"},
+			[]*BookCount{
+				{Count: 3, Title: "The Odyssey"},
+				{Count: 2, Title: "The Aeneid"},
 			},
-			s,
+			stats.RereadBooks,
 		)
 	})
+
+	t.Run("SentimentByYear", func(t *testing.T) {
+		stats := computeReadingStats([]*Reading{
+			{MeanSentiment: -0.6, MeanSentimentComputed: true, ReadAt: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)},
+			{MeanSentiment: 0.5, MeanSentimentComputed: true, ReadAt: time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)},
+			{MeanSentiment: 0.8, MeanSentimentComputed: true, ReadAt: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)},
+			{MeanSentiment: 0.2, MeanSentimentComputed: true, ReadAt: time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)},
+			{MeanSentimentComputed: true, ReadAt: time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)}, // neutral; counted
+			{ReadAt: time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC)},                              // no sentiment; ignored
+			{MeanSentiment: 0.4, MeanSentimentComputed: true},                                  // not read; ignored
+		})
+
+		assert.Len(t, stats.SentimentByYear, 2)
+
+		assert.Equal(t, "2019", stats.SentimentByYear[0].Period)
+		assert.Equal(t, []int{0, 0, 1, 1, 1}, stats.SentimentByYear[0].Counts)
+		assert.InDelta(t, 0.3333, stats.SentimentByYear[0].Mean, 0.0001)
+
+		// A sentiment on a bound falls into the bucket above it.
+		assert.Equal(t, "2021", stats.SentimentByYear[1].Period)
+		assert.Equal(t, []int{1, 0, 0, 0, 1}, stats.SentimentByYear[1].Counts)
+		assert.InDelta(t, -0.05, stats.SentimentByYear[1].Mean, 0.0001)
+	})
+
+	t.Run("WordsRead", func(t *testing.T) {
+		stats := computeReadingStats([]*Reading{
+			{WordCount: 100000},
+			{WordCount: 50000},
+			{},                                  // no page count; nothing added
+			{Abandoned: true, WordCount: 70000}, // abandoned; ignored
+		})
+
+		assert.Equal(t, 150000, stats.WordsRead)
+	})
 }
 
-func TestSanitizeGoodreadsReview(t *testing.T) {
-	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello"))
-	assert.Equal(t, "hello", sanitizeGoodreadsReview("   hello   "))
-	assert.Equal(t, "hel lo", sanitizeGoodreadsReview("   hel lo   "))
+func TestAnnotate(t *testing.T) {
+	readingsPath := filepath.Join(t.TempDir(), "goodreads.toml")
+	err := writeTOMLFile(readingsPath, &ReadingDB{
+		Readings: []*Reading{
+			{ReviewID: 124, Title: "Book 124"},
+			{ReviewID: 123, Title: "Book 123", Notes: "old", RecommendedBy: "Bob"},
+		},
+		Version: SchemaVersion,
+	})
+	assert.NoError(t, err)
 
-	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello<br>"))
-	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello<br><br>"))
-	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello<br >"))
-	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello<br/>"))
-	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello<br />"))
+	t.Run("Standard", func(t *testing.T) {
+		err := annotate(&AnnotateOptions{Notes: "My private note", NotesSet: true, ReadingsPath: readingsPath, ReviewID: 123})
+		assert.NoError(t, err)
 
-	assert.Equal(
-		t,
-		"http://example.com/hello/there",
-		sanitizeGoodreadsReview(`<a href="http://example.com/hello/there">anything</a>`),
-	)
+		readingDB, err := readReadingDB(readingsPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "", readingDB.Readings[0].Notes)
+		assert.Equal(t, "My private note", readingDB.Readings[1].Notes)
+		assert.Equal(t, "Bob", readingDB.Readings[1].RecommendedBy) // unchanged
+	})
 
-	assert.Equal(
-		t,
-		"http://example.com/hello/there",
-		sanitizeGoodreadsReview(`<a target="_blank" href="http://example.com/hello/there">anything</a>`),
-	)
+	t.Run("RecommendedBy", func(t *testing.T) {
+		err := annotate(&AnnotateOptions{RecommendedBy: "Alice", RecommendedBySet: true, ReadingsPath: readingsPath, ReviewID: 123})
+		assert.NoError(t, err)
 
-	assert.Equal(
-		t,
-		"http://example.com/hello/there",
-		sanitizeGoodreadsReview(`<a href="http://example.com/hello/there" target="_blank">anything</a>`),
-	)
+		readingDB, err := readReadingDB(readingsPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "Alice", readingDB.Readings[1].RecommendedBy)
+		assert.Equal(t, "My private note", readingDB.Readings[1].Notes) // unchanged
+	})
 
-	assert.Equal(
-		t,
-		"link to http://example.com/hello/there here",
-		sanitizeGoodreadsReview(`link to <a href="http://example.com/hello/there">anything</a> here`),
-	)
+	t.Run("NothingToAnnotate", func(t *testing.T) {
+		err := annotate(&AnnotateOptions{ReadingsPath: readingsPath, ReviewID: 123})
+		assert.EqualError(t, err, "--notes or --recommended-by is required")
+	})
 
-	assert.Equal(
-		t,
-		"http://example.com/hello/there http://example.com/hello/there",
-		sanitizeGoodreadsReview(`<a href="http://example.com/hello/there">anything</a> <a href="http://example.com/hello/there">anything</a>`),
-	)
+	t.Run("NotFound", func(t *testing.T) {
+		err := annotate(&AnnotateOptions{Notes: "My private note", NotesSet: true, ReadingsPath: readingsPath, ReviewID: 999})
+		assert.EqualError(t, err, fmt.Sprintf("no reading with review ID 999 in '%s'", readingsPath))
+	})
 
-	assert.Equal(
-		t,
-		"http://example.com/hello/there?a=b&c=d",
-		sanitizeGoodreadsReview(`<a href="http://example.com/hello/there?a=b&amp;c=d">anything</a>`),
-	)
+	t.Run("MissingReviewID", func(t *testing.T) {
+		err := annotate(&AnnotateOptions{Notes: "My private note", NotesSet: true, ReadingsPath: readingsPath})
+		assert.EqualError(t, err, "--review-id is required")
+	})
 }
 
-func TestSanitizeTweetText(t *testing.T) {
-	assert.Equal(t, "hello", sanitizeTweetText("hello"))
-	assert.Equal(t, "<tag>", sanitizeTweetText("<tag>"))
-	assert.Equal(t, "<tag>", sanitizeTweetText("&lt;tag&gt;"))
+func TestApplyTwitterAPICards(t *testing.T) {
+	tweets := []*Tweet{
+		{ID: 123},
+		{ID: 124},
+		{ID: 125},
+	}
+
+	applyTwitterAPICards(tweets, []*TwitterAPICardTweet{
+		{ID: 123, Card: &TwitterAPICard{
+			Name: "summary_large_image",
+			BindingValues: map[string]*TwitterAPICardBindingValue{
+				"description":     {StringValue: "A story about things.", Type: "STRING"},
+				"thumbnail_image": {Type: "IMAGE"},
+				"title":           {StringValue: "Things", Type: "STRING"},
+			},
+		}},
+		{ID: 124, Card: &TwitterAPICard{Name: "player"}},
+		{ID: 125},
+	})
+
+	assert.Equal(t, &TweetCard{
+		CardDescription: "A story about things.",
+		CardTitle:       "Things",
+		CardType:        "summary_large_image",
+	}, tweets[0].Card)
+	assert.Equal(t, &TweetCard{CardType: "player"}, tweets[1].Card)
+	assert.Nil(t, tweets[2].Card) // no card
 }
 
-func TestSliceReverse(t *testing.T) {
-	s := []int{1, 2, 3}
-	sliceReverse(s)
+func TestApplyTwitterAPIV2Annotations(t *testing.T) {
+	t.Run("Person", func(t *testing.T) {
+		tweets := []*Tweet{
+			{ID: 123, Entities: &TweetEntities{
+				URLs: []*TweetEntitiesURL{{URL: "https://t.co/abc"}},
+			}},
+		}
 
-	assert.Equal(
-		t,
-		[]int{3, 2, 1},
-		s,
-	)
+		applyTwitterAPIV2Annotations(tweets, []*TwitterAPIV2Tweet{
+			{ID: "123", Entities: &TwitterAPIV2Entities{Annotations: []*TwitterAPIV2Annotation{
+				{NormalizedText: "Ada Lovelace", Probability: 0.93, Type: "Person"},
+				{NormalizedText: "Babbage", Probability: 0.41, Type: "Person"},
+			}}},
+		})
+
+		assert.Equal(t, []*TweetAnnotation{
+			{NormalizedText: "Ada Lovelace", Probability: 0.93, Type: "Person"},
+			{NormalizedText: "Babbage", Type: "Person"}, // probability too low to store
+		}, tweets[0].Entities.Annotations)
+
+		// Existing entities are left alone.
+		assert.Len(t, tweets[0].Entities.URLs, 1)
+	})
+
+	t.Run("Place", func(t *testing.T) {
+		tweets := []*Tweet{
+			{ID: 123},
+			{ID: 124},
+		}
+
+		applyTwitterAPIV2Annotations(tweets, []*TwitterAPIV2Tweet{
+			{ID: "123", Entities: &TwitterAPIV2Entities{Annotations: []*TwitterAPIV2Annotation{
+				{NormalizedText: "San Francisco", Probability: 0.5, Type: "Place"},
+			}}},
+			{ID: "124"},
+		})
+
+		// A tweet without entities from v1.1 gets them for annotations.
+		assert.Equal(t, &TweetEntities{Annotations: []*TweetAnnotation{
+			{NormalizedText: "San Francisco", Type: "Place"}, // 0.5 isn't above the minimum
+		}}, tweets[0].Entities)
+		assert.Nil(t, tweets[1].Entities) // no annotations
+	})
 }
 
-func TestSliceKeepOnly(t *testing.T) {
-	s1 := []int{1, 2, 3, 4}
-	s2 := []int{2, 3, 5}
-	s1 = sliceKeepOnly(s1, s2,
-		func(i int) interface{} { return s1[i] },
-		func(i int) interface{} { return s2[i] },
-	).([]int)
+func TestApplyTwitterAPIV2Metrics(t *testing.T) {
+	tweets := []*Tweet{
+		{ID: 123},
+		{ID: 124},
+		{ID: 125, ReplyCount: 3},
+	}
 
-	assert.Equal(
-		t,
-		[]int{2, 3},
-		s1,
-	)
+	applyTwitterAPIV2Metrics(tweets, []*TwitterAPIV2Tweet{
+		{ID: "123", PublicMetrics: &TwitterAPIV2PublicMetrics{ImpressionCount: 1500, ReplyCount: 7}},
+		{ID: "124"},
+	})
+
+	assert.Equal(t, 7, tweets[0].ReplyCount)
+	assert.Equal(t, int64(1500), tweets[0].ViewCount)
+	assert.Equal(t, 0, tweets[1].ReplyCount) // no metrics
+	assert.Equal(t, 3, tweets[2].ReplyCount) // not returned by v2
 }
 
-func TestSliceUniq(t *testing.T) {
-	s := []int{1, 2, 2, 3, 4, 5, 6, 7, 7, 7, 8, 9}
-	s = sliceUniq(s, func(i int) interface{} { return s[i] }).([]int)
+func TestCheckTweetURLs(t *testing.T) {
+	var numRequests int
+	var mutex sync.Mutex
 
-	assert.Equal(
-		t,
-		[]int{1, 2, 3, 4, 5, 6, 7, 8, 9},
-		s,
-	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		numRequests++
+		mutex.Unlock()
+
+		// Runs on the server's goroutine, so FailNow (and assert) can't be
+		// used.
+		if r.Method != "HEAD" {
+			t.Errorf("unexpected method: %v", r.Method)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tweetWithURLs := func(id int64, paths ...string) *Tweet {
+		tweet := &Tweet{ID: id, Entities: &TweetEntities{}}
+		for _, path := range paths {
+			tweet.Entities.URLs = append(tweet.Entities.URLs,
+				&TweetEntitiesURL{ExpandedURL: server.URL + path})
+		}
+		return tweet
+	}
+
+	tweets := []*Tweet{
+		tweetWithURLs(1, "/ok", "/gone"),
+		tweetWithURLs(2, "/moved", "/missing"),
+		tweetWithURLs(3, "/ok"), // same URL as tweet 1; only checked once
+		{ID: 4},
+	}
+
+	err := checkTweetURLs(context.Background(), server.Client(), tweets, 2)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, tweets[0].Entities.URLs[0].Status)
+	assert.Equal(t, http.StatusGone, tweets[0].Entities.URLs[1].Status)
+	assert.Equal(t, http.StatusOK, tweets[1].Entities.URLs[0].Status) // redirects are followed
+	assert.Equal(t, http.StatusNotFound, tweets[1].Entities.URLs[1].Status)
+	assert.Equal(t, http.StatusOK, tweets[2].Entities.URLs[0].Status)
+
+	// One request for each distinct URL, plus one for the redirect target.
+	assert.Equal(t, 5, numRequests)
+}
+
+func TestChessGameFromChessComAPIGame(t *testing.T) {
+	apiGames := readChessComGamesFixture(t, "./testdata/chess_com_games.json")
+
+	t.Run("Win", func(t *testing.T) {
+		game, err := chessGameFromChessComAPIGame(apiGames[0], "brandur")
+		assert.NoError(t, err)
+		assert.Equal(t, "1c4f4e4a-4d1b-11eb-8a0e-78ac4409ff3c", game.ID)
+		assert.Equal(t, chessColorWhite, game.MyColor)
+		assert.Equal(t, 1203, game.MyRating)
+		assert.Equal(t, "Sicilian Defense", game.Opening)
+		assert.Equal(t, 1187, game.OpponentRating)
+		assert.Equal(t, "magnus_fan", game.OpponentUsername)
+		assert.Contains(t, game.PGN, "3. Qxf7#")
+		assert.Equal(t, chessPlatformChessCom, game.Platform)
+		assert.Equal(t, time.Unix(1609600000, 0).UTC(), game.PlayedAt)
+		assert.Equal(t, chessResultWin, game.Result)
+		assert.Equal(t, "600+5", game.TimeControl)
+	})
+
+	// Also checks that usernames are matched case insensitively.
+	t.Run("Draw", func(t *testing.T) {
+		game, err := chessGameFromChessComAPIGame(apiGames[1], "brandur")
+		assert.NoError(t, err)
+		assert.Equal(t, chessColorBlack, game.MyColor)
+		assert.Equal(t, "opponent123", game.OpponentUsername)
+		assert.Equal(t, chessResultDraw, game.Result)
+		assert.Equal(t, "1/86400", game.TimeControl)
+	})
+
+	t.Run("Loss", func(t *testing.T) {
+		game, err := chessGameFromChessComAPIGame(apiGames[0], "magnus_fan")
+		assert.NoError(t, err)
+		assert.Equal(t, chessResultLoss, game.Result)
+	})
+
+	t.Run("NotAPlayer", func(t *testing.T) {
+		_, err := chessGameFromChessComAPIGame(apiGames[0], "someone_else")
+		assert.Error(t, err)
+	})
+}
+
+func TestChessGameFromLichessAPIGame(t *testing.T) {
+	apiGames := readLichessGamesFixture(t, "./testdata/lichess_games.ndjson")
+
+	t.Run("Loss", func(t *testing.T) {
+		game, err := chessGameFromLichessAPIGame(apiGames[0], "brandur")
+		assert.NoError(t, err)
+		assert.Equal(t, "q7ZvsdUF", game.ID)
+		assert.Equal(t, chessColorBlack, game.MyColor)
+		assert.Equal(t, 1502, game.MyRating)
+		assert.Equal(t, "Semi-Slav Defense: Marshall Gambit", game.Opening)
+		assert.Equal(t, 2389, game.OpponentRating)
+		assert.Equal(t, "DrNykterstein", game.OpponentUsername)
+		assert.Contains(t, game.PGN, "2. Bf4 e6")
+		assert.Equal(t, chessPlatformLichess, game.Platform)
+		assert.Equal(t, time.Unix(1609600300, 0).UTC(), game.PlayedAt)
+		assert.Equal(t, chessResultLoss, game.Result)
+		assert.Equal(t, "180+2", game.TimeControl)
+	})
+
+	t.Run("DrawAgainstComputer", func(t *testing.T) {
+		game, err := chessGameFromLichessAPIGame(apiGames[1], "brandur")
+		assert.NoError(t, err)
+		assert.Equal(t, chessColorWhite, game.MyColor)
+		assert.Equal(t, "Stockfish level 3", game.OpponentUsername)
+		assert.Equal(t, chessResultDraw, game.Result)
+		assert.Equal(t, "1/259200", game.TimeControl)
+	})
+
+	t.Run("NotAPlayer", func(t *testing.T) {
+		_, err := chessGameFromLichessAPIGame(apiGames[0], "someone_else")
+		assert.Error(t, err)
+	})
+}
+
+func TestClockifyEntryFromAPITimeEntry(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/clockify_time_entries.json")
+	assert.NoError(t, err)
+
+	var entries []*ClockifyAPITimeEntry
+	err = json.Unmarshal(data, &entries)
+	assert.NoError(t, err)
+
+	projects := map[string]*ClockifyAPIProject{
+		"60a1b2c3d4e5f60012345678": {ID: "60a1b2c3d4e5f60012345678", Name: "Open Source"},
+	}
+	tags := map[string]*ClockifyAPITag{
+		"60a1b2c3d4e5f60012340001": {ID: "60a1b2c3d4e5f60012340001", Name: "code-review"},
+		"60a1b2c3d4e5f60012340002": {ID: "60a1b2c3d4e5f60012340002", Name: "work"},
+	}
+
+	t.Run("Project", func(t *testing.T) {
+		// The third tag is unknown, so it's left out.
+		assert.Equal(t, &ClockifyEntry{
+			Billable:        true,
+			Description:     "Review pull requests",
+			DurationSeconds: 5430,
+			EndedAt:         time.Date(2023, 3, 14, 17, 32, 41, 0, time.UTC),
+			ID:              "64100a1b2c3d4e0012000002",
+			ProjectID:       "60a1b2c3d4e5f60012345678",
+			ProjectName:     "Open Source",
+			StartedAt:       time.Date(2023, 3, 14, 16, 2, 11, 0, time.UTC),
+			Tags:            []string{"code-review", "work"},
+			WorkspaceID:     "5e4d2f3a9c1b7a0012ab3400",
+		}, clockifyEntryFromAPITimeEntry(entries[1], projects, tags))
+	})
+
+	t.Run("UnknownProject", func(t *testing.T) {
+		entry := clockifyEntryFromAPITimeEntry(entries[1], map[string]*ClockifyAPIProject{}, tags)
+		assert.Equal(t, "60a1b2c3d4e5f60012345678", entry.ProjectID)
+		assert.Equal(t, "", entry.ProjectName)
+	})
+
+	t.Run("Running", func(t *testing.T) {
+		entry := clockifyEntryFromAPITimeEntry(entries[0], projects, tags)
+		assert.Equal(t, "", entry.ProjectID)
+		assert.Equal(t, 0, entry.DurationSeconds)
+		assert.True(t, entry.EndedAt.IsZero())
+		assert.Nil(t, entry.Tags)
+	})
+}
+
+func TestCloudflareDayFromAPIGroups(t *testing.T) {
+	date := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Aggregated", func(t *testing.T) {
+		data, err := ioutil.ReadFile("./testdata/cloudflare_graphql.json")
+		assert.NoError(t, err)
+
+		var resp CloudflareAPIGraphQLResponse
+		err = json.Unmarshal(data, &resp)
+		assert.NoError(t, err)
+
+		// The group with an unknown country counts toward totals, but not
+		// top countries.
+		assert.Equal(t, &CloudflareDay{
+			Bandwidth:      801500,
+			CachedRequests: 145,
+			Date:           date,
+			Requests:       182,
+			Threats:        7,
+			TopCountries:   map[string]int64{"DE": 5, "GB": 25, "US": 150},
+			UniqueVisitors: 58,
+		}, cloudflareDayFromAPIGroups(date, resp.Data.Viewer.Zones[0].HTTPRequestsAdaptiveGroups))
+	})
+
+	t.Run("TopCountriesLimit", func(t *testing.T) {
+		var groups []*CloudflareAPIHTTPRequestsGroup
+		for i := 0; i < cloudflareTopCountriesLimit+2; i++ {
+			group := &CloudflareAPIHTTPRequestsGroup{Count: int64(i + 1)}
+			group.Dimensions.ClientCountryName = fmt.Sprintf("C%02d", i)
+			groups = append(groups, group)
+		}
+
+		day := cloudflareDayFromAPIGroups(date, groups)
+		assert.Len(t, day.TopCountries, cloudflareTopCountriesLimit)
+		assert.NotContains(t, day.TopCountries, "C00")
+		assert.NotContains(t, day.TopCountries, "C01")
+		assert.Equal(t, int64(cloudflareTopCountriesLimit+2), day.TopCountries[fmt.Sprintf("C%02d", cloudflareTopCountriesLimit+1)])
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.Equal(t, &CloudflareDay{Date: date}, cloudflareDayFromAPIGroups(date, nil))
+	})
+}
+
+func TestCompactTOML(t *testing.T) {
+	t.Run("PrunesZeroValues", func(t *testing.T) {
+		compacted, err := compactTOML([]byte(`
+[[readings]]
+  isbn = ""
+  rating = 0
+  title = "The Odyssey"
+
+  [[readings.authors]]
+    name = "Homer"
+    role = ""
+
+[[readings]]
+  isbn = "0140268863"
+  rating = 4
+  title = ""
+`))
+		assert.NoError(t, err)
+
+		var readingDB ReadingDB
+		assert.NoError(t, toml.Unmarshal(compacted, &readingDB))
+		assert.Equal(t, []*Reading{
+			{Authors: []*ReadingAuthor{{Name: "Homer"}}, Title: "The Odyssey"},
+			{ISBN: "0140268863", Rating: 4},
+		}, readingDB.Readings)
+
+		assert.NotContains(t, string(compacted), "isbn = \"\"")
+		assert.NotContains(t, string(compacted), "rating = 0")
+		assert.NotContains(t, string(compacted), "role")
+	})
+
+	t.Run("RoundTripsTweets", func(t *testing.T) {
+		tweetDB := &TweetDB{Tweets: newTweets(50)}
+
+		data, err := toml.Marshal(tweetDB)
+		assert.NoError(t, err)
+
+		compacted, err := compactTOML(data)
+		assert.NoError(t, err)
+
+		var roundTripped TweetDB
+		assert.NoError(t, toml.Unmarshal(compacted, &roundTripped))
+		assert.Equal(t, tweetDB, &roundTripped)
+	})
+
+	// Nil replies, retweets, and entities are already omitted by the
+	// marshaler, so tweets don't shrink by much. This is mostly here to keep
+	// an eye on the number.
+	t.Run("SizeReduction", func(t *testing.T) {
+		data, err := toml.Marshal(&TweetDB{Tweets: newTweets(5000)})
+		assert.NoError(t, err)
+
+		compacted, err := compactTOML(data)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(compacted), len(data))
+
+		t.Logf("5000 tweets: %d bytes -> %d bytes compacted (%.1f%% smaller)",
+			len(data), len(compacted),
+			100*(1-float64(len(compacted))/float64(len(data))))
+	})
+}
+
+func TestCopyTweetCards(t *testing.T) {
+	existingTweets := []*Tweet{
+		{ID: 1, Card: &TweetCard{CardType: "summary"}},
+		{ID: 2, Card: &TweetCard{CardType: "summary"}},
+	}
+
+	tweets := []*Tweet{
+		{ID: 1},
+		{ID: 2, Card: &TweetCard{CardType: "summary_large_image"}}, // freshly fetched
+		{ID: 3},
+	}
+
+	copyTweetCards(tweets, existingTweets)
+
+	assert.Equal(t, &TweetCard{CardType: "summary"}, tweets[0].Card)
+	assert.Equal(t, &TweetCard{CardType: "summary_large_image"}, tweets[1].Card)
+	assert.Nil(t, tweets[2].Card)
+}
+
+func TestCopyTweetQuotes(t *testing.T) {
+	existingTweets := []*Tweet{
+		{ID: 1, Quote: &TweetQuote{OriginalText: "Since deleted", StatusID: 11}},
+		{ID: 2, Quote: &TweetQuote{OriginalText: "Old text", StatusID: 12}},
+	}
+
+	tweets := []*Tweet{
+		{ID: 1, Quote: &TweetQuote{StatusID: 11}},                              // quoted tweet deleted
+		{ID: 2, Quote: &TweetQuote{OriginalText: "Edited text", StatusID: 12}}, // freshly fetched
+		{ID: 3},
+	}
+
+	copyTweetQuotes(tweets, existingTweets)
+
+	assert.Equal(t, "Since deleted", tweets[0].Quote.OriginalText)
+	assert.Equal(t, "Edited text", tweets[1].Quote.OriginalText)
+	assert.Nil(t, tweets[2].Quote)
+}
+
+func TestCopyTweetURLStatuses(t *testing.T) {
+	existingTweets := []*Tweet{
+		{ID: 1, Entities: &TweetEntities{URLs: []*TweetEntitiesURL{
+			{ExpandedURL: "https://example.com/a", Status: http.StatusOK},
+			{ExpandedURL: "https://example.com/b", Status: http.StatusNotFound},
+		}}},
+	}
+
+	tweets := []*Tweet{
+		{ID: 1, Entities: &TweetEntities{URLs: []*TweetEntitiesURL{
+			{ExpandedURL: "https://example.com/a"},
+			{ExpandedURL: "https://example.com/c"}, // not previously checked
+		}}},
+		{ID: 2, Entities: &TweetEntities{URLs: []*TweetEntitiesURL{
+			{ExpandedURL: "https://example.com/b"}, // different tweet
+		}}},
+	}
+
+	copyTweetURLStatuses(tweets, existingTweets)
+
+	assert.Equal(t, http.StatusOK, tweets[0].Entities.URLs[0].Status)
+	assert.Equal(t, 0, tweets[0].Entities.URLs[1].Status)
+	assert.Equal(t, 0, tweets[1].Entities.URLs[0].Status)
+}
+
+func TestComputeSentiment(t *testing.T) {
+	assert.NotEmpty(t, sentimentLexicon)
+
+	for text, expected := range map[string]float64{
+		"A brilliant, moving translation.":                   0.8,
+		"Tedious and boring. A waste of time.":               -0.4,
+		"Not boring at all, and I loved it!":                 0.6,
+		"It wasn’t good. I didn't enjoy it.":                 -0.5,
+		"Read for a book club in the spring.":                0,
+		"":                                                   0,
+		"SUPERB!!! The best book I've ever read.":            0.8,
+		"Awful, the worst, unreadable, worthless.":           -0.5333,
+		"The twist does not work, and I was fed up with it.": -0.6, // phrases
+	} {
+		score := computeSentiment(text)
+		assert.InDelta(t, expected, score, 0.0001, "text: %s", text)
+		assert.True(t, score >= -1 && score <= 1, "text: %s", text)
+	}
+
+	for phrase := range sentimentLexicon {
+		assert.LessOrEqual(t, len(strings.Fields(phrase)), sentimentMaxPhraseWords, "phrase: %s", phrase)
+	}
+}
+
+func TestComputeTweetStats(t *testing.T) {
+	stats := computeTweetStats([]*Tweet{
+		{CreatedAt: time.Date(2021, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{CreatedAt: time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{CreatedAt: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{CreatedAt: time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}, false, false)
+
+	assert.Equal(t, 4, stats.NumTweets)
+	assert.Equal(
+		t,
+		[]*PeriodCount{
+			{Count: 1, Period: "2020-12"},
+			{Count: 1, Period: "2021-01"},
+			{Count: 2, Period: "2021-02"},
+		},
+		stats.TweetsByMonth,
+	)
+
+	t.Run("Excluded", func(t *testing.T) {
+		tweets := []*Tweet{
+			{CreatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{CreatedAt: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), Retweet: &TweetRetweet{StatusID: 1}},
+			{CreatedAt: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), Reply: &TweetReply{StatusID: 2}},
+			{CreatedAt: time.Date(2021, 2, 2, 0, 0, 0, 0, time.UTC), Reply: &TweetReply{StatusID: 3},
+				Retweet: &TweetRetweet{StatusID: 4}},
+		}
+
+		stats := computeTweetStats(tweets, false, false)
+		assert.Equal(t, 4, stats.NumTweets)
+		assert.Equal(t, 0, stats.NumExcludedReplies)
+		assert.Equal(t, 0, stats.NumExcludedRetweets)
+
+		stats = computeTweetStats(tweets, true, false)
+		assert.Equal(t, 2, stats.NumTweets)
+		assert.Equal(t, 0, stats.NumExcludedReplies)
+		assert.Equal(t, 2, stats.NumExcludedRetweets)
+
+		stats = computeTweetStats(tweets, false, true)
+		assert.Equal(t, 2, stats.NumTweets)
+		assert.Equal(t, 2, stats.NumExcludedReplies)
+		assert.Equal(t, 0, stats.NumExcludedRetweets)
+
+		stats = computeTweetStats(tweets, true, true)
+		assert.Equal(t, 1, stats.NumTweets)
+		assert.Equal(t, 1, stats.NumExcludedReplies)
+		assert.Equal(t, 2, stats.NumExcludedRetweets)
+		assert.Equal(t, []*PeriodCount{{Count: 1, Period: "2021-01"}}, stats.TweetsByMonth)
+	})
+}
+
+func TestDiffNomadStays(t *testing.T) {
+	added, removed := diffNomadStays(
+		[]*NomadStay{{ID: "1"}, {ID: "2"}, {ID: "3"}},
+		[]*NomadStay{{ID: "0"}, {ID: "1"}, {ID: "2"}},
+	)
+	assert.Equal(t, []*NomadStay{{ID: "3"}}, added)
+	assert.Equal(t, []*NomadStay{{ID: "0"}}, removed)
+}
+
+func TestDownloadGoodreadsCovers(t *testing.T) {
+	jpegData, err := ioutil.ReadFile("testdata/goodreads_cover.jpg")
+	assert.NoError(t, err)
+	pngData, err := ioutil.ReadFile("testdata/goodreads_cover.png")
+	assert.NoError(t, err)
+
+	var numRequests int
+	var mutex sync.Mutex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		numRequests++
+		mutex.Unlock()
+
+		switch r.URL.Path {
+		case "/cover.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write(jpegData)
+		case "/cover.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(pngData)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+
+	// Already downloaded by a previous sync, so not requested again.
+	existingPath := filepath.Join(dir, "4.jpg")
+	assert.NoError(t, ioutil.WriteFile(existingPath, jpegData, 0644))
+
+	readings := []*Reading{
+		{ID: 1, CoverURL: server.URL + "/cover.jpg"},
+		{ID: 1, CoverURL: server.URL + "/cover.jpg"}, // reread; only downloaded once
+		{ID: 2, CoverURL: server.URL + "/cover.png"},
+		{ID: 3, CoverURL: server.URL + "/missing.jpg"},
+		{ID: 4, CoverURL: server.URL + "/cover.jpg"},
+		{ID: 5},
+	}
+
+	err = downloadGoodreadsCovers(context.Background(), server.Client(), readings, dir, 2)
+	assert.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(dir, "1.jpg"), readings[0].CoverLocalPath)
+	assert.Equal(t, filepath.Join(dir, "1.jpg"), readings[1].CoverLocalPath)
+	assert.Equal(t, filepath.Join(dir, "2.png"), readings[2].CoverLocalPath)
+	assert.Equal(t, "", readings[3].CoverLocalPath)
+	assert.Equal(t, existingPath, readings[4].CoverLocalPath)
+	assert.Equal(t, "", readings[5].CoverLocalPath)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "1.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, jpegData, data)
+
+	_, err = os.Stat(filepath.Join(dir, "3.jpg"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Equal(t, 3, numRequests)
+}
+
+func TestEncodeOutput(t *testing.T) {
+	data := []byte("[[tweets]]\n  id = \"123\"\n  text = \"Café 🎉\"\n")
+
+	t.Run("UTF8", func(t *testing.T) {
+		encoded, err := encodeOutput(data, outputEncodingUTF8)
+		assert.NoError(t, err)
+		assert.Equal(t, data, encoded)
+	})
+
+	t.Run("UTF8BOM", func(t *testing.T) {
+		encoded, err := encodeOutput(data, outputEncodingUTF8BOM)
+		assert.NoError(t, err)
+		assert.Equal(t, append([]byte("\xEF\xBB\xBF"), data...), encoded)
+
+		decoded, err := decodeOutput(encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, data, decoded)
+	})
+
+	t.Run("Latin1", func(t *testing.T) {
+		encoded, err := encodeOutput(data, outputEncodingLatin1)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("# encoding: latin-1\n[[tweets]]\n  id = \"123\"\n  text = \"Caf\xE9 ?\"\n"), encoded)
+
+		decoded, err := decodeOutput(encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, "[[tweets]]\n  id = \"123\"\n  text = \"Café ?\"\n", string(decoded))
+	})
+
+	t.Run("Latin1ValidUTF8", func(t *testing.T) {
+		// "Ã©" in Latin-1 is the same bytes as "é" in UTF-8.
+		encoded, err := encodeOutput([]byte("text = \"Ã©\"\n"), outputEncodingLatin1)
+		assert.NoError(t, err)
+
+		decoded, err := decodeOutput(encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, "text = \"Ã©\"\n", string(decoded))
+	})
+
+	t.Run("Latin1WithoutHeader", func(t *testing.T) {
+		decoded, err := decodeOutput([]byte("text = \"Caf\xE9\"\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, "text = \"Café\"\n", string(decoded))
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		_, err := encodeOutput(data, "utf-16")
+		assert.Error(t, err)
+	})
+}
+
+func TestExpandTweetURLs(t *testing.T) {
+	tweets := []*Tweet{
+		{
+			ID: 123,
+			Entities: &TweetEntities{URLs: []*TweetEntitiesURL{
+				{ExpandedURL: "https://brandur.org/fragments", URL: "https://t.co/abc"},
+				{ExpandedURL: "https://example.com/longer", URL: "https://t.co/abcd"},
+			}},
+			Text: "Read https://t.co/abc and https://t.co/abcd but not https://t.co/xyz",
+		},
+		{ID: 124, Text: "No entities https://t.co/abc"},
+		{
+			ID: 125,
+			Entities: &TweetEntities{URLs: []*TweetEntitiesURL{
+				{ExpandedURL: "https://brandur.org/fragments", URL: "https://t.co/abc"},
+			}},
+			NormalizedText: "Read https://t.co/abc",
+			Text:           "Ｒｅａｄ https://t.co/abc",
+		},
+	}
+
+	expandTweetURLs(tweets)
+	assert.Equal(t, "Read https://brandur.org/fragments and https://example.com/longer but not https://t.co/xyz", tweets[0].Text)
+	assert.Equal(t, "No entities https://t.co/abc", tweets[1].Text)
+
+	// Normalized text is expanded too.
+	assert.Equal(t, "Read https://brandur.org/fragments", tweets[2].NormalizedText)
+	assert.Equal(t, "Ｒｅａｄ https://brandur.org/fragments", tweets[2].Text)
+
+	// Entities keep their original URLs.
+	assert.Equal(t, "https://t.co/abc", tweets[0].Entities.URLs[0].URL)
+
+	// Hashes are of the expanded text.
+	assert.Equal(t, hashSHA1(tweets[0].Text), tweets[0].TextHashSHA1)
+	assert.Equal(t, hashSHA1(tweets[2].Text), tweets[2].TextHashSHA1)
+
+	t.Run("Idempotent", func(t *testing.T) {
+		expandTweetURLs(tweets)
+		assert.Equal(t, "Read https://brandur.org/fragments and https://example.com/longer but not https://t.co/xyz", tweets[0].Text)
+	})
+}
+
+func TestExportHTML(t *testing.T) {
+	dir := t.TempDir()
+
+	readingsPath := filepath.Join(dir, "goodreads.toml")
+	err := writeTOMLFile(readingsPath, &ReadingDB{
+		Readings: []*Reading{
+			{
+				Authors:  []*ReadingAuthor{{ID: 1, Name: "Ursula K. Le Guin"}, {ID: 2, Name: "Someone Else", Role: "Illustrator"}},
+				CoverURL: "https://images.gr-assets.com/books/1.jpg",
+				Rating:   4,
+				ReadAt:   time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+				Review:   "An ambiguous <utopia> & " + strings.Repeat("very ", 60) + "good.",
+				ReviewID: 123,
+				Title:    "The Dispossessed",
+			},
+			{ReviewID: 124, Title: "Unrated"},
+		},
+		Version: SchemaVersion,
+	})
+	assert.NoError(t, err)
+
+	tweetsPath := filepath.Join(dir, "twitter.toml")
+	err = writeTOMLFile(tweetsPath, &TweetDB{
+		Tweets: []*Tweet{
+			{
+				CreatedAt: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+				Entities: &TweetEntities{Medias: []*TweetEntitiesMedia{
+					{ID: 1, Type: "photo", URL: "https://pbs.twimg.com/media/1.jpg"},
+					{
+						ID:           2,
+						ThumbnailURL: "https://pbs.twimg.com/ext_tw_video_thumb/2/pu/img/2.jpg",
+						Type:         "video",
+						URL:          "https://video.twimg.com/ext_tw_video/2/pu/vid/720x1280/2.mp4",
+						Variants: []*TweetEntitiesMediaVariant{
+							{Bitrate: 2176000, ContentType: "video/mp4", URL: "https://video.twimg.com/ext_tw_video/2/pu/vid/720x1280/2.mp4"},
+						},
+					},
+				}},
+				FavoriteCount: 5,
+				ID:            2,
+				RetweetCount:  1,
+				Text:          "Newer <b>tweet</b>",
+			},
+			{CreatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), ID: 1, Text: "Older tweet"},
+			{
+				ConversationRootText: "Older tweet",
+				CreatedAt:            time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC),
+				ID:                   3,
+				Reply:                &TweetReply{StatusID: 1, User: "brandur"},
+				Text:                 "Following up",
+			},
+		},
+		Version: SchemaVersion,
+	})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = exportHTML(&buf, &ExportHTMLOptions{GoodreadsPath: readingsPath, TwitterPath: tweetsPath})
+	assert.NoError(t, err)
+
+	goldenPath := "./testdata/export.html.golden"
+	if *updateGolden {
+		assert.NoError(t, ioutil.WriteFile(goldenPath, buf.Bytes(), 0644))
+	}
+
+	expected, err := ioutil.ReadFile(goldenPath)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestExportHTMLRatingStars(t *testing.T) {
+	assert.Equal(t, "☆☆☆☆☆", exportHTMLRatingStars(0))
+	assert.Equal(t, "★★★☆☆", exportHTMLRatingStars(3))
+	assert.Equal(t, "★★★★★", exportHTMLRatingStars(7))
+}
+
+func TestExportOPML(t *testing.T) {
+	readingsPath := filepath.Join(t.TempDir(), "goodreads.toml")
+	err := writeTOMLFile(readingsPath, &ReadingDB{
+		Readings: []*Reading{
+			{
+				Authors:  []*ReadingAuthor{{ID: 1, Name: "Douglas R. Hofstadter"}, {ID: 2, Name: "Someone Else", Role: "Illustrator"}},
+				ID:       24113,
+				Rating:   5,
+				ReadAt:   time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+				ReviewID: 123,
+				Title:    `Gödel, Escher & Bach: <An "Eternal" Golden Braid>`,
+			},
+			{Authors: []*ReadingAuthor{{ID: 3, Name: "Flann O'Brien"}}, ID: 2, ReviewID: 124, Title: "Unread"},
+		},
+		Version: SchemaVersion,
+	})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = exportOPML(&buf, &ExportOPMLOptions{GoodreadsPath: readingsPath})
+	assert.NoError(t, err)
+
+	// Special characters are escaped rather than breaking the document.
+	assert.Contains(t, buf.String(),
+		`text="Gödel, Escher &amp; Bach: &lt;An &#34;Eternal&#34; Golden Braid&gt;"`)
+
+	var opml struct {
+		XMLName  xml.Name `xml:"opml"`
+		Title    string   `xml:"head>title"`
+		Outlines []struct {
+			Author   string `xml:"author,attr"`
+			Rating   int    `xml:"rating,attr"`
+			ReadDate string `xml:"readDate,attr"`
+			Text     string `xml:"text,attr"`
+			Type     string `xml:"type,attr"`
+			XMLURL   string `xml:"xmlUrl,attr"`
+		} `xml:"body>outline"`
+	}
+	err = xml.Unmarshal(buf.Bytes(), &opml)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "qself readings", opml.Title)
+	assert.Len(t, opml.Outlines, 2)
+
+	outline := opml.Outlines[0]
+	assert.Equal(t, "Douglas R. Hofstadter", outline.Author)
+	assert.Equal(t, 5, outline.Rating)
+	assert.Equal(t, "2021-03-01", outline.ReadDate)
+	assert.Equal(t, `Gödel, Escher & Bach: <An "Eternal" Golden Braid>`, outline.Text)
+	assert.Equal(t, "rss", outline.Type)
+	assert.Equal(t, "https://www.goodreads.com/book/show/24113.atom", outline.XMLURL)
+
+	// Unread books have no read date.
+	outline = opml.Outlines[1]
+	assert.Equal(t, "Flann O'Brien", outline.Author)
+	assert.Equal(t, "", outline.ReadDate)
+	assert.NotContains(t, buf.String(), `readDate=""`)
+}
+
+func TestFetchGoodreadsPage(t *testing.T) {
+	ctx := context.Background()
+	conf := &GoodreadsConf{GoodreadsID: "1", GoodreadsKey: "key"}
+
+	fixture, err := ioutil.ReadFile("testdata/goodreads_reviews_translator.xml")
+	assert.NoError(t, err)
+
+	// Serves the translator fixture while counting requests.
+	newCountingClient := func(numRequests *int) *http.Client {
+		return &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			*numRequests++
+			return &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewReader(fixture)),
+				StatusCode: http.StatusOK,
+			}, nil
+		})}
+	}
+
+	t.Run("CacheHit", func(t *testing.T) {
+		var numRequests int
+		apiReviews, err := fetchGoodreadsPage(ctx, conf, newCountingClient(&numRequests), goodreadsShelfRead, 1,
+			&SyncGoodreadsOptions{CacheDir: "testdata/goodreads_cache"})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, numRequests)
+		assert.Len(t, apiReviews, 1)
+		assert.Equal(t, 3712345678, apiReviews[0].ID)
+	})
+
+	t.Run("CacheMiss", func(t *testing.T) {
+		cacheDir := t.TempDir()
+
+		var numRequests int
+		apiReviews, err := fetchGoodreadsPage(ctx, conf, newCountingClient(&numRequests), goodreadsShelfRead, 1,
+			&SyncGoodreadsOptions{CacheDir: cacheDir, CacheTTL: time.Hour})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, numRequests)
+		assert.Len(t, apiReviews, 1)
+
+		data, err := ioutil.ReadFile(filepath.Join(cacheDir, "goodreads_1_page_1.xml"))
+		assert.NoError(t, err)
+		assert.Equal(t, fixture, data)
+
+		// A second fetch is served from the cache.
+		_, err = fetchGoodreadsPage(ctx, conf, newCountingClient(&numRequests), goodreadsShelfRead, 1,
+			&SyncGoodreadsOptions{CacheDir: cacheDir, CacheTTL: time.Hour})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, numRequests)
+	})
+
+	t.Run("CacheExpired", func(t *testing.T) {
+		cachePath := writeTestFile(t, "goodreads_1_page_1.xml", "stale")
+
+		staleTime := time.Now().Add(-2 * time.Hour)
+		assert.NoError(t, os.Chtimes(cachePath, staleTime, staleTime))
+
+		var numRequests int
+		apiReviews, err := fetchGoodreadsPage(ctx, conf, newCountingClient(&numRequests), goodreadsShelfRead, 1,
+			&SyncGoodreadsOptions{CacheDir: filepath.Dir(cachePath), CacheTTL: time.Hour})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, numRequests)
+		assert.Len(t, apiReviews, 1)
+	})
+
+	t.Run("CacheAPISort", func(t *testing.T) {
+		cacheDir := t.TempDir()
+
+		var numRequests int
+		_, err := fetchGoodreadsPage(ctx, conf, newCountingClient(&numRequests), goodreadsShelfRead, 1,
+			&SyncGoodreadsOptions{APISort: "title", CacheDir: cacheDir, CacheTTL: time.Hour})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, numRequests)
+
+		// Pages in the default order are cached separately.
+		_, err = os.Stat(filepath.Join(cacheDir, "goodreads_1_page_1_title.xml"))
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(cacheDir, "goodreads_1_page_1.xml"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("CacheUserID", func(t *testing.T) {
+		cacheDir := t.TempDir()
+
+		var numRequests int
+		_, err := fetchGoodreadsPage(ctx, conf, newCountingClient(&numRequests), goodreadsShelfRead, 1,
+			&SyncGoodreadsOptions{CacheDir: cacheDir, CacheTTL: time.Hour})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, numRequests)
+
+		// Another user's pages aren't served from the first user's cache.
+		otherConf := &GoodreadsConf{GoodreadsID: "2", GoodreadsKey: "key"}
+		_, err = fetchGoodreadsPage(ctx, otherConf, newCountingClient(&numRequests), goodreadsShelfRead, 1,
+			&SyncGoodreadsOptions{CacheDir: cacheDir, CacheTTL: time.Hour})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, numRequests)
+
+		_, err = os.Stat(filepath.Join(cacheDir, "goodreads_2_page_1.xml"))
+		assert.NoError(t, err)
+	})
+}
+
+func TestFilter(t *testing.T) {
+	isEven := func(i int) bool { return i%2 == 0 }
+
+	testCases := []struct {
+		name string
+		in   []int
+		want []int
+	}{
+		{name: "Nil", in: nil, want: nil},
+		{name: "Empty", in: []int{}, want: nil},
+		{name: "AllPass", in: []int{2, 4, 6}, want: []int{2, 4, 6}},
+		{name: "NonePass", in: []int{1, 3, 5}, want: nil},
+		{name: "PartialPass", in: []int{5, 4, 3, 2, 1, 6}, want: []int{4, 2, 6}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Filter(tc.in, isEven))
+		})
+	}
+
+	t.Run("InputUnmodified", func(t *testing.T) {
+		s := []int{1, 2, 3, 4}
+		Filter(s, isEven)
+		assert.Equal(t, []int{1, 2, 3, 4}, s)
+	})
+}
+
+func BenchmarkFilter(b *testing.B) {
+	s := benchmarkGroupByInput()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		Filter(s, func(i int) bool { return i%2 == 0 })
+	}
+}
+
+// A hand-written loop to compare against Filter.
+func BenchmarkFilterHandWritten(b *testing.B) {
+	s := benchmarkGroupByInput()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var kept []int
+		for _, elem := range s {
+			if elem%2 == 0 {
+				kept = append(kept, elem)
+			}
+		}
+	}
+}
+
+func TestFilterTweets(t *testing.T) {
+	tweets := newTweets(5)
+
+	filtered, numFiltered := filterTweets(tweets, regexp.MustCompile(`^Tweet number 3$`))
+	assert.Equal(t, 1, numFiltered)
+	assert.Len(t, filtered, 4)
+
+	for _, tweet := range filtered {
+		assert.NotEqual(t, int64(1000003), tweet.ID)
+	}
+}
+
+func TestFilterTweetsByEngagement(t *testing.T) {
+	tweets := []*Tweet{
+		{ID: 1, FavoriteCount: 0, RetweetCount: 2},
+		{ID: 2, FavoriteCount: 1, RetweetCount: 0},
+		{ID: 3, FavoriteCount: 5, RetweetCount: 3},
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		filtered, numFiltered := filterTweetsByEngagement(tweets, 0, 0)
+		assert.Equal(t, 0, numFiltered)
+		assert.Equal(t, tweets, filtered)
+	})
+
+	t.Run("MinFavorites", func(t *testing.T) {
+		filtered, numFiltered := filterTweetsByEngagement(tweets, 1, 0)
+		assert.Equal(t, 1, numFiltered)
+		assert.Equal(t, []*Tweet{tweets[1], tweets[2]}, filtered)
+	})
+
+	t.Run("MinRetweets", func(t *testing.T) {
+		filtered, numFiltered := filterTweetsByEngagement(tweets, 0, 2)
+		assert.Equal(t, 1, numFiltered)
+		assert.Equal(t, []*Tweet{tweets[0], tweets[2]}, filtered)
+	})
+
+	t.Run("Both", func(t *testing.T) {
+		filtered, numFiltered := filterTweetsByEngagement(tweets, 1, 2)
+		assert.Equal(t, 2, numFiltered)
+		assert.Equal(t, []*Tweet{tweets[2]}, filtered)
+	})
+}
+
+func TestFilterTweetsByRange(t *testing.T) {
+	tweets := []*Tweet{
+		{ID: 1, CreatedAt: time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC)},
+		{ID: 2, CreatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 3, CreatedAt: time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC)},
+		{ID: 4, CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC)
+
+	t.Run("Both", func(t *testing.T) {
+		filtered, numFiltered := filterTweetsByRange(tweets, start, end)
+		assert.Equal(t, 2, numFiltered)
+		assert.Equal(t, []*Tweet{tweets[1], tweets[2]}, filtered) // inclusive
+	})
+
+	t.Run("StartOnly", func(t *testing.T) {
+		filtered, numFiltered := filterTweetsByRange(tweets, start, time.Time{})
+		assert.Equal(t, 1, numFiltered)
+		assert.Equal(t, []*Tweet{tweets[1], tweets[2], tweets[3]}, filtered)
+	})
+
+	t.Run("EndOnly", func(t *testing.T) {
+		filtered, numFiltered := filterTweetsByRange(tweets, time.Time{}, end)
+		assert.Equal(t, 1, numFiltered)
+		assert.Equal(t, []*Tweet{tweets[0], tweets[1], tweets[2]}, filtered)
+	})
+}
+
+func TestGoodreadsConfFromOptions(t *testing.T) {
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("GOODREADS_ID", "123")
+		t.Setenv("GOODREADS_KEY", "env-key")
+
+		conf, err := goodreadsConfFromOptions(&SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, &GoodreadsConf{GoodreadsID: "123", GoodreadsKey: "env-key"}, conf)
+	})
+
+	t.Run("FlagsOverrideEnv", func(t *testing.T) {
+		t.Setenv("GOODREADS_ID", "123")
+		t.Setenv("GOODREADS_KEY", "env-key")
+
+		conf, err := goodreadsConfFromOptions(&SyncGoodreadsOptions{UserID: "456"})
+		assert.NoError(t, err)
+		assert.Equal(t, &GoodreadsConf{GoodreadsID: "456", GoodreadsKey: "env-key"}, conf)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		t.Setenv("GOODREADS_ID", "")
+		t.Setenv("GOODREADS_KEY", "")
+
+		_, err := goodreadsConfFromOptions(&SyncGoodreadsOptions{UserID: "456"})
+		assert.EqualError(t, err, "Goodreads API key should be set with --goodreads-key or GOODREADS_KEY")
+	})
+}
+
+func TestGroupBy(t *testing.T) {
+	isEven := func(i int) bool { return i%2 == 0 }
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.Equal(t, map[bool][]int{}, GroupBy([]int{}, isEven))
+		assert.Equal(t, map[bool][]int{}, GroupBy(nil, isEven))
+	})
+
+	t.Run("SingleElementGroups", func(t *testing.T) {
+		assert.Equal(
+			t,
+			map[string][]string{"a": {"a"}, "b": {"b"}, "c": {"c"}},
+			GroupBy([]string{"a", "b", "c"}, func(s string) string { return s }),
+		)
+	})
+
+	t.Run("PreservesOrder", func(t *testing.T) {
+		assert.Equal(
+			t,
+			map[bool][]int{false: {5, 3, 1}, true: {4, 2, 6}},
+			GroupBy([]int{5, 4, 3, 2, 1, 6}, isEven),
+		)
+	})
+
+	t.Run("StructElements", func(t *testing.T) {
+		tweets := []*Tweet{
+			{ID: 1, Text: "a"},
+			{ID: 2, Text: "b"},
+			{ID: 3, Text: "a"},
+		}
+
+		groups := GroupBy(tweets, func(tweet *Tweet) string { return tweet.Text })
+		assert.Len(t, groups, 2)
+		assert.Equal(t, []*Tweet{tweets[0], tweets[2]}, groups["a"])
+		assert.Equal(t, []*Tweet{tweets[1]}, groups["b"])
+	})
+}
+
+func BenchmarkGroupBy(b *testing.B) {
+	s := benchmarkGroupByInput()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		GroupBy(s, func(i int) int { return i % 100 })
+	}
+}
+
+// A hand-written map accumulation to compare against GroupBy.
+func BenchmarkGroupByHandWritten(b *testing.B) {
+	s := benchmarkGroupByInput()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		groups := make(map[int][]int)
+		for _, elem := range s {
+			groups[elem%100] = append(groups[elem%100], elem)
+		}
+	}
+}
+
+func TestLeveledLoggerLogFile(t *testing.T) {
+	t.Run("FanOut", func(t *testing.T) {
+		var stderr bytes.Buffer
+		logFile := filepath.Join(t.TempDir(), "qself.log")
+
+		l := &LeveledLogger{Level: LevelWarn, LogFile: logFile, stderrOverride: &stderr}
+		assert.NoError(t, l.Open())
+
+		for i := 0; i < 100; i++ {
+			l.Warnf("line %v", i)
+		}
+		assert.NoError(t, l.Close())
+
+		data, err := ioutil.ReadFile(logFile)
+		assert.NoError(t, err)
+		assert.Equal(t, 100, strings.Count(stderr.String(), "\n"))
+		assert.Equal(t, stderr.String(), string(data))
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "qself.log")
+
+		// "[WARN] line NN\n" is 15 bytes, so 10 lines fit in a file.
+		l := &LeveledLogger{Level: LevelWarn, LogFile: logFile, LogFileMaxSize: 150, stderrOverride: io.Discard}
+		assert.NoError(t, l.Open())
+
+		for i := 10; i < 25; i++ {
+			l.Warnf("line %v", i)
+		}
+		assert.NoError(t, l.Close())
+
+		rotated, err := ioutil.ReadFile(logFile + ".1")
+		assert.NoError(t, err)
+		assert.Equal(t, 10, strings.Count(string(rotated), "\n"))
+		assert.True(t, strings.HasPrefix(string(rotated), "[WARN] line 10\n"))
+
+		current, err := ioutil.ReadFile(logFile)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, strings.Count(string(current), "\n"))
+		assert.True(t, strings.HasPrefix(string(current), "[WARN] line 20\n"))
+	})
+}
+
+func TestLinkedInArticleFromAPIArticle(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/linkedin_articles.json")
+	assert.NoError(t, err)
+
+	var root LinkedInAPIArticlesRoot
+	err = json.Unmarshal(data, &root)
+	assert.NoError(t, err)
+
+	socialActions := &LinkedInAPISocialActions{}
+	socialActions.CommentsSummary.AggregatedTotalComments = 4
+	socialActions.LikesSummary.TotalLikes = 27
+
+	article := linkedInArticleFromAPIArticle(root.Elements[0], socialActions)
+	assert.Equal(t, &LinkedInArticle{
+		CommentCount:  4,
+		Content:       "Why queues?\n\nMost jobs don't need a dedicated queue.\n\n* Transactional\n* Simple\n\nSee the original (https://brandur.org/postgres-queues).",
+		CoverImageURL: "https://media.licdn.com/dms/image/C5612AQH/article-cover_image-shrink_720_1280/0/1609459200000",
+		ID:            "urn:li:linkedInArticle:6750000000000000101",
+		LikeCount:     27,
+		PublishedAt:   time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		Title:         "Postgres Job Queues & Failure By MVCC",
+		ViewCount:     812,
+	}, article)
+}
+
+func TestLinkedInPostFromAPIPost(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/linkedin_ugc_posts.json")
+	assert.NoError(t, err)
+
+	var root LinkedInAPIPostsRoot
+	err = json.Unmarshal(data, &root)
+	assert.NoError(t, err)
+
+	post := linkedInPostFromAPIPost(root.Elements[0], &LinkedInAPISocialActions{})
+	assert.Equal(t, &LinkedInPost{
+		CreatedAt:  time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		ID:         "urn:li:share:6750000000000000001",
+		Text:       "Wrote up some notes on Postgres queues.",
+		Visibility: "PUBLIC",
+	}, post)
+}
+
+func TestMediumPostFromRSSItem(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/medium_feed.xml")
+	assert.NoError(t, err)
+
+	var root MediumRSSRoot
+	err = xml.Unmarshal(data, &root)
+	assert.NoError(t, err)
+	assert.Len(t, root.Items, 2)
+
+	t.Run("Tags", func(t *testing.T) {
+		post, err := mediumPostFromRSSItem(root.Items[0])
+		assert.NoError(t, err)
+		assert.Equal(t, &MediumPost{
+			ID:          "4f2a1b7c9d3e",
+			PublishedAt: time.Date(2021, 3, 2, 17, 45, 12, 0, time.UTC),
+			Tags:        []string{"postgres", "databases"},
+			Title:       "Postgres Job Queues & Failure By MVCC",
+			UpdatedAt:   time.Date(2021, 3, 4, 9, 10, 11, 123000000, time.UTC),
+			URL:         "https://brandur.medium.com/postgres-job-queues-failure-by-mvcc-4f2a1b7c9d3e",
+		}, post)
+	})
+
+	t.Run("NoTags", func(t *testing.T) {
+		post, err := mediumPostFromRSSItem(root.Items[1])
+		assert.NoError(t, err)
+		assert.Equal(t, "9a8b7c6d5e4f", post.ID)
+		assert.Empty(t, post.Tags)
+	})
+
+	t.Run("MalformedPubDate", func(t *testing.T) {
+		_, err := mediumPostFromRSSItem(&MediumRSSItem{GUID: "https://medium.com/p/123", PubDate: "not a date"})
+		assert.Error(t, err)
+	})
+
+	t.Run("APIPost", func(t *testing.T) {
+		data, err := ioutil.ReadFile("./testdata/medium_post.json")
+		assert.NoError(t, err)
+
+		apiPost, err := parseMediumAPIPost(data)
+		assert.NoError(t, err)
+
+		post, err := mediumPostFromRSSItem(root.Items[0])
+		assert.NoError(t, err)
+
+		applyMediumAPIPost(post, apiPost)
+		assert.Equal(t, 1234, post.ClapCount)
+		assert.Equal(t, "How long-lived transactions stall queues.", post.Subtitle)
+	})
+}
+
+func TestMergeBeeminderGoals(t *testing.T) {
+	timestamp := time.Date(2021, 1, 26, 0, 0, 0, 0, time.UTC)
+
+	merged := mergeBeeminderGoals(
+		[]*BeeminderGoal{
+			{Slug: "reading", Pledge: 10, DataPoints: []*BeeminderDataPoint{
+				{ID: "b", Comment: "Updated", Timestamp: timestamp.Add(24 * time.Hour)},
+				{ID: "c", Timestamp: timestamp.Add(48 * time.Hour)},
+			}},
+			{Slug: "exercise"},
+		},
+		[]*BeeminderGoal{
+			{Slug: "archived"},
+			{Slug: "reading", Pledge: 5, DataPoints: []*BeeminderDataPoint{
+				{ID: "a", Timestamp: timestamp},
+				{ID: "b", Comment: "Original", Timestamp: timestamp.Add(24 * time.Hour)},
+			}},
+		},
+	)
+
+	assert.Len(t, merged, 3)
+	assert.Equal(t, "archived", merged[0].Slug)
+	assert.Equal(t, "exercise", merged[1].Slug)
+	assert.Equal(t, "reading", merged[2].Slug)
+
+	// Goal fields come from the API, while data points are merged.
+	assert.Equal(t, 10.0, merged[2].Pledge)
+	assert.Equal(t, []*BeeminderDataPoint{
+		{ID: "a", Timestamp: timestamp},
+		{ID: "b", Comment: "Updated", Timestamp: timestamp.Add(24 * time.Hour)},
+		{ID: "c", Timestamp: timestamp.Add(48 * time.Hour)},
+	}, merged[2].DataPoints)
+
+	t.Run("TimestampEdited", func(t *testing.T) {
+		merged := mergeBeeminderGoals(
+			[]*BeeminderGoal{
+				{Slug: "reading", DataPoints: []*BeeminderDataPoint{
+					{ID: "b", Timestamp: timestamp.Add(72 * time.Hour)},
+				}},
+			},
+			[]*BeeminderGoal{
+				{Slug: "reading", DataPoints: []*BeeminderDataPoint{
+					{ID: "a", Timestamp: timestamp},
+					{ID: "b", Timestamp: timestamp.Add(24 * time.Hour)},
+				}},
+			},
+		)
+
+		// The API's version is kept, and sorted by its new timestamp.
+		assert.Equal(t, []*BeeminderDataPoint{
+			{ID: "a", Timestamp: timestamp},
+			{ID: "b", Timestamp: timestamp.Add(72 * time.Hour)},
+		}, merged[0].DataPoints)
+	})
+}
+
+func TestMergeCalEvents(t *testing.T) {
+	s1 := []*CalEvent{
+		{ID: "a", StartAt: time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC), Title: "s1 a"},
+		{ID: "b", StartAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Title: "s1 b"}, // moved earlier
+	}
+	s2 := []*CalEvent{
+		{ID: "c", StartAt: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), Title: "s2 c"},
+		{ID: "b", StartAt: time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC), Title: "s2 b"},
+	}
+
+	assert.Equal(
+		t,
+		[]*CalEvent{
+			{ID: "b", StartAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Title: "s1 b"}, // s1 is preferred
+			{ID: "c", StartAt: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), Title: "s2 c"},
+			{ID: "a", StartAt: time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC), Title: "s1 a"},
+		},
+		mergeCalEvents(s1, s2),
+	)
+}
+
+func TestMergeChessGames(t *testing.T) {
+	playedAt1 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	playedAt2 := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+	playedAt3 := time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	s1 := []*ChessGame{
+		{ID: "c", PlayedAt: playedAt3, MyRating: 1203},
+		{ID: "b", PlayedAt: playedAt2, MyRating: 1202},
+	}
+	s2 := []*ChessGame{
+		{ID: "a", PlayedAt: playedAt1, MyRating: 1101},
+		{ID: "b", PlayedAt: playedAt2, MyRating: 1102},
+	}
+
+	s := mergeChessGames(s1, s2)
+
+	assert.Equal(
+		t,
+		[]*ChessGame{
+			{ID: "a", PlayedAt: playedAt1, MyRating: 1101},
+			{ID: "b", PlayedAt: playedAt2, MyRating: 1202}, // s1 is preferred
+			{ID: "c", PlayedAt: playedAt3, MyRating: 1203},
+		},
+		s,
+	)
+}
+
+func TestMergeClockifyEntries(t *testing.T) {
+	startedAt := time.Date(2023, 3, 14, 16, 0, 0, 0, time.UTC)
+
+	merged := mergeClockifyEntries(
+		[]*ClockifyEntry{
+			{ID: "b", Description: "Updated", StartedAt: startedAt.Add(time.Hour)},
+			{ID: "c", StartedAt: startedAt.Add(2 * time.Hour)},
+		},
+		[]*ClockifyEntry{
+			{ID: "a", StartedAt: startedAt},
+			{ID: "b", Description: "Original", StartedAt: startedAt.Add(time.Hour)},
+		},
+	)
+
+	assert.Len(t, merged, 3)
+	assert.Equal(t, "a", merged[0].ID)
+	assert.Equal(t, "b", merged[1].ID)
+	assert.Equal(t, "Updated", merged[1].Description)
+	assert.Equal(t, "c", merged[2].ID)
+
+	t.Run("StartedAtEdited", func(t *testing.T) {
+		merged := mergeClockifyEntries(
+			[]*ClockifyEntry{
+				{ID: "b", StartedAt: startedAt.Add(3 * time.Hour)},
+			},
+			[]*ClockifyEntry{
+				{ID: "a", StartedAt: startedAt},
+				{ID: "b", StartedAt: startedAt.Add(time.Hour)},
+			},
+		)
+
+		// The API's version is kept, and sorted by its new start time.
+		assert.Len(t, merged, 2)
+		assert.Equal(t, "a", merged[0].ID)
+		assert.Equal(t, "b", merged[1].ID)
+		assert.Equal(t, startedAt.Add(3*time.Hour), merged[1].StartedAt)
+	})
+}
+
+func TestMergeFriendReadings(t *testing.T) {
+	s1 := []*FriendReading{
+		{Reading: Reading{ReviewID: 124, Review: "s1 124"}, FriendID: 789},
+		{Reading: Reading{ReviewID: 123, Review: "s1 123"}, FriendID: 456},
+	}
+	s2 := []*FriendReading{
+		{Reading: Reading{ReviewID: 123, Review: "s2 123"}, FriendID: 456},
+		{Reading: Reading{ReviewID: 123, Review: "s2 123"}, FriendID: 789}, // same review ID, different friend
+		{Reading: Reading{ReviewID: 122, Review: "s2 122"}, FriendID: 456},
+	}
+
+	assert.Equal(
+		t,
+		[]*FriendReading{
+			{Reading: Reading{ReviewID: 123, Review: "s1 123"}, FriendID: 456}, // s1 is preferred
+			{Reading: Reading{ReviewID: 122, Review: "s2 122"}, FriendID: 456},
+			{Reading: Reading{ReviewID: 124, Review: "s1 124"}, FriendID: 789},
+			{Reading: Reading{ReviewID: 123, Review: "s2 123"}, FriendID: 789},
+		},
+		mergeFriendReadings(s1, s2),
+	)
+}
+
+func TestMergeGooglePhotos(t *testing.T) {
+	createdAt := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	merged := mergeGooglePhotos(
+		[]*GooglePhoto{
+			{CameraModel: "Pixel 6", CreatedAt: createdAt.Add(time.Hour), ID: "b"},
+			{CreatedAt: createdAt.Add(2 * time.Hour), ID: "c"},
+		},
+		[]*GooglePhoto{
+			{CreatedAt: createdAt, ID: "a"},
+			{CameraModel: "Pixel 5", CreatedAt: createdAt.Add(time.Hour), ID: "b"},
+		},
+	)
+
+	assert.Len(t, merged, 3)
+	assert.Equal(t, "a", merged[0].ID)
+	assert.Equal(t, "b", merged[1].ID)
+	assert.Equal(t, "Pixel 6", merged[1].CameraModel)
+	assert.Equal(t, "c", merged[2].ID)
+
+	t.Run("CreatedAtEdited", func(t *testing.T) {
+		merged := mergeGooglePhotos(
+			[]*GooglePhoto{
+				{CreatedAt: createdAt.Add(3 * time.Hour), ID: "b"},
+			},
+			[]*GooglePhoto{
+				{CreatedAt: createdAt, ID: "a"},
+				{CreatedAt: createdAt.Add(time.Hour), ID: "b"},
+			},
+		)
+
+		// The API's version is kept, and sorted by its new creation time.
+		assert.Len(t, merged, 2)
+		assert.Equal(t, "a", merged[0].ID)
+		assert.Equal(t, "b", merged[1].ID)
+		assert.Equal(t, createdAt.Add(3*time.Hour), merged[1].CreatedAt)
+	})
+}
+
+func TestMergeLikedTweets(t *testing.T) {
+	firstSeen := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	s1 := []*LikedTweet{
+		{Tweet: Tweet{ID: 3, Text: "s1 3"}, LikedAt: now},
+		{Tweet: Tweet{ID: 2, Text: "s1 2"}, LikedAt: now},
+	}
+	s2 := []*LikedTweet{
+		{Tweet: Tweet{ID: 2, Text: "s2 2"}, LikedAt: firstSeen},
+		{Tweet: Tweet{ID: 1, Text: "s2 1"}, LikedAt: firstSeen}, // deleted by its author
+	}
+
+	assert.Equal(
+		t,
+		[]*LikedTweet{
+			{Tweet: Tweet{ID: 3, Text: "s1 3"}, LikedAt: now},
+			{Tweet: Tweet{ID: 2, Text: "s1 2"}, LikedAt: firstSeen}, // s1 is preferred, but keeps its first seen time
+			{Tweet: Tweet{ID: 1, Text: "s2 1"}, LikedAt: firstSeen},
+		},
+		mergeLikedTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc}),
+	)
+
+	t.Run("OldPreferredOnTrivialChanges", func(t *testing.T) {
+		s1 := []*LikedTweet{
+			{Tweet: Tweet{ID: 2, Text: "sX 2", FavoriteCount: 4}, LikedAt: now},
+		}
+		s2 := []*LikedTweet{
+			{Tweet: Tweet{ID: 2, Text: "sX 2", FavoriteCount: 2}, LikedAt: firstSeen, User: "someone"},
+		}
+
+		assert.Equal(
+			t,
+			[]*LikedTweet{
+				{Tweet: Tweet{ID: 2, Text: "sX 2", FavoriteCount: 2}, LikedAt: firstSeen, User: "someone"}, // s2 is preferred
+			},
+			mergeLikedTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc}),
+		)
+	})
+}
+
+func TestMergeReadings(t *testing.T) {
+	t.Run("Standard", func(t *testing.T) {
+		s1 := []*Reading{
+			{ReviewID: 125, Review: "s1 125"},
+			{ReviewID: 124, Review: "s1 124"},
+			{ReviewID: 123, Review: "s1 123"},
+			{ReviewID: 122, Review: "s1 122"},
+		}
+		s2 := []*Reading{
+			{ReviewID: 124, Review: "s2 124"},
+			{ReviewID: 123, Review: "s2 123"},
+		}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+
+		assert.Equal(
+			t,
+			[]*Reading{
+				{ReviewID: 125, Review: "s1 125"},
+				{ReviewID: 124, Review: "s1 124"}, // s1 is preferred
+				{ReviewID: 123, Review: "s1 123"}, // s1 is preferred
+				{ReviewID: 122, Review: "s1 122"},
+			},
+			s,
+		)
+	})
+
+	t.Run("ReviewEditedAfterExisting", func(t *testing.T) {
+		older := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		newer := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+
+		s1 := []*Reading{
+			{ReviewID: 123, Review: "edited", UpdatedAt: newer},
+		}
+		s2 := []*Reading{
+			{ReviewID: 123, Review: "original", UpdatedAt: older},
+		}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+
+		assert.Equal(t, []*Reading{{ReviewID: 123, Review: "edited", UpdatedAt: newer}}, s)
+	})
+
+	t.Run("ReviewNotEditedSinceExisting", func(t *testing.T) {
+		updatedAt := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		s1 := []*Reading{
+			{ReviewID: 123, Rating: 5, Review: "stale", UpdatedAt: updatedAt},
+		}
+		s2 := []*Reading{
+			{ReviewID: 123, Rating: 5, Review: "original", UpdatedAt: updatedAt},
+		}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+
+		// The text differs without the review having been edited, so the
+		// existing text is kept
+		assert.Equal(t, []*Reading{{ReviewID: 123, Rating: 5, Review: "original", UpdatedAt: updatedAt}}, s)
+	})
+
+	t.Run("ExistingEditedAfterReview", func(t *testing.T) {
+		older := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		newer := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+
+		s1 := []*Reading{
+			{ReviewID: 123, Rating: 5, Review: "stale", UpdatedAt: older},
+		}
+		s2 := []*Reading{
+			{ReviewID: 123, Rating: 4, Review: "edited", UpdatedAt: newer},
+		}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+
+		// The rating changed, so a history point was added as of the merge
+		assert.Len(t, s, 1)
+		assert.Len(t, s[0].RatingHistory, 2)
+		assert.WithinDuration(t, time.Now(), s[0].RatingHistory[1].RecordedAt, time.Minute)
+		s[0].RatingHistory[1].RecordedAt = time.Time{}
+
+		// Other fields still come from s1
+		assert.Equal(t, []*Reading{{
+			ReviewID:  123,
+			Rating:    5,
+			Review:    "edited",
+			UpdatedAt: newer,
+			RatingHistory: []*RatingPoint{
+				{Rating: 4, RecordedAt: newer},
+				{Rating: 5},
+			},
+		}}, s)
+	})
+
+	t.Run("RatingUnchanged", func(t *testing.T) {
+		s1 := []*Reading{{ReviewID: 123, Rating: 4}}
+		s2 := []*Reading{{ReviewID: 123, Rating: 4}}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+		assert.Nil(t, s[0].RatingHistory)
+	})
+
+	t.Run("ConflictLog", func(t *testing.T) {
+		older := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		newer := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+
+		s1 := []*Reading{
+			{ReviewID: 125, Review: "s1 125"},
+			{ReviewID: 124, Review: "stale", UpdatedAt: older},
+			{ReviewID: 123, Review: "same"},
+		}
+		s2 := []*Reading{
+			{ReviewID: 124, Review: "edited", UpdatedAt: newer, Notes: "private 124"},
+			{ReviewID: 123, Review: "same"}, // no differences; nothing logged
+		}
+
+		var conflictLog bytes.Buffer
+		mergeReadings(s1, s2, sortOrderDesc, nil, &conflictLog)
+
+		assert.Equal(t, strings.Join([]string{
+			`{"api_value":"stale","chosen":"existing","existing_value":"edited","field":"review","review_id":124}`,
+			`{"api_value":"","chosen":"existing","existing_value":"private 124","field":"notes","review_id":124}`,
+		}, "\n")+"\n", conflictLog.String())
+	})
+
+	t.Run("CoverLocalPathKept", func(t *testing.T) {
+		s1 := []*Reading{
+			{ReviewID: 124, CoverLocalPath: "covers/2.png"},
+			{ReviewID: 123},
+		}
+		s2 := []*Reading{
+			{ReviewID: 124, CoverLocalPath: "covers/2.jpg"},
+			{ReviewID: 123, CoverLocalPath: "covers/1.jpg"},
+		}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+
+		assert.Equal(
+			t,
+			[]*Reading{
+				{ReviewID: 124, CoverLocalPath: "covers/2.png"},
+				{ReviewID: 123, CoverLocalPath: "covers/1.jpg"}, // kept from s2
+			},
+			s,
+		)
+	})
+
+	t.Run("MeanSentimentKept", func(t *testing.T) {
+		s1 := []*Reading{
+			{ReviewID: 126, Review: "Read it", MeanSentimentComputed: true},
+			{ReviewID: 125, Review: "Great", MeanSentiment: 0.6, MeanSentimentComputed: true},
+			{ReviewID: 124, Review: "Dull"},
+			{ReviewID: 123, Review: "Great"},
+		}
+		s2 := []*Reading{
+			{ReviewID: 126, Review: "Read it", MeanSentiment: 0.6, MeanSentimentComputed: true},
+			{ReviewID: 125, Review: "Great", MeanSentiment: 0.4, MeanSentimentComputed: true},
+			{ReviewID: 124, Review: "Great", MeanSentiment: 0.6, MeanSentimentComputed: true},
+			{ReviewID: 123, Review: "Great", MeanSentiment: 0.6, MeanSentimentComputed: true},
+		}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+
+		assert.Equal(
+			t,
+			[]*Reading{
+				{ReviewID: 126, Review: "Read it", MeanSentimentComputed: true}, // computed as neutral; not replaced
+				{ReviewID: 125, Review: "Great", MeanSentiment: 0.6, MeanSentimentComputed: true},
+				{ReviewID: 124, Review: "Dull"},                                                   // review changed; not kept
+				{ReviewID: 123, Review: "Great", MeanSentiment: 0.6, MeanSentimentComputed: true}, // kept from s2
+			},
+			s,
+		)
+	})
+
+	t.Run("NotesKept", func(t *testing.T) {
+		s1 := []*Reading{
+			{ReviewID: 124, Review: "s1 124"},
+			{ReviewID: 123, Review: "s1 123"},
+		}
+		s2 := []*Reading{
+			{ReviewID: 124, Review: "s2 124", Notes: "private 124"},
+			{ReviewID: 123, Review: "s2 123"},
+		}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+
+		assert.Equal(
+			t,
+			[]*Reading{
+				{ReviewID: 124, Review: "s1 124", Notes: "private 124"}, // notes kept from s2
+				{ReviewID: 123, Review: "s1 123"},
+			},
+			s,
+		)
+	})
+
+	t.Run("ChallengeIDsKept", func(t *testing.T) {
+		s1 := []*Reading{
+			{ReviewID: 124, Review: "s1 124"},
+			{ReviewID: 123, Review: "s1 123", ChallengeIDs: []int{11621}},
+		}
+		s2 := []*Reading{
+			{ReviewID: 124, Review: "s2 124", ChallengeIDs: []int{11621, 9801}},
+			{ReviewID: 123, Review: "s2 123", ChallengeIDs: []int{9801}},
+		}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+
+		assert.Equal(
+			t,
+			[]*Reading{
+				{ReviewID: 124, Review: "s1 124", ChallengeIDs: []int{11621, 9801}}, // kept from s2
+				{ReviewID: 123, Review: "s1 123", ChallengeIDs: []int{11621}},       // s1 is preferred
+			},
+			s,
+		)
+	})
+
+	t.Run("RecommendedByKept", func(t *testing.T) {
+		s1 := []*Reading{
+			{ReviewID: 124, Review: "s1 124"},
+			{ReviewID: 123, Review: "s1 123"},
+		}
+		s2 := []*Reading{
+			{ReviewID: 124, Review: "s2 124", RecommendedBy: "Alice"},
+			{ReviewID: 123, Review: "s2 123"},
+		}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+
+		assert.Equal(
+			t,
+			[]*Reading{
+				{ReviewID: 124, Review: "s1 124", RecommendedBy: "Alice"}, // kept from s2
+				{ReviewID: 123, Review: "s1 123"},
+			},
+			s,
+		)
+	})
+
+	t.Run("UndatedLast", func(t *testing.T) {
+		readAt := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		s1 := []*Reading{
+			{ReviewID: 125},
+			{ReviewID: 124, ReadAt: readAt},
+			{ReviewID: 123},
+			{ReviewID: 122, AbandonedAt: readAt, Abandoned: true},
+		}
+		s2 := []*Reading{
+			{ReviewID: 123},
+		}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+
+		assert.Equal(
+			t,
+			[]*Reading{
+				{ReviewID: 124, ReadAt: readAt},
+				{ReviewID: 122, AbandonedAt: readAt, Abandoned: true},
+				{ReviewID: 125},
+				{ReviewID: 123},
+			},
+			s,
+		)
+	})
+
+	t.Run("UndatedLastAsc", func(t *testing.T) {
+		readAt := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		s1 := []*Reading{
+			{ReviewID: 125},
+			{ReviewID: 124, ReadAt: readAt},
+			{ReviewID: 123},
+			{ReviewID: 122, ReadAt: readAt},
+		}
+
+		s := mergeReadings(s1, nil, sortOrderAsc, nil, nil)
+
+		assert.Equal(
+			t,
+			[]*Reading{
+				{ReviewID: 122, ReadAt: readAt},
+				{ReviewID: 124, ReadAt: readAt},
+				{ReviewID: 123},
+				{ReviewID: 125},
+			},
+			s,
+		)
+	})
+
+	t.Run("RemoveOld", func(t *testing.T) {
+		s1 := []*Reading{
+			{ReviewID: 125},
+			{ReviewID: 123},
+		}
+		s2 := []*Reading{
+			{ReviewID: 125},
+			{ReviewID: 124},
+			{ReviewID: 123},
+		}
+
+		s := mergeReadings(s1, s2, sortOrderDesc, nil, nil)
+
+		assert.Equal(
+			t,
+			[]*Reading{
+				{ReviewID: 125},
+				{ReviewID: 123},
+			},
+			s,
+		)
+	})
+
+	t.Run("KeepMissing", func(t *testing.T) {
+		s1 := []*Reading{
+			{ReviewID: 125},
+			{ReviewID: 123},
+		}
+		s2 := []*Reading{
+			{ReviewID: 125},
+			{ReviewID: 124},
+			{ReviewID: 123},
+		}
+
+		keepMissing := func(reading *Reading) bool { return reading.ReviewID == 124 }
+		s := mergeReadings(s1, s2, sortOrderDesc, keepMissing, nil)
+
+		assert.Equal(
+			t,
+			[]*Reading{
+				{ReviewID: 125},
+				{ReviewID: 124},
+				{ReviewID: 123},
+			},
+			s,
+		)
+	})
+}
+
+func TestMergeNomadStays(t *testing.T) {
+	dateStart := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	merged := mergeNomadStays(
+		[]*NomadStay{
+			{City: "Lisbon", DateStart: dateStart.Add(7 * 24 * time.Hour), ID: "2"},
+			{DateStart: dateStart.Add(14 * 24 * time.Hour), ID: "3"},
+		},
+		[]*NomadStay{
+			{DateStart: dateStart, ID: "1"},
+			{City: "Porto", DateStart: dateStart.Add(7 * 24 * time.Hour), ID: "2"},
+		},
+	)
+
+	assert.Len(t, merged, 3)
+	assert.Equal(t, "1", merged[0].ID)
+	assert.Equal(t, "2", merged[1].ID)
+	assert.Equal(t, "Lisbon", merged[1].City)
+	assert.Equal(t, "3", merged[2].ID)
+
+	t.Run("DateStartEdited", func(t *testing.T) {
+		merged := mergeNomadStays(
+			[]*NomadStay{
+				{DateStart: dateStart.Add(21 * 24 * time.Hour), ID: "2"},
+			},
+			[]*NomadStay{
+				{DateStart: dateStart, ID: "1"},
+				{DateStart: dateStart.Add(7 * 24 * time.Hour), ID: "2"},
+			},
+		)
+
+		// The API's version is kept, and sorted by its new start date.
+		assert.Len(t, merged, 2)
+		assert.Equal(t, "1", merged[0].ID)
+		assert.Equal(t, "2", merged[1].ID)
+		assert.Equal(t, dateStart.Add(21*24*time.Hour), merged[1].DateStart)
+	})
+}
+
+func TestMergeOuraSleepDays(t *testing.T) {
+	day1 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+	day3 := time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	s1 := []*OuraSleepDay{
+		{Date: day3, Score: 83},
+		{Date: day2, Score: 82},
+	}
+	s2 := []*OuraSleepDay{
+		{Date: day1, Score: 71},
+		{Date: day2, Score: 72},
+	}
+
+	s := mergeOuraSleepDays(s1, s2)
+
+	assert.Equal(
+		t,
+		[]*OuraSleepDay{
+			{Date: day1, Score: 71},
+			{Date: day2, Score: 82}, // s1 is preferred
+			{Date: day3, Score: 83},
+		},
+		s,
+	)
+}
+
+func TestMergeRunkeeperActivities(t *testing.T) {
+	startTime := time.Date(2021, 5, 1, 7, 0, 0, 0, time.UTC)
+
+	merged := mergeRunkeeperActivities(
+		[]*RunkeeperActivity{
+			{Notes: "Updated", StartTime: startTime.Add(24 * time.Hour), URI: "/fitnessActivities/2"},
+			{StartTime: startTime.Add(48 * time.Hour), URI: "/fitnessActivities/3"},
+		},
+		[]*RunkeeperActivity{
+			{StartTime: startTime, URI: "/fitnessActivities/1"},
+			{Notes: "Original", StartTime: startTime.Add(24 * time.Hour), URI: "/fitnessActivities/2"},
+		},
+	)
+
+	assert.Len(t, merged, 3)
+	assert.Equal(t, "/fitnessActivities/1", merged[0].URI)
+	assert.Equal(t, "/fitnessActivities/2", merged[1].URI)
+	assert.Equal(t, "Updated", merged[1].Notes)
+	assert.Equal(t, "/fitnessActivities/3", merged[2].URI)
+
+	t.Run("StartTimeEdited", func(t *testing.T) {
+		merged := mergeRunkeeperActivities(
+			[]*RunkeeperActivity{
+				{StartTime: startTime.Add(72 * time.Hour), URI: "/fitnessActivities/2"},
+			},
+			[]*RunkeeperActivity{
+				{StartTime: startTime, URI: "/fitnessActivities/1"},
+				{StartTime: startTime.Add(24 * time.Hour), URI: "/fitnessActivities/2"},
+			},
+		)
+
+		// The API's version is kept, and sorted by its new start time.
+		assert.Len(t, merged, 2)
+		assert.Equal(t, "/fitnessActivities/1", merged[0].URI)
+		assert.Equal(t, "/fitnessActivities/2", merged[1].URI)
+		assert.Equal(t, startTime.Add(72*time.Hour), merged[1].StartTime)
+	})
+}
+
+func TestMergeSteamGames(t *testing.T) {
+	playedAt := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s1 := []*SteamGame{
+		{AppID: 620, Name: "Portal 2", PlaytimeForeverMin: 600, PlaytimeTwoWeeksMin: 0},
+		{AppID: 413150, Name: "Stardew Valley", PlaytimeForeverMin: 4210, PlaytimeTwoWeeksMin: 95, LastPlayedAt: playedAt},
+	}
+	s2 := []*SteamGame{
+		{AppID: 400, Name: "Portal", PlaytimeForeverMin: 300},
+		{AppID: 620, Name: "Portal 2", PlaytimeForeverMin: 612, LastPlayedAt: playedAt},
+		{AppID: 413150, Name: "Stardew Valley", PlaytimeForeverMin: 4000, PlaytimeTwoWeeksMin: 30},
+	}
+
+	assert.Equal(
+		t,
+		[]*SteamGame{
+			{AppID: 400, Name: "Portal", PlaytimeForeverMin: 300},                           // no longer returned, but kept
+			{AppID: 620, Name: "Portal 2", PlaytimeForeverMin: 612, LastPlayedAt: playedAt}, // larger values kept from s2
+			{AppID: 413150, Name: "Stardew Valley", PlaytimeForeverMin: 4210, PlaytimeTwoWeeksMin: 95, LastPlayedAt: playedAt},
+		},
+		mergeSteamGames(s1, s2),
+	)
+}
+
+func TestMergeSubstackPosts(t *testing.T) {
+	publishedAt := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s1 := []*SubstackPost{
+		{ID: 2, PublishedAt: publishedAt.Add(24 * time.Hour), Title: "Second", Views: 20},
+		{ID: 3, PublishedAt: publishedAt.Add(48 * time.Hour), Title: "Third", Views: 5},
+	}
+	s2 := []*SubstackPost{
+		{ID: 1, PublishedAt: publishedAt, Title: "First", Views: 100},
+		{ID: 2, PublishedAt: publishedAt.Add(24 * time.Hour), Title: "Second", Views: 10},
+	}
+
+	assert.Equal(
+		t,
+		[]*SubstackPost{
+			{ID: 1, PublishedAt: publishedAt, Title: "First", Views: 100}, // no longer returned, but kept
+			{ID: 2, PublishedAt: publishedAt.Add(24 * time.Hour), Title: "Second", Views: 20},
+			{ID: 3, PublishedAt: publishedAt.Add(48 * time.Hour), Title: "Third", Views: 5},
+		},
+		mergeSubstackPosts(s1, s2),
+	)
+}
+
+func TestMergeTogglEntries(t *testing.T) {
+	startedAt := time.Date(2023, 3, 14, 16, 0, 0, 0, time.UTC)
+
+	merged := mergeTogglEntries(
+		[]*TogglEntry{
+			{ID: 2, Description: "Updated", StartedAt: startedAt.Add(time.Hour)},
+			{ID: 3, StartedAt: startedAt.Add(2 * time.Hour)},
+		},
+		[]*TogglEntry{
+			{ID: 1, StartedAt: startedAt},
+			{ID: 2, Description: "Original", StartedAt: startedAt.Add(time.Hour)},
+		},
+	)
+
+	assert.Len(t, merged, 3)
+	assert.Equal(t, int64(1), merged[0].ID)
+	assert.Equal(t, int64(2), merged[1].ID)
+	assert.Equal(t, "Updated", merged[1].Description)
+	assert.Equal(t, int64(3), merged[2].ID)
+
+	t.Run("StartedAtEdited", func(t *testing.T) {
+		merged := mergeTogglEntries(
+			[]*TogglEntry{
+				{ID: 2, StartedAt: startedAt.Add(3 * time.Hour)},
+			},
+			[]*TogglEntry{
+				{ID: 1, StartedAt: startedAt},
+				{ID: 2, StartedAt: startedAt.Add(time.Hour)},
+			},
+		)
+
+		// The API's version is kept, and sorted by its new start time.
+		assert.Len(t, merged, 2)
+		assert.Equal(t, int64(1), merged[0].ID)
+		assert.Equal(t, int64(2), merged[1].ID)
+		assert.Equal(t, startedAt.Add(3*time.Hour), merged[1].StartedAt)
+	})
+}
+
+func TestMergeTweets(t *testing.T) {
+	t.Run("Standard", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 125, Text: "s1 125"},
+			{ID: 124, Text: "s1 124"},
+			{ID: 122, Text: "s1 122"},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "s2 124"},
+			{ID: 123, Text: "s2 123"},
+			{ID: 121, Text: "s2 121"},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc})
+
+		assert.Equal(
+			t,
+			[]*Tweet{
+				{ID: 125, Text: "s1 125"},
+				{ID: 124, Text: "s1 124"}, // s1 is preferred
+				{ID: 123, Text: "s2 123"},
+				{ID: 122, Text: "s1 122"},
+				{ID: 121, Text: "s2 121"},
+			},
+			s,
+		)
+	})
+
+	t.Run("NewPreferredOnNonTrivialChangesInc", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 125, Text: "s1 125"},
+			// Text should be the same
+			{ID: 124, Text: "sX 124", FavoriteCount: 10, RetweetCount: 10},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2},
+			{ID: 123, Text: "s2 123"},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc})
+
+		assert.Equal(
+			t,
+			[]*Tweet{
+				{ID: 125, Text: "s1 125"},
+				{ID: 124, Text: "sX 124", FavoriteCount: 10, RetweetCount: 10}, // s1 is preferred
+				{ID: 123, Text: "s2 123"},
+			},
+			s,
+		)
+	})
+
+	t.Run("NewPreferredOnNonTrivialChangesDec", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 125, Text: "s1 125"},
+			// Text should be the same
+			{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 10, RetweetCount: 10},
+			{ID: 123, Text: "s2 123"},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc})
+
+		assert.Equal(
+			t,
+			[]*Tweet{
+				{ID: 125, Text: "s1 125"},
+				{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2}, // s1 is preferred
+				{ID: 123, Text: "s2 123"},
+			},
+			s,
+		)
+	})
+
+	t.Run("OldPreferredOnTrivialChanges", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 125, Text: "s1 125"},
+			// Text must be the same for this to work
+			{ID: 124, Text: "sX 124", FavoriteCount: 4, RetweetCount: 4},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2},
+			{ID: 123, Text: "s2 123"},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc})
+
+		assert.Equal(
+			t,
+			[]*Tweet{
+				{ID: 125, Text: "s1 125"},
+				{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2}, // s2 is preferred
+				{ID: 123, Text: "s2 123"},
+			},
+			s,
+		)
+	})
+
+	t.Run("OldPreferredOnTrivialReplyCountChanges", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", ReplyCount: 4},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", ReplyCount: 2},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{APIV2: true, Sort: sortOrderDesc})
+
+		assert.Equal(t, []*Tweet{{ID: 124, Text: "sX 124", ReplyCount: 2}}, s) // s2 is preferred
+	})
+
+	t.Run("NewPreferredOnNonTrivialReplyCountChanges", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", ReplyCount: 5},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", ReplyCount: 2},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{APIV2: true, Sort: sortOrderDesc})
+
+		assert.Equal(t, []*Tweet{{ID: 124, Text: "sX 124", ReplyCount: 5}}, s) // s1 is preferred
+	})
+
+	t.Run("ReplyCountKeptWithoutAPIV2", func(t *testing.T) {
+		// Fetched without --twitter-api-v2, so there's no reply count.
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 10},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 2, ReplyCount: 7},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc})
+
+		assert.Equal(t, 10, s[0].FavoriteCount) // s1 is preferred
+		assert.Equal(t, 7, s[0].ReplyCount)
+	})
+
+	t.Run("OldPreferredOnTrivialEngagementChanges", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", LikesByFollowers: 0.0105},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", LikesByFollowers: 0.0100},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{ComputeEngagement: true, EngagementThreshold: 0.001})
+
+		assert.Equal(t, []*Tweet{{ID: 124, Text: "sX 124", LikesByFollowers: 0.0100}}, s) // s2 is preferred
+	})
+
+	t.Run("NewPreferredOnNonTrivialEngagementChanges", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", LikesByFollowers: 0.0200},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", LikesByFollowers: 0.0100},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{ComputeEngagement: true, EngagementThreshold: 0.001})
+
+		assert.Equal(t, []*Tweet{{ID: 124, Text: "sX 124", LikesByFollowers: 0.0200}}, s) // s1 is preferred
+	})
+
+	t.Run("LikesByFollowersKeptWithoutComputeEngagement", func(t *testing.T) {
+		// Fetched without --compute-engagement, so there's no ratio.
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 10},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 2, LikesByFollowers: 0.0200},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{EngagementThreshold: 0.001})
+
+		assert.Equal(t, 10, s[0].FavoriteCount) // s1 is preferred
+		assert.Equal(t, 0.0200, s[0].LikesByFollowers)
+	})
+
+	t.Run("OldPreferredOnTrivialViewChanges", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", ViewCount: 1090},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", ViewCount: 1000},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{APIV2: true, TrivialViewThreshold: 100})
+
+		assert.Equal(t, []*Tweet{{ID: 124, Text: "sX 124", ViewCount: 1000}}, s) // s2 is preferred
+	})
+
+	t.Run("NewPreferredOnNonTrivialViewChanges", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", ViewCount: 1500},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", ViewCount: 1000},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{APIV2: true, TrivialViewThreshold: 100})
+
+		assert.Equal(t, []*Tweet{{ID: 124, Text: "sX 124", ViewCount: 1500}}, s) // s1 is preferred
+	})
+
+	t.Run("ViewCountKeptWithoutAPIV2", func(t *testing.T) {
+		// Fetched without --twitter-api-v2, so there are no views. The
+		// difference from the stored views isn't a change.
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 3},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 2, ReplyCount: 7, ViewCount: 5000},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{TrivialViewThreshold: 100})
+
+		assert.Equal(t, []*Tweet{{ID: 124, Text: "sX 124", FavoriteCount: 2, ReplyCount: 7, ViewCount: 5000}}, s) // s2 is preferred
+	})
+
+	t.Run("NewPreferredOnTrivialChangesIfAnnotationsDifferent", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 3, Entities: &TweetEntities{
+				Annotations: []*TweetAnnotation{{NormalizedText: "Paris", Probability: 0.8, Type: "Place"}},
+			}},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 2, Entities: &TweetEntities{}},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc})
+
+		assert.Len(t, s[0].Entities.Annotations, 1) // s1 is preferred
+	})
+
+	t.Run("AnnotationsKeptWhenNotReturned", func(t *testing.T) {
+		annotations := []*TweetAnnotation{{NormalizedText: "Paris", Probability: 0.8, Type: "Place"}}
+
+		// Fetched from v1.1, which doesn't return annotations.
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 10},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 2, Entities: &TweetEntities{Annotations: annotations}},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc})
+
+		assert.Equal(t, 10, s[0].FavoriteCount) // s1 is preferred
+		assert.Equal(t, annotations, s[0].Entities.Annotations)
+	})
+
+	t.Run("OldPreferredOnTrivialChangesWithoutStoredHash", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124", TextHashSHA1: hashSHA1("sX 124"), FavoriteCount: 4},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 2},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc})
+
+		assert.Equal(t, []*Tweet{
+			{ID: 124, Text: "sX 124", TextHashSHA1: hashSHA1("sX 124"), FavoriteCount: 2}, // s2 is preferred
+		}, s)
+	})
+
+	t.Run("NormalizedTextKeptOnTrivialChanges", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 124, Text: "ｓX 124", NormalizedText: "sX 124", FavoriteCount: 4},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "ｓX 124", FavoriteCount: 2},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc})
+
+		assert.Equal(t, 2, s[0].FavoriteCount) // s2 is preferred
+		assert.Equal(t, "sX 124", s[0].NormalizedText)
+	})
+
+	t.Run("NewPreferredOnTextChanges", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 124, Text: "sX 124 (edited)", TextHashSHA1: hashSHA1("sX 124 (edited)")},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", TextHashSHA1: hashSHA1("sX 124")},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc})
+
+		assert.Equal(t, "sX 124 (edited)", s[0].Text) // s1 is preferred
+	})
+
+	t.Run("NewPreferredOnTrivialChangesIfEntitiesDifferent", func(t *testing.T) {
+		s1 := []*Tweet{
+			{ID: 125, Text: "s1 125"},
+			{ID: 124, Text: "sX 124", FavoriteCount: 4, RetweetCount: 4,
+				Entities: &TweetEntities{Medias: []*TweetEntitiesMedia{{URL: "https://foo.com"}}}},
+		}
+		s2 := []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2,
+				Entities: &TweetEntities{Medias: []*TweetEntitiesMedia{{URL: "https://bar.com"}}}},
+			{ID: 123, Text: "s2 123"},
+		}
+
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{Sort: sortOrderDesc})
+
+		assert.Equal(
+			t,
+			[]*Tweet{
+				{ID: 125, Text: "s1 125"},
+				{ID: 124, Text: "sX 124", FavoriteCount: 4, RetweetCount: 4,
+					Entities: &TweetEntities{Medias: []*TweetEntitiesMedia{{URL: "https://foo.com"}}}},
+				{ID: 123, Text: "s2 123"},
+			},
+			s,
+		)
+	})
+
+	// A trivial change and a non-trivial one, for comparing strategies.
+	strategyTweets := func() ([]*Tweet, []*Tweet) {
+		return []*Tweet{
+			{ID: 125, Text: "s1 125"},
+			{ID: 124, Text: "sX 124", FavoriteCount: 3, RetweetCount: 3},
+			{ID: 123, Text: "sX 123", FavoriteCount: 10, RetweetCount: 10},
+		}, []*Tweet{
+			{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2},
+			{ID: 123, Text: "sX 123", FavoriteCount: 2, RetweetCount: 2},
+			{ID: 122, Text: "s2 122"},
+		}
+	}
+
+	t.Run("MergeStrategyPreferNew", func(t *testing.T) {
+		s1, s2 := strategyTweets()
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{MergeStrategy: mergeStrategyPreferNew, Sort: sortOrderDesc})
+
+		assert.Equal(
+			t,
+			[]*Tweet{
+				{ID: 125, Text: "s1 125"},
+				{ID: 124, Text: "sX 124", FavoriteCount: 3, RetweetCount: 3}, // trivial change still taken
+				{ID: 123, Text: "sX 123", FavoriteCount: 10, RetweetCount: 10},
+				{ID: 122, Text: "s2 122"},
+			},
+			s,
+		)
+	})
+
+	t.Run("MergeStrategyPreferExisting", func(t *testing.T) {
+		s1, s2 := strategyTweets()
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{MergeStrategy: mergeStrategyPreferExisting, Sort: sortOrderDesc})
+
+		assert.Equal(
+			t,
+			[]*Tweet{
+				{ID: 125, Text: "s1 125"}, // new tweets are still added
+				{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2},
+				{ID: 123, Text: "sX 123", FavoriteCount: 2, RetweetCount: 2}, // non-trivial change ignored
+				{ID: 122, Text: "s2 122"},
+			},
+			s,
+		)
+	})
+
+	t.Run("MergeStrategySmart", func(t *testing.T) {
+		s1, s2 := strategyTweets()
+		s := mergeTweets(s1, s2, &SyncTwitterOptions{MergeStrategy: mergeStrategySmart, Sort: sortOrderDesc})
+
+		assert.Equal(
+			t,
+			[]*Tweet{
+				{ID: 125, Text: "s1 125"},
+				{ID: 124, Text: "sX 124", FavoriteCount: 2, RetweetCount: 2}, // trivial change ignored
+				{ID: 123, Text: "sX 123", FavoriteCount: 10, RetweetCount: 10},
+				{ID: 122, Text: "s2 122"},
+			},
+			s,
+		)
+	})
+
+	t.Run("InvalidMergeStrategy", func(t *testing.T) {
+		err := syncTwitter(context.Background(), filepath.Join(t.TempDir(), "twitter.toml"),
+			&SyncTwitterOptions{MergeStrategy: "prefer-newest"})
+		assert.EqualError(t, err,
+			"unknown merge strategy 'prefer-newest' (should be 'prefer-new', 'prefer-existing', or 'smart')")
+	})
+}
+
+func TestMonzoTransactionFromAPITransaction(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/monzo_transactions.json")
+	assert.NoError(t, err)
+
+	var root MonzoAPITransactionsRoot
+	err = json.Unmarshal(data, &root)
+	assert.NoError(t, err)
+
+	t.Run("Merchant", func(t *testing.T) {
+		assert.Equal(t, &MonzoTransaction{
+			Amount:       -510,
+			Category:     "eating_out",
+			CreatedAt:    time.Date(2021, 1, 2, 12, 34, 56, 123000000, time.UTC),
+			Currency:     "GBP",
+			Description:  "THE DE BEAUVOIR DELI C LONDON        GBR",
+			ID:           "tx_00008zIcpb1TB4yeIFXMzx",
+			MerchantCity: "London",
+			MerchantName: "The De Beauvoir Deli Co.",
+			Notes:        "Salmon sandwich 🍞",
+		}, monzoTransactionFromAPITransaction(root.Transactions[0]))
+	})
+
+	t.Run("NoMerchant", func(t *testing.T) {
+		transaction := monzoTransactionFromAPITransaction(root.Transactions[1])
+		assert.Equal(t, 5000, transaction.Amount)
+		assert.True(t, transaction.IsLoad)
+		assert.Equal(t, "", transaction.MerchantCity)
+		assert.Equal(t, "", transaction.MerchantName)
+	})
+}
+
+func TestNomadStayFromAPITrip(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/nomadlist_profile.json")
+	assert.NoError(t, err)
+
+	var profile NomadListAPIProfile
+	err = json.Unmarshal(data, &profile)
+	assert.NoError(t, err)
+
+	stay, err := nomadStayFromAPITrip(profile.Trips[0])
+	assert.NoError(t, err)
+	assert.Equal(t, &NomadStay{
+		City:         "Lisbon",
+		Country:      "Portugal",
+		CountryCode:  "PT",
+		DateEnd:      time.Date(2019, 5, 15, 0, 0, 0, 0, time.UTC),
+		DateStart:    time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC),
+		DurationDays: 14,
+		ID:           "3894321",
+		Latitude:     38.7223,
+		Longitude:    -9.1393,
+	}, stay)
+
+	t.Run("BadDate", func(t *testing.T) {
+		_, err := nomadStayFromAPITrip(&NomadListAPITrip{ID: "1", DateStart: "May 1", DateEnd: "2019-05-15"})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Run("Proxy", func(t *testing.T) {
+		proxy, requests := newMockProxy(t)
+		setHTTPProxy(t, proxy.URL)
+
+		resp, err := newHTTPClient().Get("http://api.example.com/resource")
+		assert.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Len(t, *requests, 1)
+		assert.Equal(t, "http://api.example.com/resource", (*requests)[0].URL.String())
+	})
+
+	t.Run("NoProxy", func(t *testing.T) {
+		assert.Nil(t, newHTTPClient().Transport)
+	})
+}
+
+func TestNewTwitterHTTPClient(t *testing.T) {
+	proxy, requests := newMockProxy(t)
+	setHTTPProxy(t, proxy.URL)
+
+	client := newTwitterHTTPClient(context.Background(), &TwitterConf{
+		TwitterAccessSecret:   "secret",
+		TwitterAccessToken:    "token",
+		TwitterConsumerKey:    "key",
+		TwitterConsumerSecret: "secret",
+	})
+
+	resp, err := client.Get("http://api.twitter.com/1.1/users/show.json")
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	// Requests are still signed when sent through the proxy.
+	assert.Len(t, *requests, 1)
+	assert.Equal(t, "http://api.twitter.com/1.1/users/show.json", (*requests)[0].URL.String())
+	assert.Contains(t, (*requests)[0].Header.Get("Authorization"), `oauth_consumer_key="key"`)
+}
+
+func TestOpenWeatherDayFromAPIHours(t *testing.T) {
+	date := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	day := openWeatherDayFromAPIHours(date, []*OpenWeatherAPIHour{
+		{
+			FeelsLike: 1.5, Humidity: 90, Sunrise: 1609602888, Sunset: 1609633514, Temp: 4.0, UVI: 0,
+			Weather: []*OpenWeatherAPIWeather{{Description: "overcast clouds", Main: "Clouds"}},
+		},
+		{
+			FeelsLike: 4.0, Humidity: 80, Rain: &OpenWeatherAPIPrecipitation{OneHourMM: 1.25}, Temp: 7.5, UVI: 0.8,
+			Weather: []*OpenWeatherAPIWeather{{Description: "light rain", Main: "Rain"}},
+		},
+		{
+			FeelsLike: 3.0, Humidity: 85, Snow: &OpenWeatherAPIPrecipitation{OneHourMM: 0.5}, Temp: 5.0, UVI: 0.3,
+			Weather: []*OpenWeatherAPIWeather{{Description: "moderate rain", Main: "Rain"}},
+		},
+	})
+
+	assert.Equal(t, &OpenWeatherDay{
+		Date:               date,
+		FeelsLikeMaxC:      4.0,
+		Humidity:           85,
+		PrecipitationMM:    1.75,
+		SunriseAt:          time.Date(2021, 1, 2, 15, 54, 48, 0, time.UTC),
+		SunsetAt:           time.Date(2021, 1, 3, 0, 25, 14, 0, time.UTC),
+		TempMaxC:           7.5,
+		TempMinC:           4.0,
+		UVI:                0.8,
+		WeatherDescription: "light rain", // first description of the most common condition
+		WeatherMain:        "Rain",
+	}, day)
+}
+
+func TestOuraSleepDayFromAPIDailySleep(t *testing.T) {
+	dailySleep := &OuraAPIDailySleep{
+		Contributors: &OuraAPIDailySleepContributors{Timing: 84},
+		Day:          "2021-01-02",
+		Score:        82,
+	}
+
+	t.Run("WithPeriod", func(t *testing.T) {
+		day, err := ouraSleepDayFromAPIDailySleep(dailySleep, &OuraAPISleepPeriod{
+			Day:                "2021-01-02",
+			DeepSleepDuration:  4800,
+			Efficiency:         91,
+			Latency:            600,
+			LightSleepDuration: 14400,
+			Readiness:          &OuraAPISleepPeriodReadiness{TemperatureDeviation: -0.2},
+			REMSleepDuration:   6000,
+			TotalSleepDuration: 25200,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(
+			t,
+			&OuraSleepDay{
+				Date:             time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+				DeepSleep:        4800,
+				Efficiency:       91,
+				LatencyMin:       10,
+				LightSleep:       14400,
+				REMSleep:         6000,
+				Score:            82,
+				TemperatureDelta: -0.2,
+				TimingScore:      84,
+				TotalSleep:       25200,
+			},
+			day,
+		)
+	})
+
+	t.Run("WithoutPeriod", func(t *testing.T) {
+		day, err := ouraSleepDayFromAPIDailySleep(dailySleep, nil)
+		assert.NoError(t, err)
+
+		assert.Equal(
+			t,
+			&OuraSleepDay{
+				Date:        time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+				Score:       82,
+				TimingScore: 84,
+			},
+			day,
+		)
+	})
+}
+
+func TestParseHTTPProxy(t *testing.T) {
+	proxyURL, err := parseHTTPProxy("http://proxy.example.com:3128")
+	assert.NoError(t, err)
+	assert.Equal(t, "proxy.example.com:3128", proxyURL.Host)
+
+	_, err = parseHTTPProxy("socks5://localhost:1080")
+	assert.NoError(t, err)
+
+	_, err = parseHTTPProxy("proxy.example.com:3128")
+	assert.Error(t, err)
+
+	_, err = parseHTTPProxy("ftp://proxy.example.com")
+	assert.Error(t, err)
+
+	_, err = parseHTTPProxy("http://")
+	assert.Error(t, err)
+}
+
+func TestParseOvercastOPML(t *testing.T) {
+	t.Run("MissingURL", func(t *testing.T) {
+		episodes, numSkipped, err := parseOvercastOPML(strings.NewReader(`<opml><body>
+			<outline type="rss" text="Show" xmlUrl="https://example.com/feed">
+				<outline type="podcast-episode" title="Episode" pubDate="2021-01-04T18:00:00-05:00" />
+			</outline>
+		</body></opml>`))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, numSkipped)
+		assert.Empty(t, episodes)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, _, err := parseOvercastOPML(strings.NewReader("not OPML"))
+		assert.Error(t, err)
+	})
+}
+
+func TestParseRunningAheadCSV(t *testing.T) {
+	t.Run("MissingColumns", func(t *testing.T) {
+		// An export without heart rate, cadence, shoes, or notes.
+		workouts, numSkipped, err := parseRunningAheadCSV(strings.NewReader(
+			"Date,Course,Time,Distance(mi),Type\n" +
+				"2021-03-14,Track,20:00,2.5,Run\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, numSkipped)
+
+		assert.Equal(t, []*RunningWorkout{
+			{
+				ActivityType: "Run",
+				CourseKm:     2.5 * kmPerMile,
+				Date:         time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC),
+				DurationSec:  1200,
+				ID:           hashSHA1("2021-03-14T00:00:00Z\nTrack"),
+			},
+		}, workouts)
+	})
+
+	t.Run("MissingDate", func(t *testing.T) {
+		_, _, err := parseRunningAheadCSV(strings.NewReader("Course,Time\nTrack,20:00\n"))
+		assert.EqualError(t, err, "CSV export has no 'date' column")
+	})
+}
+
+func TestParseRunningAheadDuration(t *testing.T) {
+	for s, expected := range map[string]int{
+		"1:02:03": 3723,
+		"45:10":   2710,
+		"45:10.6": 2711,
+		"90":      90,
+	} {
+		duration, err := parseRunningAheadDuration(s)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, duration, "duration: %s", s)
+	}
+
+	for _, s := range []string{"", "1:2:3:4", "a:10", "-5:00"} {
+		_, err := parseRunningAheadDuration(s)
+		assert.Error(t, err, "duration: %s", s)
+	}
+}
+
+func TestParseSleepCycleCSV(t *testing.T) {
+	t.Run("Comma", func(t *testing.T) {
+		// An older export with a comma delimiter, and without heart rate or
+		// steps.
+		nights, numSkipped, err := parseSleepCycleCSV(strings.NewReader(
+			"Start,End,Sleep quality,Notes\n" +
+				"2021-01-01 23:00:00,2021-01-02 07:00:00,80%,\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, numSkipped)
+
+		assert.Equal(t, []*SleepCycleNight{
+			{
+				DurationMin: 480,
+				EndAt:       time.Date(2021, 1, 2, 7, 0, 0, 0, time.UTC),
+				ID:          "2021-01-01T23:00:00Z",
+				QualityPct:  80,
+				StartAt:     time.Date(2021, 1, 1, 23, 0, 0, 0, time.UTC),
+			},
+		}, nights)
+	})
+
+	t.Run("MissingStart", func(t *testing.T) {
+		_, _, err := parseSleepCycleCSV(strings.NewReader("End;Sleep Quality\n2021-01-02 07:00:00;80%\n"))
+		assert.EqualError(t, err, "CSV export has no 'start' column")
+	})
+}
+
+func TestParseSQLiteCreateTable(t *testing.T) {
+	columns, err := parseSQLiteCreateTable(
+		"CREATE TABLE content (ContentID TEXT NOT NULL, \"Book Title\" TEXT, [Price] DECIMAL(10, 2), " +
+			"Progress REAL NOT NULL DEFAULT 0, Note TEXT DEFAULT 'a, b', Ratio DEFAULT 'REAL', " +
+			"PRIMARY KEY (ContentID))")
+	assert.NoError(t, err)
+	assert.Equal(t, []*SQLiteColumn{
+		{Name: "ContentID"},
+		{Name: "Book Title"},
+		{Name: "Price"},
+		{Name: "Progress", Real: true},
+		{Name: "Note"},
+		{Name: "Ratio"},
+	}, columns)
+
+	columns, err = parseSQLiteCreateTable("CREATE TABLE t (a TEXT, id integer  primary key, b DOUBLE PRECISION)")
+	assert.NoError(t, err)
+	assert.Equal(t, []*SQLiteColumn{
+		{Name: "a"},
+		{Name: "id", RowID: true},
+		{Name: "b", Real: true},
+	}, columns)
+
+	_, err = parseSQLiteCreateTable("CREATE TABLE t (id TEXT PRIMARY KEY) WITHOUT ROWID")
+	assert.EqualError(t, err, "WITHOUT ROWID tables aren't supported")
+}
+
+func TestPWSDayRecordFromWUAPIObservation(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/wu_history_daily.json")
+	assert.NoError(t, err)
+
+	var root WUAPIHistoryRoot
+	err = json.Unmarshal(data, &root)
+	assert.NoError(t, err)
+
+	day, err := pwsDayRecordFromWUAPIObservation(root.Observations[0])
+	assert.NoError(t, err)
+	assert.Equal(t, &PWSDayRecord{
+		AvgHumidity:     79.4,
+		AvgPressureHpa:  1018.9,
+		AvgTempC:        10.3,
+		Date:            time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), // local date, not UTC
+		MaxUVIndex:      3.0,
+		MaxWindSpeedKmh: 24.1,
+		PrecipitationMM: 4.57,
+	}, day)
+
+	t.Run("MissingMetric", func(t *testing.T) {
+		_, err := pwsDayRecordFromWUAPIObservation(&WUAPIObservation{
+			ObsTimeLocal: "2021-01-02 23:59:58",
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestParseGoodreadsTime(t *testing.T) {
+	expected := time.Date(2021, 2, 2, 15, 4, 5, 0, time.FixedZone("", -8*60*60))
+
+	for _, s := range []string{
+		"Tue Feb 2 15:04:05 -0800 2021",    // English
+		"mar feb 2 15:04:05 -0800 2021",    // Spanish
+		"Mar. févr. 2 15:04:05 -0800 2021", // French
+		"Di Feb 2 15:04:05 -0800 2021",     // German
+		"mar feb 2 15:04:05 -0800 2021",    // Italian
+		"ter fev 2 15:04:05 -0800 2021",    // Portuguese
+		"di feb 2 15:04:05 -0800 2021",     // Dutch
+	} {
+		t.Run(s, func(t *testing.T) {
+			parsed, err := parseGoodreadsTime(s, "")
+			assert.NoError(t, err)
+			assert.True(t, expected.Equal(parsed), "expected %v, got %v", expected, parsed)
+		})
+	}
+
+	t.Run("CustomFormat", func(t *testing.T) {
+		parsed, err := parseGoodreadsTime("2021-02-02", "2006-01-02")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2021, 2, 2, 0, 0, 0, 0, time.UTC), parsed)
+	})
+
+	t.Run("Unparseable", func(t *testing.T) {
+		_, err := parseGoodreadsTime("xyz abc 2 15:04:05 -0800 2021", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestReadReadingDB(t *testing.T) {
+	t.Run("Unversioned", func(t *testing.T) {
+		path := writeTestFile(t, "goodreads.toml", `
+[[readings]]
+  review_id = 123
+  title = "The Odyssey"
+`)
+
+		readingDB, err := readReadingDB(path)
+		assert.NoError(t, err)
+		assert.Equal(t, SchemaVersion, readingDB.Version)
+		assert.Equal(t, "The Odyssey", readingDB.Readings[0].Title)
+	})
+
+	t.Run("Current", func(t *testing.T) {
+		path := writeTestFile(t, "goodreads.toml", fmt.Sprintf(`
+version = %v
+
+[[readings]]
+  review_id = 123
+`, SchemaVersion))
+
+		readingDB, err := readReadingDB(path)
+		assert.NoError(t, err)
+		assert.Equal(t, SchemaVersion, readingDB.Version)
+	})
+
+	t.Run("NewerVersion", func(t *testing.T) {
+		path := writeTestFile(t, "goodreads.toml", fmt.Sprintf(`
+version = %v
+`, SchemaVersion+1))
+
+		_, err := readReadingDB(path)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "upgrade qself")
+	})
+}
+
+func TestReadTweetDB(t *testing.T) {
+	t.Run("Unversioned", func(t *testing.T) {
+		path := writeTestFile(t, "twitter.toml", `
+[[tweets]]
+  id = 123
+  text = "Hello, world."
+`)
+
+		tweetDB, err := readTweetDB(path)
+		assert.NoError(t, err)
+		assert.Equal(t, SchemaVersion, tweetDB.Version)
+		assert.Equal(t, "Hello, world.", tweetDB.Tweets[0].Text)
+	})
+
+	t.Run("V1CreatedAtUnix", func(t *testing.T) {
+		path := writeTestFile(t, "twitter.toml", `
+version = 1
+
+[[tweets]]
+  created_at = 2021-01-02T15:04:05Z
+  id = 123
+  text = "Hello, world."
+`)
+
+		tweetDB, err := readTweetDB(path)
+		assert.NoError(t, err)
+		assert.Equal(t, SchemaVersion, tweetDB.Version)
+		assert.Equal(t, int64(1609599845), tweetDB.Tweets[0].CreatedAtUnix)
+	})
+
+	t.Run("NewerVersion", func(t *testing.T) {
+		path := writeTestFile(t, "twitter.toml", fmt.Sprintf(`
+version = %v
+`, SchemaVersion+1))
+
+		_, err := readTweetDB(path)
+		assert.Error(t, err)
+	})
+}
+
+func TestReadingFromAPIReview(t *testing.T) {
+	t.Run("Abandoned", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_abandoned.xml")
+		assert.Len(t, apiReviews, 1)
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{AbandonedShelf: "abandoned"})
+		assert.NoError(t, err)
+
+		assert.True(t, reading.Abandoned)
+		assert.Equal(t, time.Date(2021, 2, 14, 8, 0, 0, 0, time.UTC), reading.AbandonedAt.UTC())
+		assert.True(t, reading.ReadAt.IsZero())
+		assert.Equal(t, 0.0, reading.ReadingSpeedPPD)
+	})
+
+	t.Run("AbandonedShelfNotSet", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_abandoned.xml")
+		assert.Len(t, apiReviews, 1)
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		assert.False(t, reading.Abandoned)
+		assert.True(t, reading.AbandonedAt.IsZero())
+		assert.False(t, reading.ReadAt.IsZero())
+	})
+
+	t.Run("Translator", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_translator.xml")
+		assert.Len(t, apiReviews, 1)
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(
+			t,
+			[]*ReadingAuthor{
+				{ID: 903, Name: "Homer"},
+				{ID: 1002, Name: "Robert Fagles", Role: "Translator"},
+			},
+			reading.Authors,
+		)
+	})
+
+	t.Run("CommunityRating", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_translator.xml")
+		assert.Len(t, apiReviews, 1)
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 3.97, reading.CommunityRating)
+	})
+
+	t.Run("CoverURL", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_translator.xml")
+		assert.Len(t, apiReviews, 1)
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(t,
+			"https://i.gr-assets.com/images/S/compressed.photo.goodreads.com/books/1390173285m/1381.jpg",
+			reading.CoverURL)
+	})
+
+	t.Run("Language", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_translator.xml")
+		assert.Len(t, apiReviews, 1)
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "en", reading.Language)
+	})
+
+	t.Run("MeanSentiment", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_translator.xml")
+		assert.Len(t, apiReviews, 1)
+
+		apiReviews[0].Body = "A <b>brilliant</b>, moving translation.<br />Not boring at all."
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, reading.MeanSentiment)
+		assert.False(t, reading.MeanSentimentComputed)
+
+		reading, err = readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{ComputeSentiment: true})
+		assert.NoError(t, err)
+		assert.InDelta(t, 0.7, reading.MeanSentiment, 0.0001)
+		assert.True(t, reading.MeanSentimentComputed)
+
+		// There's no sentiment without a review.
+		apiReviews[0].Body = ""
+		reading, err = readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{ComputeSentiment: true})
+		assert.NoError(t, err)
+		assert.False(t, reading.MeanSentimentComputed)
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_kindle.xml")
+		assert.Len(t, apiReviews, 1)
+
+		// Not one of the known formats, but still stored.
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Kindle Edition", reading.Format)
+	})
+
+	t.Run("ReadingSpeed", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_translator.xml")
+		assert.Len(t, apiReviews, 1)
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, time.Date(2020, 11, 2, 10, 11, 12, 0, time.FixedZone("", -8*60*60)).Unix(),
+			reading.DateAdded.Unix())
+		assert.InDelta(t, 541.0/20, reading.ReadingSpeedPPD, 0.0001)
+	})
+
+	t.Run("ReadingSpeedMalformedDateAdded", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_translator.xml")
+		assert.Len(t, apiReviews, 1)
+
+		// The reading is still kept, just without a date added or speed.
+		apiReviews[0].DateAdded = "not a date"
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		assert.True(t, reading.DateAdded.IsZero())
+		assert.Equal(t, 0.0, reading.ReadingSpeedPPD)
+	})
+
+	t.Run("StartedAt", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_started.xml")
+		assert.Len(t, apiReviews, 1)
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, time.Date(2021, 3, 2, 17, 0, 0, 0, time.UTC), reading.StartedAt.UTC())
+
+		// Measured from when the book was started rather than added.
+		assert.InDelta(t, 272.0/8, reading.ReadingSpeedPPD, 0.0001)
+	})
+
+	t.Run("StartedAtUnset", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_kindle.xml")
+		assert.Len(t, apiReviews, 1)
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		assert.True(t, reading.StartedAt.IsZero())
+		assert.InDelta(t, 319.0/8, reading.ReadingSpeedPPD, 0.0001)
+	})
+
+	t.Run("UpdatedAt", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_translator.xml")
+		assert.Len(t, apiReviews, 1)
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, time.Date(2020, 12, 1, 16, 9, 10, 0, time.UTC), reading.UpdatedAt.UTC())
+	})
+
+	t.Run("MalformedReadAt", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_translator.xml")
+		apiReviews[0].ReadAt = "not a date"
+
+		_, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("WordCount", func(t *testing.T) {
+		apiReviews := readAPIReviewsFixture(t, "testdata/goodreads_reviews_translator.xml")
+		assert.Len(t, apiReviews, 1)
+
+		reading, err := readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 541*250, reading.WordCount)
+
+		reading, err = readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{WordsPerPage: 300})
+		assert.NoError(t, err)
+		assert.Equal(t, 541*300, reading.WordCount)
+
+		apiReviews[0].Book.NumPages = 0
+		reading, err = readingFromAPIReview(apiReviews[0], &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, reading.WordCount)
+	})
+
+	t.Run("WordCountMarkedEstimated", func(t *testing.T) {
+		data, err := toml.Marshal(&Reading{NumPages: 10, WordCount: 2500})
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "# Estimated from num_pages\nword_count = 2500\n")
+	})
+}
+
+func TestReadingSpeedPPD(t *testing.T) {
+	start := time.Date(2021, 1, 1, 21, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 50.0, readingSpeedPPD(500, start, start.AddDate(0, 0, 10)))
+
+	// Same day counts as one day even if less than 24 hours apart
+	assert.Equal(t, 300.0, readingSpeedPPD(300, start, start.Add(2*time.Hour)))
+
+	// Missing data
+	assert.Equal(t, 0.0, readingSpeedPPD(0, start, start.AddDate(0, 0, 10)))
+	assert.Equal(t, 0.0, readingSpeedPPD(500, time.Time{}, start))
+	assert.Equal(t, 0.0, readingSpeedPPD(500, start, time.Time{}))
+
+	// Added after being read
+	assert.Equal(t, 0.0, readingSpeedPPD(500, start, start.AddDate(0, 0, -10)))
+}
+
+func TestReconcileRatingHistory(t *testing.T) {
+	older := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Unchanged", func(t *testing.T) {
+		reading := &Reading{Rating: 4}
+		reconcileRatingHistory(reading, &Reading{Rating: 4, UpdatedAt: older}, now)
+		assert.Nil(t, reading.RatingHistory)
+	})
+
+	t.Run("FirstChange", func(t *testing.T) {
+		reading := &Reading{Rating: 5}
+		reconcileRatingHistory(reading, &Reading{Rating: 4, UpdatedAt: older}, now)
+		assert.Equal(t, []*RatingPoint{
+			{Rating: 4, RecordedAt: older},
+			{Rating: 5, RecordedAt: now},
+		}, reading.RatingHistory)
+	})
+
+	t.Run("SubsequentChange", func(t *testing.T) {
+		existing := &Reading{Rating: 5, UpdatedAt: older, RatingHistory: []*RatingPoint{
+			{Rating: 4, RecordedAt: older},
+			{Rating: 5, RecordedAt: now},
+		}}
+
+		// Same as the last point; history carried over untouched
+		reading := &Reading{Rating: 5}
+		reconcileRatingHistory(reading, existing, later)
+		assert.Equal(t, existing.RatingHistory, reading.RatingHistory)
+
+		reading = &Reading{Rating: 3}
+		reconcileRatingHistory(reading, existing, later)
+		assert.Equal(t, []*RatingPoint{
+			{Rating: 4, RecordedAt: older},
+			{Rating: 5, RecordedAt: now},
+			{Rating: 3, RecordedAt: later},
+		}, reading.RatingHistory)
+	})
+}
+
+func TestSanitizeGoodreadsReview(t *testing.T) {
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello", true))
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("   hello   ", true))
+	assert.Equal(t, "hel lo", sanitizeGoodreadsReview("   hel lo   ", true))
+
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello<br>", true))
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello<br><br>", true))
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello<br >", true))
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello<br/>", true))
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("hello<br />", true))
+
+	assert.Equal(
+		t,
+		"http://example.com/hello/there",
+		sanitizeGoodreadsReview(`<a href="http://example.com/hello/there">anything</a>`, true),
+	)
+
+	assert.Equal(
+		t,
+		"http://example.com/hello/there",
+		sanitizeGoodreadsReview(`<a target="_blank" href="http://example.com/hello/there">anything</a>`, true),
+	)
+
+	assert.Equal(
+		t,
+		"http://example.com/hello/there",
+		sanitizeGoodreadsReview(`<a href="http://example.com/hello/there" target="_blank">anything</a>`, true),
+	)
+
+	assert.Equal(
+		t,
+		"link to http://example.com/hello/there here",
+		sanitizeGoodreadsReview(`link to <a href="http://example.com/hello/there">anything</a> here`, true),
+	)
+
+	assert.Equal(
+		t,
+		"http://example.com/hello/there http://example.com/hello/there",
+		sanitizeGoodreadsReview(`<a href="http://example.com/hello/there">anything</a> <a href="http://example.com/hello/there">anything</a>`, true),
+	)
+
+	assert.Equal(
+		t,
+		"http://example.com/hello/there?a=b&c=d",
+		sanitizeGoodreadsReview(`<a href="http://example.com/hello/there?a=b&amp;c=d">anything</a>`, true),
+	)
+
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("<span>hello</span>", true))
+	assert.Equal(t, "hello", sanitizeGoodreadsReview(`<span style="font-weight: 400;">hello</span>`, true))
+	assert.Equal(t, "hello there", sanitizeGoodreadsReview(`hello <span class="a"><span class="b">there</span></span>`, true))
+
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("<p>hello</p>", true))
+	assert.Equal(t, "hello\n\nthere", sanitizeGoodreadsReview("<p>hello</p><p>there</p>", true))
+	assert.Equal(t, "hello\n\nthere", sanitizeGoodreadsReview(`<p dir="ltr">hello</p><p dir="ltr">there</p>`, true))
+	assert.Equal(t, "hello\n\nthere", sanitizeGoodreadsReview("hello<p>there</p>", true))
+
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("<b>hello</b>", true))
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("<strong>hello</strong>", true))
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("<i>hello</i>", true))
+	assert.Equal(t, "hello", sanitizeGoodreadsReview("<em>hello</em>", true))
+	assert.Equal(t, "hello", sanitizeGoodreadsReview(`<b class="x">hello</b>`, true))
+	assert.Equal(t, "hello there", sanitizeGoodreadsReview("<b>hello <i>there</i></b>", true))
+	assert.Equal(t, "hello\nthere", sanitizeGoodreadsReview("<b>hello</b><br /><em>there</em>", true))
+
+	assert.Equal(
+		t,
+		"A great book.\n\nhttp://example.com/hello/there",
+		sanitizeGoodreadsReview(`<p><span style="color: red;"><strong>A great <em>book</em>.</strong></span></p><p><a href="http://example.com/hello/there"><b>anything</b></a></p>`, true),
+	)
+
+	t.Run("HTMLEntities", func(t *testing.T) {
+		assert.Equal(t, "a && b", sanitizeGoodreadsReview("a &amp;&amp; b", true))
+		assert.Equal(t, "if a < b", sanitizeGoodreadsReview("if a &lt; b", true))
+		assert.Equal(t, "if a > b", sanitizeGoodreadsReview("if a &gt; b", true))
+		assert.Equal(t, "a\u00a0b", sanitizeGoodreadsReview("a&nbsp;b", true))
+	})
+
+	t.Run("HTMLEntitiesNoDecode", func(t *testing.T) {
+		assert.Equal(t, "a &amp;&amp; b", sanitizeGoodreadsReview("a &amp;&amp; b", false))
+		assert.Equal(t, "if a &lt; b", sanitizeGoodreadsReview("if a &lt; b", false))
+		assert.Equal(t, "if a &gt; b", sanitizeGoodreadsReview("if a &gt; b", false))
+		assert.Equal(t, "a&nbsp;b", sanitizeGoodreadsReview("a&nbsp;b", false))
+
+		// Tags are still stripped
+		assert.Equal(t, "a &amp; b", sanitizeGoodreadsReview("<b>a</b> &amp; b", false))
+	})
+}
+
+func TestRunkeeperActivityFromAPIActivity(t *testing.T) {
+	activity, err := runkeeperActivityFromAPIActivity(&RunkeeperAPIActivity{
+		AverageHeartRate: 152,
+		Duration:         1500,
+		StartTime:        "Sat, 2 Jan 2021 07:30:00",
+		TotalCalories:    410,
+		TotalDistance:    5000,
+		Type:             "Running",
+		URI:              "/fitnessActivities/1002",
+		UTCOffset:        -8,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &RunkeeperActivity{
+		AverageHeartrate:    152,
+		AveragePaceMinPerKm: 5,
+		Duration:            1500,
+		StartTime:           time.Date(2021, 1, 2, 15, 30, 0, 0, time.UTC),
+		TotalCalories:       410,
+		TotalDistanceM:      5000,
+		Type:                "Running",
+		URI:                 "/fitnessActivities/1002",
+	}, activity)
+
+	t.Run("BadStartTime", func(t *testing.T) {
+		_, err := runkeeperActivityFromAPIActivity(&RunkeeperAPIActivity{StartTime: "2021-01-02T07:30:00Z"})
+		assert.Error(t, err)
+	})
+}
+
+func TestSanitizeLinkedInArticle(t *testing.T) {
+	assert.Equal(t, "hello", sanitizeLinkedInArticle("<p>hello</p>"))
+	assert.Equal(t, "hello\n\nthere", sanitizeLinkedInArticle("<p>hello</p><p>there</p>"))
+	assert.Equal(t, "hello\nthere", sanitizeLinkedInArticle("hello<br>there"))
+
+	assert.Equal(t, "Heading\n\nBody", sanitizeLinkedInArticle("<h1>Heading</h1><p>Body</p>"))
+	assert.Equal(t, "* one\n* two", sanitizeLinkedInArticle(`<ol class="list"><li>one</li><li>two</li></ol>`))
+	assert.Equal(t, "a quote", sanitizeLinkedInArticle("<blockquote>a quote</blockquote>"))
+
+	assert.Equal(
+		t,
+		"a link (http://example.com/hello/there)",
+		sanitizeLinkedInArticle(`<a target="_blank" href="http://example.com/hello/there">a link</a>`),
+	)
+
+	// Unknown tags are dropped, and entities decoded.
+	assert.Equal(t, "bold & italic", sanitizeLinkedInArticle(`<figure><strong>bold</strong> &amp; <em class="x">italic</em></figure>`))
+
+	// Runs of blank lines are collapsed.
+	assert.Equal(t, "hello\n\nthere", sanitizeLinkedInArticle("<p>hello</p><p></p><p> </p><p>there</p>"))
+}
+
+func TestSanitizeTweetText(t *testing.T) {
+	assert.Equal(t, "hello", sanitizeTweetText("hello", true))
+	assert.Equal(t, "<tag>", sanitizeTweetText("<tag>", true))
+	assert.Equal(t, "<tag>", sanitizeTweetText("&lt;tag&gt;", true))
+	assert.Equal(t, "&lt;tag&gt;", sanitizeTweetText("&lt;tag&gt;", false))
+}
+
+func TestScheduleInstall(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Crontab", func(t *testing.T) {
+		env, calls := newFakeScheduleEnv(t, "linux", map[string]string{
+			"crontab -l": "MAILTO=me@example.com\n" +
+				"30 1 * * * /usr/bin/backup\n" +
+				"0 */12 * * * /old/qself sync-all # com.qself.sync\n",
+		})
+
+		err := scheduleInstall(ctx, env, &ScheduleOptions{
+			IntervalHours: 6,
+			LogPath:       "/var/log/qself log.txt",
+			SyncAllArgs:   []string{"--goodreads-path", "data/goodreads.toml"},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"crontab -l", "crontab -"}, calls.Commands())
+		assert.Equal(t,
+			"MAILTO=me@example.com\n"+
+				"30 1 * * * /usr/bin/backup\n"+
+				"0 */6 * * * /usr/local/bin/qself sync-all --goodreads-path data/goodreads.toml "+
+				">> '/var/log/qself log.txt' 2>&1 # com.qself.sync\n",
+			calls.Stdin("crontab -"))
+	})
+
+	t.Run("CrontabDaily", func(t *testing.T) {
+		env, calls := newFakeScheduleEnv(t, "linux", map[string]string{
+			"crontab -u brandur -l": "!no crontab for brandur",
+		})
+
+		err := scheduleInstall(ctx, env, &ScheduleOptions{CronUser: "brandur", IntervalHours: 24})
+		assert.NoError(t, err)
+
+		assert.Equal(t,
+			"0 0 * * * /usr/local/bin/qself sync-all # com.qself.sync\n",
+			calls.Stdin("crontab -u brandur -"))
+	})
+
+	t.Run("CrontabIntervalTooLong", func(t *testing.T) {
+		env, _ := newFakeScheduleEnv(t, "linux", nil)
+
+		err := scheduleInstall(ctx, env, &ScheduleOptions{IntervalHours: 48})
+		assert.EqualError(t, err, "--interval-hours can be at most 24 with cron (was 48)")
+	})
+
+	t.Run("CrontabError", func(t *testing.T) {
+		env, _ := newFakeScheduleEnv(t, "linux", map[string]string{
+			"crontab -l": "!crontab: permission denied",
+		})
+
+		err := scheduleInstall(ctx, env, &ScheduleOptions{IntervalHours: 6})
+		assert.Error(t, err)
+	})
+
+	t.Run("IntervalInvalid", func(t *testing.T) {
+		env, _ := newFakeScheduleEnv(t, "linux", nil)
+
+		err := scheduleInstall(ctx, env, &ScheduleOptions{IntervalHours: 0})
+		assert.EqualError(t, err, "--interval-hours should be at least 1 (was 0)")
+	})
+
+	t.Run("LaunchAgent", func(t *testing.T) {
+		env, calls := newFakeScheduleEnv(t, "darwin", nil)
+
+		err := scheduleInstall(ctx, env, &ScheduleOptions{
+			IntervalHours: 6,
+			LogPath:       "/tmp/qself.log",
+			SyncAllArgs:   []string{"--twitter-path", "data/twitter & likes.toml"},
+		})
+		assert.NoError(t, err)
+
+		plistPath := filepath.Join(env.HomeDir, "Library", "LaunchAgents", "com.qself.sync.plist")
+		assert.Equal(t, []string{"launchctl load -w " + plistPath}, calls.Commands())
+
+		data, err := ioutil.ReadFile(plistPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "<string>com.qself.sync</string>")
+		assert.Contains(t, string(data), "<string>/usr/local/bin/qself</string>\n\t\t<string>sync-all</string>")
+		assert.Contains(t, string(data), "<string>data/twitter &amp; likes.toml</string>")
+		assert.Contains(t, string(data), "<key>StartInterval</key>\n\t<integer>21600</integer>")
+		assert.Contains(t, string(data), "<key>StandardOutPath</key>\n\t<string>/tmp/qself.log</string>")
+
+		// Installing again unloads the previous agent first.
+		err = scheduleInstall(ctx, env, &ScheduleOptions{IntervalHours: 12})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"launchctl load -w " + plistPath,
+			"launchctl unload -w " + plistPath,
+			"launchctl load -w " + plistPath,
+		}, calls.Commands())
+	})
+
+	t.Run("ScheduledTask", func(t *testing.T) {
+		env, calls := newFakeScheduleEnv(t, "windows", nil)
+		env.Executable = `C:\Program Files\qself\qself.exe`
+
+		err := scheduleInstall(ctx, env, &ScheduleOptions{
+			CronUser:      "brandur",
+			IntervalHours: 6,
+			LogPath:       `C:\qself.log`,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{
+			`schtasks /create /tn com.qself.sync ` +
+				`/tr cmd /c ""C:\Program Files\qself\qself.exe" sync-all >> C:\qself.log 2>&1" ` +
+				`/sc HOURLY /mo 6 /f /ru brandur`,
+		}, calls.Commands())
+	})
+
+	t.Run("ScheduledTaskDaily", func(t *testing.T) {
+		env, calls := newFakeScheduleEnv(t, "windows", nil)
+
+		err := scheduleInstall(ctx, env, &ScheduleOptions{IntervalHours: 48})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"schtasks /create /tn com.qself.sync /tr /usr/local/bin/qself sync-all /sc DAILY /mo 2 /f",
+		}, calls.Commands())
+	})
+}
+
+func TestScheduleRemove(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Crontab", func(t *testing.T) {
+		env, calls := newFakeScheduleEnv(t, "linux", map[string]string{
+			"crontab -l": "30 1 * * * /usr/bin/backup\n" +
+				"0 */6 * * * /usr/local/bin/qself sync-all # com.qself.sync\n",
+		})
+
+		err := scheduleRemove(ctx, env, &ScheduleOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"crontab -l", "crontab -"}, calls.Commands())
+		assert.Equal(t, "30 1 * * * /usr/bin/backup\n", calls.Stdin("crontab -"))
+	})
+
+	t.Run("CrontabNotFound", func(t *testing.T) {
+		env, calls := newFakeScheduleEnv(t, "linux", map[string]string{
+			"crontab -l": "30 1 * * * /usr/bin/backup\n",
+		})
+
+		err := scheduleRemove(ctx, env, &ScheduleOptions{})
+		assert.NoError(t, err)
+
+		// The crontab isn't rewritten.
+		assert.Equal(t, []string{"crontab -l"}, calls.Commands())
+	})
+
+	t.Run("LaunchAgent", func(t *testing.T) {
+		env, calls := newFakeScheduleEnv(t, "darwin", nil)
+
+		err := scheduleInstall(ctx, env, &ScheduleOptions{IntervalHours: 6})
+		assert.NoError(t, err)
+
+		err = scheduleRemove(ctx, env, &ScheduleOptions{})
+		assert.NoError(t, err)
+
+		plistPath := filepath.Join(env.HomeDir, "Library", "LaunchAgents", "com.qself.sync.plist")
+		assert.Equal(t, []string{
+			"launchctl load -w " + plistPath,
+			"launchctl unload -w " + plistPath,
+		}, calls.Commands())
+
+		_, err = os.Stat(plistPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("ScheduledTask", func(t *testing.T) {
+		env, calls := newFakeScheduleEnv(t, "windows", nil)
+
+		err := scheduleRemove(ctx, env, &ScheduleOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"schtasks /delete /tn com.qself.sync /f"}, calls.Commands())
+	})
+}
+
+func TestSearch(t *testing.T) {
+	tweetsPath := filepath.Join(t.TempDir(), "twitter.toml")
+	err := writeTOMLFile(tweetsPath, &TweetDB{
+		Tweets: []*Tweet{
+			{
+				CreatedAt:      time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC),
+				ID:             3,
+				NormalizedText: "Golang tips",
+				Text:           "Ｇｏｌａｎｇ tips",
+			},
+			{
+				CreatedAt:      time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+				ID:             2,
+				NormalizedText: "Caf\u00e9 time\nagain",
+				Text:           "Cafe\u0301 time\nagain",
+			},
+			{CreatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), ID: 1, Text: "Golang is fine"},
+		},
+		Version: SchemaVersion,
+	})
+	assert.NoError(t, err)
+
+	searchTweets := func(query string) string {
+		var buf bytes.Buffer
+		err := search(&buf, query, &SearchOptions{TwitterPath: tweetsPath})
+		assert.NoError(t, err)
+		return buf.String()
+	}
+
+	// Case is ignored, and fullwidth letters match plain ones.
+	assert.Equal(t, "2021-01-03  3\n    Ｇｏｌａｎｇ tips\n\n2021-01-01  1\n    Golang is fine\n\n2 tweet(s) found\n",
+		searchTweets("GOLANG"))
+
+	// The query is normalized too, so a fullwidth or decomposed query matches.
+	assert.Equal(t, "2021-01-03  3\n    Ｇｏｌａｎｇ tips\n\n1 tweet(s) found\n", searchTweets("ｇｏｌａｎｇ ｔｉｐｓ"))
+	assert.Equal(t, "2021-01-02  2\n    Cafe\u0301 time\n    again\n\n1 tweet(s) found\n", searchTweets("cafe\u0301"))
+	assert.Equal(t, "2021-01-02  2\n    Cafe\u0301 time\n    again\n\n1 tweet(s) found\n", searchTweets("caf\u00e9"))
+
+	assert.Equal(t, "0 tweet(s) found\n", searchTweets("rust"))
+
+	err = search(io.Discard, "golang", &SearchOptions{TwitterPath: "PATH"})
+	assert.EqualError(t, err, "Twitter source path should be set with --twitter-path")
+}
+
+func TestSetConversationRootTexts(t *testing.T) {
+	newReply := func(id, replyToID int64, user, text string) *Tweet {
+		return &Tweet{ID: id, Reply: &TweetReply{StatusID: replyToID, User: user}, Text: text}
+	}
+
+	t.Run("Thread", func(t *testing.T) {
+		tweets := []*Tweet{
+			newReply(4, 3, "brandur", "Fourth"),
+			newReply(3, 2, "Brandur", "Third"),
+			newReply(2, 1, "brandur", "Second"),
+			{ID: 1, Text: "First", ConversationRootText: "stale"},
+		}
+		setConversationRootTexts(tweets, "brandur")
+
+		assert.Equal(t, "First", tweets[0].ConversationRootText)
+		assert.Equal(t, "First", tweets[1].ConversationRootText)
+		assert.Equal(t, "First", tweets[2].ConversationRootText)
+		assert.Equal(t, "", tweets[3].ConversationRootText)
+	})
+
+	t.Run("ThreadUnderOtherUser", func(t *testing.T) {
+		// The root of the user's own thread is a reply to someone else.
+		tweets := []*Tweet{
+			newReply(2, 1, "brandur", "Second"),
+			newReply(1, 100, "someone", "First"),
+		}
+		setConversationRootTexts(tweets, "brandur")
+
+		assert.Equal(t, "First", tweets[0].ConversationRootText)
+		assert.Equal(t, "", tweets[1].ConversationRootText)
+	})
+
+	t.Run("RootMissing", func(t *testing.T) {
+		tweets := []*Tweet{
+			newReply(3, 2, "brandur", "Third"),
+			newReply(2, 1, "brandur", "Second"),
+		}
+		setConversationRootTexts(tweets, "brandur")
+
+		assert.Equal(t, "", tweets[0].ConversationRootText)
+		assert.Equal(t, "", tweets[1].ConversationRootText)
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		tweets := []*Tweet{
+			newReply(2, 1, "brandur", "Second"),
+			newReply(1, 2, "brandur", "First"),
+		}
+		setConversationRootTexts(tweets, "brandur")
+
+		assert.Equal(t, "", tweets[0].ConversationRootText)
+		assert.Equal(t, "", tweets[1].ConversationRootText)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		tweets := []*Tweet{
+			newReply(2, 1, "brandur", "Second"),
+			{ID: 1, Text: strings.Repeat("é", 300)},
+		}
+		setConversationRootTexts(tweets, "brandur")
+
+		assert.Equal(t, strings.Repeat("é", 280), tweets[0].ConversationRootText)
+	})
+}
+
+func TestSetRereadCounts(t *testing.T) {
+	readings := []*Reading{
+		{ID: 1, ReviewID: 3, ReadAt: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 1, ReviewID: 1, ReadAt: time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, ReviewID: 2, ReadAt: time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 1, ReviewID: 4, Abandoned: true},
+		{ID: 1, ReviewID: 5, ReadAt: time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	setRereadCounts(readings)
+
+	assert.Equal(t, 3, readings[0].RereadCount)
+	assert.Equal(t, 1, readings[1].RereadCount)
+	assert.Equal(t, 1, readings[2].RereadCount)
+	assert.Equal(t, 0, readings[3].RereadCount)
+	assert.Equal(t, 2, readings[4].RereadCount)
+}
+
+func TestSliceReverse(t *testing.T) {
+	s := []int{1, 2, 3}
+	sliceReverse(s)
+
+	assert.Equal(
+		t,
+		[]int{3, 2, 1},
+		s,
+	)
+}
+
+func TestSliceKeepOnly(t *testing.T) {
+	s1 := []int{1, 2, 3, 4}
+	s2 := []int{2, 3, 5}
+	s1 = sliceKeepOnly(s1, s2,
+		func(i int) interface{} { return s1[i] },
+		func(i int) interface{} { return s2[i] },
+	).([]int)
+
+	assert.Equal(
+		t,
+		[]int{2, 3},
+		s1,
+	)
+}
+
+func TestSliceUniq(t *testing.T) {
+	s := []int{1, 2, 2, 3, 4, 5, 6, 7, 7, 7, 8, 9}
+	s = sliceUniq(s, func(i int) interface{} { return s[i] }).([]int)
+
+	assert.Equal(
+		t,
+		[]int{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		s,
+	)
+}
+
+func TestSortReadings(t *testing.T) {
+	unsortedReadings := func() []*Reading {
+		return []*Reading{{ReviewID: 2}, {ReviewID: 3}, {ReviewID: 1}}
+	}
+	reviewIDs := func(readings []*Reading) []int {
+		var ids []int
+		for _, reading := range readings {
+			ids = append(ids, reading.ReviewID)
+		}
+		return ids
+	}
+
+	t.Run("Asc", func(t *testing.T) {
+		readings := unsortedReadings()
+		sortReadings(readings, sortOrderAsc)
+		assert.Equal(t, []int{1, 2, 3}, reviewIDs(readings))
+	})
+
+	t.Run("Desc", func(t *testing.T) {
+		readings := unsortedReadings()
+		sortReadings(readings, sortOrderDesc)
+		assert.Equal(t, []int{3, 2, 1}, reviewIDs(readings))
+	})
+
+	t.Run("DefaultDesc", func(t *testing.T) {
+		readings := unsortedReadings()
+		sortReadings(readings, "")
+		assert.Equal(t, []int{3, 2, 1}, reviewIDs(readings))
+	})
+
+	t.Run("MergeAsc", func(t *testing.T) {
+		s := mergeReadings(
+			[]*Reading{{ReviewID: 3}, {ReviewID: 1}, {ReviewID: 2}},
+			[]*Reading{{ReviewID: 2}, {ReviewID: 1}},
+			sortOrderAsc,
+			nil,
+			nil,
+		)
+		assert.Equal(t, []int{1, 2, 3}, reviewIDs(s))
+	})
+}
+
+func TestSortTweets(t *testing.T) {
+	unsortedTweets := func() []*Tweet {
+		return []*Tweet{{ID: 124}, {ID: 125}, {ID: 123}}
+	}
+	tweetIDs := func(tweets []*Tweet) []int64 {
+		var ids []int64
+		for _, tweet := range tweets {
+			ids = append(ids, tweet.ID)
+		}
+		return ids
+	}
+
+	t.Run("Asc", func(t *testing.T) {
+		tweets := unsortedTweets()
+		sortTweets(tweets, sortOrderAsc)
+		assert.Equal(t, []int64{123, 124, 125}, tweetIDs(tweets))
+	})
+
+	t.Run("Desc", func(t *testing.T) {
+		tweets := unsortedTweets()
+		sortTweets(tweets, sortOrderDesc)
+		assert.Equal(t, []int64{125, 124, 123}, tweetIDs(tweets))
+	})
+
+	t.Run("MergeAsc", func(t *testing.T) {
+		s := mergeTweets(
+			[]*Tweet{{ID: 125}, {ID: 124}},
+			[]*Tweet{{ID: 124}, {ID: 123}},
+			&SyncTwitterOptions{Sort: sortOrderAsc},
+		)
+		assert.Equal(t, []int64{123, 124, 125}, tweetIDs(s))
+	})
+}
+
+func TestSQLiteDBReadTable(t *testing.T) {
+	db, err := openSQLiteDB("testdata/kobo.sqlite")
+	assert.NoError(t, err)
+
+	// Bookmarks span several pages under an interior page.
+	rows, err := db.ReadTable("Bookmark")
+	assert.NoError(t, err)
+	assert.Len(t, rows, 44)
+	assert.Equal(t, "5e1c1d0a-0001", rows[0].String("BookmarkID"))
+	assert.Equal(t, 0.1, rows[0]["ChapterProgress"])
+	assert.Nil(t, rows[0]["Annotation"])
+
+	// Rows from before a column was added don't have it, and long values are
+	// read from overflow pages.
+	assert.Nil(t, rows[41]["Type"])
+	assert.Equal(t, "note", rows[42].String("Type"))
+	assert.Equal(t, strings.Repeat("x", 3000), rows[42].String("Annotation"))
+
+	_, err = db.ReadTable("Missing")
+	assert.EqualError(t, err, "SQLite table 'Missing' not found")
+
+	_, err = openSQLiteDB("testdata/kobo.sql")
+	assert.EqualError(t, err, "not a SQLite database")
+
+	t.Run("WriteAheadLog", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/kobo.sqlite")
+		assert.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "KoboReader.sqlite")
+		assert.NoError(t, os.WriteFile(path, data, 0o644))
+
+		// An empty log has been checkpointed.
+		assert.NoError(t, os.WriteFile(path+"-wal", nil, 0o644))
+		_, err = openSQLiteDB(path)
+		assert.NoError(t, err)
+
+		assert.NoError(t, os.WriteFile(path+"-wal", []byte("changes"), 0o644))
+		_, err = openSQLiteDB(path)
+		assert.EqualError(t, err,
+			fmt.Sprintf("'%s' has changes in a write-ahead log that aren't in the database yet", path))
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		// A database of a single leaf page, whose only cell is a varint cut
+		// off by the end of the page.
+		data := make([]byte, 1024)
+		copy(data, sqliteMagic)
+		binary.BigEndian.PutUint16(data[16:18], 1024)
+		data[sqliteHeaderSize] = sqlitePageTypeLeafTable
+		binary.BigEndian.PutUint16(data[sqliteHeaderSize+3:], 1)
+		binary.BigEndian.PutUint16(data[sqliteHeaderSize+8:], 1023)
+		data[1023] = 0xff
+
+		db, err := newSQLiteDB(data)
+		assert.NoError(t, err)
+
+		_, err = db.ReadTable("Bookmark")
+		assert.EqualError(t, err, "error reading SQLite schema: SQLite cell out of range")
+
+		for _, payload := range [][]byte{
+			{},
+			{0xff},             // header size cut off
+			{0x00},             // header size smaller than its own varint
+			{0x05, 0x01},       // header size larger than the record
+			{0x03, 0x01, 0x81}, // serial type cut off
+			{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, // negative header size
+		} {
+			_, err := parseSQLiteRecord(payload)
+			assert.EqualError(t, err, "SQLite record header out of range", "payload: %x", payload)
+		}
+	})
+}
+
+func TestSyncAll(t *testing.T) {
+	// Goodreads and Google Calendar fail for lack of configuration, while
+	// Twitter is served from fixtures and succeeds.
+	t.Setenv("CAL_CALENDAR_ID", "")
+	t.Setenv("CAL_CREDENTIALS_JSON", "")
+	t.Setenv("GOODREADS_ID", "")
+	t.Setenv("GOODREADS_KEY", "")
+	t.Setenv("TWITTER_CONSUMER_KEY", "key")
+	t.Setenv("TWITTER_CONSUMER_SECRET", "secret")
+	t.Setenv("TWITTER_ACCESS_TOKEN", "token")
+	t.Setenv("TWITTER_ACCESS_SECRET", "secret")
+	t.Setenv("TWITTER_USER", "brandur")
+
+	newFixtureClient(t, map[string]string{
+		"/1.1/users/show.json":                        "testdata/twitter_users_show.json",
+		"/1.1/statuses/user_timeline.json":            "testdata/twitter_user_timeline.json",
+		"/1.1/statuses/user_timeline.json?max_id=101": "testdata/twitter_user_timeline_page_2.json",
+	})
+
+	t.Run("PartialFailure", func(t *testing.T) {
+		dir := t.TempDir()
+
+		opts := newSyncAllOptions()
+		opts.CalPath = filepath.Join(dir, "cal.toml")
+		opts.GoodreadsPath = filepath.Join(dir, "goodreads.toml")
+		opts.TwitterPath = filepath.Join(dir, "twitter.toml")
+
+		err := syncAll(context.Background(), opts)
+
+		var syncAllErr *SyncAllError
+		assert.True(t, errors.As(err, &syncAllErr), "unexpected error: %v", err)
+		assert.Len(t, syncAllErr.Errs, 2)
+		assert.True(t, strings.HasPrefix(syncAllErr.Errs[0].Error(), "cal: "), syncAllErr.Errs[0].Error())
+		assert.True(t, strings.HasPrefix(syncAllErr.Errs[1].Error(), "goodreads: "), syncAllErr.Errs[1].Error())
+
+		// Failures of the other syncs didn't stop Twitter's.
+		_, err = os.Stat(opts.TwitterPath)
+		assert.NoError(t, err)
+	})
+
+	t.Run("FailFast", func(t *testing.T) {
+		dir := t.TempDir()
+
+		opts := newSyncAllOptions()
+		opts.FailFast = true
+		opts.GoodreadsPath = filepath.Join(dir, "goodreads.toml")
+
+		err := syncAll(context.Background(), opts)
+		assert.Error(t, err)
+
+		var syncAllErr *SyncAllError
+		assert.False(t, errors.As(err, &syncAllErr))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		opts := newSyncAllOptions()
+		opts.TwitterPath = filepath.Join(t.TempDir(), "twitter.toml")
+
+		err := syncAll(context.Background(), opts)
+		assert.NoError(t, err)
+	})
+}
+
+func TestSyncBeeminder(t *testing.T) {
+	t.Setenv("BEEMINDER_AUTH_TOKEN", "token")
+	t.Setenv("BEEMINDER_USERNAME", "alice")
+
+	newFixtureClient(t, map[string]string{
+		"/api/v1/users/alice/goals.json?auth_token=token":                     "testdata/beeminder_goals.json",
+		"/api/v1/users/alice/goals/exercise/datapoints.json?auth_token=token": "testdata/beeminder_datapoints_exercise.json",
+		"/api/v1/users/alice/goals/reading/datapoints.json?auth_token=token":  "testdata/beeminder_datapoints_reading.json",
+	})
+
+	// A data point that's since been deleted from Beeminder is kept.
+	targetPath := filepath.Join(t.TempDir(), "beeminder.toml")
+	err := writeTOMLFile(targetPath, &BeeminderDB{
+		Goals: []*BeeminderGoal{
+			{Slug: "reading", DataPoints: []*BeeminderDataPoint{
+				{ID: "5ff8a1c0cb8a6f0012000000", Timestamp: time.Date(2021, 1, 25, 0, 0, 0, 0, time.UTC), Value: 7},
+			}},
+		},
+	})
+	assert.NoError(t, err)
+
+	err = syncBeeminder(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var beeminderDB BeeminderDB
+	err = readTOMLFile(targetPath, &beeminderDB)
+	assert.NoError(t, err)
+	assert.Len(t, beeminderDB.Goals, 2)
+
+	assert.Equal(t, &BeeminderGoal{
+		CurrentRate: 3.5,
+		GoalDate:    time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC),
+		Metric:      "workouts",
+		Pledge:      30,
+		Safebuf:     1,
+		Slug:        "exercise",
+		Title:       "Work out",
+		DataPoints: []*BeeminderDataPoint{
+			{
+				Comment:   "Run via Runkeeper",
+				ID:        "5ff8a1c0cb8a6f0012000101",
+				Timestamp: time.Date(2021, 1, 27, 0, 0, 0, 0, time.UTC),
+				Value:     1,
+			},
+		},
+	}, beeminderDB.Goals[0])
+
+	reading := beeminderDB.Goals[1]
+	assert.Equal(t, "reading", reading.Slug)
+	assert.True(t, reading.GoalDate.IsZero())
+	assert.Len(t, reading.DataPoints, 3)
+	assert.Equal(t, "5ff8a1c0cb8a6f0012000000", reading.DataPoints[0].ID)
+	assert.Equal(t, "5ff8a1c0cb8a6f0012000001", reading.DataPoints[1].ID)
+	assert.Equal(t, "Finished The Odyssey", reading.DataPoints[2].Comment)
+	assert.Equal(t, 42.0, reading.DataPoints[2].Value)
+}
+
+func TestSyncCal(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	keyData, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+
+	credentialsData, err := json.Marshal(&CalAPIServiceAccount{
+		ClientEmail: "qself@project.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyData})),
+		TokenURI:    "https://oauth2.googleapis.com/token",
+	})
+	assert.NoError(t, err)
+
+	credentialsPath := filepath.Join(dir, "credentials.json")
+	assert.NoError(t, ioutil.WriteFile(credentialsPath, credentialsData, 0600))
+
+	t.Setenv("CAL_CALENDAR_ID", "me@example.com")
+	t.Setenv("CAL_CREDENTIALS_JSON", credentialsPath)
+
+	newFixtureClient(t, map[string]string{
+		"/token": "testdata/cal_token.json",
+		"/calendar/v3/calendars/me@example.com/events":                 "testdata/cal_events.json",
+		"/calendar/v3/calendars/me@example.com/events?pageToken=page2": "testdata/cal_events_page_2.json",
+	})
+
+	targetPath := filepath.Join(dir, "cal.toml")
+	err = syncCal(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var calEventDB CalEventDB
+	err = readTOMLFile(targetPath, &calEventDB)
+	assert.NoError(t, err)
+	assert.Len(t, calEventDB.Events, 2)
+
+	assert.Equal(t, &CalEvent{
+		Attendees:   []string{"me@example.com", "alice@example.com"},
+		Description: "Catch up",
+		EndAt:       time.Date(2021, 1, 2, 17, 30, 0, 0, time.UTC),
+		ID:          "event1",
+		Location:    "Blue Bottle, Mint Plaza",
+		Organizer:   "me@example.com",
+		StartAt:     time.Date(2021, 1, 2, 17, 0, 0, 0, time.UTC),
+		Status:      "confirmed",
+		Title:       "Coffee with Alice",
+	}, calEventDB.Events[0])
+
+	assert.Equal(t, &CalEvent{
+		Attendees: []string{}, // empty after a round trip through TOML
+		EndAt:     time.Date(2021, 1, 6, 0, 0, 0, 0, time.UTC),
+		ID:        "event2",
+		IsAllDay:  true,
+		Organizer: "me@example.com",
+		StartAt:   time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC),
+		Status:    "confirmed",
+		Title:     "Vacation",
+	}, calEventDB.Events[1])
+}
+
+func TestSyncCanceled(t *testing.T) {
+	// Cancels the sync's context as soon as it makes its first request, then
+	// fails that request the way a real transport would.
+	cancelOnRequest := func(t *testing.T, cancel context.CancelFunc) {
+		defaultTransport := http.DefaultTransport
+		t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+		http.DefaultTransport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			cancel()
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})
+	}
+
+	t.Run("Goodreads", func(t *testing.T) {
+		t.Setenv("GOODREADS_ID", "123")
+		t.Setenv("GOODREADS_KEY", "key")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cancelOnRequest(t, cancel)
+
+		targetPath := filepath.Join(t.TempDir(), "goodreads.toml")
+		err := syncGoodreads(ctx, targetPath, &SyncGoodreadsOptions{})
+		assert.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+
+		_, err = os.Stat(targetPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Twitter", func(t *testing.T) {
+		t.Setenv("TWITTER_CONSUMER_KEY", "key")
+		t.Setenv("TWITTER_CONSUMER_SECRET", "secret")
+		t.Setenv("TWITTER_ACCESS_TOKEN", "token")
+		t.Setenv("TWITTER_ACCESS_SECRET", "secret")
+		t.Setenv("TWITTER_USER", "brandur")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cancelOnRequest(t, cancel)
+
+		targetPath := filepath.Join(t.TempDir(), "twitter.toml")
+		err := syncTwitter(ctx, targetPath, &SyncTwitterOptions{})
+		assert.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+
+		_, err = os.Stat(targetPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("All", func(t *testing.T) {
+		t.Setenv("GOODREADS_ID", "123")
+		t.Setenv("GOODREADS_KEY", "key")
+		t.Setenv("TWITTER_CONSUMER_KEY", "key")
+		t.Setenv("TWITTER_CONSUMER_SECRET", "secret")
+		t.Setenv("TWITTER_ACCESS_TOKEN", "token")
+		t.Setenv("TWITTER_ACCESS_SECRET", "secret")
+		t.Setenv("TWITTER_USER", "brandur")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cancelOnRequest(t, cancel)
+
+		dir := t.TempDir()
+		opts := newSyncAllOptions()
+		opts.GoodreadsPath = filepath.Join(dir, "goodreads.toml")
+		opts.TwitterPath = filepath.Join(dir, "twitter.toml")
+
+		err := syncAll(ctx, opts)
+		assert.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestSyncClockify(t *testing.T) {
+	t.Setenv("CLOCKIFY_API_KEY", "key")
+
+	newFixtureClient(t, map[string]string{
+		"/api/v1/user":       "testdata/clockify_user.json",
+		"/api/v1/workspaces": "testdata/clockify_workspaces.json",
+		"/api/v1/workspaces/5e4d2f3a9c1b7a0012ab3400/projects": "testdata/clockify_projects.json",
+		"/api/v1/workspaces/5e4d2f3a9c1b7a0012ab3400/tags":     "testdata/clockify_tags.json",
+		"/api/v1/workspaces/5e4d2f3a9c1b7a0012ab3400/user/5e4d2f3a9c1b7a0012ab34cd/time-entries?page=1&page-size=200&start=2023-03-06T00:00:00Z": "testdata/clockify_time_entries.json",
+
+		// No entries in the second workspace, so its projects and tags
+		// aren't fetched.
+		"/api/v1/workspaces/5e4d2f3a9c1b7a0012ab3401/user/5e4d2f3a9c1b7a0012ab34cd/time-entries?page=1&page-size=200": "testdata/clockify_time_entries_empty.json",
+	})
+
+	targetPath := filepath.Join(t.TempDir(), "clockify.toml")
+	err := writeTOMLFile(targetPath, &ClockifyDB{
+		Entries: []*ClockifyEntry{
+			{ID: "64100a1b2c3d4e0012000001", StartedAt: time.Date(2023, 3, 13, 9, 0, 0, 0, time.UTC)},
+		},
+	})
+	assert.NoError(t, err)
+
+	err = syncClockify(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var clockifyDB ClockifyDB
+	err = readTOMLFile(targetPath, &clockifyDB)
+	assert.NoError(t, err)
+
+	// The running entry is skipped.
+	assert.Len(t, clockifyDB.Entries, 2)
+	assert.Equal(t, "64100a1b2c3d4e0012000001", clockifyDB.Entries[0].ID)
+	assert.Equal(t, "64100a1b2c3d4e0012000002", clockifyDB.Entries[1].ID)
+	assert.Equal(t, "Open Source", clockifyDB.Entries[1].ProjectName)
+	assert.Equal(t, []string{"code-review", "work"}, clockifyDB.Entries[1].Tags)
+}
+
+func TestSyncCloudflare(t *testing.T) {
+	t.Setenv("CF_API_TOKEN", "token")
+	t.Setenv("CF_ZONE_ID", "zone")
+
+	t.Run("Incremental", func(t *testing.T) {
+		newFixtureClient(t, map[string]string{
+			"/client/v4/graphql": "testdata/cloudflare_graphql.json",
+		})
+
+		// Days before the last one stored are refetched, while older ones
+		// are kept as they are.
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		targetPath := filepath.Join(t.TempDir(), "cloudflare.toml")
+		err := writeTOMLFile(targetPath, &CloudflareDB{
+			Days: []*CloudflareDay{
+				{Date: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Requests: 10},
+				{Date: today.AddDate(0, 0, -1), Requests: 20},
+			},
+		})
+		assert.NoError(t, err)
+
+		err = syncCloudflare(context.Background(), targetPath)
+		assert.NoError(t, err)
+
+		var cloudflareDB CloudflareDB
+		err = readTOMLFile(targetPath, &cloudflareDB)
+		assert.NoError(t, err)
+		assert.Len(t, cloudflareDB.Days, cloudflareRefetchDays+3)
+
+		assert.Equal(t, int64(10), cloudflareDB.Days[0].Requests)
+		for i, day := range cloudflareDB.Days[1:] {
+			assert.Equal(t, today.AddDate(0, 0, -cloudflareRefetchDays-1+i), day.Date)
+			assert.Equal(t, int64(182), day.Requests)
+			assert.Equal(t, map[string]int64{"DE": 5, "GB": 25, "US": 150}, day.TopCountries)
+		}
+	})
+
+	t.Run("GraphQLError", func(t *testing.T) {
+		newFixtureClient(t, map[string]string{
+			"/client/v4/graphql": "testdata/cloudflare_graphql_error.json",
+		})
+
+		err := syncCloudflare(context.Background(), filepath.Join(t.TempDir(), "cloudflare.toml"))
+		assert.EqualError(t, err, "error from Cloudflare: zone 'zone' does not have access to the path")
+	})
+}
+
+func TestSyncExist(t *testing.T) {
+	t.Setenv("EXIST_ACCESS_TOKEN", "token")
+
+	newFixtureClient(t, map[string]string{
+		"/api/2/attributes/":                        "testdata/exist_attributes.json",
+		"/api/2/attributes/values/?attribute=mood":  "testdata/exist_values_mood.json",
+		"/api/2/attributes/values/?attribute=steps": "testdata/exist_values_steps.json",
+	})
+
+	// Attributes that aren't fetched are kept from existing days.
+	targetPath := filepath.Join(t.TempDir(), "exist.toml")
+	err := writeTOMLFile(targetPath, &ExistDB{
+		Days: []*ExistDay{
+			{Attributes: map[string]interface{}{"steps": 1, "weight": 70.5}, Date: time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC)},
+		},
+	})
+	assert.NoError(t, err)
+
+	t.Run("AllAttributes", func(t *testing.T) {
+		err := syncExist(context.Background(), targetPath, &SyncExistOptions{})
+		assert.NoError(t, err)
+
+		var existDB ExistDB
+		err = readTOMLFile(targetPath, &existDB)
+		assert.NoError(t, err)
+
+		// The day from before the incremental start date and the day without
+		// a value aren't stored.
+		assert.Len(t, existDB.Days, 2)
+
+		assert.Equal(t, time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), existDB.Days[0].Date.UTC())
+		assert.Equal(t, map[string]interface{}{"steps": int64(10233)}, existDB.Days[0].Attributes)
+
+		assert.Equal(t, time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC), existDB.Days[1].Date.UTC())
+		assert.Equal(t, map[string]interface{}{"mood": int64(4), "steps": int64(8412), "weight": 70.5}, existDB.Days[1].Attributes)
+	})
+
+	t.Run("LimitedAttributes", func(t *testing.T) {
+		err := syncExist(context.Background(), targetPath, &SyncExistOptions{Attributes: []string{"mood"}})
+		assert.NoError(t, err)
+
+		var existDB ExistDB
+		err = readTOMLFile(targetPath, &existDB)
+		assert.NoError(t, err)
+		assert.Len(t, existDB.Days, 2)
+		assert.Equal(t, map[string]interface{}{"mood": int64(4), "steps": int64(8412), "weight": 70.5}, existDB.Days[1].Attributes)
+	})
+}
+
+func TestSyncGoodreads(t *testing.T) {
+	t.Setenv("GOODREADS_ID", "123")
+	t.Setenv("GOODREADS_KEY", "key")
+
+	ctx := context.Background()
+
+	// Only the first page has reviews. Segments paging past it get the
+	// empty fixture.
+	fixtures := map[string]string{
+		"/review/list/123.xml":        "testdata/goodreads_reviews_empty.xml",
+		"/review/list/123.xml?page=1": "testdata/goodreads_reviews_translator.xml",
+	}
+
+	t.Run("FirstRun", func(t *testing.T) {
+		newFixtureClient(t, fixtures)
+
+		targetPath := filepath.Join(t.TempDir(), "goodreads.toml")
+		err := syncGoodreads(ctx, targetPath, &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		readingDB, err := readReadingDB(targetPath)
+		assert.NoError(t, err)
+		assert.Len(t, readingDB.Readings, 1)
+		assert.Equal(t, 3712345678, readingDB.Readings[0].ReviewID)
+		assert.Equal(t, "The Odyssey", readingDB.Readings[0].Title)
+		assert.Equal(t, 1, readingDB.Readings[0].RereadCount)
+		assert.Equal(t, SchemaVersion, readingDB.Version)
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		newFixtureClient(t, fixtures)
+
+		// The first reading's review was edited more recently than the API
+		// says, so its text is kept. The second was deleted from Goodreads.
+		targetPath := filepath.Join(t.TempDir(), "goodreads.toml")
+		err := writeTOMLFile(targetPath, &ReadingDB{
+			Readings: []*Reading{
+				{
+					ID:        2165,
+					Review:    "Worth the read twice.",
+					ReviewID:  3712345678,
+					Title:     "Odyssey",
+					UpdatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+				{ID: 2166, ReviewID: 1000, Title: "The Iliad"},
+			},
+			Version: SchemaVersion,
+		})
+		assert.NoError(t, err)
+
+		err = syncGoodreads(ctx, targetPath, &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		readingDB, err := readReadingDB(targetPath)
+		assert.NoError(t, err)
+		assert.Len(t, readingDB.Readings, 1)
+		assert.Equal(t, 3712345678, readingDB.Readings[0].ReviewID)
+		assert.Equal(t, "The Odyssey", readingDB.Readings[0].Title)
+		assert.Equal(t, "Worth the read twice.", readingDB.Readings[0].Review)
+	})
+
+	t.Run("AbandonedShelfNotSet", func(t *testing.T) {
+		newFixtureClient(t, fixtures)
+
+		// Without an abandoned shelf its readings aren't fetched, so stored
+		// ones are kept instead of being removed as deleted.
+		abandonedAt := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+		targetPath := filepath.Join(t.TempDir(), "goodreads.toml")
+		err := writeTOMLFile(targetPath, &ReadingDB{
+			Readings: []*Reading{
+				{Abandoned: true, AbandonedAt: abandonedAt, ID: 153747, ReviewID: 3798765432, Title: "Moby-Dick"},
+				{ID: 2166, ReviewID: 1000, Title: "The Iliad"},
+			},
+			Version: SchemaVersion,
+		})
+		assert.NoError(t, err)
+
+		err = syncGoodreads(ctx, targetPath, &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		readingDB, err := readReadingDB(targetPath)
+		assert.NoError(t, err)
+		assert.Len(t, readingDB.Readings, 2)
+		assert.ElementsMatch(t, []int{3712345678, 3798765432},
+			[]int{readingDB.Readings[0].ReviewID, readingDB.Readings[1].ReviewID})
+	})
+
+	t.Run("SkippedReview", func(t *testing.T) {
+		newFixtureClient(t, map[string]string{
+			"/review/list/123.xml":        "testdata/goodreads_reviews_empty.xml",
+			"/review/list/123.xml?page=1": "testdata/goodreads_reviews_malformed.xml",
+		})
+
+		// The Iliad's review has a malformed read at date, so it's skipped,
+		// but the stored reading is kept because it wasn't deleted.
+		targetPath := filepath.Join(t.TempDir(), "goodreads.toml")
+		err := writeTOMLFile(targetPath, &ReadingDB{
+			Readings: []*Reading{
+				{ID: 1371, ReviewID: 3712345679, Title: "The Iliad"},
+			},
+			Version: SchemaVersion,
+		})
+		assert.NoError(t, err)
+
+		err = syncGoodreads(ctx, targetPath, &SyncGoodreadsOptions{})
+		assert.NoError(t, err)
+
+		readingDB, err := readReadingDB(targetPath)
+		assert.NoError(t, err)
+		assert.Len(t, readingDB.Readings, 2)
+		assert.ElementsMatch(t, []int{3712345678, 3712345679},
+			[]int{readingDB.Readings[0].ReviewID, readingDB.Readings[1].ReviewID})
+	})
+
+	t.Run("SkippedReviewStrict", func(t *testing.T) {
+		newFixtureClient(t, map[string]string{
+			"/review/list/123.xml":        "testdata/goodreads_reviews_empty.xml",
+			"/review/list/123.xml?page=1": "testdata/goodreads_reviews_malformed.xml",
+		})
+
+		targetPath := filepath.Join(t.TempDir(), "goodreads.toml")
+		err := syncGoodreads(ctx, targetPath, &SyncGoodreadsOptions{Strict: true})
+		assert.EqualError(t, err, "skipped 1 review(s) in strict mode")
+
+		_, err = os.Stat(targetPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("CredentialFlags", func(t *testing.T) {
+		t.Setenv("GOODREADS_ID", "")
+		t.Setenv("GOODREADS_KEY", "")
+
+		// Requests with any other credentials don't match a fixture, and
+		// fail the test.
+		newFixtureClient(t, map[string]string{
+			"/review/list/456.xml?key=flag-key":        "testdata/goodreads_reviews_empty.xml",
+			"/review/list/456.xml?key=flag-key&page=1": "testdata/goodreads_reviews_translator.xml",
+		})
+
+		targetPath := filepath.Join(t.TempDir(), "goodreads.toml")
+		err := syncGoodreads(ctx, targetPath, &SyncGoodreadsOptions{Key: "flag-key", UserID: "456"})
+		assert.NoError(t, err)
+
+		readingDB, err := readReadingDB(targetPath)
+		assert.NoError(t, err)
+		assert.Len(t, readingDB.Readings, 1)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		newFixtureClient(t, map[string]string{
+			"/review/list/123.xml": "403 testdata/goodreads_forbidden.txt",
+		})
+
+		targetPath := filepath.Join(t.TempDir(), "goodreads.toml")
+		err := syncGoodreads(ctx, targetPath, &SyncGoodreadsOptions{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected status code from Goodreads: 403")
+
+		_, err = os.Stat(targetPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("APISort", func(t *testing.T) {
+		// Requests in any other order don't match a fixture, and fail the
+		// test.
+		newFixtureClient(t, map[string]string{
+			"/review/list/123.xml?sort=title":        "testdata/goodreads_reviews_empty.xml",
+			"/review/list/123.xml?page=1&sort=title": "testdata/goodreads_reviews_translator.xml",
+		})
+
+		targetPath := filepath.Join(t.TempDir(), "goodreads.toml")
+		err := syncGoodreads(ctx, targetPath, &SyncGoodreadsOptions{APISort: "title"})
+		assert.NoError(t, err)
+
+		readingDB, err := readReadingDB(targetPath)
+		assert.NoError(t, err)
+		assert.Len(t, readingDB.Readings, 1)
+	})
+
+	t.Run("InvalidAPISort", func(t *testing.T) {
+		// Any request fails the test.
+		newFixtureClient(t, map[string]string{})
+
+		targetPath := filepath.Join(t.TempDir(), "goodreads.toml")
+		err := syncGoodreads(ctx, targetPath, &SyncGoodreadsOptions{APISort: "page_count"})
+		assert.EqualError(t, err,
+			"unknown Goodreads sort 'page_count' (should be one of: date_read, date_added, title, author, rating)")
+	})
+
+	t.Run("InvalidCoverDownloadConcurrency", func(t *testing.T) {
+		// Any request fails the test.
+		newFixtureClient(t, map[string]string{})
+
+		targetPath := filepath.Join(t.TempDir(), "goodreads.toml")
+		err := syncGoodreads(ctx, targetPath, &SyncGoodreadsOptions{
+			CoverDownloadConcurrency: 0,
+			CoverDownloadDir:         t.TempDir(),
+		})
+		assert.EqualError(t, err, "cover download concurrency should be at least 1 (was 0)")
+	})
+}
+
+func TestSyncGoodreadsChallenges(t *testing.T) {
+	t.Setenv("GOODREADS_ID", "123")
+	t.Setenv("GOODREADS_KEY", "key")
+
+	newFixtureClient(t, map[string]string{
+		"/reading_challenges/user_challenges.xml?id=123&key=key": "testdata/goodreads_challenges.xml",
+	})
+
+	readingsPath := filepath.Join(t.TempDir(), "goodreads.toml")
+	err := writeTOMLFile(readingsPath, &ReadingDB{
+		Readings: []*Reading{
+			{ID: 2165, ReviewID: 3712345678, Title: "The Odyssey"},
+			{ID: 2166, ReviewID: 1000, Title: "The Iliad", ChallengeIDs: []int{1}}, // no longer in a challenge
+			{ID: 40121378, ReviewID: 3798765432, Title: "Atomic Habits"},
+		},
+		Version: SchemaVersion,
+	})
+	assert.NoError(t, err)
+
+	err = syncGoodreadsChallenges(context.Background(), readingsPath, &SyncGoodreadsChallengesOptions{})
+	assert.NoError(t, err)
+
+	readingDB, err := readReadingDB(readingsPath)
+	assert.NoError(t, err)
+	assert.Len(t, readingDB.Readings, 3)
+	assert.Equal(t, []int{9801, 11621}, readingDB.Readings[0].ChallengeIDs)
+	assert.Empty(t, readingDB.Readings[1].ChallengeIDs)
+	assert.Equal(t, []int{11621}, readingDB.Readings[2].ChallengeIDs)
+}
+
+func TestSyncGoodreadsFriends(t *testing.T) {
+	t.Setenv("GOODREADS_ID", "123")
+	t.Setenv("GOODREADS_KEY", "key")
+
+	newFixtureClient(t, map[string]string{
+		"/friend/user/123.xml?page=1": "testdata/goodreads_friends.xml",
+		"/review/list/456.xml":        "testdata/goodreads_reviews_translator.xml",
+		"/review/list/789.xml":        "testdata/goodreads_reviews_kindle.xml",
+	})
+
+	// Alice's reading is already stored, and is merged rather than
+	// duplicated.
+	targetPath := filepath.Join(t.TempDir(), "goodreads_friends.toml")
+	err := writeTOMLFile(targetPath, &FriendReadingDB{
+		Readings: []*FriendReading{
+			{Reading: Reading{ReviewID: 3712345678, Title: "Odyssey"}, FriendID: 456, FriendName: "Alice Example"},
+		},
+	})
+	assert.NoError(t, err)
+
+	err = syncGoodreadsFriends(context.Background(), targetPath, &SyncGoodreadsFriendsOptions{MaxFriends: defaultMaxFriends})
+	assert.NoError(t, err)
+
+	var friendReadingDB FriendReadingDB
+	err = readTOMLFile(targetPath, &friendReadingDB)
+	assert.NoError(t, err)
+	assert.Len(t, friendReadingDB.Readings, 2)
+
+	assert.Equal(t, 456, friendReadingDB.Readings[0].FriendID)
+	assert.Equal(t, "Alice Example", friendReadingDB.Readings[0].FriendName)
+	assert.Equal(t, 3712345678, friendReadingDB.Readings[0].ReviewID)
+	assert.Equal(t, "The Odyssey", friendReadingDB.Readings[0].Title)
+
+	assert.Equal(t, 789, friendReadingDB.Readings[1].FriendID)
+	assert.Equal(t, "Bob Example", friendReadingDB.Readings[1].FriendName)
+	assert.Equal(t, "Atomic Habits", friendReadingDB.Readings[1].Title)
+
+	t.Run("MaxFriends", func(t *testing.T) {
+		// Requests for Bob's shelf don't match a fixture, and fail the test.
+		newFixtureClient(t, map[string]string{
+			"/friend/user/123.xml?page=1": "testdata/goodreads_friends.xml",
+			"/review/list/456.xml":        "testdata/goodreads_reviews_translator.xml",
+		})
+
+		targetPath := filepath.Join(t.TempDir(), "goodreads_friends.toml")
+		err := syncGoodreadsFriends(context.Background(), targetPath, &SyncGoodreadsFriendsOptions{MaxFriends: 1})
+		assert.NoError(t, err)
+
+		var friendReadingDB FriendReadingDB
+		err = readTOMLFile(targetPath, &friendReadingDB)
+		assert.NoError(t, err)
+		assert.Len(t, friendReadingDB.Readings, 1)
+		assert.Equal(t, 456, friendReadingDB.Readings[0].FriendID)
+	})
+}
+
+func TestSyncGooglePhotos(t *testing.T) {
+	dir := t.TempDir()
+
+	credentialsData, err := json.Marshal(&GooglePhotosAPICredentials{
+		ClientID:     "client.apps.googleusercontent.com",
+		ClientSecret: "secret",
+		RefreshToken: "refresh",
+	})
+	assert.NoError(t, err)
+
+	credentialsPath := filepath.Join(dir, "credentials.json")
+	assert.NoError(t, ioutil.WriteFile(credentialsPath, credentialsData, 0600))
+
+	t.Setenv("GOOGLE_PHOTOS_CREDENTIALS_JSON", credentialsPath)
+
+	newFixtureClient(t, map[string]string{
+		"/token":                         "testdata/cal_token.json",
+		"/v1/albums":                     "testdata/google_photos_albums.json",
+		"/v1/mediaItems":                 "testdata/google_photos_media_items.json",
+		"/v1/mediaItems?pageToken=page2": "testdata/google_photos_media_items_page_2.json",
+		"/v1/mediaItems:search":          "testdata/google_photos_album_search.json",
+	})
+
+	// Photos from previous syncs are kept.
+	targetPath := filepath.Join(dir, "google_photos.toml")
+	err = writeTOMLFile(targetPath, &GooglePhotosDB{
+		Photos: []*GooglePhoto{{CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), ID: "old1"}},
+	})
+	assert.NoError(t, err)
+
+	err = syncGooglePhotos(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var googlePhotosDB GooglePhotosDB
+	err = readTOMLFile(targetPath, &googlePhotosDB)
+	assert.NoError(t, err)
+
+	// The photo with a malformed width is skipped.
+	assert.Len(t, googlePhotosDB.Photos, 3)
+	assert.Equal(t, "old1", googlePhotosDB.Photos[0].ID)
+
+	assert.Equal(t, &GooglePhoto{
+		AlbumIDs:        []string{"album1"},
+		ApertureFNumber: 1.6,
+		CameraMake:      "Apple",
+		CameraModel:     "iPhone 12 Pro",
+		CreatedAt:       time.Date(2021, 6, 12, 14, 3, 21, 0, time.UTC),
+		ExposureTime:    "0.002s",
+		Filename:        "IMG_0001.jpg",
+		FocalLength:     4.2,
+		Height:          3024,
+		ID:              "photo1",
+		ISOEquivalent:   32,
+		MimeType:        "image/jpeg",
+		Width:           4032,
+	}, googlePhotosDB.Photos[1])
+
+	// Videos have no camera information.
+	assert.Equal(t, &GooglePhoto{
+		AlbumIDs:  []string{"album1"},
+		CreatedAt: time.Date(2021, 6, 13, 9, 30, 0, 0, time.UTC),
+		Filename:  "VID_0002.mp4",
+		Height:    1080,
+		ID:        "video1",
+		MimeType:  "video/mp4",
+		Width:     1920,
+	}, googlePhotosDB.Photos[2])
+}
+
+func TestSyncKobo(t *testing.T) {
+	// Annotations and sessions from previous syncs are kept.
+	annotationsPath := filepath.Join(t.TempDir(), "kobo_annotations.toml")
+	err := writeTOMLFile(annotationsPath, &KoboAnnotationDB{
+		Annotations: []*KoboAnnotation{{ID: "deleted", CreatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	})
+	assert.NoError(t, err)
+
+	sessionsPath := filepath.Join(t.TempDir(), "kobo_sessions.toml")
+	err = writeTOMLFile(sessionsPath, &KoboSessionDB{
+		Sessions: []*KoboSession{{ID: "deleted", StartedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	})
+	assert.NoError(t, err)
+
+	err = syncKobo("testdata/kobo.sqlite", annotationsPath, sessionsPath)
+	assert.NoError(t, err)
+
+	var annotationDB KoboAnnotationDB
+	err = readTOMLFile(annotationsPath, &annotationDB)
+	assert.NoError(t, err)
+
+	// The dog-ear and hidden highlight are left out, and the bookmark with an
+	// invalid time skipped.
+	assert.Len(t, annotationDB.Annotations, 42)
+	assert.Equal(t, "deleted", annotationDB.Annotations[0].ID)
+
+	annotation := annotationDB.Annotations[1]
+	assert.Equal(t, "", annotation.Annotation)
+	assert.Equal(t, "Dune", annotation.BookTitle)
+	assert.Equal(t, "Book One: Dune", annotation.ChapterTitle)
+	assert.Equal(t, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC), annotation.CreatedAt.UTC())
+	assert.Equal(t, "5e1c1d0a-0001", annotation.ID)
+	assert.Equal(t, "I must not fear. Fear is the mind-killer.", annotation.Text)
+
+	annotation = annotationDB.Annotations[41]
+	assert.Equal(t, strings.Repeat("x", 3000), annotation.Annotation)
+	assert.Equal(t, "Walden", annotation.BookTitle)
+	assert.Equal(t, "", annotation.ChapterTitle)
+	assert.Equal(t, "5e1c1d0a-0043", annotation.ID)
+
+	var sessionDB KoboSessionDB
+	err = readTOMLFile(sessionsPath, &sessionDB)
+	assert.NoError(t, err)
+
+	// Only events for leaving a book after reading it are sessions, and the
+	// one without a reading time is skipped.
+	assert.Len(t, sessionDB.Sessions, 3)
+	assert.Equal(t, "deleted", sessionDB.Sessions[0].ID)
+
+	session := sessionDB.Sessions[1]
+	assert.Equal(t, "Dune", session.BookTitle)
+	assert.Equal(t, 2730, session.DurationSec)
+	assert.Equal(t, time.Date(2021, 3, 4, 5, 45, 30, 0, time.UTC), session.EndedAt.UTC())
+	assert.Equal(t, "8a7f0e3c-0002", session.ID)
+	assert.Equal(t, time.Date(2021, 3, 4, 5, 0, 0, 0, time.UTC), session.StartedAt.UTC())
+
+	assert.Equal(t, 1200, sessionDB.Sessions[2].DurationSec)
+	assert.Equal(t, "Walden", sessionDB.Sessions[2].BookTitle)
+
+	t.Run("WriteAheadLog", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/kobo.sqlite")
+		assert.NoError(t, err)
+
+		dbPath := filepath.Join(t.TempDir(), "KoboReader.sqlite")
+		assert.NoError(t, os.WriteFile(dbPath, data, 0o644))
+		assert.NoError(t, os.WriteFile(dbPath+"-wal", []byte("changes"), 0o644))
+
+		err = syncKobo(dbPath, annotationsPath, sessionsPath)
+		assert.ErrorContains(t, err, "write-ahead log")
+	})
+}
+
+func TestSyncLinkedIn(t *testing.T) {
+	t.Setenv("LINKEDIN_ACCESS_TOKEN", "token")
+
+	newFixtureClient(t, map[string]string{
+		"/v2/me":       "testdata/linkedin_me.json",
+		"/v2/ugcPosts": "testdata/linkedin_ugc_posts.json",
+
+		"/v2/socialActions/urn:li:share:6750000000000000001": "testdata/linkedin_social_actions.json",
+		"/v2/socialActions/urn:li:share:6760000000000000002": "testdata/linkedin_social_actions.json",
+	})
+
+	// Stored posts that are no longer returned by the API are kept.
+	targetPath := filepath.Join(t.TempDir(), "linkedin.toml")
+	err := writeTOMLFile(targetPath, &LinkedInDB{
+		Posts: []*LinkedInPost{
+			{CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), ID: "urn:li:share:6600000000000000000"},
+		},
+	})
+	assert.NoError(t, err)
+
+	err = syncLinkedIn(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var linkedInDB LinkedInDB
+	err = readTOMLFile(targetPath, &linkedInDB)
+	assert.NoError(t, err)
+	assert.Len(t, linkedInDB.Posts, 3)
+
+	assert.Equal(t, "urn:li:share:6600000000000000000", linkedInDB.Posts[0].ID)
+
+	assert.Equal(t, "urn:li:share:6750000000000000001", linkedInDB.Posts[1].ID)
+	assert.Equal(t, 12, linkedInDB.Posts[1].LikeCount)
+	assert.Equal(t, 3, linkedInDB.Posts[1].CommentCount)
+
+	assert.Equal(t, "urn:li:share:6760000000000000002", linkedInDB.Posts[2].ID)
+	assert.Equal(t, "CONNECTIONS", linkedInDB.Posts[2].Visibility)
+}
+
+func TestSyncLinkedInArticles(t *testing.T) {
+	t.Setenv("LINKEDIN_ACCESS_TOKEN", "token")
+
+	newFixtureClient(t, map[string]string{
+		"/v2/me":       "testdata/linkedin_me.json",
+		"/v2/articles": "testdata/linkedin_articles.json",
+
+		"/v2/socialActions/urn:li:linkedInArticle:6750000000000000101": "testdata/linkedin_social_actions.json",
+		"/v2/socialActions/urn:li:linkedInArticle:6760000000000000102": "testdata/linkedin_social_actions.json",
+	})
+
+	// Stored articles that are no longer returned by the API are kept.
+	targetPath := filepath.Join(t.TempDir(), "linkedin_articles.toml")
+	err := writeTOMLFile(targetPath, &LinkedInArticleDB{
+		Articles: []*LinkedInArticle{
+			{ID: "urn:li:linkedInArticle:6600000000000000100", PublishedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	})
+	assert.NoError(t, err)
+
+	err = syncLinkedInArticles(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var linkedInArticleDB LinkedInArticleDB
+	err = readTOMLFile(targetPath, &linkedInArticleDB)
+	assert.NoError(t, err)
+	assert.Len(t, linkedInArticleDB.Articles, 3)
+
+	assert.Equal(t, "urn:li:linkedInArticle:6600000000000000100", linkedInArticleDB.Articles[0].ID)
+
+	assert.Equal(t, "urn:li:linkedInArticle:6750000000000000101", linkedInArticleDB.Articles[1].ID)
+	assert.Equal(t, 12, linkedInArticleDB.Articles[1].LikeCount)
+	assert.Equal(t, 3, linkedInArticleDB.Articles[1].CommentCount)
+	assert.Equal(t, 812, linkedInArticleDB.Articles[1].ViewCount)
+
+	assert.Equal(t, "urn:li:linkedInArticle:6760000000000000102", linkedInArticleDB.Articles[2].ID)
+	assert.Equal(t, "Short one.", linkedInArticleDB.Articles[2].Content)
+}
+
+func TestSyncMedium(t *testing.T) {
+	t.Setenv("MEDIUM_USERNAME", "brandur")
+
+	// The second post's API request fails.
+	newFixtureClient(t, map[string]string{
+		"/feed/@brandur":            "testdata/medium_feed.xml",
+		"/_/api/posts/4f2a1b7c9d3e": "testdata/medium_post.json",
+		"/_/api/posts/9a8b7c6d5e4f": "404 testdata/medium_not_found.txt",
+	})
+
+	targetPath := filepath.Join(t.TempDir(), "medium.toml")
+	err := writeTOMLFile(targetPath, &MediumDB{
+		Posts: []*MediumPost{
+			{ClapCount: 56, ID: "9a8b7c6d5e4f", PublishedAt: time.Date(2021, 1, 15, 8, 0, 0, 0, time.UTC), Subtitle: "Stored subtitle."},
+			{ClapCount: 10, ID: "4f2a1b7c9d3e", PublishedAt: time.Date(2021, 3, 2, 17, 45, 12, 0, time.UTC), Subtitle: "Old subtitle."},
+		},
+	})
+	assert.NoError(t, err)
+
+	err = syncMedium(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var mediumDB MediumDB
+	err = readTOMLFile(targetPath, &mediumDB)
+	assert.NoError(t, err)
+	assert.Len(t, mediumDB.Posts, 2)
+
+	// Stored values are kept when the fetch failed.
+	assert.Equal(t, "9a8b7c6d5e4f", mediumDB.Posts[0].ID)
+	assert.Equal(t, 56, mediumDB.Posts[0].ClapCount)
+	assert.Equal(t, "Stored subtitle.", mediumDB.Posts[0].Subtitle)
+
+	assert.Equal(t, "4f2a1b7c9d3e", mediumDB.Posts[1].ID)
+	assert.Equal(t, 1234, mediumDB.Posts[1].ClapCount)
+	assert.Equal(t, "How long-lived transactions stall queues.", mediumDB.Posts[1].Subtitle)
+}
+
+func TestSyncMonzo(t *testing.T) {
+	t.Setenv("MONZO_ACCESS_TOKEN", "token")
+
+	newFixtureClient(t, map[string]string{
+		"/accounts": "testdata/monzo_accounts.json",
+
+		// Only the open account is paged. The first page is a full one, so
+		// the next is requested since its newest transaction.
+		"/transactions?account_id=acc_00009237aqC8c5umZmrRdh&since=2020-11-06T10:00:00Z":       "testdata/monzo_transactions_page_1.json",
+		"/transactions?account_id=acc_00009237aqC8c5umZmrRdh&since=tx_0000A000000000000000100": "testdata/monzo_transactions.json",
+	})
+
+	// Stored transactions are kept, and syncing resumes from the last of
+	// them minus monzoRefetchDays.
+	targetPath := filepath.Join(t.TempDir(), "monzo.toml")
+	err := writeTOMLFile(targetPath, &MonzoDB{
+		Transactions: []*MonzoTransaction{
+			{CreatedAt: time.Date(2020, 11, 20, 10, 0, 0, 0, time.UTC), ID: "tx_00009OldTransaction"},
+		},
+	})
+	assert.NoError(t, err)
+
+	err = syncMonzo(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var monzoDB MonzoDB
+	err = readTOMLFile(targetPath, &monzoDB)
+	assert.NoError(t, err)
+	assert.Len(t, monzoDB.Transactions, 103)
+
+	assert.Equal(t, "tx_00009OldTransaction", monzoDB.Transactions[0].ID)
+	assert.Equal(t, "tx_0000A000000000000000001", monzoDB.Transactions[1].ID)
+	assert.Equal(t, "tx_0000A000000000000000100", monzoDB.Transactions[100].ID)
+	assert.Equal(t, "tx_00008zIcpb1TB4yeIFXMzx", monzoDB.Transactions[101].ID)
+	assert.Equal(t, "tx_00009AbcdEfgHijKlmNopq", monzoDB.Transactions[102].ID)
+}
+
+func TestSyncOvercast(t *testing.T) {
+	// Episodes from previous exports are kept.
+	targetPath := filepath.Join(t.TempDir(), "overcast.toml")
+	err := writeTOMLFile(targetPath, &OvercastDB{
+		Episodes: []*PodcastEpisode{{
+			AddedAt:    time.Date(2020, 12, 1, 12, 0, 0, 0, time.UTC),
+			EpisodeURL: "https://example.com/old.mp3",
+		}},
+	})
+	assert.NoError(t, err)
+
+	err = syncOvercast("testdata/overcast.opml", targetPath)
+	assert.NoError(t, err)
+
+	var overcastDB OvercastDB
+	err = readTOMLFile(targetPath, &overcastDB)
+	assert.NoError(t, err)
+
+	// The episode with an invalid publish date is skipped.
+	assert.Len(t, overcastDB.Episodes, 4)
+	assert.Equal(t, "https://example.com/old.mp3", overcastDB.Episodes[0].EpisodeURL)
+
+	// A played episode without an enclosure URL falls back to its web page,
+	// and a feed without a title to its text.
+	episode := overcastDB.Episodes[1]
+	assert.Equal(t, time.Date(2020, 12, 20, 18, 0, 0, 0, time.UTC), episode.AddedAt.UTC())
+	assert.Equal(t, "https://www.dancarlin.com/hh62", episode.EpisodeURL)
+	assert.Equal(t, "https://feeds.feedburner.com/dancarlin/history", episode.FeedURL)
+	assert.Equal(t, time.Date(2020, 12, 28, 5, 0, 0, 0, time.UTC), episode.PlayedAt.UTC())
+	assert.Equal(t, 0, episode.Progress)
+	assert.Equal(t, "Hardcore History", episode.ShowTitle)
+	assert.Equal(t, "Supernova in the East I", episode.Title)
+
+	episode = overcastDB.Episodes[2]
+	assert.Equal(t, "https://example.com/thetalkshow/301.mp3", episode.EpisodeURL)
+	assert.Equal(t, time.Date(2021, 1, 6, 13, 30, 0, 0, time.UTC), episode.PlayedAt.UTC())
+	assert.Equal(t, 7200, episode.Progress)
+	assert.Equal(t, "The Talk Show", episode.ShowTitle)
+
+	// Episodes that haven't been played through have no played time.
+	episode = overcastDB.Episodes[3]
+	assert.Equal(t, "https://example.com/thetalkshow/302.mp3", episode.EpisodeURL)
+	assert.True(t, episode.PlayedAt.IsZero())
+	assert.Equal(t, 1800, episode.Progress)
+}
+
+func TestSyncRunkeeper(t *testing.T) {
+	t.Setenv("RUNKEEPER_ACCESS_TOKEN", "token")
+
+	newFixtureClient(t, map[string]string{
+		"/fitnessActivities":        "testdata/runkeeper_feed.json",
+		"/fitnessActivities?page=1": "testdata/runkeeper_feed_page_2.json",
+		"/fitnessActivities/1001":   "testdata/runkeeper_activity_1001.json",
+		"/fitnessActivities/1002":   "testdata/runkeeper_activity_1002.json",
+	})
+
+	targetPath := filepath.Join(t.TempDir(), "runkeeper.toml")
+	err := syncRunkeeper(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var runkeeperDB RunkeeperDB
+	err = readTOMLFile(targetPath, &runkeeperDB)
+	assert.NoError(t, err)
+	assert.Len(t, runkeeperDB.Activities, 2)
+
+	// Oldest first, across pages.
+	assert.Equal(t, &RunkeeperActivity{
+		Duration:      2700,
+		Notes:         "Stationary bike.",
+		StartTime:     time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+		TotalCalories: 350,
+		Type:          "Cycling",
+		URI:           "/fitnessActivities/1001",
+	}, runkeeperDB.Activities[0])
+
+	assert.Equal(t, "/fitnessActivities/1002", runkeeperDB.Activities[1].URI)
+	assert.Equal(t, 152.0, runkeeperDB.Activities[1].AverageHeartrate)
+}
+
+func TestSyncRunningAhead(t *testing.T) {
+	// Workouts from previous exports are kept.
+	targetPath := filepath.Join(t.TempDir(), "runningahead.toml")
+	err := writeTOMLFile(targetPath, &RunningAheadDB{
+		Workouts: []*RunningWorkout{{ID: "abc", Date: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	})
+	assert.NoError(t, err)
+
+	err = syncRunningAhead("testdata/runningahead.csv", targetPath)
+	assert.NoError(t, err)
+
+	var runningAheadDB RunningAheadDB
+	err = readTOMLFile(targetPath, &runningAheadDB)
+	assert.NoError(t, err)
+
+	// The row with an invalid date is skipped.
+	assert.Len(t, runningAheadDB.Workouts, 4)
+	assert.Equal(t, "abc", runningAheadDB.Workouts[0].ID)
+
+	workout := runningAheadDB.Workouts[1]
+	assert.Equal(t, "Run", workout.ActivityType)
+	assert.Equal(t, 152, workout.AvgHR)
+	assert.Equal(t, 172, workout.CadenceRPM)
+	assert.InDelta(t, 9.978, workout.CourseKm, 0.001)
+	assert.Equal(t, time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC), workout.Date.UTC())
+	assert.Equal(t, 3723, workout.DurationSec)
+	assert.Equal(t, hashSHA1("2021-03-14T00:00:00Z\nSeawall Loop"), workout.ID)
+	assert.Equal(t, "Windy, but fast", workout.Notes)
+	assert.Equal(t, "Pegasus 37", workout.ShoeID)
+
+	// Missing values are left empty.
+	workout = runningAheadDB.Workouts[2]
+	assert.Equal(t, 0, workout.AvgHR)
+	assert.Equal(t, 0, workout.CadenceRPM)
+	assert.Equal(t, 2711, workout.DurationSec)
+	assert.Equal(t, "Interval", workout.ActivityType)
+
+	workout = runningAheadDB.Workouts[3]
+	assert.Equal(t, time.Date(2021, 3, 18, 0, 0, 0, 0, time.UTC), workout.Date.UTC())
+	assert.Equal(t, "", workout.ShoeID)
+
+	// Importing the same export again doesn't duplicate workouts.
+	err = syncRunningAhead("testdata/runningahead.csv", targetPath)
+	assert.NoError(t, err)
+
+	err = readTOMLFile(targetPath, &runningAheadDB)
+	assert.NoError(t, err)
+	assert.Len(t, runningAheadDB.Workouts, 4)
+}
+
+func TestSyncSleepCycle(t *testing.T) {
+	// Nights from previous exports are kept.
+	targetPath := filepath.Join(t.TempDir(), "sleep_cycle.toml")
+	err := writeTOMLFile(targetPath, &SleepCycleDB{
+		Nights: []*SleepCycleNight{{ID: "2020-12-31T23:00:00-08:00", StartAt: time.Date(2021, 1, 1, 7, 0, 0, 0, time.UTC)}},
+	})
+	assert.NoError(t, err)
+
+	err = syncSleepCycle("testdata/sleep_cycle.csv", targetPath)
+	assert.NoError(t, err)
+
+	var sleepCycleDB SleepCycleDB
+	err = readTOMLFile(targetPath, &sleepCycleDB)
+	assert.NoError(t, err)
+
+	// The row with an invalid start is skipped.
+	assert.Len(t, sleepCycleDB.Nights, 3)
+	assert.Equal(t, "2020-12-31T23:00:00-08:00", sleepCycleDB.Nights[0].ID)
+
+	night := sleepCycleDB.Nights[1]
+	assert.Equal(t, 475, night.DurationMin)
+	assert.Equal(t, time.Date(2021, 1, 2, 15, 5, 0, 0, time.UTC), night.EndAt.UTC())
+	assert.Equal(t, 52, night.HeartRateBPM)
+	assert.Equal(t, "2021-01-01T23:10:00-08:00", night.ID)
+	assert.Equal(t, "Drank coffee:Stressful day", night.Notes)
+	assert.Equal(t, 85.0, night.QualityPct)
+	assert.Equal(t, time.Date(2021, 1, 2, 7, 10, 0, 0, time.UTC), night.StartAt.UTC())
+	assert.Equal(t, 10233, night.Steps)
+
+	// Missing values are left empty.
+	night = sleepCycleDB.Nights[2]
+	assert.Equal(t, 0, night.HeartRateBPM)
+	assert.Equal(t, 72.0, night.QualityPct)
+	assert.Equal(t, 0, night.Steps)
+}
+
+func TestSyncSteam(t *testing.T) {
+	t.Setenv("STEAM_API_KEY", "key")
+	t.Setenv("STEAM_USER_ID", "76561197960287930")
+
+	newFixtureClient(t, map[string]string{
+		"/IPlayerService/GetOwnedGames/v0001/?key=key&steamid=76561197960287930": "testdata/steam_owned_games.json",
+	})
+
+	targetPath := filepath.Join(t.TempDir(), "steam.toml")
+	err := writeTOMLFile(targetPath, &SteamDB{
+		Games: []*SteamGame{{AppID: 400, Name: "Portal", PlaytimeForeverMin: 300}},
+	})
+	assert.NoError(t, err)
+
+	err = syncSteam(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var steamDB SteamDB
+	err = readTOMLFile(targetPath, &steamDB)
+	assert.NoError(t, err)
+	assert.Len(t, steamDB.Games, 4)
+
+	assert.Equal(t, 400, steamDB.Games[0].AppID)
+
+	assert.Equal(t, &SteamGame{
+		AppID:               413150,
+		ImgIconURL:          "https://media.steampowered.com/steamcommunity/public/images/apps/413150/35d1377200084a4034238c05b0c8930451e2eb40.jpg",
+		LastPlayedAt:        time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		Name:                "Stardew Valley",
+		PlaytimeForeverMin:  4210,
+		PlaytimeTwoWeeksMin: 95,
+	}, steamDB.Games[2])
+
+	// Never played, so no last played time or icon.
+	assert.Equal(t, &SteamGame{AppID: 1145360, Name: "Hades"}, steamDB.Games[3])
+}
+
+func TestSyncStripe(t *testing.T) {
+	t.Setenv("STRIPE_SECRET_KEY", "sk_test_123")
+
+	newFixtureClient(t, map[string]string{
+		"/v1/charges":                     "testdata/stripe_charges.json",
+		"/v1/charges?starting_after=ch_2": "testdata/stripe_charges_page_2.json",
+		"/v1/payouts":                     "testdata/stripe_payouts.json",
+	})
+
+	dir := t.TempDir()
+	chargesPath := filepath.Join(dir, "stripe_charges.toml")
+	payoutsPath := filepath.Join(dir, "stripe_payouts.toml")
+
+	err := syncStripe(context.Background(), chargesPath, payoutsPath)
+	assert.NoError(t, err)
+
+	var chargeDB StripeChargeDB
+	err = readTOMLFile(chargesPath, &chargeDB)
+	assert.NoError(t, err)
+
+	// Stored oldest first.
+	assert.Equal(t, []*StripeCharge{
+		{
+			AmountCents:          1000,
+			BalanceTransactionID: "txn_1",
+			CreatedAt:            time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+			Currency:             "usd",
+			CustomerEmail:        "alice@example.com", // billing details preferred
+			Description:          "Book sale",
+			ID:                   "ch_1",
+		},
+		{
+			AmountCents:          2500,
+			BalanceTransactionID: "txn_2",
+			CreatedAt:            time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC),
+			Currency:             "usd",
+			CustomerEmail:        "bob@example.com",
+			Description:          "Consulting",
+			ID:                   "ch_2",
+			Refunded:             true,
+			RefundedAmountCents:  2500,
+		},
+	}, chargeDB.Charges)
+
+	var balanceDB StripeBalanceDB
+	err = readTOMLFile(payoutsPath, &balanceDB)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*StripeBalance{
+		{
+			AmountCents:          970,
+			ArrivalAt:            time.Date(2021, 1, 5, 0, 0, 0, 0, time.UTC),
+			BalanceTransactionID: "txn_3",
+			CreatedAt:            time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC),
+			Currency:             "usd",
+			Description:          "STRIPE PAYOUT",
+			ID:                   "po_1",
+			Method:               "standard",
+			Status:               "paid",
+		},
+	}, balanceDB.Payouts)
+}
+
+func TestSyncSubstack(t *testing.T) {
+	t.Setenv("SUBSTACK_COOKIE", "s%3Asession")
+	t.Setenv("SUBSTACK_SUBDOMAIN", "example")
+
+	newFixtureClient(t, map[string]string{
+		"/api/v1/posts?limit=50&offset=0": "testdata/substack_posts.json",
+	})
+
+	targetPath := filepath.Join(t.TempDir(), "substack.toml")
+
+	err := syncSubstack(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var substackDB SubstackDB
+	err = readTOMLFile(targetPath, &substackDB)
+	assert.NoError(t, err)
+
+	// Stored oldest first.
+	assert.Equal(t, []*SubstackPost{
+		{
+			ClickRate:     0.08,
+			ID:            101,
+			Likes:         30,
+			OpenRate:      0.61,
+			PublishedAt:   time.Date(2021, 1, 6, 15, 0, 0, 0, time.UTC),
+			Slug:          "hello-world",
+			Title:         "Hello, world",
+			TruncatedBody: "Welcome to the first issue.",
+			Type:          "newsletter",
+			Views:         1210,
+		},
+		{
+			AudioURL:      "https://example.substack.com/api/v1/audio/upload/102/src",
+			ClickRate:     0.04,
+			ID:            102,
+			Likes:         12,
+			OpenRate:      0.52,
+			PodcastURL:    "https://api.substack.com/feed/podcast/102.mp3",
+			PublishedAt:   time.Date(2021, 2, 3, 15, 0, 0, 0, time.UTC),
+			Slug:          "on-podcasting",
+			Title:         "On podcasting",
+			TruncatedBody: "This week we try something different.",
+			Type:          "podcast",
+			Views:         840,
+		},
+	}, substackDB.Posts)
+}
+
+func TestSyncTelegram(t *testing.T) {
+	t.Setenv("TELEGRAM_BOT_TOKEN", "token")
+	t.Setenv("TELEGRAM_CHANNEL_ID", "-1001234567890")
+
+	t.Run("Merge", func(t *testing.T) {
+		newFixtureClient(t, map[string]string{
+			"/bottoken/getUpdates":          "testdata/telegram_updates_empty.json",
+			"/bottoken/getUpdates?offset=0": "testdata/telegram_updates.json",
+			"/bottoken/getFile":             "testdata/telegram_file.json",
+		})
+
+		// Telegram discards updates once they're fetched, so stored messages
+		// have to be kept.
+		targetPath := filepath.Join(t.TempDir(), "telegram.toml")
+		err := writeTOMLFile(targetPath, &TelegramDB{
+			Messages: []*TelegramMessage{{MessageID: 12, Text: "An old note."}},
+		})
+		assert.NoError(t, err)
+
+		err = syncTelegram(context.Background(), targetPath)
+		assert.NoError(t, err)
+
+		var telegramDB TelegramDB
+		err = readTOMLFile(targetPath, &telegramDB)
+		assert.NoError(t, err)
+		assert.Len(t, telegramDB.Messages, 3)
+
+		assert.Equal(t, 12, telegramDB.Messages[0].MessageID)
+
+		assert.Equal(t, &TelegramMessage{
+			Caption:   "Lisbon from the castle.",
+			Date:      time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC),
+			MessageID: 40,
+			PhotoURL:  "photos/file_12.jpg",
+		}, telegramDB.Messages[1])
+
+		assert.Equal(t, &TelegramMessage{
+			Date:      time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			MessageID: 41,
+			Text:      "Postgres queues hold up better than you'd think.",
+		}, telegramDB.Messages[2])
+	})
+
+	t.Run("ConfirmingCallFails", func(t *testing.T) {
+		newFixtureClient(t, map[string]string{
+			"/bottoken/getUpdates":          "500 testdata/telegram_error.json",
+			"/bottoken/getUpdates?offset=0": "testdata/telegram_updates.json",
+			"/bottoken/getFile":             "testdata/telegram_file.json",
+		})
+
+		// Updates confirmed by the failed call are already written.
+		targetPath := filepath.Join(t.TempDir(), "telegram.toml")
+		err := syncTelegram(context.Background(), targetPath)
+		assert.EqualError(t, err, "error from Telegram calling getUpdates: 500 (Internal Server Error)")
+
+		var telegramDB TelegramDB
+		err = readTOMLFile(targetPath, &telegramDB)
+		assert.NoError(t, err)
+		assert.Len(t, telegramDB.Messages, 2)
+	})
+}
+
+func TestSyncTwitter(t *testing.T) {
+	t.Setenv("TWITTER_CONSUMER_KEY", "key")
+	t.Setenv("TWITTER_CONSUMER_SECRET", "secret")
+	t.Setenv("TWITTER_ACCESS_TOKEN", "token")
+	t.Setenv("TWITTER_ACCESS_SECRET", "secret")
+	t.Setenv("TWITTER_USER", "brandur")
+
+	fixtures := map[string]string{
+		"/1.1/users/show.json":                        "testdata/twitter_users_show.json",
+		"/1.1/statuses/user_timeline.json":            "testdata/twitter_user_timeline.json",
+		"/1.1/statuses/user_timeline.json?max_id=101": "testdata/twitter_user_timeline_page_2.json",
+	}
+
+	t.Run("AdsSkipped", func(t *testing.T) {
+		newFixtureClient(t, fixtures)
+
+		targetPath := filepath.Join(t.TempDir(), "twitter.toml")
+		err := syncTwitter(context.Background(), targetPath, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+
+		tweetDB, err := readTweetDB(targetPath)
+		assert.NoError(t, err)
+		assert.Len(t, tweetDB.Tweets, 1)
+		assert.Equal(t, int64(102), tweetDB.Tweets[0].ID)
+		assert.False(t, tweetDB.Tweets[0].IsAd)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		// Paging starts and ends at the source's tweets just outside the
+		// range, so there's no fixture for requests without them.
+		newFixtureClient(t, map[string]string{
+			"/1.1/users/show.json": "testdata/twitter_users_show.json",
+			"/1.1/statuses/user_timeline.json?max_id=300&since_id=50": "testdata/twitter_user_timeline.json",
+			"/1.1/statuses/user_timeline.json?max_id=101&since_id=50": "testdata/twitter_user_timeline_page_2.json",
+		})
+
+		sourcePath := filepath.Join(t.TempDir(), "twitter.toml")
+		err := writeTOMLFile(sourcePath, &TweetDB{
+			Tweets: []*Tweet{
+				{CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), ID: 300},
+				{CreatedAt: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), ID: 150},
+				{CreatedAt: time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC), ID: 50},
+			},
+			Version: SchemaVersion,
+		})
+		assert.NoError(t, err)
+
+		// A previous ranged sync's file has only tweets within the range.
+		targetPath := filepath.Join(t.TempDir(), "twitter_2021.toml")
+		err = writeTOMLFile(targetPath, &TweetDB{
+			Tweets: []*Tweet{
+				{CreatedAt: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), ID: 120},
+			},
+			Version: SchemaVersion,
+		})
+		assert.NoError(t, err)
+
+		err = syncTwitter(context.Background(), targetPath, &SyncTwitterOptions{
+			RangeEnd:        "2021-12-31T23:59:59Z",
+			RangeSourcePath: sourcePath,
+			RangeStart:      "2021-01-02T00:00:00Z",
+		})
+		assert.NoError(t, err)
+
+		// The source's tweets within the range are merged in.
+		tweetDB, err := readTweetDB(targetPath)
+		assert.NoError(t, err)
+		assert.Len(t, tweetDB.Tweets, 3)
+		assert.Equal(t, int64(150), tweetDB.Tweets[0].ID)
+		assert.Equal(t, int64(120), tweetDB.Tweets[1].ID)
+		assert.Equal(t, int64(102), tweetDB.Tweets[2].ID)
+
+		// The source is left as it was.
+		sourceTweetDB, err := readTweetDB(sourcePath)
+		assert.NoError(t, err)
+		assert.Len(t, sourceTweetDB.Tweets, 3)
+
+		// Without a source, the whole timeline is paged through again.
+		newFixtureClient(t, fixtures)
+
+		err = syncTwitter(context.Background(), targetPath, &SyncTwitterOptions{
+			RangeEnd:   "2021-12-31T23:59:59Z",
+			RangeStart: "2021-01-02T00:00:00Z",
+		})
+		assert.NoError(t, err)
+
+		tweetDB, err = readTweetDB(targetPath)
+		assert.NoError(t, err)
+		assert.Len(t, tweetDB.Tweets, 3)
+	})
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		err := syncTwitter(context.Background(), filepath.Join(t.TempDir(), "twitter.toml"),
+			&SyncTwitterOptions{RangeStart: "2021-01-01"})
+		assert.ErrorContains(t, err, "error parsing --tweet-start")
+
+		err = syncTwitter(context.Background(), filepath.Join(t.TempDir(), "twitter.toml"),
+			&SyncTwitterOptions{RangeEnd: "2020-01-01T00:00:00Z", RangeStart: "2021-01-01T00:00:00Z"})
+		assert.EqualError(t, err, "--tweet-end should be after --tweet-start")
+
+		err = syncTwitter(context.Background(), filepath.Join(t.TempDir(), "twitter.toml"),
+			&SyncTwitterOptions{RangeSourcePath: "twitter.toml"})
+		assert.EqualError(t, err, "--tweet-range-source needs --tweet-start or --tweet-end")
+
+		targetPath := filepath.Join(t.TempDir(), "twitter.toml")
+		err = syncTwitter(context.Background(), targetPath,
+			&SyncTwitterOptions{RangeSourcePath: targetPath, RangeStart: "2021-01-01T00:00:00Z"})
+		assert.EqualError(t, err, "--tweet-range-source should be a different file than the one being synced")
+	})
+
+	t.Run("IncludeAds", func(t *testing.T) {
+		newFixtureClient(t, fixtures)
+
+		targetPath := filepath.Join(t.TempDir(), "twitter.toml")
+		err := syncTwitter(context.Background(), targetPath, &SyncTwitterOptions{IncludeAds: true})
+		assert.NoError(t, err)
+
+		tweetDB, err := readTweetDB(targetPath)
+		assert.NoError(t, err)
+		assert.Len(t, tweetDB.Tweets, 2)
+		assert.Equal(t, int64(101), tweetDB.Tweets[1].ID)
+		assert.True(t, tweetDB.Tweets[1].IsAd)
+	})
+}
+
+func TestSyncTwitterLikes(t *testing.T) {
+	t.Run("Standard", func(t *testing.T) {
+		client := twitter.NewClient(newFixtureClient(t, map[string]string{
+			"/1.1/favorites/list.json":             "testdata/twitter_favorites.json",
+			"/1.1/favorites/list.json?max_id=1001": "testdata/twitter_favorites_page_2.json",
+		}))
+
+		// A like of a tweet that's since been deleted is kept.
+		targetPath := filepath.Join(t.TempDir(), "twitter_likes.toml")
+		err := writeTOMLFile(targetPath, &LikedTweetDB{
+			Tweets: []*LikedTweet{{Tweet: Tweet{ID: 999, Text: "Deleted"}, User: "gone"}},
+		})
+		assert.NoError(t, err)
+
+		numSkipped, err := syncTwitterLikes(context.Background(), client, 123, targetPath, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, numSkipped)
+
+		var likedTweetDB LikedTweetDB
+		err = readTOMLFile(targetPath, &likedTweetDB)
+		assert.NoError(t, err)
+		assert.Len(t, likedTweetDB.Tweets, 3)
+		assert.Equal(t, SchemaVersion, likedTweetDB.Version)
+
+		like := likedTweetDB.Tweets[0]
+		assert.Equal(t, int64(1002), like.ID)
+		assert.Equal(t, "A tweet worth liking & keeping", like.Text)
+		assert.Equal(t, 12, like.FavoriteCount)
+		assert.Equal(t, "someone", like.User)
+		assert.Equal(t, int64(42), like.UserID)
+		assert.False(t, like.LikedAt.IsZero())
+
+		assert.Equal(t, int64(1001), likedTweetDB.Tweets[1].ID)
+		assert.Equal(t, int64(999), likedTweetDB.Tweets[2].ID)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		client := twitter.NewClient(newFixtureClient(t, map[string]string{
+			"/1.1/favorites/list.json": "404 testdata/twitter_not_found.json",
+		}))
+
+		targetPath := filepath.Join(t.TempDir(), "twitter_likes.toml")
+		err := writeTOMLFile(targetPath, &LikedTweetDB{
+			Tweets: []*LikedTweet{{Tweet: Tweet{ID: 999, Text: "Deleted"}, User: "gone"}},
+		})
+		assert.NoError(t, err)
+
+		_, err = syncTwitterLikes(context.Background(), client, 123, targetPath, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+
+		var likedTweetDB LikedTweetDB
+		err = readTOMLFile(targetPath, &likedTweetDB)
+		assert.NoError(t, err)
+		assert.Len(t, likedTweetDB.Tweets, 1)
+		assert.Equal(t, int64(999), likedTweetDB.Tweets[0].ID)
+	})
+}
+
+func TestSyncWeatherOpenWeather(t *testing.T) {
+	t.Setenv("OPENWEATHER_API_KEY", "key")
+	t.Setenv("OPENWEATHER_LAT", "49.2827")
+	t.Setenv("OPENWEATHER_LON", "-123.1207")
+
+	// Every hour gets the same fixture, which puts the location at UTC-8.
+	newFixtureClient(t, map[string]string{
+		"/data/3.0/onecall/timemachine?appid=key&lat=49.2827&lon=-123.1207&units=metric": "testdata/openweather_timemachine.json",
+	})
+
+	nowLocal := time.Now().In(time.FixedZone("", -28800))
+	today := time.Date(nowLocal.Year(), nowLocal.Month(), nowLocal.Day(), 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	// Yesterday was stored before it was over, so it's fetched again.
+	targetPath := filepath.Join(t.TempDir(), "openweather.toml")
+	err := writeTOMLFile(targetPath, &OpenWeatherDB{
+		Days: []*OpenWeatherDay{
+			{Date: today.AddDate(0, 0, -5), TempMaxC: 10},
+			{Date: yesterday, TempMaxC: 2},
+		},
+	})
+	assert.NoError(t, err)
+
+	err = syncWeatherOpenWeather(context.Background(), targetPath)
+	assert.NoError(t, err)
+
+	var openWeatherDB OpenWeatherDB
+	err = readTOMLFile(targetPath, &openWeatherDB)
+	assert.NoError(t, err)
+	assert.Len(t, openWeatherDB.Days, 3)
+
+	assert.Equal(t, today.AddDate(0, 0, -5), openWeatherDB.Days[0].Date)
+	assert.Equal(t, 10.0, openWeatherDB.Days[0].TempMaxC)
+
+	assert.Equal(t, &OpenWeatherDay{
+		Date:               yesterday,
+		FeelsLikeMaxC:      3.2,
+		Humidity:           87,
+		PrecipitationMM:    12, // 0.5 mm for each of 24 hours
+		SunriseAt:          time.Date(2021, 1, 2, 15, 54, 48, 0, time.UTC),
+		SunsetAt:           time.Date(2021, 1, 3, 0, 25, 14, 0, time.UTC),
+		TempMaxC:           6.5,
+		TempMinC:           6.5,
+		UVI:                0.4,
+		WeatherDescription: "light rain",
+		WeatherMain:        "Rain",
+	}, openWeatherDB.Days[1])
+
+	assert.Equal(t, today, openWeatherDB.Days[2].Date)
+}
+
+func TestSyncWithings(t *testing.T) {
+	t.Setenv("WITHINGS_ACCESS_TOKEN", "access-token")
+	t.Setenv("WITHINGS_CLIENT_ID", "client-id")
+	t.Setenv("WITHINGS_CLIENT_SECRET", "client-secret")
+	t.Setenv("WITHINGS_REFRESH_TOKEN", "refresh-token")
+
+	ctx := context.Background()
+
+	fixtures := map[string]string{
+		"/measure?action=getmeas":          "testdata/withings_measures.json",
+		"/measure?action=getmeas&offset=2": "testdata/withings_measures_page_2.json",
+	}
+
+	t.Run("FirstRun", func(t *testing.T) {
+		newFixtureClient(t, fixtures)
+
+		dir := t.TempDir()
+		targetPath := filepath.Join(dir, "withings.toml")
+		err := syncWithings(ctx, targetPath)
+		assert.NoError(t, err)
+
+		var withingsDB WithingsDB
+		err = readTOMLFile(targetPath, &withingsDB)
+		assert.NoError(t, err)
+		assert.Len(t, withingsDB.Measurements, 2)
+		assert.Equal(t, time.Unix(1700000000, 0).UTC(), withingsDB.Measurements[0].Date)
+		assert.Equal(t, 62, withingsDB.Measurements[0].HeartRateBPM)
+		assert.InDelta(t, 72.1, withingsDB.Measurements[1].WeightKg, 0.0001)
+
+		// No refresh was needed, so no tokens were saved.
+		_, err = os.Stat(filepath.Join(dir, "withings_token.toml"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("SavedTokenExpired", func(t *testing.T) {
+		// Requests refreshing with any other refresh token don't match a
+		// fixture, and fail the test.
+		newFixtureClient(t, map[string]string{
+			"/measure?action=getmeas":                "testdata/withings_measures.json",
+			"/measure?action=getmeas&offset=2":       "testdata/withings_measures_page_2.json",
+			"/v2/oauth2?refresh_token=saved-refresh": "testdata/withings_token.json",
+		})
+
+		dir := t.TempDir()
+		tokenPath := filepath.Join(dir, "withings_token.toml")
+		err := writeWithingsToken(tokenPath, &WithingsToken{
+			AccessToken:  "saved-access",
+			ExpiresAt:    time.Now().Add(-time.Hour),
+			RefreshToken: "saved-refresh",
+		})
+		assert.NoError(t, err)
+
+		err = syncWithings(ctx, filepath.Join(dir, "withings.toml"))
+		assert.NoError(t, err)
+
+		var token WithingsToken
+		err = readTOMLFile(tokenPath, &token)
+		assert.NoError(t, err)
+		assert.Equal(t, "new-access-token", token.AccessToken)
+		assert.Equal(t, "new-refresh-token", token.RefreshToken)
+		assert.True(t, token.ExpiresAt.After(time.Now()))
+
+		info, err := os.Stat(tokenPath)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("InvalidTokenWithoutRefresh", func(t *testing.T) {
+		t.Setenv("WITHINGS_REFRESH_TOKEN", "")
+
+		newFixtureClient(t, map[string]string{
+			"/measure": "testdata/withings_invalid_token.json",
+		})
+
+		err := syncWithings(ctx, filepath.Join(t.TempDir(), "withings.toml"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "can't be refreshed")
+	})
+}
+
+func TestTogglEntryFromAPITimeEntry(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/toggl_time_entries.json")
+	assert.NoError(t, err)
+
+	var entries []*TogglAPITimeEntry
+	err = json.Unmarshal(data, &entries)
+	assert.NoError(t, err)
+
+	projects := map[int64]*TogglAPIProject{
+		190234567: {ID: 190234567, Name: "Open Source", WorkspaceID: 7012345},
+	}
+
+	t.Run("Project", func(t *testing.T) {
+		assert.Equal(t, &TogglEntry{
+			Billable:        true,
+			Description:     "Review pull requests",
+			DurationSeconds: 5430,
+			ID:              3154987011,
+			ProjectID:       190234567,
+			ProjectName:     "Open Source",
+			StartedAt:       time.Date(2023, 3, 14, 16, 2, 11, 0, time.UTC),
+			StoppedAt:       time.Date(2023, 3, 14, 17, 32, 41, 0, time.UTC),
+			Tags:            []string{"code-review", "work"},
+			WorkspaceID:     7012345,
+		}, togglEntryFromAPITimeEntry(entries[0], projects))
+	})
+
+	t.Run("UnknownProject", func(t *testing.T) {
+		entry := togglEntryFromAPITimeEntry(entries[0], map[int64]*TogglAPIProject{})
+		assert.Equal(t, int64(190234567), entry.ProjectID)
+		assert.Equal(t, "", entry.ProjectName)
+	})
+
+	t.Run("NoProject", func(t *testing.T) {
+		entry := togglEntryFromAPITimeEntry(entries[1], projects)
+		assert.Equal(t, int64(0), entry.ProjectID)
+		assert.Equal(t, "", entry.ProjectName)
+		assert.True(t, entry.StoppedAt.IsZero())
+	})
+}
+
+func TestTweetRangeAnchorIDs(t *testing.T) {
+	// Stored out of order to check that they're sorted by ID.
+	tweets := []*Tweet{
+		{ID: 30, CreatedAt: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 10, CreatedAt: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 40, CreatedAt: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 20, CreatedAt: time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{ID: 50, CreatedAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC)
+
+	t.Run("Both", func(t *testing.T) {
+		sinceID, maxID := tweetRangeAnchorIDs(tweets, start, end)
+		assert.Equal(t, int64(20), sinceID)
+		assert.Equal(t, int64(40), maxID)
+	})
+
+	t.Run("Open", func(t *testing.T) {
+		sinceID, maxID := tweetRangeAnchorIDs(tweets, time.Time{}, time.Time{})
+		assert.Equal(t, int64(0), sinceID)
+		assert.Equal(t, int64(0), maxID)
+	})
+
+	t.Run("NoneOutside", func(t *testing.T) {
+		sinceID, maxID := tweetRangeAnchorIDs(tweets,
+			time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, int64(0), sinceID)
+		assert.Equal(t, int64(0), maxID)
+	})
+}
+
+func TestTweetFromAPITweet(t *testing.T) {
+	t.Run("CreatedAt", func(t *testing.T) {
+		tweet, err := tweetFromAPITweet(newAPITweet(), &SyncTwitterOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, time.Date(2021, 1, 2, 15, 4, 5, 0, time.UTC), tweet.CreatedAt.UTC())
+		assert.Equal(t, int64(1609599845), tweet.CreatedAtUnix)
+	})
+
+	t.Run("EngagementScore", func(t *testing.T) {
+		apiTweet := newAPITweet()
+		apiTweet.FavoriteCount = 10
+		apiTweet.RetweetCount = 3
+
+		tweet, err := tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 16.0, tweet.EngagementScore)
+
+		tweet, err = tweetFromAPITweet(apiTweet, &SyncTwitterOptions{EngagementWeights: []float64{2.0, 0.0, 0.0, 0.0}})
+		assert.NoError(t, err)
+		assert.Equal(t, 20.0, tweet.EngagementScore)
+	})
+
+	t.Run("MediaDimensions", func(t *testing.T) {
+		data, err := ioutil.ReadFile("./testdata/twitter_tweet_media.json")
+		assert.NoError(t, err)
+
+		var apiTweet twitter.Tweet
+		err = json.Unmarshal(data, &apiTweet)
+		assert.NoError(t, err)
+
+		tweet, err := tweetFromAPITweet(&apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []*TweetEntitiesMedia{
+			{
+				Height: 1536,
+				ID:     1345395512139370497,
+				Type:   "photo",
+				URL:    "https://pbs.twimg.com/media/ErIbgzoVcAEQ7xk.jpg",
+				Width:  2048,
+			},
+			{
+				Height:       1280,
+				ID:           1345395512139370498,
+				ThumbnailURL: "https://pbs.twimg.com/ext_tw_video_thumb/1345395512139370498/pu/img/Yx2kL8p.jpg",
+				Type:         "video",
+				URL:          "https://video.twimg.com/ext_tw_video/1345395512139370498/pu/vid/720x1280/pQ8rT2w.mp4",
+				Variants: []*TweetEntitiesMediaVariant{
+					{Bitrate: 2176000, ContentType: "video/mp4", URL: "https://video.twimg.com/ext_tw_video/1345395512139370498/pu/vid/720x1280/pQ8rT2w.mp4"},
+					{Bitrate: 832000, ContentType: "video/mp4", URL: "https://video.twimg.com/ext_tw_video/1345395512139370498/pu/vid/480x852/Ab9sLk3.mp4"},
+					{Bitrate: 632000, ContentType: "video/mp4", URL: "https://video.twimg.com/ext_tw_video/1345395512139370498/pu/vid/320x568/c2x5nQn.mp4"},
+					{ContentType: "application/x-mpegURL", URL: "https://video.twimg.com/ext_tw_video/1345395512139370498/pu/pl/Fj3kXm.m3u8"},
+				},
+				Width: 720,
+			},
+		}, tweet.Entities.Medias)
+
+		// Sizes missing from the API leave dimensions zero, and they're
+		// left out of the written data.
+		apiTweet.ExtendedEntities = nil
+		apiTweet.Entities.Media[0].Sizes = twitter.MediaSizes{}
+
+		tweet, err = tweetFromAPITweet(&apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, tweet.Entities.Medias, 1)
+		assert.Equal(t, 0, tweet.Entities.Medias[0].Height)
+		assert.Equal(t, 0, tweet.Entities.Medias[0].Width)
+
+		data, err = toml.Marshal(tweet.Entities.Medias[0])
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "height")
+		assert.NotContains(t, string(data), "variants")
+		assert.NotContains(t, string(data), "width")
+	})
+
+	t.Run("IsAd", func(t *testing.T) {
+		tweet, err := tweetFromAPITweet(newAPITweet(), &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.False(t, tweet.IsAd)
+
+		apiTweet := newAPITweet()
+		apiTweet.Source = "Twitter Ads Composer (Promoted)"
+		tweet, err = tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.True(t, tweet.IsAd)
+
+		apiTweet = newAPITweet()
+		apiTweet.Scopes = map[string]interface{}{"followers": false}
+		tweet, err = tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.True(t, tweet.IsAd)
+	})
+
+	t.Run("GeoPointOnly", func(t *testing.T) {
+		apiTweet := newAPITweet()
+		apiTweet.Coordinates = &twitter.Coordinates{
+			Coordinates: [2]float64{-123.1207, 49.2827},
+			Type:        "Point",
+		}
+
+		tweet, err := tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(
+			t,
+			&TweetGeo{Latitude: 49.2827, Longitude: -123.1207},
+			tweet.Geo,
+		)
+	})
+
+	t.Run("GeoPlaceOnly", func(t *testing.T) {
+		apiTweet := newAPITweet()
+		apiTweet.Place = newAPIPlace()
+
+		tweet, err := tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(
+			t,
+			&TweetGeo{
+				Country:     "Canada",
+				CountryCode: "CA",
+				Latitude:    49.25,
+				Longitude:   -123.0,
+				PlaceName:   "Vancouver, British Columbia",
+				PlaceType:   "city",
+			},
+			tweet.Geo,
+		)
+	})
+
+	t.Run("GeoPointAndPlace", func(t *testing.T) {
+		apiTweet := newAPITweet()
+		apiTweet.Coordinates = &twitter.Coordinates{
+			Coordinates: [2]float64{-123.1207, 49.2827},
+			Type:        "Point",
+		}
+		apiTweet.Place = newAPIPlace()
+
+		tweet, err := tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+
+		assert.Equal(
+			t,
+			&TweetGeo{
+				Country:     "Canada",
+				CountryCode: "CA",
+				Latitude:    49.2827,   // point preferred
+				Longitude:   -123.1207, // point preferred
+				PlaceName:   "Vancouver, British Columbia",
+				PlaceType:   "city",
+			},
+			tweet.Geo,
+		)
+	})
+
+	t.Run("WithheldInCountriesNone", func(t *testing.T) {
+		apiTweet := newAPITweet()
+		apiTweet.WithheldInCountries = []string{}
+
+		tweet, err := tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, tweet.WithheldInCountries)
+
+		data, err := toml.Marshal(tweet)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "withheld_in_countries")
+	})
+
+	t.Run("WithheldInCountriesSingle", func(t *testing.T) {
+		apiTweet := newAPITweet()
+		apiTweet.WithheldInCountries = []string{"DE"}
+
+		tweet, err := tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"DE"}, tweet.WithheldInCountries)
+	})
+
+	t.Run("WithheldInCountriesMultiple", func(t *testing.T) {
+		apiTweet := newAPITweet()
+		apiTweet.WithheldInCountries = []string{"DE", "FR", "TR"}
+
+		tweet, err := tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"DE", "FR", "TR"}, tweet.WithheldInCountries)
+	})
+
+	t.Run("MalformedCreatedAt", func(t *testing.T) {
+		apiTweet := newAPITweet()
+		apiTweet.CreatedAt = "not a date"
+
+		_, err := tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("GeoNone", func(t *testing.T) {
+		tweet, err := tweetFromAPITweet(newAPITweet(), &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Nil(t, tweet.Geo)
+	})
+
+	t.Run("QuoteNone", func(t *testing.T) {
+		tweet, err := tweetFromAPITweet(newAPITweet(), &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Nil(t, tweet.Quote)
+	})
+
+	t.Run("Quote", func(t *testing.T) {
+		apiTweet := newAPITweet()
+		apiTweet.QuotedStatusID = 456
+		apiTweet.QuotedStatus = &twitter.Tweet{
+			CreatedAt: "Fri Jan 01 09:30:00 +0000 2021",
+			FullText:  "Quoted &amp; original",
+			ID:        456,
+			User:      &twitter.User{ID: 789, ScreenName: "someone"},
+		}
+
+		tweet, err := tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, &TweetQuote{
+			OriginalCreatedAt: time.Date(2021, 1, 1, 9, 30, 0, 0, time.UTC),
+			OriginalText:      "Quoted & original",
+			StatusID:          456,
+			User:              "someone",
+			UserID:            789,
+		}, tweet.Quote)
+	})
+
+	t.Run("QuoteDeleted", func(t *testing.T) {
+		apiTweet := newAPITweet()
+		apiTweet.QuotedStatusID = 456
+
+		tweet, err := tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, &TweetQuote{StatusID: 456}, tweet.Quote)
+	})
+
+	t.Run("TextHashSHA1", func(t *testing.T) {
+		tweet, err := tweetFromAPITweet(newAPITweet(), &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "2ae01472317d1935a84797ec1983ae243fc6aa28", tweet.TextHashSHA1)
+
+		apiTweet := newAPITweet()
+		apiTweet.FullText = "Hello, world. ✨"
+
+		tweet, err = tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "fb7838b87107ea83c961430d58ac0ff04b796e88", tweet.TextHashSHA1)
+	})
+
+	t.Run("NormalizedText", func(t *testing.T) {
+		// Text that's already normalized isn't stored again.
+		tweet, err := tweetFromAPITweet(newAPITweet(), &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "", tweet.NormalizedText)
+
+		// Fullwidth ASCII, and an "e" followed by a combining acute accent.
+		apiTweet := newAPITweet()
+		apiTweet.FullText = "Ｈｅｌｌｏ, cafe\u0301 ２０２１"
+
+		tweet, err = tweetFromAPITweet(apiTweet, &SyncTwitterOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "Ｈｅｌｌｏ, cafe\u0301 ２０２１", tweet.Text)
+		assert.Equal(t, "Hello, caf\u00e9 2021", tweet.NormalizedText)
+
+		tweet, err = tweetFromAPITweet(apiTweet, &SyncTwitterOptions{NoNormalizeText: true})
+		assert.NoError(t, err)
+		assert.Equal(t, "", tweet.NormalizedText)
+	})
+}
+
+func TestValidateReadings(t *testing.T) {
+	warnings := validateReadings([]*Reading{
+		{ReviewID: 1, Title: "Finished", ReadAt: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ReviewID: 2, Title: "Abandoned", Abandoned: true, AbandonedAt: time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{ReviewID: 3, Title: "Abandoned Undated", Abandoned: true},
+		{ReviewID: 4, Title: "Paperback", Format: "Paperback", ReadAt: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{ReviewID: 5, Title: "Kindle", Format: "Kindle Edition", ReadAt: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{ReviewID: 6, Title: "French", Language: "fr", ReadAt: time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{ReviewID: 7, Title: "English", Language: "eng", ReadAt: time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{ReviewID: 8, Title: "Counted", NumPages: 200, WordCount: 50000, ReadAt: time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)},
+		{ReviewID: 9, Title: "Uncounted", NumPages: 200, ReadAt: time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{ReviewID: 10, Title: "Started", StartedAt: time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC), ReadAt: time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{ReviewID: 11, Title: "Started Late", StartedAt: time.Date(2021, 12, 2, 0, 0, 0, 0, time.UTC), ReadAt: time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{ReviewID: 12, Title: "Started Unread", StartedAt: time.Date(2021, 12, 2, 0, 0, 0, 0, time.UTC)},
+	})
+
+	assert.Equal(t, []string{
+		"Review 3 ('Abandoned Undated') is abandoned, but has no abandoned at time (its shelf has no read date)",
+		"Review 5 ('Kindle') has unknown format 'Kindle Edition'",
+		"Review 7 ('English') has language 'eng', which isn't an ISO 639-1 code",
+		"Review 9 ('Uncounted') has 200 pages, but no word count (it may have been synced by an older version of qself)",
+		"Review 11 ('Started Late') was started at 2021-12-02, after it was read at 2021-12-01",
+	}, warnings)
+}
+
+func TestWakaTimeDayFromAPISummary(t *testing.T) {
+	t.Run("Standard", func(t *testing.T) {
+		day, err := wakaTimeDayFromAPISummary(&WakaTimeAPISummary{
+			Editors:          []*WakaTimeAPIStat{{Name: "Vim", TotalSeconds: 3600.5}},
+			GrandTotal:       &WakaTimeAPIGrandTotal{TotalSeconds: 3600.5},
+			Languages:        []*WakaTimeAPIStat{{Name: "Go", TotalSeconds: 3000}, {Name: "Markdown", TotalSeconds: 600.5}},
+			OperatingSystems: []*WakaTimeAPIStat{{Name: "Mac", TotalSeconds: 3600.5}},
+			Projects:         []*WakaTimeAPIStat{{Name: "qself", TotalSeconds: 3600.5}},
+			Range:            &WakaTimeAPIRange{Date: "2021-01-02"},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(
+			t,
+			&WakaTimeDay{
+				Date:         time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+				Editors:      []*WakaTimeEditor{{Name: "Vim", Seconds: 3600}},
+				Languages:    []*WakaTimeLang{{Name: "Go", Seconds: 3000}, {Name: "Markdown", Seconds: 600}},
+				OSes:         []*WakaTimeOS{{Name: "Mac", Seconds: 3600}},
+				Projects:     []*WakaTimeProject{{Name: "qself", Seconds: 3600}},
+				TotalSeconds: 3600,
+			},
+			day,
+		)
+	})
+
+	t.Run("MissingRange", func(t *testing.T) {
+		_, err := wakaTimeDayFromAPISummary(&WakaTimeAPISummary{
+			GrandTotal: &WakaTimeAPIGrandTotal{TotalSeconds: 3600},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestWithingsMeasurementsFromAPIGroups(t *testing.T) {
+	measurements := withingsMeasurementsFromAPIGroups([]*WithingsAPIMeasureGroup{
+		{Date: 1700000000, Measures: []*WithingsAPIMeasure{
+			{Type: withingsMeasureTypeWeight, Unit: -3, Value: 80000},
+			{Type: withingsMeasureTypeFatRatio, Unit: -1, Value: 205},
+			{Type: withingsMeasureTypeMuscleMass, Unit: -1, Value: 360},
+			{Type: withingsMeasureTypeHydration, Unit: -2, Value: 4400},
+			{Type: withingsMeasureTypeBoneMass, Unit: -2, Value: 325},
+		}},
+		{Date: 1700000000, Measures: []*WithingsAPIMeasure{
+			{Type: withingsMeasureTypeHeartPulse, Unit: 0, Value: 58},
+		}},
+
+		// Without a weight, kilogram measures can't be made percentages.
+		{Date: 1700086400, Measures: []*WithingsAPIMeasure{
+			{Type: withingsMeasureTypeMuscleMass, Unit: -1, Value: 360},
+		}},
+	})
+
+	assert.Len(t, measurements, 2)
+
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), measurements[0].Date)
+	assert.InDelta(t, 80.0, measurements[0].WeightKg, 0.0001)
+	assert.InDelta(t, 20.5, measurements[0].BodyFatPct, 0.0001)
+	assert.InDelta(t, 45.0, measurements[0].MuscleMassPct, 0.0001)
+	assert.InDelta(t, 55.0, measurements[0].HydrationPct, 0.0001)
+	assert.InDelta(t, 3.25, measurements[0].BoneMassKg, 0.0001)
+	assert.Equal(t, 58, measurements[0].HeartRateBPM)
+
+	assert.Equal(t, time.Unix(1700086400, 0).UTC(), measurements[1].Date)
+	assert.Equal(t, 0.0, measurements[1].MuscleMassPct)
+}
+
+func benchmarkGroupByInput() []int {
+	s := make([]int, 100000)
+	for i := range s {
+		s[i] = i
+	}
+	return s
+}
+
+func newAPIPlace() *twitter.Place {
+	return &twitter.Place{
+		BoundingBox: &twitter.BoundingBox{
+			Coordinates: [][][2]float64{
+				{
+					{-123.5, 49.0},
+					{-122.5, 49.0},
+					{-122.5, 49.5},
+					{-123.5, 49.5},
+				},
+			},
+			Type: "Polygon",
+		},
+		Country:     "Canada",
+		CountryCode: "CA",
+		FullName:    "Vancouver, British Columbia",
+		Name:        "Vancouver",
+		PlaceType:   "city",
+	}
+}
+
+func newAPITweet() *twitter.Tweet {
+	return &twitter.Tweet{
+		CreatedAt: "Sat Jan 02 15:04:05 +0000 2021",
+		Entities:  &twitter.Entities{},
+		FullText:  "Hello, world.",
+		ID:        123,
+	}
+}
+
+func readAPIReviewsFixture(t *testing.T, path string) []*APIReview {
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+
+	var root APIReviewsRoot
+	err = xml.Unmarshal(data, &root)
+	assert.NoError(t, err)
+
+	return root.Reviews
+}
+
+// Generates a set of tweets resembling a real archive, with a mix of plain
+// tweets, replies, retweets, and tweets carrying entities. Entities are left
+// nil when empty because compactTOML doesn't preserve empty tables.
+func newTweets(n int) []*Tweet {
+	tweets := make([]*Tweet, n)
+	for i := range tweets {
+		tweet := &Tweet{
+			CreatedAt: time.Date(2021, 1, 2, 15, 4, 5, 0, time.UTC).Add(time.Duration(i) * time.Hour),
+			ID:        int64(1000000 + i),
+			Text:      fmt.Sprintf("Tweet number %d", i),
+		}
+
+		switch i % 4 {
+		case 1:
+			tweet.FavoriteCount = i % 17
+			tweet.Reply = &TweetReply{
+				StatusID: int64(999 + i),
+				User:     "brandur",
+				UserID:   12345,
+			}
+			tweet.Entities = &TweetEntities{
+				UserMentions: []*TweetEntitiesUserMention{
+					{User: "brandur", UserID: 12345},
+				},
+			}
+		case 2:
+			tweet.Retweet = &TweetRetweet{
+				StatusID: int64(888 + i),
+				User:     "brandur",
+				UserID:   12345,
+			}
+		case 3:
+			tweet.RetweetCount = i % 5
+			tweet.Entities = &TweetEntities{
+				URLs: []*TweetEntitiesURL{
+					{
+						DisplayURL:  "brandur.org",
+						ExpandedURL: "https://brandur.org",
+						URL:         "https://t.co/abc",
+					},
+				},
+			}
+		}
+
+		tweets[i] = tweet
+	}
+	return tweets
+}
+
+func readChessComGamesFixture(t *testing.T, path string) []*ChessComAPIGame {
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+
+	var root ChessComAPIGamesRoot
+	err = json.Unmarshal(data, &root)
+	assert.NoError(t, err)
+
+	return root.Games
+}
+
+// Starts a server that acts as an HTTP proxy, responding to every request
+// itself and recording it rather than forwarding it on.
+func newMockProxy(t *testing.T) (*httptest.Server, *[]*http.Request) {
+	var requests []*http.Request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy, &requests
+}
+
+func setHTTPProxy(t *testing.T, rawURL string) {
+	proxyURL, err := parseHTTPProxy(rawURL)
+	assert.NoError(t, err)
+
+	rootOptions.HTTPProxyURL = proxyURL
+	t.Cleanup(func() { rootOptions.HTTPProxyURL = nil })
+}
+
+// Writes a file with the given contents to a temporary directory, returning
+// its path.
+func writeTestFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	return path
+}
+
+// fakeScheduleCalls records the OS commands run by a ScheduleEnv from
+// newFakeScheduleEnv.
+type fakeScheduleCalls struct {
+	commands []string
+	stdins   []string
+}
+
+// Commands returns each command that was run, with its arguments joined by
+// spaces.
+func (c *fakeScheduleCalls) Commands() []string {
+	return c.commands
+}
+
+// Stdin returns the input given to the last run of the given command.
+func (c *fakeScheduleCalls) Stdin(command string) string {
+	for i := len(c.commands) - 1; i >= 0; i-- {
+		if c.commands[i] == command {
+			return c.stdins[i]
+		}
+	}
+	return ""
+}
+
+// Returns a ScheduleEnv for the given OS that records commands instead of
+// running them, with a temporary home directory. Commands found in outputs
+// (with arguments joined by spaces) produce the mapped output, or fail with it
+// if it starts with "!".
+func newFakeScheduleEnv(t *testing.T, goos string, outputs map[string]string) (*ScheduleEnv, *fakeScheduleCalls) {
+	calls := &fakeScheduleCalls{}
+
+	return &ScheduleEnv{
+		Executable: "/usr/local/bin/qself",
+		GOOS:       goos,
+		HomeDir:    t.TempDir(),
+		RunCommand: func(ctx context.Context, stdin string, name string, args ...string) (string, error) {
+			command := strings.Join(append([]string{name}, args...), " ")
+			calls.commands = append(calls.commands, command)
+			calls.stdins = append(calls.stdins, stdin)
+
+			out := outputs[command]
+			if strings.HasPrefix(out, "!") {
+				return out[1:], fmt.Errorf("error running '%s': exit status 1", name)
+			}
+			return out, nil
+		},
+	}, calls
+}
+
+// Returns a client that serves pre-recorded responses from a test server
+// instead of making real requests. fixtures maps a request path, optionally
+// followed by query parameters that must also match (as either query
+// parameters or form values of the request), to the path of a file
+// whose contents are served. When several keys match, the one with the most
+// query parameters wins. A fixture path may be prefixed with a status code
+// like "403 " to serve it with that status instead of 200. Unmatched requests
+// fail the test.
+//
+// The handler runs on the server's goroutine, from which the test mustn't be
+// stopped with FailNow, so problems are reported with Errorf and a 500
+// instead of with assert.
+//
+// The client's transport is also installed as http.DefaultTransport for the
+// duration of the test so that sync functions, which build their own clients,
+// use it too.
+func newFixtureClient(t *testing.T, fixtures map[string]string) *http.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fail := func(format string, args ...interface{}) {
+			t.Errorf(format, args...)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		if err := r.ParseForm(); err != nil {
+			fail("error parsing form of request %v: %v", r.URL, err)
+			return
+		}
+
+		var bestFixture string
+		bestNumParams := -1
+
+		for key, fixture := range fixtures {
+			keyURL, err := url.Parse(key)
+			if err != nil {
+				fail("error parsing fixture key %q: %v", key, err)
+				return
+			}
+
+			if keyURL.Path != r.URL.Path {
+				continue
+			}
+
+			keyQuery := keyURL.Query()
+			matches := true
+			for name := range keyQuery {
+				if keyQuery.Get(name) != r.Form.Get(name) {
+					matches = false
+					break
+				}
+			}
+
+			if matches && len(keyQuery) > bestNumParams {
+				bestFixture = fixture
+				bestNumParams = len(keyQuery)
+			}
+		}
+
+		if bestNumParams < 0 {
+			fail("no fixture for request: %v", r.URL)
+			return
+		}
+
+		status := http.StatusOK
+		if code, path, ok := strings.Cut(bestFixture, " "); ok {
+			var err error
+			status, err = strconv.Atoi(code)
+			if err != nil {
+				fail("error parsing status of fixture %q: %v", bestFixture, err)
+				return
+			}
+			bestFixture = path
+		}
+
+		data, err := ioutil.ReadFile(bestFixture)
+		if err != nil {
+			fail("error reading fixture: %v", err)
+			return
+		}
+
+		w.WriteHeader(status)
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+
+	serverTransport := server.Client().Transport
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = serverURL.Scheme
+		req.URL.Host = serverURL.Host
+		return serverTransport.RoundTrip(req)
+	})
+
+	defaultTransport := http.DefaultTransport
+	t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+	http.DefaultTransport = transport
+
+	return &http.Client{Transport: transport}
+}
+
+// Returns SyncAllOptions with the path of every sync set to "PATH", which
+// skips it. Tests set the paths of the syncs that they want to run.
+func newSyncAllOptions() *SyncAllOptions {
+	return &SyncAllOptions{
+		BeeminderPath:          "PATH",
+		CalPath:                "PATH",
+		ChessPath:              "PATH",
+		ClockifyPath:           "PATH",
+		CloudflarePath:         "PATH",
+		ExistPath:              "PATH",
+		GoodreadsPath:          "PATH",
+		GooglePhotosPath:       "PATH",
+		LinkedInArticlesPath:   "PATH",
+		LinkedInPath:           "PATH",
+		MediumPath:             "PATH",
+		MonzoPath:              "PATH",
+		NomadListPath:          "PATH",
+		OuraReadinessPath:      "PATH",
+		OuraSleepPath:          "PATH",
+		RunkeeperPath:          "PATH",
+		SteamPath:              "PATH",
+		StripeChargesPath:      "PATH",
+		StripePayoutsPath:      "PATH",
+		SubstackPath:           "PATH",
+		TelegramPath:           "PATH",
+		TogglPath:              "PATH",
+		TwitterLikesPath:       "PATH",
+		TwitterPath:            "PATH",
+		WakaTimePath:           "PATH",
+		WaniKaniPath:           "PATH",
+		WeatherOpenWeatherPath: "PATH",
+		WeatherPWSPath:         "PATH",
+		WithingsPath:           "PATH",
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func readLichessGamesFixture(t *testing.T, path string) []*LichessAPIGame {
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+
+	var games []*LichessAPIGame
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var game LichessAPIGame
+		assert.NoError(t, decoder.Decode(&game))
+		games = append(games, &game)
+	}
+
+	return games
 }
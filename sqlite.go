@@ -0,0 +1,509 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+const (
+	// sqliteHeaderSize is the size of the header at the start of a SQLite
+	// database file, which also sits at the start of its first page.
+	sqliteHeaderSize = 100
+
+	// sqliteMaxDepth is the deepest a table's b-tree is descended before
+	// the database is considered corrupt. Trees are never nearly this deep,
+	// but a corrupt file could otherwise point pages at each other forever.
+	sqliteMaxDepth = 64
+
+	sqlitePageTypeInteriorTable = 0x05
+	sqlitePageTypeLeafTable     = 0x0d
+)
+
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// Columns of the sqlite_schema table, which describes every table and index
+// in a database and is rooted at the first page.
+var sqliteSchemaColumns = []*SQLiteColumn{
+	{Name: "type"},
+	{Name: "name"},
+	{Name: "tbl_name"},
+	{Name: "rootpage"},
+	{Name: "sql"},
+}
+
+// SQLiteColumn is a column of a SQLite table as declared in its CREATE TABLE
+// statement.
+type SQLiteColumn struct {
+	Name string
+
+	// Real is whether the column has REAL affinity. SQLite stores real
+	// values without a fractional part as integers to save space, and they
+	// have to be converted back when read.
+	Real bool
+
+	// RowID is whether the column is an INTEGER PRIMARY KEY. It's an alias
+	// for the row ID, so its value isn't stored in records.
+	RowID bool
+}
+
+// SQLiteDB is a minimal read-only reader for SQLite database files. It
+// supports only what's needed to import data from files like a Kobo's
+// KoboReader.sqlite: reading every row of an ordinary table. Indexes, views,
+// and WITHOUT ROWID tables aren't supported, and a database with changes
+// still in a write-ahead log is refused rather than read without them.
+type SQLiteDB struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+}
+
+// SQLiteRow is a single row of a SQLite table keyed by column name. Values
+// are nil, int64, float64, string, or []byte depending on their storage
+// class.
+type SQLiteRow map[string]interface{}
+
+// Int returns the value of an integer column, or 0 if it's missing or not an
+// integer.
+func (r SQLiteRow) Int(column string) int64 {
+	i, _ := r[column].(int64)
+	return i
+}
+
+// String returns the value of a text column, or an empty string if it's
+// missing or not text.
+func (r SQLiteRow) String(column string) string {
+	s, _ := r[column].(string)
+	return s
+}
+
+// openSQLiteDB reads the SQLite database at path into memory.
+func openSQLiteDB(path string) (*SQLiteDB, error) {
+	// Changes in a write-ahead log haven't been written to the database file
+	// yet, and would silently be missed.
+	if info, err := os.Stat(path + "-wal"); err == nil && info.Size() > 0 {
+		return nil, fmt.Errorf("'%s' has changes in a write-ahead log that aren't in the database yet", path)
+	} else if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSQLiteDB(data)
+}
+
+func newSQLiteDB(data []byte) (*SQLiteDB, error) {
+	if len(data) < sqliteHeaderSize || !bytes.Equal(data[:len(sqliteMagic)], sqliteMagic) {
+		return nil, errors.New("not a SQLite database")
+	}
+
+	// A page size of 1 stands for 65536, which doesn't fit in two bytes.
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid SQLite page size: %v", pageSize)
+	}
+
+	if encoding := binary.BigEndian.Uint32(data[56:60]); encoding != 0 && encoding != 1 {
+		return nil, fmt.Errorf("unsupported SQLite text encoding: %v (only UTF-8 is supported)", encoding)
+	}
+
+	return &SQLiteDB{
+		data:       data,
+		pageSize:   pageSize,
+		usableSize: pageSize - int(data[20]),
+	}, nil
+}
+
+// ReadTable reads every row of the table with the given name.
+func (db *SQLiteDB) ReadTable(name string) ([]SQLiteRow, error) {
+	schema, err := db.readRows(1, sqliteSchemaColumns)
+	if err != nil {
+		return nil, fmt.Errorf("error reading SQLite schema: %w", err)
+	}
+
+	for _, object := range schema {
+		if object.String("type") != "table" || !strings.EqualFold(object.String("name"), name) {
+			continue
+		}
+
+		columns, err := parseSQLiteCreateTable(object.String("sql"))
+		if err != nil {
+			return nil, fmt.Errorf("error reading schema of SQLite table '%s': %w", name, err)
+		}
+
+		rows, err := db.readRows(int(object.Int("rootpage")), columns)
+		if err != nil {
+			return nil, fmt.Errorf("error reading SQLite table '%s': %w", name, err)
+		}
+
+		return rows, nil
+	}
+
+	return nil, fmt.Errorf("SQLite table '%s' not found", name)
+}
+
+// Returns the page with the given number, which starts at 1.
+func (db *SQLiteDB) page(number int) ([]byte, error) {
+	if number < 1 || number*db.pageSize > len(db.data) {
+		return nil, fmt.Errorf("SQLite page %v out of range", number)
+	}
+
+	return db.data[(number-1)*db.pageSize : number*db.pageSize][:db.usableSize], nil
+}
+
+// Reads the payload of a leaf table cell of the given size, following
+// overflow pages if it doesn't fit in the cell.
+func (db *SQLiteDB) payload(page []byte, offset int, size int64) ([]byte, error) {
+	// How much of a payload is stored in its cell before spilling onto
+	// overflow pages is defined by the file format.
+	if size < 0 || size > int64(len(db.data)) {
+		return nil, errors.New("SQLite payload size out of range")
+	}
+
+	maxLocal := int64(db.usableSize - 35)
+	local := size
+	if size > maxLocal {
+		minLocal := int64((db.usableSize-12)*32/255 - 23)
+		local = minLocal + (size-minLocal)%int64(db.usableSize-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+
+	if offset+int(local) > len(page) {
+		return nil, errors.New("SQLite cell out of range")
+	}
+
+	payload := make([]byte, 0, size)
+	payload = append(payload, page[offset:offset+int(local)]...)
+	if local == size {
+		return payload, nil
+	}
+
+	if offset+int(local)+4 > len(page) {
+		return nil, errors.New("SQLite cell out of range")
+	}
+	overflow := int(binary.BigEndian.Uint32(page[offset+int(local):]))
+
+	for int64(len(payload)) < size {
+		overflowPage, err := db.page(overflow)
+		if err != nil {
+			return nil, err
+		}
+
+		n := int64(len(overflowPage) - 4)
+		if remaining := size - int64(len(payload)); remaining < n {
+			n = remaining
+		}
+		payload = append(payload, overflowPage[4:4+n]...)
+		overflow = int(binary.BigEndian.Uint32(overflowPage))
+	}
+
+	return payload, nil
+}
+
+// Reads every row of the table b-tree rooted at the given page.
+func (db *SQLiteDB) readRows(rootPage int, columns []*SQLiteColumn) ([]SQLiteRow, error) {
+	var rows []SQLiteRow
+
+	var walk func(number, depth int) error
+	walk = func(number, depth int) error {
+		if depth > sqliteMaxDepth {
+			return errors.New("SQLite b-tree too deep")
+		}
+
+		page, err := db.page(number)
+		if err != nil {
+			return err
+		}
+
+		// The first page starts with the database header rather than its
+		// b-tree page header, but cell offsets are still from the page's
+		// start.
+		header := page
+		if number == 1 {
+			header = page[sqliteHeaderSize:]
+		}
+
+		numCells := int(binary.BigEndian.Uint16(header[3:5]))
+
+		switch header[0] {
+		case sqlitePageTypeInteriorTable:
+			cellPointers := header[12:]
+			if len(cellPointers) < numCells*2 {
+				return errors.New("SQLite cell pointers out of range")
+			}
+
+			for i := 0; i < numCells; i++ {
+				offset := int(binary.BigEndian.Uint16(cellPointers[i*2:]))
+				if offset+4 > len(page) {
+					return errors.New("SQLite cell out of range")
+				}
+
+				if err := walk(int(binary.BigEndian.Uint32(page[offset:])), depth+1); err != nil {
+					return err
+				}
+			}
+
+			return walk(int(binary.BigEndian.Uint32(header[8:12])), depth+1)
+
+		case sqlitePageTypeLeafTable:
+			cellPointers := header[8:]
+			if len(cellPointers) < numCells*2 {
+				return errors.New("SQLite cell pointers out of range")
+			}
+
+			for i := 0; i < numCells; i++ {
+				offset := int(binary.BigEndian.Uint16(cellPointers[i*2:]))
+				if offset >= len(page) {
+					return errors.New("SQLite cell out of range")
+				}
+
+				size, n := sqliteVarint(page[offset:])
+				if n == 0 {
+					return errors.New("SQLite cell out of range")
+				}
+				offset += n
+
+				rowID, n := sqliteVarint(page[offset:])
+				if n == 0 {
+					return errors.New("SQLite cell out of range")
+				}
+				offset += n
+
+				payload, err := db.payload(page, offset, size)
+				if err != nil {
+					return err
+				}
+
+				values, err := parseSQLiteRecord(payload)
+				if err != nil {
+					return err
+				}
+
+				// Rows written before columns were added with ALTER TABLE
+				// have fewer values than there are columns, and the missing
+				// ones are left nil.
+				row := make(SQLiteRow, len(columns))
+				for j, column := range columns {
+					var value interface{}
+					if j < len(values) {
+						value = values[j]
+					}
+
+					switch i, ok := value.(int64); {
+					case column.RowID && value == nil:
+						value = rowID
+					case column.Real && ok:
+						value = float64(i)
+					}
+
+					row[column.Name] = value
+				}
+				rows = append(rows, row)
+			}
+
+			return nil
+
+		default:
+			return fmt.Errorf("unsupported SQLite page type: %#x", header[0])
+		}
+	}
+
+	if err := walk(rootPage, 0); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// Parses the columns of a table from its CREATE TABLE statement.
+func parseSQLiteCreateTable(sql string) ([]*SQLiteColumn, error) {
+	start := strings.Index(sql, "(")
+	end := strings.LastIndex(sql, ")")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("unexpected CREATE TABLE statement: %s", sql)
+	}
+
+	if strings.Contains(strings.ToUpper(sql[end:]), "WITHOUT ROWID") {
+		return nil, errors.New("WITHOUT ROWID tables aren't supported")
+	}
+
+	// Split definitions on commas that aren't nested in parentheses like
+	// those of a DECIMAL(10,5) type or a CHECK constraint, or quoted.
+	var definitions []string
+	var depth int
+	var quote rune
+	last := start + 1
+	for i, r := range sql[start+1 : end] {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == '[':
+			quote = ']'
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			definitions = append(definitions, sql[last:start+1+i])
+			last = start + 1 + i + 1
+		}
+	}
+	definitions = append(definitions, sql[last:end])
+
+	var columns []*SQLiteColumn
+	for _, definition := range definitions {
+		definition = strings.TrimSpace(definition)
+		if definition == "" {
+			continue
+		}
+
+		// Table constraints come after columns.
+		upper := strings.ToUpper(definition)
+		if strings.HasPrefix(upper, "CONSTRAINT") || strings.HasPrefix(upper, "PRIMARY KEY") ||
+			strings.HasPrefix(upper, "UNIQUE") || strings.HasPrefix(upper, "CHECK") ||
+			strings.HasPrefix(upper, "FOREIGN KEY") {
+			break
+		}
+
+		var name, rest string
+		switch definition[0] {
+		case '"', '\'', '`', '[':
+			closing := definition[0]
+			if closing == '[' {
+				closing = ']'
+			}
+			i := strings.IndexByte(definition[1:], closing)
+			if i == -1 {
+				return nil, fmt.Errorf("unterminated column name: %s", definition)
+			}
+			name, rest = definition[1:i+1], definition[i+2:]
+		default:
+			name = strings.Fields(definition)[0]
+			rest = definition[len(name):]
+		}
+
+		// Affinity comes from the names in the column's type. Constraints
+		// after it are included, but apart from a default value they won't
+		// contain any.
+		declaration := strings.Join(strings.Fields(strings.ToUpper(rest)), " ")
+		declaredType := declaration
+		if i := strings.Index(declaredType, "DEFAULT"); i != -1 {
+			declaredType = declaredType[:i]
+		}
+
+		columns = append(columns, &SQLiteColumn{
+			Name: name,
+			Real: !strings.Contains(declaredType, "INT") && !strings.Contains(declaredType, "CHAR") &&
+				!strings.Contains(declaredType, "CLOB") && !strings.Contains(declaredType, "TEXT") &&
+				(strings.Contains(declaredType, "REAL") || strings.Contains(declaredType, "FLOA") ||
+					strings.Contains(declaredType, "DOUB")),
+			RowID: strings.HasPrefix(declaration, "INTEGER PRIMARY KEY"),
+		})
+	}
+
+	return columns, nil
+}
+
+// Parses the values of a record, the format in which SQLite stores a row.
+func parseSQLiteRecord(payload []byte) ([]interface{}, error) {
+	// The header's size includes the varint it's stored in.
+	headerSize, n := sqliteVarint(payload)
+	if n == 0 || headerSize < int64(n) || headerSize > int64(len(payload)) {
+		return nil, errors.New("SQLite record header out of range")
+	}
+
+	var serialTypes []int64
+	for offset := n; offset < int(headerSize); {
+		serialType, n := sqliteVarint(payload[offset:headerSize])
+		if n == 0 {
+			return nil, errors.New("SQLite record header out of range")
+		}
+		serialTypes = append(serialTypes, serialType)
+		offset += n
+	}
+
+	body := payload[headerSize:]
+	values := make([]interface{}, len(serialTypes))
+	for i, serialType := range serialTypes {
+		var size int
+		switch {
+		case serialType >= 1 && serialType <= 4:
+			size = int(serialType)
+		case serialType == 5:
+			size = 6
+		case serialType == 6 || serialType == 7:
+			size = 8
+		case serialType >= 12:
+			size = int(serialType-12) / 2
+		}
+
+		if size > len(body) {
+			return nil, errors.New("SQLite record value out of range")
+		}
+		value := body[:size]
+		body = body[size:]
+
+		switch {
+		case serialType == 0:
+			values[i] = nil
+		case serialType >= 1 && serialType <= 6:
+			// Integers are big-endian two's complement of varying sizes.
+			var v int64
+			for _, b := range value {
+				v = v<<8 | int64(b)
+			}
+			shift := 64 - 8*uint(size)
+			values[i] = v << shift >> shift
+		case serialType == 7:
+			values[i] = math.Float64frombits(binary.BigEndian.Uint64(value))
+		case serialType == 8:
+			values[i] = int64(0)
+		case serialType == 9:
+			values[i] = int64(1)
+		case serialType >= 12 && serialType%2 == 0:
+			values[i] = append([]byte(nil), value...)
+		case serialType >= 13:
+			values[i] = string(value)
+		default:
+			return nil, fmt.Errorf("unsupported SQLite serial type: %v", serialType)
+		}
+	}
+
+	return values, nil
+}
+
+// Decodes a SQLite variable-length integer, returning it and the number of
+// bytes read. These are big-endian and up to nine bytes long. The high bit of
+// each of the first eight bytes is set when another follows, and the ninth
+// contributes all eight of its bits. Zero bytes are read if b ends before the
+// varint does.
+func sqliteVarint(b []byte) (int64, int) {
+	var v uint64
+	for i := 0; i < len(b) && i < 9; i++ {
+		if i == 8 {
+			return int64(v<<8 | uint64(b[i])), 9
+		}
+
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return int64(v), i + 1
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,56 @@
+-- Source of kobo.sqlite, a cut down KoboReader.sqlite with only the tables
+-- qself reads. They're declared as Kobo's firmware declares them. Regenerate
+-- with:
+--
+--     rm kobo.sqlite && sqlite3 kobo.sqlite < kobo.sql
+--
+-- The small page size spreads bookmarks across several pages, and a long note
+-- spills onto overflow pages.
+
+PRAGMA page_size = 1024;
+
+CREATE TABLE content (ContentID TEXT NOT NULL, ContentType TEXT NOT NULL, MimeType TEXT NOT NULL, BookID TEXT, BookTitle TEXT, ImageId TEXT, Title TEXT COLLATE NOCASE, Attribution TEXT COLLATE NOCASE, Description TEXT, DateCreated TEXT, ShortCoverKey TEXT, adobe_location TEXT, Publisher TEXT, IsEncrypted BOOL, DateLastRead TEXT, FirstTimeReading BOOL, ChapterIDBookmarked TEXT, ParagraphBookmarked INTEGER, BookmarkWordOffset INTEGER, NumShortcovers INTEGER, VolumeIndex INTEGER, ___NumPages INTEGER, ReadStatus INTEGER, ___SyncTime TEXT, ___UserID TEXT NOT NULL, PublicationId TEXT, ___FileOffset INTEGER, ___FileSize INTEGER, ___PercentRead INTEGER, ___ExpirationStatus INTEGER, FavouritesIndex NUMERIC NOT NULL DEFAULT -1, Accessibility INTEGER DEFAULT 1, ContentURL TEXT, Language TEXT, BookshelfTags TEXT, IsDownloaded BIT NOT NULL DEFAULT 1, FeedbackType INTEGER DEFAULT 0, AverageRating INTEGER DEFAULT 0, Depth INTEGER, PageProgressDirection TEXT, InWishlist TEXT NOT NULL DEFAULT 'FALSE', ISBN TEXT, WishlistedDate TEXT NOT NULL DEFAULT '0000-00-00T00:00:00.000', FeedbackTypeSynced INTEGER NOT NULL DEFAULT 0, IsSocialEnabled TEXT NOT NULL DEFAULT 'true', EpubType INTEGER DEFAULT -1, Monetization INTEGER DEFAULT 2, ExternalId TEXT, Series TEXT, SeriesNumber TEXT, Subtitle TEXT, WordCount INTEGER DEFAULT -1, Fallback TEXT, RestOfBookEstimate INTEGER, CurrentChapterEstimate INTEGER, CurrentChapterProgress FLOAT, PocketStatus INTEGER DEFAULT 0, UnsyncedPocketChanges TEXT, ImageUrl TEXT, DateAdded TEXT, WorkId TEXT, Properties TEXT, RenditionSpread TEXT, RatingCount INTEGER DEFAULT 0, ReviewsSyncDate TEXT, MediaOverlay TEXT, MediaOverlayType TEXT, RedirectPreviewUrl BOOL, PreviewFileSize INTEGER, EntitlementId TEXT, CrossRevisionId TEXT, DownloadUrl BOOL, ReadStateSynced BOOL DEFAULT false, TimesStartedReading INTEGER, TimeSpentReading INTEGER, LastTimeStartedReading TEXT, LastTimeFinishedReading TEXT, ApplicableSubscriptions TEXT, ExternalIds TEXT, PurchaseRevisionId TEXT, SeriesID TEXT, SeriesNumberFloat REAL, AdobeLoanExpiration TEXT, HideFromHomePage BOOL, IsInternetArchive BOOL, titleKana TEXT, subtitleKana TEXT, seriesKana TEXT, attributionKana TEXT, publisherKana TEXT, IsPurchaseable BOOL, IsSupported BOOL, AnnotationsSyncToken TEXT, DateModified TEXT, PRIMARY KEY (ContentID));
+
+INSERT INTO content (ContentID, ContentType, MimeType, BookTitle, Title, Attribution, ___UserID, ReadStatus, ___PercentRead, TimeSpentReading) VALUES ('file:///mnt/onboard/dune.epub', '6', 'application/epub+zip', NULL, 'Dune', 'Frank Herbert', 'adobe_user', 1, 12, 2730);
+INSERT INTO content (ContentID, ContentType, MimeType, BookID, BookTitle, Title, ___UserID) VALUES ('file:///mnt/onboard/dune.epub#(3)OEBPS/ch01.html', '899', 'application/xhtml+xml', 'file:///mnt/onboard/dune.epub', 'Dune', 'Book One: Dune', 'adobe_user');
+INSERT INTO content (ContentID, ContentType, MimeType, BookTitle, Title, Attribution, ___UserID, ReadStatus, ___PercentRead, TimeSpentReading) VALUES ('file:///mnt/onboard/walden.epub', '6', 'application/epub+zip', NULL, 'Walden', 'Henry David Thoreau', 'adobe_user', 1, 40, 1200);
+
+-- Created as older firmware did. Later columns are added further down with
+-- ALTER TABLE, the way firmware updates add them.
+CREATE TABLE Bookmark (BookmarkID TEXT NOT NULL, VolumeID TEXT NOT NULL, ContentID TEXT NOT NULL, StartContainerPath TEXT NOT NULL, StartContainerChildIndex INTEGER NOT NULL, StartOffset INTEGER NOT NULL, EndContainerPath TEXT NOT NULL, EndContainerChildIndex INTEGER NOT NULL, EndOffset INTEGER NOT NULL, Text TEXT, Annotation TEXT, ExtraAnnotationData BLOB, DateCreated TEXT, ChapterProgress REAL NOT NULL DEFAULT 0, Hidden BOOL NOT NULL DEFAULT 0, Version TEXT, DateModified TEXT, Creator TEXT, UUID TEXT, UserID TEXT, SyncTime TEXT, Published BIT DEFAULT false, PRIMARY KEY (BookmarkID));
+
+INSERT INTO Bookmark (BookmarkID, VolumeID, ContentID, StartContainerPath, StartContainerChildIndex, StartOffset, EndContainerPath, EndContainerChildIndex, EndOffset, Text, DateCreated, ChapterProgress, Hidden) VALUES ('5e1c1d0a-0001', 'file:///mnt/onboard/dune.epub', 'file:///mnt/onboard/dune.epub#(3)OEBPS/ch01.html', 'span#kobo\.1\.1', -99, 0, 'span#kobo\.1\.2', -99, 41, ' I must not fear. Fear is the mind-killer. ', '2021-03-04T05:06:07.000', 0.1, 'false');
+
+-- Enough highlights to need more than one page.
+WITH RECURSIVE n(i) AS (SELECT 2 UNION ALL SELECT i + 1 FROM n WHERE i < 40)
+INSERT INTO Bookmark (BookmarkID, VolumeID, ContentID, StartContainerPath, StartContainerChildIndex, StartOffset, EndContainerPath, EndContainerChildIndex, EndOffset, Text, DateCreated, ChapterProgress, Hidden)
+SELECT printf('5e1c1d0a-%04d', i), 'file:///mnt/onboard/walden.epub', 'file:///mnt/onboard/walden.epub', 'span#kobo\.2\.1', -99, 0, 'span#kobo\.2\.2', -99, 60, printf('I went to the woods because I wished to live deliberately (%d).', i), printf('2021-04-01T10:%02d:00Z', i), 0.5, 'false'
+FROM n;
+
+-- A dog-ear, which has no text.
+INSERT INTO Bookmark (BookmarkID, VolumeID, ContentID, StartContainerPath, StartContainerChildIndex, StartOffset, EndContainerPath, EndContainerChildIndex, EndOffset, DateCreated, ChapterProgress, Hidden) VALUES ('5e1c1d0a-0041', 'file:///mnt/onboard/walden.epub', 'file:///mnt/onboard/walden.epub', 'span#kobo\.3\.1', -99, 0, 'span#kobo\.3\.1', -99, 0, '2021-04-02T10:00:00Z', 0.6, 'false');
+
+-- An invalid creation time.
+INSERT INTO Bookmark (BookmarkID, VolumeID, ContentID, StartContainerPath, StartContainerChildIndex, StartOffset, EndContainerPath, EndContainerChildIndex, EndOffset, Text, DateCreated, ChapterProgress, Hidden) VALUES ('5e1c1d0a-0042', 'file:///mnt/onboard/walden.epub', 'file:///mnt/onboard/walden.epub', 'span#kobo\.4\.1', -99, 0, 'span#kobo\.4\.2', -99, 19, 'Simplify, simplify.', 'yesterday', 0.7, 'false');
+
+ALTER TABLE Bookmark ADD COLUMN ContextString TEXT;
+ALTER TABLE Bookmark ADD COLUMN Type TEXT;
+
+-- Rows from before the columns were added are missing them.
+INSERT INTO Bookmark (BookmarkID, VolumeID, ContentID, StartContainerPath, StartContainerChildIndex, StartOffset, EndContainerPath, EndContainerChildIndex, EndOffset, Text, Annotation, DateCreated, ChapterProgress, Hidden, Type) VALUES ('5e1c1d0a-0043', 'file:///mnt/onboard/walden.epub', 'file:///mnt/onboard/walden.epub', 'span#kobo\.5\.1', -99, 0, 'span#kobo\.5\.2', -99, 37, 'Our life is frittered away by detail.', printf('%.3000c', 'x'), '2021-04-03 10:00:00', 0.8, 'false', 'note');
+
+-- A highlight that was deleted on the device, which hides it instead of
+-- removing it until it's synced with Kobo.
+INSERT INTO Bookmark (BookmarkID, VolumeID, ContentID, StartContainerPath, StartContainerChildIndex, StartOffset, EndContainerPath, EndContainerChildIndex, EndOffset, Text, DateCreated, ChapterProgress, Hidden, Type) VALUES ('5e1c1d0a-0044', 'file:///mnt/onboard/walden.epub', 'file:///mnt/onboard/walden.epub', 'span#kobo\.6\.1', -99, 0, 'span#kobo\.6\.2', -99, 25, 'Beware of all enterprises that require new clothes.', '2021-04-04T10:00:00Z', 0.9, 'true', 'highlight');
+
+CREATE TABLE AnalyticsEvents (Id TEXT NOT NULL, Type TEXT NOT NULL, Count INTEGER NOT NULL DEFAULT 1, Timestamp TEXT NOT NULL, Attributes TEXT, Metrics TEXT, PRIMARY KEY (Id));
+
+INSERT INTO AnalyticsEvents VALUES ('8a7f0e3c-0001', 'OpenContent', 1, '2021-03-04T05:00:00Z', '{"volumeid":"file:///mnt/onboard/dune.epub","ContentType":"6"}', '{}');
+INSERT INTO AnalyticsEvents VALUES ('8a7f0e3c-0002', 'LeaveContent', 1, '2021-03-04T05:45:30Z', '{"volumeid":"file:///mnt/onboard/dune.epub","ContentType":"6","progress":"12"}', '{"SecondsRead":"2730","PagesTurned":"41","IdleTime":"0","ButtonPressCount":"0"}');
+INSERT INTO AnalyticsEvents VALUES ('8a7f0e3c-0003', 'LeaveContent', 1, '2021-04-01T10:20:00.000', '{"volumeid":"file:///mnt/onboard/walden.epub","ContentType":"6","progress":"40"}', '{"SecondsRead":1200,"PagesTurned":18}');
+
+-- Left without reading anything.
+INSERT INTO AnalyticsEvents VALUES ('8a7f0e3c-0004', 'LeaveContent', 1, '2021-04-02T09:00:00Z', '{"volumeid":"file:///mnt/onboard/walden.epub","ContentType":"6","progress":"40"}', '{"SecondsRead":"0","PagesTurned":"0"}');
+
+-- Metrics without the time spent reading.
+INSERT INTO AnalyticsEvents VALUES ('8a7f0e3c-0005', 'LeaveContent', 1, '2021-04-02T10:00:00Z', '{"volumeid":"file:///mnt/onboard/walden.epub","ContentType":"6","progress":"40"}', '{"PagesTurned":"3"}');